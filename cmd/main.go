@@ -6,9 +6,11 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/colinjuang/shop-go/internal/app/job"
 	"github.com/colinjuang/shop-go/internal/app/router"
 	"github.com/colinjuang/shop-go/internal/config"
 	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/scheduler"
 	"github.com/colinjuang/shop-go/internal/server"
 )
 
@@ -30,6 +32,11 @@ func main() {
 	// 创建 router
 	r := router.NewRouter(cfg)
 
+	// 启动后台任务
+	sched := scheduler.New()
+	job.RegisterJobs(sched)
+	sched.Start()
+
 	// 创建 server 错误通道
 	serverErrors := make(chan error, 1)
 
@@ -57,6 +64,9 @@ func main() {
 	case sig := <-shutdown:
 		fmt.Printf("Received shutdown signal: %v\n", sig)
 
+		// 停止后台任务
+		sched.Stop()
+
 		// 执行优雅关闭
 		if err := srv.Shutdown(); err != nil {
 			fmt.Printf("Graceful shutdown failed: %v\n", err)
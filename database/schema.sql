@@ -13,6 +13,10 @@ CREATE TABLE IF NOT EXISTS `users` (
   `city` varchar(50) DEFAULT NULL COMMENT '城市',
   `province` varchar(50) DEFAULT NULL COMMENT '省份',
   `district` varchar(50) DEFAULT NULL COMMENT '区县',
+  `role` tinyint(1) NOT NULL DEFAULT 1 COMMENT '角色：1普通用户，2管理员',
+  `points` int(11) NOT NULL DEFAULT 0 COMMENT '积分余额',
+  `tier_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '会员等级ID，0表示非会员',
+  `wallet` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '钱包余额（分销佣金）',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
@@ -41,12 +45,15 @@ CREATE TABLE IF NOT EXISTS `categories` (
   `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
   `name` varchar(100) NOT NULL COMMENT '分类名称',
   `parent_id` int(10) unsigned DEFAULT 0 COMMENT '父分类ID',
+  `path` varchar(255) NOT NULL DEFAULT '' COMMENT '从一级分类到本分类的ID路径，如 /1/5/12/',
+  `level` int(10) unsigned NOT NULL DEFAULT 1 COMMENT '层级，一级分类为1',
   `image` varchar(255) DEFAULT NULL COMMENT '分类图片',
   `sort_order` int(10) unsigned DEFAULT 0 COMMENT '排序',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
-  KEY `idx_parent_id` (`parent_id`)
+  KEY `idx_parent_id` (`parent_id`),
+  KEY `idx_path` (`path`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品分类表';
 
 -- 商品表
@@ -56,10 +63,13 @@ CREATE TABLE IF NOT EXISTS `products` (
   `description` text DEFAULT NULL COMMENT '商品描述',
   `price` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '商品价格',
   `stock` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '库存数量',
-  `category_id` int(10) unsigned NOT NULL COMMENT '分类ID',
+  `sale_count` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '销量，付款时增加，退款时扣回',
+  `category_id` int(10) unsigned NOT NULL COMMENT '分类ID，可以是任意层级',
+  `category_path` varchar(255) NOT NULL DEFAULT '' COMMENT '所属分类的路径，用于查询分类及其子孙分类的商品',
   `images` text DEFAULT NULL COMMENT '商品图片，逗号分隔',
   `main_image` varchar(255) DEFAULT NULL COMMENT '主图',
   `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态：1上架，0下架',
+  `is_bundle` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否组合商品，库存由组件库存决定',
   `hot` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否热销',
   `recommend` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否推荐',
   `sort_order` int(10) unsigned DEFAULT 0 COMMENT '排序',
@@ -67,6 +77,7 @@ CREATE TABLE IF NOT EXISTS `products` (
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   KEY `idx_category_id` (`category_id`),
+  KEY `idx_category_path` (`category_path`),
   KEY `idx_hot` (`hot`),
   KEY `idx_recommend` (`recommend`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品表';
@@ -74,26 +85,65 @@ CREATE TABLE IF NOT EXISTS `products` (
 -- 轮播图表
 CREATE TABLE IF NOT EXISTS `banners` (
   `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
-  `image` varchar(255) NOT NULL COMMENT '图片URL',
-  `link` varchar(255) DEFAULT NULL COMMENT '链接URL',
+  `title` varchar(100) NOT NULL COMMENT '标题',
+  `image_url` varchar(255) NOT NULL COMMENT '图片URL',
+  `link_type` tinyint(1) NOT NULL DEFAULT '0' COMMENT '0: 不跳转, 1: 商品, 2: 分类, 3: 促销活动, 4: 小程序页面, 5: 其他小程序',
+  `link_value` varchar(255) DEFAULT NULL COMMENT '商品、分类、促销ID或页面路径',
+  `link_app_id` varchar(64) DEFAULT NULL COMMENT '跳转其他小程序时的AppID',
+  `start_at` datetime DEFAULT NULL COMMENT '开始展示时间，为空表示立即',
+  `end_at` datetime DEFAULT NULL COMMENT '结束展示时间，为空表示不结束',
+  `audience` tinyint(1) NOT NULL DEFAULT '0' COMMENT '0: 全部用户, 1: 新用户, 2: 会员',
+  `cities` varchar(500) DEFAULT NULL COMMENT '展示的城市，逗号分隔，为空表示不限',
+  `status` tinyint(1) NOT NULL DEFAULT '1' COMMENT '0: 停用, 1: 启用',
   `sort_order` int(10) unsigned DEFAULT 0 COMMENT '排序',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  PRIMARY KEY (`id`)
+  PRIMARY KEY (`id`),
+  KEY `idx_start_at` (`start_at`),
+  KEY `idx_end_at` (`end_at`),
+  KEY `idx_status` (`status`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='首页轮播图表';
 
 -- 促销活动表
 CREATE TABLE IF NOT EXISTS `promotions` (
   `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
   `title` varchar(100) NOT NULL COMMENT '活动标题',
-  `image` varchar(255) NOT NULL COMMENT '活动图片',
-  `link` varchar(255) DEFAULT NULL COMMENT '活动链接',
-  `sort_order` int(10) unsigned DEFAULT 0 COMMENT '排序',
+  `image_url` varchar(255) DEFAULT NULL COMMENT '首页广告图片，为空时不展示',
+  `description` varchar(500) DEFAULT NULL COMMENT '活动说明',
+  `scope` tinyint(1) NOT NULL DEFAULT '0' COMMENT '0: 全部商品, 1: 指定分类, 2: 指定商品',
+  `rule_type` tinyint(1) NOT NULL DEFAULT '0' COMMENT '0: 仅广告, 1: 满减, 2: 满折, 3: 买N送M, 4: 第二件半价',
+  `threshold` decimal(10,2) NOT NULL DEFAULT '0.00' COMMENT '满减、满折的门槛金额',
+  `discount` decimal(10,2) NOT NULL DEFAULT '0.00' COMMENT '满减金额',
+  `rate` decimal(4,2) NOT NULL DEFAULT '0.00' COMMENT '满折折扣率，如0.8为八折',
+  `buy_qty` int(10) NOT NULL DEFAULT '0' COMMENT '买N送M的N',
+  `free_qty` int(10) NOT NULL DEFAULT '0' COMMENT '买N送M的M',
+  `repeatable` tinyint(1) NOT NULL DEFAULT '0' COMMENT '满减是否每满门槛减一次',
+  `exclusive` tinyint(1) NOT NULL DEFAULT '0' COMMENT '不与其他促销活动同时享受',
+  `coupon_stackable` tinyint(1) NOT NULL DEFAULT '0' COMMENT '可与优惠券叠加使用',
+  `priority` int(10) NOT NULL DEFAULT '0' COMMENT '叠加时优先级高的先计算',
+  `start_at` datetime DEFAULT NULL COMMENT '开始时间，为空表示立即',
+  `end_at` datetime DEFAULT NULL COMMENT '结束时间，为空表示不结束',
+  `status` tinyint(1) NOT NULL DEFAULT '1' COMMENT '0: 停用, 1: 启用',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  PRIMARY KEY (`id`)
+  PRIMARY KEY (`id`),
+  KEY `idx_start_at` (`start_at`),
+  KEY `idx_end_at` (`end_at`),
+  KEY `idx_status` (`status`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='促销活动表';
 
+-- 促销活动范围表
+CREATE TABLE IF NOT EXISTS `promotion_scopes` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `promotion_id` int(10) unsigned NOT NULL COMMENT '促销活动ID',
+  `category_id` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '指定分类时为分类ID',
+  `product_id` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '指定商品时为商品ID',
+  PRIMARY KEY (`id`),
+  KEY `idx_promotion_id` (`promotion_id`),
+  KEY `idx_category_id` (`category_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='促销活动范围表';
+
 -- 购物车表
 CREATE TABLE IF NOT EXISTS `cart_items` (
   `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
@@ -101,6 +151,8 @@ CREATE TABLE IF NOT EXISTS `cart_items` (
   `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
   `quantity` int(10) unsigned NOT NULL DEFAULT 1 COMMENT '数量',
   `selected` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否选中',
+  `blessing` varchar(200) NOT NULL DEFAULT '' COMMENT '祝福语，同一商品祝福语不同时为不同的购物车项',
+  `added_price` decimal(10,2) NOT NULL DEFAULT '0.00' COMMENT '加购时的商品价格',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
@@ -115,19 +167,31 @@ CREATE TABLE IF NOT EXISTS `orders` (
   `order_no` varchar(100) NOT NULL COMMENT '订单编号',
   `total_amount` decimal(10,2) NOT NULL COMMENT '订单总金额',
   `payment_amount` decimal(10,2) NOT NULL COMMENT '实付金额',
-  `status` tinyint(1) NOT NULL DEFAULT 0 COMMENT '订单状态：0待付款，1已付款，2已发货，3已完成，4已取消',
+  `member_discount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '会员优惠金额',
+  `promotion_discount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '促销活动优惠金额',
+  `shipping_fee` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '运费',
+  `points_used` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '使用积分',
+  `points_discount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '积分抵扣金额',
+  `refunded_amount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '已部分退款金额',
+  `status` tinyint(1) NOT NULL DEFAULT 0 COMMENT '订单状态：0待付款，1已付款，2已发货，3已完成，4已取消，5已退款，6拼团中，7已付定金',
   `payment_time` timestamp NULL DEFAULT NULL COMMENT '付款时间',
+  `completed_at` timestamp NULL DEFAULT NULL COMMENT '完成时间',
   `address_id` int(10) unsigned DEFAULT NULL COMMENT '地址ID',
   `receiver_name` varchar(50) DEFAULT NULL COMMENT '收货人姓名',
   `receiver_phone` varchar(20) DEFAULT NULL COMMENT '收货人电话',
   `address` varchar(255) DEFAULT NULL COMMENT '收货地址',
+  `group_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '拼团ID，0表示非拼团订单',
+  `pre_sale_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '预售活动ID，0表示非预售订单',
+  `delivery_date` date DEFAULT NULL COMMENT '期望配送日期，为空表示尽快配送',
   `payment_type` tinyint(1) NOT NULL DEFAULT 1 COMMENT '支付方式：1微信支付',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '订单创建时间',
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_order_no` (`order_no`),
   KEY `idx_user_id` (`user_id`),
-  KEY `idx_status` (`status`)
+  KEY `idx_status` (`status`),
+  KEY `idx_group_id` (`group_id`),
+  KEY `idx_pre_sale_id` (`pre_sale_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订单表';
 
 -- 订单商品表
@@ -136,16 +200,686 @@ CREATE TABLE IF NOT EXISTS `order_items` (
   `order_id` int(10) unsigned NOT NULL COMMENT '订单ID',
   `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
   `quantity` int(10) unsigned NOT NULL COMMENT '数量',
-  `price` decimal(10,2) NOT NULL COMMENT '价格',
+  `price` decimal(10,2) NOT NULL COMMENT '成交单价',
+  `original_price` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '商品原价',
   `name` varchar(200) NOT NULL COMMENT '商品名称',
   `image` varchar(255) DEFAULT NULL COMMENT '商品图片',
+  `is_bundle` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否组合商品行，库存由组件行扣减',
+  `parent_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '组件行所属的组合商品行ID，0表示非组件行',
+  `allocated` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '组件行分摊的组合商品成交金额',
+  `refunded_qty` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '已部分退款数量',
+  `promotion_discount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '整行的促销优惠合计',
+  `cart_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '来源购物车项ID，付款后从购物车扣除，0表示不是从购物车下单',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   KEY `idx_order_id` (`order_id`),
-  KEY `idx_product_id` (`product_id`)
+  KEY `idx_product_id` (`product_id`),
+  KEY `idx_parent_id` (`parent_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订单商品表';
 
+-- 积分流水表
+CREATE TABLE IF NOT EXISTS `points_ledgers` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `type` tinyint(2) NOT NULL COMMENT '类型：1订单完成，3首单奖励，4退款扣回，5下单抵扣，6抵扣退还，7过期，8人工调整',
+  `change` int(11) NOT NULL COMMENT '积分变动',
+  `balance` int(11) NOT NULL COMMENT '变动后余额',
+  `remaining` int(11) NOT NULL DEFAULT 0 COMMENT '剩余可用积分（仅增加类流水）',
+  `expire_at` datetime DEFAULT NULL COMMENT '过期时间',
+  `order_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '关联订单ID',
+  `operator_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '操作人ID',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_order_id` (`order_id`),
+  KEY `idx_expire_at` (`expire_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='积分流水表';
+
+-- 分类积分规则表
+CREATE TABLE IF NOT EXISTS `points_rules` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `category_id` int(10) unsigned NOT NULL COMMENT '分类ID',
+  `earn_rate` decimal(10,2) NOT NULL COMMENT '每消费1元获得的积分',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_category_id` (`category_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='分类积分规则表';
+
+-- 会员等级表
+CREATE TABLE IF NOT EXISTS `member_tiers` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(50) NOT NULL COMMENT '等级名称',
+  `level` int(10) NOT NULL COMMENT '等级，数值越大等级越高',
+  `min_spend` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '近12个月消费门槛',
+  `discount_rate` decimal(4,2) NOT NULL DEFAULT 1.00 COMMENT '会员折扣',
+  `free_shipping` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否包邮',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_level` (`level`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='会员等级表';
+
+-- 商品会员价表
+CREATE TABLE IF NOT EXISTS `product_member_prices` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `tier_id` int(10) unsigned NOT NULL COMMENT '会员等级ID',
+  `price` decimal(10,2) NOT NULL COMMENT '会员价',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_product_tier` (`product_id`, `tier_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品会员价表';
+
+-- 秒杀活动表
+CREATE TABLE IF NOT EXISTS `flash_sales` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `title` varchar(100) DEFAULT NULL COMMENT '活动标题',
+  `deal_price` decimal(10,2) NOT NULL COMMENT '秒杀价',
+  `quantity` int(10) unsigned NOT NULL COMMENT '秒杀总数量',
+  `sold` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '已生成订单数量',
+  `limit_per_user` int(10) unsigned NOT NULL DEFAULT 1 COMMENT '每人限购数量，0表示不限',
+  `start_time` datetime NOT NULL COMMENT '开始时间',
+  `end_time` datetime NOT NULL COMMENT '结束时间',
+  `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态：0停用，1启用',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`),
+  KEY `idx_start_time` (`start_time`),
+  KEY `idx_end_time` (`end_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='秒杀活动表';
+
+-- 秒杀订单表
+CREATE TABLE IF NOT EXISTS `flash_sale_orders` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `flash_sale_id` int(10) unsigned NOT NULL COMMENT '秒杀活动ID',
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `request_id` varchar(64) NOT NULL COMMENT '抢购请求ID',
+  `order_id` int(10) unsigned NOT NULL COMMENT '订单ID',
+  `quantity` int(10) unsigned NOT NULL COMMENT '购买数量',
+  `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态：1已下单，2已取消',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_request_id` (`request_id`),
+  KEY `idx_sale_user` (`flash_sale_id`, `user_id`),
+  KEY `idx_order_id` (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='秒杀订单表';
+
+-- 创建拼团活动表
+CREATE TABLE IF NOT EXISTS `group_buy_campaigns` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `title` varchar(100) DEFAULT NULL COMMENT '活动标题',
+  `group_price` decimal(10,2) NOT NULL COMMENT '拼团价',
+  `group_size` int(10) unsigned NOT NULL COMMENT '成团人数',
+  `time_limit` int(10) unsigned NOT NULL COMMENT '成团时限（分钟）',
+  `start_time` timestamp NOT NULL COMMENT '开始时间',
+  `end_time` timestamp NOT NULL COMMENT '结束时间',
+  `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态：1启用，0停用',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`),
+  KEY `idx_start_time` (`start_time`),
+  KEY `idx_end_time` (`end_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='拼团活动表';
+
+-- 创建拼团表
+CREATE TABLE IF NOT EXISTS `group_buy_groups` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `campaign_id` int(10) unsigned NOT NULL COMMENT '拼团活动ID',
+  `leader_id` int(10) unsigned NOT NULL COMMENT '团长用户ID',
+  `required_size` int(10) unsigned NOT NULL COMMENT '成团人数',
+  `paid_count` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '已支付人数',
+  `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态：1拼团中，2成功，3失败',
+  `expire_at` timestamp NOT NULL COMMENT '成团截止时间',
+  `completed_at` timestamp NULL DEFAULT NULL COMMENT '成团或失败时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_campaign_id` (`campaign_id`),
+  KEY `idx_leader_id` (`leader_id`),
+  KEY `idx_status_expire` (`status`, `expire_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='拼团表';
+
+-- 创建拼团成员表
+CREATE TABLE IF NOT EXISTS `group_buy_participants` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `group_id` int(10) unsigned NOT NULL COMMENT '拼团ID',
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `order_id` int(10) unsigned NOT NULL COMMENT '订单ID',
+  `is_leader` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否团长',
+  `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态：1待支付，2已支付，3已退款，4已取消',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_order_id` (`order_id`),
+  KEY `idx_group_user` (`group_id`, `user_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='拼团成员表';
+
+-- 创建预售活动表
+CREATE TABLE IF NOT EXISTS `pre_sales` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `title` varchar(100) DEFAULT NULL COMMENT '活动标题',
+  `pre_sale_price` decimal(10,2) NOT NULL COMMENT '预售价，含定金',
+  `deposit` decimal(10,2) NOT NULL COMMENT '每件定金',
+  `deposit_start` timestamp NOT NULL COMMENT '定金开始时间',
+  `deposit_end` timestamp NOT NULL COMMENT '定金截止时间',
+  `balance_start` timestamp NOT NULL COMMENT '尾款开始时间',
+  `balance_end` timestamp NOT NULL COMMENT '尾款截止时间',
+  `ship_at` timestamp NULL DEFAULT NULL COMMENT '预计发货时间',
+  `forfeit_policy` varchar(20) DEFAULT NULL COMMENT '尾款逾期定金处理：forfeit不退，refund退回，为空使用全局配置',
+  `status` tinyint(1) NOT NULL DEFAULT 1 COMMENT '状态：1启用，0停用',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`),
+  KEY `idx_deposit_end` (`deposit_end`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='预售活动表';
+
+-- 创建订单支付记录表
+CREATE TABLE IF NOT EXISTS `order_payments` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `order_id` int(10) unsigned NOT NULL COMMENT '订单ID',
+  `payment_no` varchar(100) NOT NULL COMMENT '支付单号',
+  `stage` tinyint(1) NOT NULL COMMENT '支付阶段：1定金，2尾款',
+  `amount` decimal(10,2) NOT NULL COMMENT '支付金额',
+  `status` tinyint(1) NOT NULL DEFAULT 0 COMMENT '状态：0待支付，1已支付，2已退款，3定金不退，4已关闭',
+  `due_start` timestamp NULL DEFAULT NULL COMMENT '支付开始时间',
+  `due_end` timestamp NOT NULL COMMENT '支付截止时间',
+  `paid_at` timestamp NULL DEFAULT NULL COMMENT '支付时间',
+  `reminded_at` timestamp NULL DEFAULT NULL COMMENT '催付提醒时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_payment_no` (`payment_no`),
+  KEY `idx_order_id` (`order_id`),
+  KEY `idx_stage_status_due` (`stage`, `status`, `due_end`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订单支付记录表';
+
+-- 创建周期订阅表
+CREATE TABLE IF NOT EXISTS `subscriptions` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `subscription_no` varchar(100) NOT NULL COMMENT '订阅单号，预付时作为支付单号',
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `product_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '指定商品ID，0表示花艺师自选',
+  `category_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '花艺师自选的分类ID',
+  `budget` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '花艺师自选每束预算',
+  `quantity` int(11) NOT NULL COMMENT '每次配送数量',
+  `frequency` varchar(20) NOT NULL COMMENT '配送频率：weekly每周，biweekly每两周',
+  `weekday` tinyint(1) NOT NULL COMMENT '配送日，0为周日',
+  `delivery_slot` varchar(50) DEFAULT NULL COMMENT '配送时段',
+  `address_id` int(10) unsigned NOT NULL COMMENT '收货地址ID',
+  `blessing` varchar(255) DEFAULT NULL COMMENT '祝福语',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `total_deliveries` int(11) NOT NULL COMMENT '配送总次数',
+  `delivered_count` int(11) NOT NULL DEFAULT 0 COMMENT '已生成订单的次数',
+  `next_delivery_date` date NOT NULL COMMENT '下次配送日期',
+  `prepaid` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否预付',
+  `unit_price` decimal(10,2) NOT NULL COMMENT '锁定单价',
+  `delivery_amount` decimal(10,2) NOT NULL COMMENT '每次配送金额，含运费',
+  `paid_amount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '预付金额',
+  `balance` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '预付剩余金额',
+  `status` tinyint(1) NOT NULL DEFAULT 0 COMMENT '状态：0待支付，1配送中，2已暂停，3已取消，4已完成',
+  `paid_at` timestamp NULL DEFAULT NULL COMMENT '预付时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_subscription_no` (`subscription_no`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_status_next` (`status`, `next_delivery_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='周期订阅表';
+
+-- 创建订阅配送记录表
+CREATE TABLE IF NOT EXISTS `subscription_deliveries` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `subscription_id` int(10) unsigned NOT NULL COMMENT '订阅ID',
+  `sequence` int(11) NOT NULL COMMENT '第几次配送',
+  `delivery_date` date NOT NULL COMMENT '配送日期',
+  `order_id` int(10) unsigned NOT NULL COMMENT '配送订单ID',
+  `amount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '从预付金额中扣除的金额',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_subscription_seq` (`subscription_id`, `sequence`),
+  KEY `idx_order_id` (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订阅配送记录表';
+
+-- 创建推荐关系表
+CREATE TABLE IF NOT EXISTS `referrals` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `referrer_id` int(10) unsigned NOT NULL COMMENT '一级推荐人ID',
+  `parent_referrer_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '二级推荐人ID',
+  `scene` varchar(64) DEFAULT NULL COMMENT '首次登录的小程序码scene',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_id` (`user_id`),
+  KEY `idx_referrer_id` (`referrer_id`),
+  KEY `idx_parent_referrer_id` (`parent_referrer_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='推荐关系表';
+
+-- 创建分享点击表
+CREATE TABLE IF NOT EXISTS `referral_clicks` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `referrer_id` int(10) unsigned NOT NULL COMMENT '推荐人ID',
+  `product_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '分享的商品ID',
+  `visitor_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '访客用户ID，未登录为0',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_referrer_id` (`referrer_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='分享点击表';
+
+-- 创建佣金规则表
+CREATE TABLE IF NOT EXISTS `commission_rules` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '商品ID，分类规则为0',
+  `category_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '分类ID，商品规则为0',
+  `level1_rate` decimal(5,4) NOT NULL COMMENT '一级佣金比例',
+  `level2_rate` decimal(5,4) NOT NULL DEFAULT 0.0000 COMMENT '二级佣金比例',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_product_category` (`product_id`, `category_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='佣金规则表';
+
+-- 创建佣金记录表
+CREATE TABLE IF NOT EXISTS `commissions` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `order_id` int(10) unsigned NOT NULL COMMENT '订单ID',
+  `level` tinyint(1) NOT NULL COMMENT '分佣层级：1一级，2二级',
+  `referrer_id` int(10) unsigned NOT NULL COMMENT '推荐人ID',
+  `buyer_id` int(10) unsigned NOT NULL COMMENT '买家ID',
+  `order_no` varchar(100) DEFAULT NULL COMMENT '订单编号',
+  `base_amount` decimal(10,2) NOT NULL COMMENT '计佣金额',
+  `amount` decimal(10,2) NOT NULL COMMENT '佣金金额',
+  `status` tinyint(1) NOT NULL DEFAULT 0 COMMENT '状态：0待结算，1已结算，2已冲回，3已取消',
+  `settled_at` timestamp NULL DEFAULT NULL COMMENT '结算时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_order_level` (`order_id`, `level`),
+  KEY `idx_referrer_id` (`referrer_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='佣金记录表';
+
+-- 创建钱包流水表
+CREATE TABLE IF NOT EXISTS `wallet_ledgers` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `type` tinyint(2) NOT NULL COMMENT '类型：1佣金入账，2退款扣回，3申请提现，4提现退回',
+  `change` decimal(10,2) NOT NULL COMMENT '变动金额',
+  `balance` decimal(10,2) NOT NULL COMMENT '变动后余额',
+  `ref_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '关联佣金或提现ID',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='钱包流水表';
+
+-- 创建提现申请表
+CREATE TABLE IF NOT EXISTS `withdrawals` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `amount` decimal(10,2) NOT NULL COMMENT '提现金额',
+  `status` tinyint(1) NOT NULL DEFAULT 0 COMMENT '状态：0待审核，1已通过，2已驳回',
+  `reviewer_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '审核人ID',
+  `remark` varchar(255) DEFAULT NULL COMMENT '审核备注',
+  `reviewed_at` timestamp NULL DEFAULT NULL COMMENT '审核时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='提现申请表';
+
+-- 创建组合商品组件表
+CREATE TABLE IF NOT EXISTS `bundle_items` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `bundle_id` int(10) unsigned NOT NULL COMMENT '组合商品ID',
+  `product_id` int(10) unsigned NOT NULL COMMENT '组件商品ID',
+  `quantity` int(10) unsigned NOT NULL DEFAULT 1 COMMENT '每套数量',
+  `sort_order` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '排序',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_bundle_id` (`bundle_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='组合商品组件表';
+
+-- 创建订单部分退款表
+CREATE TABLE IF NOT EXISTS `order_refunds` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `order_id` int(10) unsigned NOT NULL COMMENT '订单ID',
+  `refund_no` varchar(100) NOT NULL COMMENT '退款单号',
+  `amount` decimal(10,2) NOT NULL COMMENT '退款金额',
+  `reason` varchar(255) DEFAULT NULL COMMENT '退款原因',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_refund_no` (`refund_no`),
+  KEY `idx_order_id` (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订单部分退款表';
+
+-- 创建商品限购规则表
+CREATE TABLE IF NOT EXISTS `purchase_limits` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `period` tinyint(1) NOT NULL DEFAULT 0 COMMENT '限购周期：0累计，1每天，2每周，3每月',
+  `max_quantity` int(10) unsigned NOT NULL COMMENT '周期内最多购买数量',
+  `start_at` timestamp NULL DEFAULT NULL COMMENT '生效开始时间',
+  `end_at` timestamp NULL DEFAULT NULL COMMENT '生效结束时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品限购规则表';
+
+-- 创建收藏表
+CREATE TABLE IF NOT EXISTS `favorites` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `saved_price` decimal(10,2) NOT NULL COMMENT '收藏时的价格',
+  `out_of_stock` tinyint(1) NOT NULL DEFAULT 0 COMMENT '上次检查时是否缺货',
+  `notified_price` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '已提醒过的降价价格',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_product` (`user_id`, `product_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='收藏表';
+
+-- 创建浏览足迹表
+CREATE TABLE IF NOT EXISTS `browse_histories` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(10) unsigned NOT NULL COMMENT '用户ID',
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `viewed_at` timestamp NOT NULL COMMENT '最近浏览时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_product` (`user_id`, `product_id`),
+  KEY `idx_user_viewed` (`user_id`, `viewed_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='浏览足迹表';
+
+-- 创建商品相似度表
+CREATE TABLE IF NOT EXISTS `product_similarities` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `similar_id` int(10) unsigned NOT NULL COMMENT '相似商品ID',
+  `score` double NOT NULL COMMENT '相似度',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_product_similar` (`product_id`, `similar_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品相似度表';
+
+-- 创建商品相册表
+CREATE TABLE IF NOT EXISTS `product_gallery_items` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `type` tinyint(1) NOT NULL COMMENT '类型：1图片，2视频',
+  `object_name` varchar(255) NOT NULL COMMENT 'MinIO对象名',
+  `sort_order` int(10) unsigned DEFAULT 0 COMMENT '排序',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`),
+  KEY `idx_object_name` (`object_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品相册表';
+
+-- 创建商品图文详情表
+CREATE TABLE IF NOT EXISTS `product_detail_blocks` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `type` tinyint(1) NOT NULL COMMENT '类型：1图片，3富文本',
+  `object_name` varchar(255) DEFAULT NULL COMMENT '图片块的MinIO对象名',
+  `content` text DEFAULT NULL COMMENT '文本块清理后的HTML',
+  `sort_order` int(10) unsigned DEFAULT 0 COMMENT '排序',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`),
+  KEY `idx_object_name` (`object_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品图文详情表';
+
+-- 创建媒体对象表
+CREATE TABLE IF NOT EXISTS `media_objects` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `object_name` varchar(255) NOT NULL COMMENT 'MinIO对象名',
+  `unreferenced_at` timestamp NULL DEFAULT NULL COMMENT '不再被引用的时间，被引用时为空',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_object_name` (`object_name`),
+  KEY `idx_unreferenced_at` (`unreferenced_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='媒体对象表';
+
+-- 创建商品定时计划表
+CREATE TABLE IF NOT EXISTS `product_schedules` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `action` tinyint(1) NOT NULL COMMENT '动作：1上架，2下架，3调价',
+  `price` decimal(10,2) DEFAULT 0.00 COMMENT '调价后的价格',
+  `revert_of` int(10) unsigned DEFAULT 0 COMMENT '结束计划对应的开始计划ID',
+  `previous_price` decimal(10,2) DEFAULT 0.00 COMMENT '执行前的价格',
+  `run_at` timestamp NOT NULL COMMENT '执行时间',
+  `status` tinyint(1) DEFAULT 0 COMMENT '状态：0待执行，1已执行，2已取消',
+  `applied_at` timestamp NULL DEFAULT NULL COMMENT '实际执行时间',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`),
+  KEY `idx_revert_of` (`revert_of`),
+  KEY `idx_product_schedule_due` (`status`, `run_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品定时计划表';
+
+-- 创建商品价格历史表
+CREATE TABLE IF NOT EXISTS `price_histories` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `price` decimal(10,2) NOT NULL COMMENT '变更后的价格',
+  `previous_price` decimal(10,2) NOT NULL COMMENT '变更前的价格',
+  `effective_at` timestamp NOT NULL COMMENT '生效时间',
+  `schedule_id` int(10) unsigned DEFAULT 0 COMMENT '定时计划ID',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_price_history_product` (`product_id`, `effective_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品价格历史表';
+
+-- 创建库存流水表
+CREATE TABLE IF NOT EXISTS `inventory_movements` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `type` tinyint(1) NOT NULL COMMENT '类型：1下单扣减，2取消归还，3退款退回，4人工调整，5盘点，6损耗报废，7入库',
+  `quantity` int(10) NOT NULL COMMENT '变动数量，减少为负',
+  `stock_after` int(10) NOT NULL COMMENT '变动后的可售库存',
+  `actor_id` int(10) unsigned DEFAULT 0 COMMENT '操作人用户ID，系统任务为0',
+  `ref_type` varchar(20) DEFAULT NULL COMMENT '关联单据类型',
+  `ref_id` int(10) unsigned DEFAULT 0 COMMENT '关联单据ID',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_inventory_product` (`product_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='库存流水表';
+
+-- 创建库存预警表
+CREATE TABLE IF NOT EXISTS `stock_alerts` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `threshold` int(10) NOT NULL COMMENT '预警阈值，可售库存不高于该值时提醒',
+  `alerted_at` timestamp NULL DEFAULT NULL COMMENT '已提醒时间，库存回到阈值以上后清空',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='库存预警表';
+
+-- 创建库存批次表
+CREATE TABLE IF NOT EXISTS `stock_batches` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `received_at` date NOT NULL COMMENT '入库日期',
+  `shelf_life_days` int(10) NOT NULL COMMENT '保质天数',
+  `expires_at` date NOT NULL COMMENT '到期日，当天起不可售',
+  `quantity` int(10) NOT NULL COMMENT '入库数量',
+  `remaining` int(10) NOT NULL COMMENT '剩余可售数量',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_stock_batch_product` (`product_id`, `expires_at`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='库存批次表';
+
+-- 创建库存批次占用表
+CREATE TABLE IF NOT EXISTS `stock_batch_allocations` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `batch_id` int(10) unsigned NOT NULL COMMENT '批次ID',
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `ref_type` varchar(20) NOT NULL COMMENT '单据类型',
+  `ref_id` int(10) unsigned NOT NULL COMMENT '单据ID',
+  `quantity` int(10) NOT NULL COMMENT '仍占用的数量',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_batch_id` (`batch_id`),
+  KEY `idx_stock_allocation_ref` (`ref_type`, `ref_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='库存批次占用表';
+
+-- 创建供应商表
+CREATE TABLE IF NOT EXISTS `suppliers` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) NOT NULL COMMENT '供应商名称',
+  `contact` varchar(50) DEFAULT NULL COMMENT '联系人',
+  `phone` varchar(20) DEFAULT NULL COMMENT '联系电话',
+  `wechat_id` varchar(50) DEFAULT NULL COMMENT '微信号',
+  `address` varchar(255) DEFAULT NULL COMMENT '地址',
+  `lead_days` int(10) NOT NULL DEFAULT '0' COMMENT '下单到到货的天数，0表示使用默认值',
+  `status` tinyint(1) NOT NULL DEFAULT '1' COMMENT '状态：0停用，1启用',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='供应商表';
+
+-- 创建采购单表
+CREATE TABLE IF NOT EXISTS `purchase_orders` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `po_no` varchar(32) NOT NULL COMMENT '采购单号',
+  `supplier_id` int(10) unsigned NOT NULL COMMENT '供应商ID',
+  `status` tinyint(1) NOT NULL DEFAULT '0' COMMENT '状态：0草稿，1已下单，2部分到货，3已到货，4已取消，5已关闭',
+  `expected_at` date DEFAULT NULL COMMENT '预计到货日期',
+  `total_amount` decimal(10,2) NOT NULL DEFAULT '0.00' COMMENT '采购金额',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_by` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '创建人用户ID',
+  `ordered_at` timestamp NULL DEFAULT NULL COMMENT '提交下单时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_po_no` (`po_no`),
+  KEY `idx_supplier_id` (`supplier_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='采购单表';
+
+-- 创建采购单明细表
+CREATE TABLE IF NOT EXISTS `purchase_order_items` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `purchase_order_id` int(10) unsigned NOT NULL COMMENT '采购单ID',
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `name` varchar(100) DEFAULT NULL COMMENT '下单时的商品名称',
+  `quantity` int(10) NOT NULL COMMENT '采购数量',
+  `received_qty` int(10) NOT NULL DEFAULT '0' COMMENT '已到货数量',
+  `unit_cost` decimal(10,2) NOT NULL DEFAULT '0.00' COMMENT '采购单价',
+  `shelf_life_days` int(10) NOT NULL DEFAULT '0' COMMENT '保质天数，大于0时按批次入库',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_purchase_order_id` (`purchase_order_id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='采购单明细表';
+
+-- 商品属性表
+CREATE TABLE IF NOT EXISTS `attributes` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `code` varchar(50) NOT NULL COMMENT '编码，如 occasion、recipient、color',
+  `name` varchar(50) NOT NULL COMMENT '展示名称',
+  `category_id` int(10) unsigned NOT NULL DEFAULT 0 COMMENT '适用的分类，含其子孙分类，0表示全部分类',
+  `multi_valued` tinyint(1) NOT NULL DEFAULT 1 COMMENT '商品是否可以有多个值',
+  `sort_order` int(10) NOT NULL DEFAULT 0 COMMENT '排序',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_code` (`code`),
+  KEY `idx_category_id` (`category_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品属性表';
+
+-- 商品属性值表
+CREATE TABLE IF NOT EXISTS `attribute_values` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `attribute_id` int(10) unsigned NOT NULL COMMENT '属性ID',
+  `value` varchar(50) NOT NULL COMMENT '属性值，如 生日',
+  `sort_order` int(10) NOT NULL DEFAULT 0 COMMENT '排序',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_attribute_id` (`attribute_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品属性值表';
+
+-- 商品属性标签表
+CREATE TABLE IF NOT EXISTS `product_attribute_values` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` int(10) unsigned NOT NULL COMMENT '商品ID',
+  `attribute_id` int(10) unsigned NOT NULL COMMENT '属性ID',
+  `value_id` int(10) unsigned NOT NULL COMMENT '属性值ID',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_product_value` (`product_id`, `value_id`),
+  KEY `idx_attribute_id` (`attribute_id`),
+  KEY `idx_value_id` (`value_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='商品属性标签表';
+
+-- 轮播图每日统计表
+CREATE TABLE IF NOT EXISTS `banner_daily_stats` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `banner_id` int(10) unsigned NOT NULL COMMENT '轮播图ID',
+  `date` date NOT NULL COMMENT '日期',
+  `impressions` bigint(20) NOT NULL DEFAULT '0' COMMENT '曝光次数',
+  `clicks` bigint(20) NOT NULL DEFAULT '0' COMMENT '点击次数',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_banner_date` (`banner_id`, `date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='轮播图每日统计表';
+
+-- 订单项促销优惠表
+CREATE TABLE IF NOT EXISTS `order_item_promotions` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `order_id` int(10) unsigned NOT NULL COMMENT '订单ID',
+  `order_item_id` int(10) unsigned NOT NULL COMMENT '订单项ID',
+  `promotion_id` int(10) unsigned NOT NULL COMMENT '促销活动ID',
+  `title` varchar(100) DEFAULT NULL COMMENT '下单时的活动标题',
+  `rule_type` tinyint(1) NOT NULL DEFAULT '0' COMMENT '下单时的活动规则类型',
+  `discount` decimal(10,2) NOT NULL COMMENT '分摊到订单项的优惠金额',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_order_id` (`order_id`),
+  KEY `idx_order_item_id` (`order_item_id`),
+  KEY `idx_promotion_id` (`promotion_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订单项促销优惠表';
+
+-- 首页区块配置表
+CREATE TABLE IF NOT EXISTS `home_sections` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `section_key` varchar(20) NOT NULL COMMENT '区块：banners, categories, promotions, recommend, hot',
+  `title` varchar(50) DEFAULT NULL COMMENT '区块标题',
+  `visible` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否显示',
+  `limit_count` int(11) NOT NULL DEFAULT '0' COMMENT '展示数量，0表示默认数量',
+  `sort_order` int(11) NOT NULL DEFAULT '0' COMMENT '排序',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_section_key` (`section_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='首页区块配置表';
+
 -- 添加外键约束（如果需要）
 -- ALTER TABLE `addresses` ADD CONSTRAINT `fk_address_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
 -- ALTER TABLE `cart_items` ADD CONSTRAINT `fk_cart_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
@@ -155,6 +889,7 @@ CREATE TABLE IF NOT EXISTS `order_items` (
 -- ALTER TABLE `order_items` ADD CONSTRAINT `fk_order_item_product` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) ON DELETE SET NULL;
 
 -- 添加初始数据
+-- 分类和商品的路径由 POST /api/admin/category/rebuild-paths 按父分类生成
 INSERT INTO `categories` (`name`, `parent_id`, `image`, `sort_order`) VALUES 
 ('鲜花', 0, 'category/flowers.jpg', 1),
 ('绿植', 0, 'category/plants.jpg', 2),
@@ -167,15 +902,19 @@ INSERT INTO `categories` (`name`, `parent_id`, `image`, `sort_order`) VALUES
 ('康乃馨', 1, 'category/carnations.jpg', 3),
 ('向日葵', 1, 'category/sunflowers.jpg', 4);
 
-INSERT INTO `banners` (`image`, `link`, `sort_order`) VALUES
-('banners/spring_sale.jpg', '/products?category_id=1', 1),
-('banners/new_arrivals.jpg', '/products?recommend=1', 2),
-('banners/gift_ideas.jpg', '/products?category_id=4', 3);
+INSERT INTO `banners` (`title`, `image_url`, `link_type`, `link_value`, `sort_order`) VALUES
+('春季特惠', 'banners/spring_sale.jpg', 2, '1', 1),
+('新品上市', 'banners/new_arrivals.jpg', 4, '/pages/goods/list?recommend=1', 2),
+('送礼精选', 'banners/gift_ideas.jpg', 2, '4', 3);
+
+INSERT INTO `promotions` (`title`, `image_url`, `scope`, `rule_type`, `threshold`, `discount`, `repeatable`, `priority`) VALUES
+('母亲节特惠', 'promotions/mothers_day.jpg', 1, 1, 199.00, 30.00, 1, 2),
+('新品上市', 'promotions/new_collection.jpg', 0, 0, 0.00, 0.00, 0, 1),
+('节日礼盒', 'promotions/holiday_gifts.jpg', 1, 4, 0.00, 0.00, 0, 0);
 
-INSERT INTO `promotions` (`title`, `image`, `link`, `sort_order`) VALUES
-('母亲节特惠', 'promotions/mothers_day.jpg', '/products?category_id=3', 1),
-('新品上市', 'promotions/new_collection.jpg', '/products?hot=1', 2),
-('节日礼盒', 'promotions/holiday_gifts.jpg', '/products?category_id=4', 3);
+INSERT INTO `promotion_scopes` (`promotion_id`, `category_id`) VALUES
+(1, 3),
+(3, 4);
 
 -- 添加示例产品
 INSERT INTO `products` (`name`, `description`, `price`, `stock`, `category_id`, `images`, `main_image`, `status`, `hot`, `recommend`, `sort_order`) VALUES
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAttributeApi registers all product attribute api
+func RegisterAttributeApi(router *gin.Engine) {
+	attributeHandler := handler.NewAttributeHandler()
+
+	api := router.Group("/api")
+	{
+		// 获取分类适用的属性及属性值
+		api.GET("/attribute", attributeHandler.GetAttributes)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取全部属性
+		admin.GET("/attributes", attributeHandler.GetAttributes)
+		// 创建属性
+		admin.POST("/attributes", attributeHandler.CreateAttribute)
+		// 更新属性及属性值
+		admin.PUT("/attributes/:id", attributeHandler.UpdateAttribute)
+		// 删除属性
+		admin.DELETE("/attributes/:id", attributeHandler.DeleteAttribute)
+		// 设置商品属性值
+		admin.PUT("/product/:id/attributes", attributeHandler.SetProductAttributes)
+	}
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,8 +11,27 @@ import (
 func RegisterBannerApi(router *gin.Engine) {
 	bannerHandler := handler.NewBannerHandler()
 	api := router.Group("/api")
+	// 可选登录，按新用户、会员投放
+	api.Use(middleware.OptionalAuthMiddleware())
 	{
 		// 获取轮播图
 		api.GET("/banner", bannerHandler.GetBanners)
+		// 记录轮播图点击
+		api.POST("/banner/:id/click", bannerHandler.RecordClick)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取全部轮播图
+		admin.GET("/banners", bannerHandler.GetAdminBanners)
+		// 创建轮播图
+		admin.POST("/banners", bannerHandler.CreateBanner)
+		// 更新轮播图
+		admin.PUT("/banners/:id", bannerHandler.UpdateBanner)
+		// 删除轮播图
+		admin.DELETE("/banners/:id", bannerHandler.DeleteBanner)
+		// 获取轮播图每日曝光和点击
+		admin.GET("/banners/:id/stats", bannerHandler.GetBannerStats)
 	}
 }
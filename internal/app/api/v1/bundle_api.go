@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterBundleApi registers all bundle product api
+func RegisterBundleApi(router *gin.Engine) {
+	bundleHandler := handler.NewBundleHandler()
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取组合商品组件
+		admin.GET("/product/:id/bundle", bundleHandler.GetBundleItems)
+		// 设置组合商品组件
+		admin.PUT("/product/:id/bundle", bundleHandler.SetBundleItems)
+		// 取消组合商品
+		admin.DELETE("/product/:id/bundle", bundleHandler.RemoveBundle)
+	}
+}
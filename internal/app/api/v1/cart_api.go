@@ -16,11 +16,17 @@ func RegisterCartApi(router *gin.Engine) {
 		api.POST("/cart", cartHandler.AddToCart)
 		// 获取购物车列表
 		api.GET("/cart", cartHandler.GetCartList)
+		// 获取选中商品的结算金额
+		api.GET("/cart/summary", cartHandler.GetCartSummary)
+		// 修改购物车商品数量
+		api.PUT("/cart/quantity", cartHandler.UpdateCartQuantity)
 		// 更新购物车商品状态
-		api.PUT("/cart/:productId/:selected", cartHandler.UpdateCartStatus)
+		api.PUT("/cart/:id/:selected", cartHandler.UpdateCartStatus)
 		// 更新购物车所有商品状态
 		api.PUT("/cart/all/:selected", cartHandler.UpdateAllCartStatus)
 		// 删除购物车商品
 		api.DELETE("/cart", cartHandler.DeleteCart)
+		// 清除失效商品
+		api.DELETE("/cart/invalid", cartHandler.RemoveInvalidCarts)
 	}
 }
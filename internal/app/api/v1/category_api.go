@@ -2,6 +2,7 @@ package v1
 
 import (
 	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,4 +21,25 @@ func RegisterCategoryApi(router *gin.Engine) {
 		// 获取一级分类
 		api.GET("/category/level1", categoryHandler.GetLevel1Categories)
 	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 创建分类
+		admin.POST("/category", categoryHandler.CreateCategory)
+		// 同一父分类下的子分类排序
+		admin.PUT("/category/sort", categoryHandler.SortCategories)
+		// 按父分类重建分类和商品的路径
+		admin.POST("/category/rebuild-paths", categoryHandler.RebuildPaths)
+		// 更新分类
+		admin.PUT("/category/:id", categoryHandler.UpdateCategory)
+		// 删除空分类
+		admin.DELETE("/category/:id", categoryHandler.DeleteCategory)
+		// 移动分类及其子树
+		admin.PUT("/category/:id/move", categoryHandler.MoveCategory)
+		// 合并分类
+		admin.PUT("/category/:id/merge", categoryHandler.MergeCategory)
+		// 修改商品所属分类
+		admin.PUT("/product/:id/category", categoryHandler.SetProductCategory)
+	}
 }
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDistributionApi registers all distribution api
+func RegisterDistributionApi(router *gin.Engine) {
+	distributionHandler := handler.NewDistributionHandler()
+
+	api := router.Group("/api")
+	api.Use(middleware.OptionalAuthMiddleware())
+	{
+		// 上报分享点击
+		api.POST("/distribution/click", distributionHandler.RecordClick)
+	}
+
+	auth := router.Group("/api")
+	auth.Use(middleware.AuthMiddleware())
+	{
+		// 获取商品分享参数
+		auth.GET("/distribution/share", distributionHandler.GetShare)
+		// 获取分销统计
+		auth.GET("/distribution/stats", distributionHandler.GetStats)
+		// 获取佣金明细
+		auth.GET("/distribution/commissions", distributionHandler.GetCommissions)
+		// 获取钱包流水
+		auth.GET("/distribution/wallet", distributionHandler.GetWalletLedgers)
+		// 申请提现
+		auth.POST("/distribution/withdraw", distributionHandler.Withdraw)
+		// 获取我的提现申请
+		auth.GET("/distribution/withdrawals", distributionHandler.GetMyWithdrawals)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取佣金规则
+		admin.GET("/distribution/rules", distributionHandler.GetRules)
+		// 保存商品或分类佣金规则
+		admin.POST("/distribution/rules", distributionHandler.SaveRule)
+		// 删除佣金规则
+		admin.DELETE("/distribution/rules/:id", distributionHandler.DeleteRule)
+		// 获取提现申请列表
+		admin.GET("/distribution/withdrawals", distributionHandler.GetWithdrawals)
+		// 通过提现申请
+		admin.POST("/distribution/withdrawals/:id/approve", distributionHandler.ApproveWithdrawal)
+		// 驳回提现申请，金额退回钱包
+		admin.POST("/distribution/withdrawals/:id/reject", distributionHandler.RejectWithdrawal)
+	}
+}
@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterFavoriteApi registers all favorite api
+func RegisterFavoriteApi(router *gin.Engine) {
+	favoriteHandler := handler.NewFavoriteHandler()
+	api := router.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	{
+		// 收藏商品
+		api.POST("/favorite", favoriteHandler.AddFavorite)
+		// 取消收藏
+		api.DELETE("/favorite", favoriteHandler.RemoveFavorite)
+		// 获取收藏列表
+		api.GET("/favorite", favoriteHandler.GetFavorites)
+	}
+}
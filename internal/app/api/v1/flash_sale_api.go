@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterFlashSaleApi registers all flash sale api
+func RegisterFlashSaleApi(router *gin.Engine) {
+	flashSaleHandler := handler.NewFlashSaleHandler()
+
+	api := router.Group("/api")
+	{
+		// 获取进行中及即将开始的秒杀活动
+		api.GET("/flash-sale", flashSaleHandler.GetActiveFlashSales)
+		// 获取秒杀活动详情
+		api.GET("/flash-sale/:id", flashSaleHandler.GetFlashSale)
+	}
+
+	auth := router.Group("/api")
+	auth.Use(middleware.AuthMiddleware())
+	{
+		// 秒杀抢购，返回请求ID
+		auth.POST("/flash-sale/:id/buy", flashSaleHandler.Buy)
+		// 查询抢购结果
+		auth.GET("/flash-sale/result/:requestId", flashSaleHandler.GetResult)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取秒杀活动列表
+		admin.GET("/flash-sale", flashSaleHandler.GetFlashSales)
+		// 创建秒杀活动
+		admin.POST("/flash-sale", flashSaleHandler.CreateFlashSale)
+		// 更新秒杀活动
+		admin.PUT("/flash-sale/:id", flashSaleHandler.UpdateFlashSale)
+		// 启用/停用秒杀活动
+		admin.POST("/flash-sale/:id/status", flashSaleHandler.SetFlashSaleStatus)
+	}
+}
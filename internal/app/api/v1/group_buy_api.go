@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterGroupBuyApi registers all group-buy api
+func RegisterGroupBuyApi(router *gin.Engine) {
+	groupBuyHandler := handler.NewGroupBuyHandler()
+
+	api := router.Group("/api")
+	// 可选登录，登录用户查看团详情时返回分享参数
+	api.Use(middleware.OptionalAuthMiddleware())
+	{
+		// 获取进行中的拼团活动
+		api.GET("/group-buy", groupBuyHandler.GetActiveCampaigns)
+		// 获取拼团活动详情及可参与的团
+		api.GET("/group-buy/:id", groupBuyHandler.GetCampaign)
+		// 获取团详情，登录时返回分享参数
+		api.GET("/group-buy/group/:groupId", groupBuyHandler.GetGroup)
+		// 解析小程序码scene参数
+		api.GET("/group-buy/share/scene", groupBuyHandler.ResolveShareScene)
+	}
+
+	auth := router.Group("/api")
+	auth.Use(middleware.AuthMiddleware())
+	{
+		// 开团，生成待支付订单
+		auth.POST("/group-buy/:id/open", groupBuyHandler.OpenGroup)
+		// 参团，生成待支付订单
+		auth.POST("/group-buy/group/:groupId/join", groupBuyHandler.JoinGroup)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取拼团活动列表
+		admin.GET("/group-buy", groupBuyHandler.GetCampaigns)
+		// 创建拼团活动
+		admin.POST("/group-buy", groupBuyHandler.CreateCampaign)
+		// 更新拼团活动
+		admin.PUT("/group-buy/:id", groupBuyHandler.UpdateCampaign)
+		// 启用/停用拼团活动
+		admin.POST("/group-buy/:id/status", groupBuyHandler.SetCampaignStatus)
+	}
+}
@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterHistoryApi registers all browsing history api
+func RegisterHistoryApi(router *gin.Engine) {
+	historyHandler := handler.NewHistoryHandler()
+	api := router.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	{
+		// 获取浏览足迹
+		api.GET("/history", historyHandler.GetHistories)
+		// 删除一条浏览足迹
+		api.DELETE("/history", historyHandler.DeleteHistory)
+		// 清空浏览足迹
+		api.DELETE("/history/all", historyHandler.ClearHistories)
+	}
+}
@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterHomeApi registers all home api
+func RegisterHomeApi(router *gin.Engine) {
+	homeHandler := handler.NewHomeHandler()
+	api := router.Group("/api")
+	// 可选登录，轮播图受众、个性化推荐和会员价依赖当前用户
+	api.Use(middleware.OptionalAuthMiddleware())
+	{
+		// 获取首页聚合数据
+		api.GET("/home", homeHandler.GetHome)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取首页区块配置
+		admin.GET("/home/sections", homeHandler.GetHomeSections)
+		// 更新首页区块顺序和显示
+		admin.PUT("/home/sections", homeHandler.UpdateHomeSections)
+	}
+}
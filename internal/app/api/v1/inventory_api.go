@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterInventoryApi registers all inventory api
+func RegisterInventoryApi(router *gin.Engine) {
+	inventoryHandler := handler.NewInventoryHandler()
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取低库存商品
+		admin.GET("/inventory/low-stock", inventoryHandler.GetLowStockProducts)
+		// 获取未来几天预计过期报废的数量
+		admin.GET("/inventory/expected-wastage", inventoryHandler.GetExpectedWastage)
+		// 获取商品可售、占用及在库数量
+		admin.GET("/inventory/:id", inventoryHandler.GetInventory)
+		// 获取商品库存流水
+		admin.GET("/inventory/:id/movements", inventoryHandler.GetMovements)
+		// 人工调整、盘点或报损
+		admin.POST("/inventory/:id/adjust", inventoryHandler.AdjustStock)
+		// 设置库存预警阈值
+		admin.PUT("/inventory/:id/alert", inventoryHandler.SetStockAlert)
+		// 删除库存预警
+		admin.DELETE("/inventory/:id/alert", inventoryHandler.DeleteStockAlert)
+		// 获取商品有剩余的库存批次
+		admin.GET("/inventory/:id/batches", inventoryHandler.GetBatches)
+		// 批次入库
+		admin.POST("/inventory/:id/batches", inventoryHandler.CreateBatch)
+	}
+}
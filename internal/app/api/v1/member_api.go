@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterMemberApi registers all membership api
+func RegisterMemberApi(router *gin.Engine) {
+	memberHandler := handler.NewMemberHandler()
+
+	api := router.Group("/api")
+	{
+		// 获取会员等级列表
+		api.GET("/member/tiers", memberHandler.GetTiers)
+	}
+
+	auth := router.Group("/api")
+	auth.Use(middleware.AuthMiddleware())
+	{
+		// 获取当前用户会员信息
+		auth.GET("/member", memberHandler.GetMemberInfo)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 创建会员等级
+		admin.POST("/member/tiers", memberHandler.CreateTier)
+		// 更新会员等级
+		admin.PUT("/member/tiers/:id", memberHandler.UpdateTier)
+		// 删除会员等级
+		admin.DELETE("/member/tiers/:id", memberHandler.DeleteTier)
+		// 设置商品会员价
+		admin.POST("/product/:id/member-prices", memberHandler.SetMemberPrice)
+		// 删除商品会员价
+		admin.DELETE("/product/:id/member-prices/:tierId", memberHandler.DeleteMemberPrice)
+	}
+}
@@ -19,11 +19,26 @@ func RegisterOrderApi(router *gin.Engine) {
 		api.POST("/order/buy", orderHandler.CreateOrderAndPay)
 		// 提交订单
 		api.POST("/order/submit", orderHandler.CreateOrder)
+		// 结算购物车选中商品
+		api.POST("/order/checkout", orderHandler.CheckoutCart)
 		// 获取微信支付信息
 		api.GET("/order/pay", orderHandler.GetWechatPayInfo)
 		// 检查微信支付状态
 		api.GET("/order/pay/status", orderHandler.CheckWechatPayStatus)
 		// 获取订单列表
 		api.GET("/order/list", orderHandler.GetOrderList)
+		// 确认收货
+		api.POST("/order/:id/confirm", orderHandler.ConfirmReceipt)
+		// 取消订单
+		api.POST("/order/:id/cancel", orderHandler.CancelOrder)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 订单退款
+		admin.POST("/order/:id/refund", orderHandler.RefundOrder)
+		// 按订单项部分退款
+		admin.POST("/order/:id/refund-items", orderHandler.RefundOrderItems)
 	}
 }
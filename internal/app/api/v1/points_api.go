@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPointsApi registers all loyalty points api
+func RegisterPointsApi(router *gin.Engine) {
+	pointsHandler := handler.NewPointsHandler()
+
+	api := router.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	{
+		// 获取积分余额
+		api.GET("/points", pointsHandler.GetBalance)
+		// 获取积分明细
+		api.GET("/points/history", pointsHandler.GetHistory)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 调整用户积分
+		admin.POST("/points/adjust", pointsHandler.AdjustPoints)
+		// 获取用户积分明细
+		admin.GET("/points/history", pointsHandler.GetUserHistory)
+		// 获取分类积分规则
+		admin.GET("/points/rules", pointsHandler.GetRules)
+		// 保存分类积分规则
+		admin.POST("/points/rules", pointsHandler.SaveRule)
+		// 删除分类积分规则
+		admin.DELETE("/points/rules/:categoryId", pointsHandler.DeleteRule)
+	}
+}
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPreSaleApi registers all pre-sale api
+func RegisterPreSaleApi(router *gin.Engine) {
+	preSaleHandler := handler.NewPreSaleHandler()
+
+	api := router.Group("/api")
+	{
+		// 获取定金阶段进行中及即将开始的预售活动
+		api.GET("/pre-sale", preSaleHandler.GetActivePreSales)
+		// 获取预售活动详情
+		api.GET("/pre-sale/:id", preSaleHandler.GetPreSale)
+	}
+
+	auth := router.Group("/api")
+	auth.Use(middleware.AuthMiddleware())
+	{
+		// 预售下单，生成待付定金订单
+		auth.POST("/pre-sale/:id/order", preSaleHandler.PlaceOrder)
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取预售活动列表
+		admin.GET("/pre-sale", preSaleHandler.GetPreSales)
+		// 创建预售活动
+		admin.POST("/pre-sale", preSaleHandler.CreatePreSale)
+		// 更新未开始的预售活动
+		admin.PUT("/pre-sale/:id", preSaleHandler.UpdatePreSale)
+		// 启用/停用预售活动
+		admin.POST("/pre-sale/:id/status", preSaleHandler.SetPreSaleStatus)
+	}
+}
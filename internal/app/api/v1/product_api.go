@@ -2,6 +2,7 @@ package v1
 
 import (
 	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,14 +11,22 @@ import (
 func RegisterProductApi(router *gin.Engine) {
 	productHandler := handler.NewProductHandler()
 	api := router.Group("/api")
+	// 可选登录，登录用户返回其会员价
+	api.Use(middleware.OptionalAuthMiddleware())
 	{
 		// 获取商品列表
 		api.GET("/product", productHandler.GetProducts)
+		// 获取商品列表的属性筛选项及计数
+		api.GET("/product/facets", productHandler.GetFacets)
 		// 获取商品详情
 		api.GET("/product/:id", productHandler.GetProductDetail)
 		// 获取推荐商品
 		api.GET("/product/recommend", productHandler.GetRecommendProducts)
 		// 获取热门商品
 		api.GET("/product/hot", productHandler.GetHotProducts)
+		// 获取销量排行
+		api.GET("/product/ranking", productHandler.GetSalesRanking)
+		// 获取商品在配送日期可售的库存
+		api.GET("/product/:id/stock", productHandler.GetStock)
 	}
 }
@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterProductMediaApi registers all product gallery and detail content api
+func RegisterProductMediaApi(router *gin.Engine) {
+	mediaHandler := handler.NewProductMediaHandler()
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 上传商品图片或视频
+		admin.POST("/product/media/upload", mediaHandler.UploadMedia)
+		// 获取商品相册
+		admin.GET("/product/:id/gallery", mediaHandler.GetGallery)
+		// 设置商品相册
+		admin.PUT("/product/:id/gallery", mediaHandler.SetGallery)
+		// 调整相册顺序
+		admin.PUT("/product/:id/gallery/sort", mediaHandler.SortGallery)
+		// 获取商品详情内容
+		admin.GET("/product/:id/detail", mediaHandler.GetDetail)
+		// 设置商品详情内容
+		admin.PUT("/product/:id/detail", mediaHandler.SetDetail)
+	}
+}
@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterProductScheduleApi registers all product schedule api
+func RegisterProductScheduleApi(router *gin.Engine) {
+	scheduleHandler := handler.NewProductScheduleHandler()
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取商品定时计划
+		admin.GET("/product/:id/schedules", scheduleHandler.GetSchedules)
+		// 创建商品定时上下架或调价计划
+		admin.POST("/product/:id/schedules", scheduleHandler.CreateSchedule)
+		// 取消定时计划
+		admin.PUT("/product-schedules/:id/cancel", scheduleHandler.CancelSchedule)
+		// 获取商品价格变更记录，带at参数时获取该时间生效的价格
+		admin.GET("/product/:id/price-history", scheduleHandler.GetPriceHistory)
+	}
+}
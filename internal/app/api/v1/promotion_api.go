@@ -2,6 +2,7 @@ package v1
 
 import (
 	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,4 +15,17 @@ func RegisterPromotionApi(router *gin.Engine) {
 		// 获取促销广告
 		api.GET("/promotion", promotionHandler.GetPromotions)
 	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取全部促销活动
+		admin.GET("/promotions", promotionHandler.GetAdminPromotions)
+		// 创建促销活动
+		admin.POST("/promotions", promotionHandler.CreatePromotion)
+		// 更新促销活动
+		admin.PUT("/promotions/:id", promotionHandler.UpdatePromotion)
+		// 删除促销活动
+		admin.DELETE("/promotions/:id", promotionHandler.DeletePromotion)
+	}
 }
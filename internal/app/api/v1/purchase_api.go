@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPurchaseApi registers all supplier and purchase order api
+func RegisterPurchaseApi(router *gin.Engine) {
+	purchaseHandler := handler.NewPurchaseHandler()
+	reportHandler := handler.NewReportHandler()
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取供应商列表
+		admin.GET("/suppliers", purchaseHandler.GetSuppliers)
+		// 创建供应商
+		admin.POST("/suppliers", purchaseHandler.CreateSupplier)
+		// 更新供应商
+		admin.PUT("/suppliers/:id", purchaseHandler.UpdateSupplier)
+		// 获取补货建议
+		admin.GET("/purchase-orders/suggestions", purchaseHandler.GetReorderSuggestions)
+		// 获取采购单列表
+		admin.GET("/purchase-orders", purchaseHandler.GetPurchaseOrders)
+		// 创建草稿采购单
+		admin.POST("/purchase-orders", purchaseHandler.CreatePurchaseOrder)
+		// 获取采购单详情
+		admin.GET("/purchase-orders/:id", purchaseHandler.GetPurchaseOrder)
+		// 修改草稿采购单
+		admin.PUT("/purchase-orders/:id", purchaseHandler.UpdatePurchaseOrder)
+		// 提交下单
+		admin.PUT("/purchase-orders/:id/submit", purchaseHandler.SubmitPurchaseOrder)
+		// 取消采购单，部分到货时关闭
+		admin.PUT("/purchase-orders/:id/cancel", purchaseHandler.CancelPurchaseOrder)
+		// 到货入库
+		admin.POST("/purchase-orders/:id/receive", purchaseHandler.ReceivePurchaseOrder)
+		// 导出采购单PDF或CSV
+		admin.GET("/purchase-orders/:id/export", reportHandler.ExportPurchaseOrder)
+	}
+}
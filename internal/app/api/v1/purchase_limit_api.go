@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPurchaseLimitApi registers all purchase limit api
+func RegisterPurchaseLimitApi(router *gin.Engine) {
+	limitHandler := handler.NewPurchaseLimitHandler()
+
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	{
+		// 获取商品限购规则
+		admin.GET("/product/:id/purchase-limits", limitHandler.GetLimits)
+		// 创建商品限购规则
+		admin.POST("/product/:id/purchase-limits", limitHandler.CreateLimit)
+		// 更新限购规则
+		admin.PUT("/purchase-limits/:id", limitHandler.UpdateLimit)
+		// 删除限购规则
+		admin.DELETE("/purchase-limits/:id", limitHandler.DeleteLimit)
+	}
+}
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"github.com/colinjuang/shop-go/internal/app/handler"
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSubscriptionApi registers all subscription api
+func RegisterSubscriptionApi(router *gin.Engine) {
+	subscriptionHandler := handler.NewSubscriptionHandler()
+
+	auth := router.Group("/api")
+	auth.Use(middleware.AuthMiddleware())
+	{
+		// 创建周期订阅
+		auth.POST("/subscription", subscriptionHandler.CreateSubscription)
+		// 获取我的周期订阅
+		auth.GET("/subscription", subscriptionHandler.GetSubscriptions)
+		// 获取订阅详情及配送记录
+		auth.GET("/subscription/:id", subscriptionHandler.GetSubscription)
+		// 支付预付订阅
+		auth.POST("/subscription/:id/pay", subscriptionHandler.PaySubscription)
+		// 暂停订阅
+		auth.POST("/subscription/:id/pause", subscriptionHandler.PauseSubscription)
+		// 恢复订阅
+		auth.POST("/subscription/:id/resume", subscriptionHandler.ResumeSubscription)
+		// 跳过下一次配送
+		auth.POST("/subscription/:id/skip", subscriptionHandler.SkipNextDelivery)
+		// 取消订阅，退还剩余预付款
+		auth.POST("/subscription/:id/cancel", subscriptionHandler.CancelSubscription)
+	}
+}
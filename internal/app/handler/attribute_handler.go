@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AttributeHandler handles product attribute API endpoints
+type AttributeHandler struct {
+	attributeService *service.AttributeService
+}
+
+// NewAttributeHandler creates a new attribute handler
+func NewAttributeHandler() *AttributeHandler {
+	return &AttributeHandler{
+		attributeService: service.NewAttributeService(),
+	}
+}
+
+// GetAttributes gets the attributes of a ?category_id=, including those of its ancestors, or all attributes without it
+func (h *AttributeHandler) GetAttributes(c *gin.Context) {
+	var categoryID *uint64
+	if idStr := c.Query("category_id"); idStr != "" {
+		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
+			categoryID = &id
+		}
+	}
+
+	if categoryID == nil {
+		attributes, err := h.attributeService.GetAttributes()
+		if err != nil {
+			handleAttributeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, response.SuccessResponse(attributes))
+		return
+	}
+
+	attributes, err := h.attributeService.GetCategoryAttributes(categoryID)
+	if err != nil {
+		handleAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(attributes))
+}
+
+// CreateAttribute creates an attribute with its values (admin)
+func (h *AttributeHandler) CreateAttribute(c *gin.Context) {
+	var req request.AttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	attribute, err := h.attributeService.CreateAttribute(req)
+	if err != nil {
+		handleAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(attribute))
+}
+
+// UpdateAttribute updates an attribute and its values (admin)
+func (h *AttributeHandler) UpdateAttribute(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.AttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	attribute, err := h.attributeService.UpdateAttribute(id, req)
+	if err != nil {
+		handleAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(attribute))
+}
+
+// DeleteAttribute deletes an attribute and removes it from products (admin)
+func (h *AttributeHandler) DeleteAttribute(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.attributeService.DeleteAttribute(id); err != nil {
+		handleAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// SetProductAttributes replaces the attribute values of a product (admin)
+func (h *AttributeHandler) SetProductAttributes(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.ProductAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	attributes, err := h.attributeService.SetProductAttributes(id, req)
+	if err != nil {
+		handleAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(attributes))
+}
+
+// handleAttributeError 属性业务错误返回400，资源不存在返回404，其余返回500
+func handleAttributeError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidAttributeValue,
+		err == pkgerrors.ErrAttributeCodeExists:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
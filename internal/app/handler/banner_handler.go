@@ -2,8 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/colinjuang/shop-go/internal/app/request"
 	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -21,9 +24,9 @@ func NewBannerHandler() *BannerHandler {
 	}
 }
 
-// GetBanners gets all banners for the carousel
+// GetBanners gets the banners for the carousel shown now to the viewer in the ?city=
 func (h *BannerHandler) GetBanners(c *gin.Context) {
-	banners, err := h.bannerService.GetBanners()
+	banners, err := h.bannerService.GetBanners(viewerID(c), c.Query("city"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
@@ -31,3 +34,122 @@ func (h *BannerHandler) GetBanners(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.SuccessResponse(banners))
 }
+
+// RecordClick counts a click on a banner
+func (h *BannerHandler) RecordClick(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.bannerService.RecordClick(c.Request.Context(), id); err != nil {
+		handleBannerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// GetAdminBanners gets all banners, optionally of one ?status= (admin)
+func (h *BannerHandler) GetAdminBanners(c *gin.Context) {
+	var status *int
+	if statusStr := c.Query("status"); statusStr != "" {
+		if s, err := strconv.Atoi(statusStr); err == nil {
+			status = &s
+		}
+	}
+
+	banners, err := h.bannerService.GetAdminBanners(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(banners))
+}
+
+// CreateBanner creates a banner (admin)
+func (h *BannerHandler) CreateBanner(c *gin.Context) {
+	var req request.BannerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	banner, err := h.bannerService.CreateBanner(req)
+	if err != nil {
+		handleBannerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(banner))
+}
+
+// UpdateBanner updates a banner (admin)
+func (h *BannerHandler) UpdateBanner(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.BannerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	banner, err := h.bannerService.UpdateBanner(id, req)
+	if err != nil {
+		handleBannerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(banner))
+}
+
+// DeleteBanner deletes a banner (admin)
+func (h *BannerHandler) DeleteBanner(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.bannerService.DeleteBanner(id); err != nil {
+		handleBannerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// GetBannerStats gets the daily impressions and clicks of a banner between ?start_date= and ?end_date= (admin)
+func (h *BannerHandler) GetBannerStats(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	stats, err := h.bannerService.GetBannerStats(c.Request.Context(), id, c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		handleBannerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(stats))
+}
+
+// handleBannerError 轮播图业务错误返回400，资源不存在返回404，其余返回500
+func handleBannerError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidBanner:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
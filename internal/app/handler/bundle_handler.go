@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// BundleHandler handles bundle product API endpoints
+type BundleHandler struct {
+	bundleService *service.BundleService
+}
+
+// NewBundleHandler creates a new bundle handler
+func NewBundleHandler() *BundleHandler {
+	return &BundleHandler{
+		bundleService: service.NewBundleService(),
+	}
+}
+
+// GetBundleItems gets the components of a bundle product (admin)
+func (h *BundleHandler) GetBundleItems(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	items, err := h.bundleService.GetBundleItems(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(items))
+}
+
+// SetBundleItems sets the components of a bundle product (admin)
+func (h *BundleHandler) SetBundleItems(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.SetBundleItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.bundleService.SetBundleItems(id, req); err != nil {
+		handleBundleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// RemoveBundle turns a bundle product back into a normal product (admin)
+func (h *BundleHandler) RemoveBundle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.bundleService.RemoveBundle(id); err != nil {
+		handleBundleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handleBundleError 组合商品业务错误返回400，资源不存在返回404，其余返回500
+func handleBundleError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidBundleItems:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
@@ -43,13 +43,9 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 		return
 	}
 
-	err := h.cartService.AddToCart(reqUser.UserID, request.ProductID, request.Quantity)
+	err := h.cartService.AddToCart(reqUser.UserID, request.ProductID, request.Quantity, request.Blessing)
 	if err != nil {
-		if err == pkgerrors.ErrOutOfStock {
-			c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
-		} else {
-			c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
-		}
+		handleCartError(c, err)
 		return
 	}
 
@@ -73,6 +69,45 @@ func (h *CartHandler) GetCartList(c *gin.Context) {
 	c.JSON(http.StatusOK, response.SuccessResponse(cart))
 }
 
+// GetCartSummary gets the amounts of the selected cart items, priced the same way as checkout
+func (h *CartHandler) GetCartSummary(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	summary, err := h.cartService.GetCartSummary(reqUser.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(summary))
+}
+
+// UpdateCartQuantity changes the quantity of a cart item
+func (h *CartHandler) UpdateCartQuantity(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var request request.UpdateCartQuantityRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.cartService.UpdateCartQuantity(reqUser.UserID, request.ID, request.Quantity); err != nil {
+		handleCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
 // UpdateCartStatus updates the status of a cart item
 func (h *CartHandler) UpdateCartStatus(c *gin.Context) {
 	reqUser := middleware.GetRequestUser(c)
@@ -81,10 +116,10 @@ func (h *CartHandler) UpdateCartStatus(c *gin.Context) {
 		return
 	}
 
-	productIdStr := c.Param("productId")
-	productId, err := strconv.ParseUint(productIdStr, 10, 64)
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid cart ID"))
 		return
 	}
 
@@ -95,7 +130,7 @@ func (h *CartHandler) UpdateCartStatus(c *gin.Context) {
 		return
 	}
 
-	err = h.cartService.UpdateCartStatus(reqUser.UserID, productId, selected)
+	err = h.cartService.UpdateCartStatus(reqUser.UserID, id, selected)
 	if err != nil {
 		if err == pkgerrors.ErrCartNotFound {
 			c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
@@ -159,3 +194,37 @@ func (h *CartHandler) DeleteCart(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.SuccessResponse(nil))
 }
+
+// RemoveInvalidCarts deletes the cart items that are off sale or sold out
+func (h *CartHandler) RemoveInvalidCarts(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	removed, err := h.cartService.RemoveInvalidCarts(reqUser.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(gin.H{"removed": removed}))
+}
+
+// handleCartError 购物车业务错误返回400，资源不存在返回404，其余返回500
+func handleCartError(c *gin.Context, err error) {
+	if limitErr, ok := pkgerrors.AsPurchaseLimitError(err); ok {
+		c.JSON(http.StatusBadRequest, response.ErrorResponseWithData(http.StatusBadRequest, limitErr.Message, limitErr))
+		return
+	}
+
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrOutOfStock, err == pkgerrors.ErrProductOffSale:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
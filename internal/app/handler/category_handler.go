@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/colinjuang/shop-go/internal/app/request"
 	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -72,3 +74,167 @@ func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.SuccessResponse(tree))
 }
+
+// CreateCategory creates a category under any parent (admin)
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req request.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(req)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(category))
+}
+
+// UpdateCategory updates the name, image and sort order of a category (admin)
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(id, req)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(category))
+}
+
+// DeleteCategory deletes an empty category (admin)
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(id); err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// MoveCategory moves a category and its subtree under a new parent (admin)
+func (h *CategoryHandler) MoveCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.CategoryMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	category, err := h.categoryService.MoveCategory(id, req)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(category))
+}
+
+// SortCategories reorders the children of a parent (admin)
+func (h *CategoryHandler) SortCategories(c *gin.Context) {
+	var req request.CategorySortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.categoryService.SortCategories(req); err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// MergeCategory merges a category into a target category (admin)
+func (h *CategoryHandler) MergeCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.CategoryMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	category, err := h.categoryService.MergeCategory(id, req)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(category))
+}
+
+// RebuildPaths recomputes category and product paths from the parent links (admin)
+func (h *CategoryHandler) RebuildPaths(c *gin.Context) {
+	updated, err := h.categoryService.RebuildPaths()
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(gin.H{"updated": updated}))
+}
+
+// SetProductCategory moves a product to a category of any level (admin)
+func (h *CategoryHandler) SetProductCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.ProductCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.categoryService.SetProductCategory(id, req); err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handleCategoryError 分类业务错误返回400，资源不存在返回404，其余返回500
+func handleCategoryError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidCategory,
+		err == pkgerrors.ErrCategoryNotEmpty:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// DistributionHandler handles referral commission API endpoints
+type DistributionHandler struct {
+	distributionService *service.DistributionService
+}
+
+// NewDistributionHandler creates a new distribution handler
+func NewDistributionHandler() *DistributionHandler {
+	return &DistributionHandler{
+		distributionService: service.NewDistributionService(),
+	}
+}
+
+// RecordClick records a visit through a shared mini program code
+func (h *DistributionHandler) RecordClick(c *gin.Context) {
+	var req request.ReferralClickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.distributionService.RecordClick(viewerID(c), req.Scene); err != nil {
+		handleDistributionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// GetShare gets the share scene of a product for the current user
+func (h *DistributionHandler) GetShare(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Query("productId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(h.distributionService.GetShare(reqUser.UserID, productID)))
+}
+
+// GetStats gets the clicks, orders and earnings of the current user
+func (h *DistributionHandler) GetStats(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	stats, err := h.distributionService.GetStats(reqUser.UserID)
+	if err != nil {
+		handleDistributionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(stats))
+}
+
+// GetCommissions gets the commissions of the current user
+func (h *DistributionHandler) GetCommissions(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	pagination, err := h.distributionService.GetCommissions(reqUser.UserID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// GetWalletLedgers gets the wallet history of the current user
+func (h *DistributionHandler) GetWalletLedgers(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	pagination, err := h.distributionService.GetWalletLedgers(reqUser.UserID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// Withdraw requests a withdrawal of the wallet balance
+func (h *DistributionHandler) Withdraw(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req request.WithdrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	withdrawal, err := h.distributionService.Withdraw(reqUser.UserID, req)
+	if err != nil {
+		handleDistributionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(withdrawal))
+}
+
+// GetMyWithdrawals gets the withdrawals of the current user
+func (h *DistributionHandler) GetMyWithdrawals(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	pagination, err := h.distributionService.GetWithdrawals(reqUser.UserID, nil, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// GetWithdrawals gets withdrawals of all users, optionally by status (admin)
+func (h *DistributionHandler) GetWithdrawals(c *gin.Context) {
+	page, pageSize := getPageParams(c)
+
+	// Get status filter
+	var status *int
+	if statusStr := c.Query("status"); statusStr != "" {
+		if s, err := strconv.Atoi(statusStr); err == nil {
+			status = &s
+		}
+	}
+
+	pagination, err := h.distributionService.GetWithdrawals(0, status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// ApproveWithdrawal approves a pending withdrawal (admin)
+func (h *DistributionHandler) ApproveWithdrawal(c *gin.Context) {
+	h.reviewWithdrawal(c, true)
+}
+
+// RejectWithdrawal rejects a pending withdrawal and returns the amount to the wallet (admin)
+func (h *DistributionHandler) RejectWithdrawal(c *gin.Context) {
+	h.reviewWithdrawal(c, false)
+}
+
+// reviewWithdrawal 审核提现申请
+func (h *DistributionHandler) reviewWithdrawal(c *gin.Context, approve bool) {
+	reqUser := middleware.GetRequestUser(c)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	// 审核备注可选
+	var req request.WithdrawalReviewRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if err := h.distributionService.ReviewWithdrawal(reqUser.UserID, id, approve, req); err != nil {
+		handleDistributionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// GetRules gets all commission rules (admin)
+func (h *DistributionHandler) GetRules(c *gin.Context) {
+	rules, err := h.distributionService.GetRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(rules))
+}
+
+// SaveRule creates or updates a commission rule of a product or a category (admin)
+func (h *DistributionHandler) SaveRule(c *gin.Context) {
+	var req request.CommissionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.distributionService.SaveRule(req); err != nil {
+		handleDistributionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// DeleteRule removes a commission rule (admin)
+func (h *DistributionHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.distributionService.DeleteRule(id); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handleDistributionError 分销业务错误返回400，资源不存在返回404，其余返回500
+func handleDistributionError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidReferralScene,
+		err == pkgerrors.ErrInvalidCommissionRule,
+		err == pkgerrors.ErrInsufficientBalance,
+		err == pkgerrors.ErrWithdrawBelowMinimum,
+		err == pkgerrors.ErrWithdrawalStatusInvalid:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// FavoriteHandler handles favorite API endpoints
+type FavoriteHandler struct {
+	favoriteService *service.FavoriteService
+}
+
+// NewFavoriteHandler creates a new favorite handler
+func NewFavoriteHandler() *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteService: service.NewFavoriteService(),
+	}
+}
+
+// AddFavorite saves a product to the user's favorites
+func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req request.AddFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.favoriteService.AddFavorite(reqUser.UserID, req.ProductID); err != nil {
+		if pkgerrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		} else {
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// RemoveFavorite removes a product from the user's favorites
+func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Query("productId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+		return
+	}
+
+	if err := h.favoriteService.RemoveFavorite(reqUser.UserID, productID); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// GetFavorites gets the user's favorites
+func (h *FavoriteHandler) GetFavorites(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	favorites, err := h.favoriteService.GetFavorites(reqUser.UserID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(favorites))
+}
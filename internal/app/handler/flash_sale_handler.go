@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// FlashSaleHandler handles flash sale API endpoints
+type FlashSaleHandler struct {
+	flashSaleService *service.FlashSaleService
+}
+
+// NewFlashSaleHandler creates a new flash sale handler
+func NewFlashSaleHandler() *FlashSaleHandler {
+	return &FlashSaleHandler{
+		flashSaleService: service.NewFlashSaleService(),
+	}
+}
+
+// GetActiveFlashSales gets ongoing and upcoming flash sales
+func (h *FlashSaleHandler) GetActiveFlashSales(c *gin.Context) {
+	sales, err := h.flashSaleService.GetActiveFlashSales()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(sales))
+}
+
+// GetFlashSale gets a flash sale by ID
+func (h *FlashSaleHandler) GetFlashSale(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	sale, err := h.flashSaleService.GetFlashSale(id)
+	if err != nil {
+		handleFlashSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(sale))
+}
+
+// Buy queues a flash sale purchase for the current user
+func (h *FlashSaleHandler) Buy(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.FlashSaleBuyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result, err := h.flashSaleService.Buy(reqUser.UserID, id, req)
+	if err != nil {
+		handleFlashSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(result))
+}
+
+// GetResult polls the result of a flash sale purchase
+func (h *FlashSaleHandler) GetResult(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	result, err := h.flashSaleService.GetResult(reqUser.UserID, c.Param("requestId"))
+	if err != nil {
+		handleFlashSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(result))
+}
+
+// GetFlashSales gets all flash sales with pagination (admin)
+func (h *FlashSaleHandler) GetFlashSales(c *gin.Context) {
+	page, pageSize := getPageParams(c)
+	pagination, err := h.flashSaleService.GetFlashSales(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// CreateFlashSale creates a flash sale (admin)
+func (h *FlashSaleHandler) CreateFlashSale(c *gin.Context) {
+	var req request.FlashSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	sale, err := h.flashSaleService.CreateFlashSale(req)
+	if err != nil {
+		handleFlashSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(sale))
+}
+
+// UpdateFlashSale updates a flash sale before it starts (admin)
+func (h *FlashSaleHandler) UpdateFlashSale(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.FlashSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	sale, err := h.flashSaleService.UpdateFlashSale(id, req)
+	if err != nil {
+		handleFlashSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(sale))
+}
+
+// SetFlashSaleStatus enables or disables a flash sale (admin)
+func (h *FlashSaleHandler) SetFlashSaleStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.FlashSaleStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.flashSaleService.SetFlashSaleStatus(id, req.Status); err != nil {
+		handleFlashSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handleFlashSaleError 秒杀业务错误返回400，资源不存在返回404，其余返回500
+func handleFlashSaleError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrFlashSaleNotStarted,
+		err == pkgerrors.ErrFlashSaleEnded,
+		err == pkgerrors.ErrFlashSaleSoldOut,
+		err == pkgerrors.ErrFlashSaleLimitExceeded,
+		err == pkgerrors.ErrFlashSaleStarted:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// GroupBuyHandler handles group-buy API endpoints
+type GroupBuyHandler struct {
+	groupBuyService *service.GroupBuyService
+}
+
+// NewGroupBuyHandler creates a new group-buy handler
+func NewGroupBuyHandler() *GroupBuyHandler {
+	return &GroupBuyHandler{
+		groupBuyService: service.NewGroupBuyService(),
+	}
+}
+
+// GetActiveCampaigns gets ongoing group-buy campaigns
+func (h *GroupBuyHandler) GetActiveCampaigns(c *gin.Context) {
+	campaigns, err := h.groupBuyService.GetActiveCampaigns()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaigns))
+}
+
+// GetCampaign gets a campaign with its joinable groups
+func (h *GroupBuyHandler) GetCampaign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	campaign, err := h.groupBuyService.GetCampaign(id)
+	if err != nil {
+		handleGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaign))
+}
+
+// GetGroup gets a group with its members
+func (h *GroupBuyHandler) GetGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("groupId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	group, err := h.groupBuyService.GetGroup(id, viewerID(c))
+	if err != nil {
+		handleGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(group))
+}
+
+// ResolveShareScene resolves the scene of a group-buy mini program code
+func (h *GroupBuyHandler) ResolveShareScene(c *gin.Context) {
+	result, err := h.groupBuyService.ResolveShareScene(c.Query("scene"))
+	if err != nil {
+		handleGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(result))
+}
+
+// OpenGroup opens a group for the current user
+func (h *GroupBuyHandler) OpenGroup(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	campaignID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.GroupBuyJoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result, err := h.groupBuyService.OpenGroup(reqUser.UserID, campaignID, req)
+	if err != nil {
+		handleGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(result))
+}
+
+// JoinGroup joins a group for the current user
+func (h *GroupBuyHandler) JoinGroup(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("groupId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.GroupBuyJoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result, err := h.groupBuyService.JoinGroup(reqUser.UserID, groupID, req)
+	if err != nil {
+		handleGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(result))
+}
+
+// GetCampaigns gets all campaigns with pagination (admin)
+func (h *GroupBuyHandler) GetCampaigns(c *gin.Context) {
+	page, pageSize := getPageParams(c)
+	pagination, err := h.groupBuyService.GetCampaigns(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// CreateCampaign creates a campaign (admin)
+func (h *GroupBuyHandler) CreateCampaign(c *gin.Context) {
+	var req request.GroupBuyCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	campaign, err := h.groupBuyService.CreateCampaign(req)
+	if err != nil {
+		handleGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaign))
+}
+
+// UpdateCampaign updates a campaign before it starts (admin)
+func (h *GroupBuyHandler) UpdateCampaign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.GroupBuyCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	campaign, err := h.groupBuyService.UpdateCampaign(id, req)
+	if err != nil {
+		handleGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaign))
+}
+
+// SetCampaignStatus enables or disables a campaign (admin)
+func (h *GroupBuyHandler) SetCampaignStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.GroupBuyCampaignStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.groupBuyService.SetCampaignStatus(id, req.Status); err != nil {
+		handleGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handleGroupBuyError 拼团业务错误返回400，资源不存在返回404，其余返回500
+func handleGroupBuyError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrGroupBuyNotStarted,
+		err == pkgerrors.ErrGroupBuyEnded,
+		err == pkgerrors.ErrGroupBuyGroupClosed,
+		err == pkgerrors.ErrGroupBuyGroupFull,
+		err == pkgerrors.ErrGroupBuyAlreadyJoined,
+		err == pkgerrors.ErrGroupBuyStarted,
+		err == pkgerrors.ErrGroupBuyInvalidScene,
+		err == pkgerrors.ErrOutOfStock:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
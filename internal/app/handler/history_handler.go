@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// HistoryHandler handles browsing history API endpoints
+type HistoryHandler struct {
+	historyService *service.HistoryService
+}
+
+// NewHistoryHandler creates a new history handler
+func NewHistoryHandler() *HistoryHandler {
+	return &HistoryHandler{
+		historyService: service.NewHistoryService(),
+	}
+}
+
+// GetHistories gets the user's recently viewed products
+func (h *HistoryHandler) GetHistories(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	histories, err := h.historyService.GetHistories(c.Request.Context(), reqUser.UserID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(histories))
+}
+
+// DeleteHistory removes one product from the user's browsing history
+func (h *HistoryHandler) DeleteHistory(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Query("productId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+		return
+	}
+
+	if err := h.historyService.DeleteHistory(c.Request.Context(), reqUser.UserID, productID); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// ClearHistories clears the user's browsing history
+func (h *HistoryHandler) ClearHistories(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	if err := h.historyService.ClearHistories(c.Request.Context(), reqUser.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
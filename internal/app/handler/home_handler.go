@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// HomeHandler handles home page API endpoints
+type HomeHandler struct {
+	homeService *service.HomeService
+}
+
+// NewHomeHandler creates a new home handler
+func NewHomeHandler() *HomeHandler {
+	return &HomeHandler{
+		homeService: service.NewHomeService(),
+	}
+}
+
+// GetHome gets the home page sections for the viewer in the ?city=; failed sections are returned with an error status
+func (h *HomeHandler) GetHome(c *gin.Context) {
+	c.JSON(http.StatusOK, response.SuccessResponse(h.homeService.GetHome(viewerID(c), c.Query("city"))))
+}
+
+// GetHomeSections gets the config of all home sections (admin)
+func (h *HomeHandler) GetHomeSections(c *gin.Context) {
+	sections, err := h.homeService.GetSections()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(sections))
+}
+
+// UpdateHomeSections replaces the order and visibility of the home sections (admin)
+func (h *HomeHandler) UpdateHomeSections(c *gin.Context) {
+	var req request.HomeSectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	sections, err := h.homeService.UpdateSections(req)
+	if err != nil {
+		handleHomeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(sections))
+}
+
+// handleHomeError 首页配置业务错误返回400，其余返回500
+func handleHomeError(c *gin.Context, err error) {
+	switch {
+	case err == pkgerrors.ErrInvalidHomeSection:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
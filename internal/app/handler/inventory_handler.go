@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryHandler handles inventory API endpoints
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler() *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: service.NewInventoryService(),
+	}
+}
+
+// GetInventory gets the available, reserved and on-hand stock of a product (admin)
+func (h *InventoryHandler) GetInventory(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	inventory, err := h.inventoryService.GetInventory(productID)
+	if err != nil {
+		handleInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(inventory))
+}
+
+// GetMovements gets the inventory ledger of a product, optionally of one movement type (admin)
+func (h *InventoryHandler) GetMovements(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var movementType *int
+	if typeParam := c.Query("type"); typeParam != "" {
+		t, err := strconv.Atoi(typeParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid type"))
+			return
+		}
+		movementType = &t
+	}
+
+	page, pageSize := getPageParams(c)
+	movements, err := h.inventoryService.GetMovements(productID, movementType, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(movements))
+}
+
+// AdjustStock records a manual adjustment, a stock-take or a wastage of a product (admin)
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.InventoryAdjustRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	reqUser := middleware.GetRequestUser(c)
+	movement, err := h.inventoryService.AdjustStock(reqUser.UserID, productID, req)
+	if err != nil {
+		handleInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(movement))
+}
+
+// SetStockAlert sets the low-stock threshold of a product (admin)
+func (h *InventoryHandler) SetStockAlert(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.StockAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.inventoryService.SetStockAlert(productID, req.Threshold); err != nil {
+		handleInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// DeleteStockAlert removes the low-stock threshold of a product (admin)
+func (h *InventoryHandler) DeleteStockAlert(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.inventoryService.DeleteStockAlert(productID); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// GetLowStockProducts gets the products at or below their low-stock threshold (admin)
+func (h *InventoryHandler) GetLowStockProducts(c *gin.Context) {
+	products, err := h.inventoryService.GetLowStockProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(products))
+}
+
+// GetBatches gets the batches of a product with units left (admin)
+func (h *InventoryHandler) GetBatches(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	batches, err := h.inventoryService.GetBatches(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(batches))
+}
+
+// CreateBatch receives a batch of a product into stock (admin)
+func (h *InventoryHandler) CreateBatch(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.StockBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	reqUser := middleware.GetRequestUser(c)
+	batch, err := h.inventoryService.CreateBatch(reqUser.UserID, productID, req)
+	if err != nil {
+		handleInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(batch))
+}
+
+// GetExpectedWastage gets the units expected to expire unsold in the coming ?days= days (admin)
+func (h *InventoryHandler) GetExpectedWastage(c *gin.Context) {
+	days := 7
+	if d, err := strconv.Atoi(c.DefaultQuery("days", "7")); err == nil && d > 0 && d <= 30 {
+		days = d
+	}
+
+	wastage, err := h.inventoryService.GetExpectedWastage(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(wastage))
+}
+
+// handleInventoryError 库存业务错误返回400，资源不存在返回404，其余返回500
+func handleInventoryError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidInventoryAdjustment, err == pkgerrors.ErrBundleStockDerived:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
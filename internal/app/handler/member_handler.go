@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// MemberHandler handles membership API endpoints
+type MemberHandler struct {
+	memberService *service.MemberService
+}
+
+// NewMemberHandler creates a new member handler
+func NewMemberHandler() *MemberHandler {
+	return &MemberHandler{
+		memberService: service.NewMemberService(),
+	}
+}
+
+// GetMemberInfo gets the membership info of the current user
+func (h *MemberHandler) GetMemberInfo(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	info, err := h.memberService.GetMemberInfo(reqUser.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(info))
+}
+
+// GetTiers gets all membership tiers
+func (h *MemberHandler) GetTiers(c *gin.Context) {
+	tiers, err := h.memberService.GetTiers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(tiers))
+}
+
+// CreateTier creates a membership tier (admin)
+func (h *MemberHandler) CreateTier(c *gin.Context) {
+	var req request.MemberTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	tier, err := h.memberService.CreateTier(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(tier))
+}
+
+// UpdateTier updates a membership tier (admin)
+func (h *MemberHandler) UpdateTier(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.MemberTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	tier, err := h.memberService.UpdateTier(id, req)
+	if err != nil {
+		if err == pkgerrors.ErrTierNotFound {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		} else {
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(tier))
+}
+
+// DeleteTier deletes a membership tier (admin)
+func (h *MemberHandler) DeleteTier(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.memberService.DeleteTier(id); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// SetMemberPrice sets a fixed member price of a product (admin)
+func (h *MemberHandler) SetMemberPrice(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+		return
+	}
+
+	var req request.MemberPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.memberService.SetMemberPrice(productID, req); err != nil {
+		if pkgerrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		} else {
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// DeleteMemberPrice removes a fixed member price of a product (admin)
+func (h *MemberHandler) DeleteMemberPrice(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+		return
+	}
+	tierID, err := strconv.ParseUint(c.Param("tierId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid tier ID"))
+		return
+	}
+
+	if err := h.memberService.DeleteMemberPrice(productID, tierID); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/colinjuang/shop-go/internal/app/request"
 	"github.com/colinjuang/shop-go/internal/app/response"
 	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -68,7 +69,7 @@ func (h *OrderHandler) CreateOrderAndPay(c *gin.Context) {
 
 	err := h.orderService.CreateOrderAndPay(reqUser.UserID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		h.handleOrderError(c, err)
 		return
 	}
 
@@ -91,13 +92,36 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	err := h.orderService.CreateOrder(reqUser.UserID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		h.handleOrderError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse(nil))
 }
 
+// CheckoutCart 结算购物车中选中的商品，返回创建的订单
+func (h *OrderHandler) CheckoutCart(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req request.CheckoutCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	order, err := h.orderService.CheckoutCart(reqUser.UserID, req)
+	if err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(order))
+}
+
 // GetWechatPayInfo 获取微信支付信息
 func (h *OrderHandler) GetWechatPayInfo(c *gin.Context) {
 	reqUser := middleware.GetRequestUser(c)
@@ -119,10 +143,18 @@ func (h *OrderHandler) GetWechatPayInfo(c *gin.Context) {
 		return
 	}
 
+	// 分阶段支付的订单按当前阶段的支付单号和金额发起支付
+	paymentNo, amount, err := h.orderService.GetAmountDue(order)
+	if err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
 	// In a real implementation, we would call WeChat Payment API
 	// For now, we'll return a mock response
 	paymentResponse := response.PaymentResponse{
-		PaymentID: "wx" + orderNo,
+		PaymentID: "wx" + paymentNo,
+		Amount:    amount,
 		AppID:     "your-app-id",
 		TimeStamp: strconv.FormatInt(order.CreatedAt.Unix(), 10),
 		NonceStr:  "random-string",
@@ -157,10 +189,12 @@ func (h *OrderHandler) CheckWechatPayStatus(c *gin.Context) {
 
 	// In a real implementation, we would check payment status with WeChat
 	// For now, we'll simulate payment success
-	if order.Status == model.OrderStatusPending {
-		// Update order status to paid
-		err = h.orderService.UpdateOrderStatus(order.ID, reqUser.UserID, model.OrderStatusPaid)
-		if err != nil {
+	if order.Status == model.OrderStatusPending || order.Status == model.OrderStatusDepositPaid {
+		// Update order status to paid, group-buy orders wait for the group to fill
+		// and pre-orders are paid in two stages
+		err = h.orderService.MarkPaid(reqUser.UserID, order.ID)
+		if err != nil && err != pkgerrors.ErrOrderStatusInvalid &&
+			err != pkgerrors.ErrPaymentWindowNotOpen && err != pkgerrors.ErrPaymentWindowClosed {
 			c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 			return
 		}
@@ -174,9 +208,10 @@ func (h *OrderHandler) CheckWechatPayStatus(c *gin.Context) {
 	}
 
 	resp := gin.H{
-		"order_no": order.OrderNo,
-		"status":   order.Status,
-		"paid":     order.Status >= model.OrderStatusPaid,
+		"order_no":     order.OrderNo,
+		"status":       order.Status,
+		"paid":         order.Status >= model.OrderStatusPaid && order.Status != model.OrderStatusDepositPaid,
+		"deposit_paid": order.Status == model.OrderStatusDepositPaid, // 预售订单已付定金待付尾款
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse(resp))
@@ -219,3 +254,107 @@ func (h *OrderHandler) GetOrderList(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
 }
+
+// ConfirmReceipt 确认收货
+func (h *OrderHandler) ConfirmReceipt(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid order ID"))
+		return
+	}
+
+	if err := h.orderService.ConfirmReceipt(reqUser.UserID, orderID); err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// CancelOrder 取消待支付订单
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid order ID"))
+		return
+	}
+
+	if err := h.orderService.CancelOrder(reqUser.UserID, orderID); err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// RefundOrder 订单退款（管理员）
+func (h *OrderHandler) RefundOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid order ID"))
+		return
+	}
+
+	if err := h.orderService.RefundOrder(orderID, "管理员退款"); err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// RefundOrderItems 按订单项部分退款（管理员）
+func (h *OrderHandler) RefundOrderItems(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid order ID"))
+		return
+	}
+
+	var req request.RefundOrderItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.orderService.RefundOrderItems(orderID, req); err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handleOrderError 将业务错误转换为 400，其余为 500
+func (h *OrderHandler) handleOrderError(c *gin.Context, err error) {
+	// 超出限购时返回限购详情，供小程序展示
+	if limitErr, ok := pkgerrors.AsPurchaseLimitError(err); ok {
+		c.JSON(http.StatusBadRequest, response.ErrorResponseWithData(http.StatusBadRequest, limitErr.Message, limitErr))
+		return
+	}
+
+	switch err {
+	case pkgerrors.ErrOutOfStock, pkgerrors.ErrProductOffSale, pkgerrors.ErrCartNotFound,
+		pkgerrors.ErrAddressNotFound, pkgerrors.ErrOrderNotFound,
+		pkgerrors.ErrOrderStatusInvalid, pkgerrors.ErrCartCheckoutPending, pkgerrors.ErrInvalidInput,
+		pkgerrors.ErrPaymentWindowNotOpen, pkgerrors.ErrPaymentWindowClosed,
+		pkgerrors.ErrInsufficientPoints, pkgerrors.ErrPointsExceedLimit,
+		pkgerrors.ErrRefundQuantityExceeded, pkgerrors.ErrPartialRefundNotAllowed,
+		pkgerrors.ErrInvalidDeliveryDate:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
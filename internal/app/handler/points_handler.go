@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PointsHandler handles loyalty points API endpoints
+type PointsHandler struct {
+	pointsService *service.PointsService
+}
+
+// NewPointsHandler creates a new points handler
+func NewPointsHandler() *PointsHandler {
+	return &PointsHandler{
+		pointsService: service.NewPointsService(),
+	}
+}
+
+// GetBalance gets the points balance of the current user
+func (h *PointsHandler) GetBalance(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	balance, err := h.pointsService.GetBalance(reqUser.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(balance))
+}
+
+// GetHistory gets the points history of the current user
+func (h *PointsHandler) GetHistory(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	pagination, err := h.pointsService.GetHistory(reqUser.UserID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// GetUserHistory gets the points history of any user (admin)
+func (h *PointsHandler) GetUserHistory(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid user ID"))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	pagination, err := h.pointsService.GetHistory(userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// AdjustPoints changes a user's points balance manually (admin)
+func (h *PointsHandler) AdjustPoints(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+
+	var req request.AdjustPointsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	err := h.pointsService.Adjust(reqUser.UserID, req)
+	if err != nil {
+		if err == pkgerrors.ErrInsufficientPoints {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		} else {
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// GetRules gets the earn rate of all categories (admin)
+func (h *PointsHandler) GetRules(c *gin.Context) {
+	rules, err := h.pointsService.GetRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(rules))
+}
+
+// SaveRule creates or updates the earn rate of a category (admin)
+func (h *PointsHandler) SaveRule(c *gin.Context) {
+	var req request.PointsRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.pointsService.SaveRule(req); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// DeleteRule removes the earn rate of a category (admin)
+func (h *PointsHandler) DeleteRule(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("categoryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid category ID"))
+		return
+	}
+
+	if err := h.pointsService.DeleteRule(categoryID); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// getPageParams 获取分页参数
+func getPageParams(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	// Apply minimum values
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PreSaleHandler handles pre-sale API endpoints
+type PreSaleHandler struct {
+	preSaleService *service.PreSaleService
+}
+
+// NewPreSaleHandler creates a new pre-sale handler
+func NewPreSaleHandler() *PreSaleHandler {
+	return &PreSaleHandler{
+		preSaleService: service.NewPreSaleService(),
+	}
+}
+
+// GetActivePreSales gets pre-sales open for deposit or upcoming
+func (h *PreSaleHandler) GetActivePreSales(c *gin.Context) {
+	preSales, err := h.preSaleService.GetActivePreSales()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(preSales))
+}
+
+// GetPreSale gets a pre-sale by ID
+func (h *PreSaleHandler) GetPreSale(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	preSale, err := h.preSaleService.GetPreSale(id)
+	if err != nil {
+		handlePreSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(preSale))
+}
+
+// PlaceOrder places a pre-order for the current user
+func (h *PreSaleHandler) PlaceOrder(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.PreSaleOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result, err := h.preSaleService.PlaceOrder(reqUser.UserID, id, req)
+	if err != nil {
+		handlePreSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(result))
+}
+
+// GetPreSales gets all pre-sales with pagination (admin)
+func (h *PreSaleHandler) GetPreSales(c *gin.Context) {
+	page, pageSize := getPageParams(c)
+	pagination, err := h.preSaleService.GetPreSales(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// CreatePreSale creates a pre-sale (admin)
+func (h *PreSaleHandler) CreatePreSale(c *gin.Context) {
+	var req request.PreSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	preSale, err := h.preSaleService.CreatePreSale(req)
+	if err != nil {
+		handlePreSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(preSale))
+}
+
+// UpdatePreSale updates a pre-sale before it starts (admin)
+func (h *PreSaleHandler) UpdatePreSale(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.PreSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	preSale, err := h.preSaleService.UpdatePreSale(id, req)
+	if err != nil {
+		handlePreSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(preSale))
+}
+
+// SetPreSaleStatus enables or disables a pre-sale (admin)
+func (h *PreSaleHandler) SetPreSaleStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.PreSaleStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.preSaleService.SetPreSaleStatus(id, req.Status); err != nil {
+		handlePreSaleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handlePreSaleError 预售业务错误返回400，资源不存在返回404，其余返回500
+func handlePreSaleError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrPreSaleNotStarted,
+		err == pkgerrors.ErrPreSaleEnded,
+		err == pkgerrors.ErrPreSaleStarted,
+		err == pkgerrors.ErrPreSaleInvalidSchedule,
+		err == pkgerrors.ErrOutOfStock:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/colinjuang/shop-go/internal/app/middleware"
 	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
 	"github.com/colinjuang/shop-go/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -12,13 +18,17 @@ import (
 
 // ProductHandler handles product-related API endpoints
 type ProductHandler struct {
-	productService *service.ProductService
+	productService   *service.ProductService
+	historyService   *service.HistoryService
+	attributeService *service.AttributeService
 }
 
 // NewProductHandler creates a new product handler
 func NewProductHandler() *ProductHandler {
 	return &ProductHandler{
-		productService: service.NewProductService(),
+		productService:   service.NewProductService(),
+		historyService:   service.NewHistoryService(),
+		attributeService: service.NewAttributeService(),
 	}
 }
 
@@ -31,12 +41,24 @@ func (h *ProductHandler) GetProductDetail(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.GetProductByID(id)
+	userID := viewerID(c)
+	product, err := h.productService.GetProductByID(id, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
+	// 登录用户记录浏览足迹，在后台进行，不影响详情返回及其耗时
+	if userID > 0 {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := h.historyService.RecordView(ctx, userID, id); err != nil {
+				logger.Warnf("Failed to record view of product %d by user %d: %v", id, userID, err)
+			}
+		}()
+	}
+
 	c.JSON(http.StatusOK, response.SuccessResponse(product))
 }
 
@@ -54,13 +76,8 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		pageSize = 10
 	}
 
-	// Get category ID filter
-	var categoryID *uint64
-	if idStr := c.Query("category_id"); idStr != "" {
-		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
-			categoryID = &id
-		}
-	}
+	// Get category ID, recommend and attribute value filters
+	categoryID, recommend, valueIDs := getProductFilters(c)
 
 	// Get hot filter
 	var hot *bool
@@ -74,20 +91,8 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		}
 	}
 
-	// Get recommend filter
-	var recommend *bool
-	if recStr := c.Query("recommend"); recStr != "" {
-		if recStr == "1" || recStr == "true" {
-			recVal := true
-			recommend = &recVal
-		} else if recStr == "0" || recStr == "false" {
-			recVal := false
-			recommend = &recVal
-		}
-	}
-
 	// Get products
-	pagination, err := h.productService.GetProducts(page, pageSize, categoryID, hot, recommend)
+	pagination, err := h.productService.GetProducts(page, pageSize, categoryID, hot, recommend, valueIDs, viewerID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
@@ -104,7 +109,7 @@ func (h *ProductHandler) GetRecommendProducts(c *gin.Context) {
 		limit = l
 	}
 
-	products, err := h.productService.GetRecommendProducts(limit)
+	products, err := h.productService.GetRecommendProducts(limit, viewerID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
@@ -121,7 +126,7 @@ func (h *ProductHandler) GetHotProducts(c *gin.Context) {
 		limit = l
 	}
 
-	products, err := h.productService.GetHotProducts(limit)
+	products, err := h.productService.GetHotProducts(limit, viewerID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
@@ -129,3 +134,116 @@ func (h *ProductHandler) GetHotProducts(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.SuccessResponse(products))
 }
+
+// GetSalesRanking gets the best sellers of a rolling window, overall or within a category
+func (h *ProductHandler) GetSalesRanking(c *gin.Context) {
+	window := c.DefaultQuery("window", "24h")
+
+	var categoryID uint64
+	if idStr := c.Query("category_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid category ID"))
+			return
+		}
+		categoryID = id
+	}
+
+	limit := 20
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	ranking, err := h.productService.GetSalesRanking(window, categoryID, limit, viewerID(c))
+	if err != nil {
+		if err == pkgerrors.ErrInvalidRankingWindow {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(ranking))
+}
+
+// GetStock gets the stock of a product that can be delivered on ?delivery_date= (today when empty)
+func (h *ProductHandler) GetStock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	stock, err := h.productService.GetStockOn(id, c.Query("delivery_date"))
+	if err != nil {
+		switch {
+		case pkgerrors.IsNotFound(err):
+			c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		case err == pkgerrors.ErrInvalidDeliveryDate:
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(stock))
+}
+
+// GetFacets gets the attribute filters of the product list with product counts per value
+func (h *ProductHandler) GetFacets(c *gin.Context) {
+	categoryID, recommend, valueIDs := getProductFilters(c)
+
+	facets, err := h.attributeService.GetFacets(categoryID, recommend, valueIDs)
+	if err != nil {
+		if pkgerrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(facets))
+}
+
+// getProductFilters 解析商品列表的分类、推荐和属性值筛选，格式错误的参数忽略。属性值为逗号分隔的ID，如 values=3,7
+func getProductFilters(c *gin.Context) (*uint64, *bool, []uint64) {
+	var categoryID *uint64
+	if idStr := c.Query("category_id"); idStr != "" {
+		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
+			categoryID = &id
+		}
+	}
+
+	var recommend *bool
+	if recStr := c.Query("recommend"); recStr != "" {
+		if recStr == "1" || recStr == "true" {
+			recVal := true
+			recommend = &recVal
+		} else if recStr == "0" || recStr == "false" {
+			recVal := false
+			recommend = &recVal
+		}
+	}
+
+	var valueIDs []uint64
+	if valuesStr := c.Query("values"); valuesStr != "" {
+		for _, part := range strings.Split(valuesStr, ",") {
+			if id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64); err == nil {
+				valueIDs = append(valueIDs, id)
+			}
+		}
+	}
+
+	return categoryID, recommend, valueIDs
+}
+
+// viewerID 获取当前浏览用户ID，游客返回0
+func viewerID(c *gin.Context) uint64 {
+	if reqUser := middleware.GetRequestUser(c); reqUser != nil {
+		return reqUser.UserID
+	}
+	return 0
+}
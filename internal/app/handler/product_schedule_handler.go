@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ProductScheduleHandler handles product schedule and price history API endpoints
+type ProductScheduleHandler struct {
+	scheduleService *service.ProductScheduleService
+}
+
+// NewProductScheduleHandler creates a new product schedule handler
+func NewProductScheduleHandler() *ProductScheduleHandler {
+	return &ProductScheduleHandler{
+		scheduleService: service.NewProductScheduleService(),
+	}
+}
+
+// GetSchedules gets the schedules of a product (admin)
+func (h *ProductScheduleHandler) GetSchedules(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	schedules, err := h.scheduleService.GetSchedules(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(schedules))
+}
+
+// CreateSchedule schedules a change of a product (admin)
+func (h *ProductScheduleHandler) CreateSchedule(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.ProductScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	schedules, err := h.scheduleService.CreateSchedule(productID, req)
+	if err != nil {
+		handleProductScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(schedules))
+}
+
+// CancelSchedule cancels a pending schedule (admin)
+func (h *ProductScheduleHandler) CancelSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.scheduleService.CancelSchedule(id); err != nil {
+		handleProductScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// GetPriceHistory gets the price changes of a product, or the price in force at the time given by ?at= (admin)
+func (h *ProductScheduleHandler) GetPriceHistory(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if atParam := c.Query("at"); atParam != "" {
+		at, err := time.Parse(time.RFC3339, atParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid time, expected RFC3339"))
+			return
+		}
+		price, err := h.scheduleService.GetPriceAt(productID, at)
+		if err != nil {
+			handleProductScheduleError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, response.SuccessResponse(price))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	histories, err := h.scheduleService.GetPriceHistory(productID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(histories))
+}
+
+// handleProductScheduleError 定时计划业务错误返回400，资源不存在返回404，其余返回500
+func handleProductScheduleError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidProductSchedule, err == pkgerrors.ErrProductScheduleStatusInvalid:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
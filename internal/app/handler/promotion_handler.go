@@ -2,13 +2,16 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/colinjuang/shop-go/internal/app/request"
 	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
-// PromotionHandler handles home page API endpoints
+// PromotionHandler handles promotion API endpoints
 type PromotionHandler struct {
 	promotionService *service.PromotionService
 }
@@ -20,7 +23,7 @@ func NewPromotionHandler() *PromotionHandler {
 	}
 }
 
-// GetPromotions gets all promotions
+// GetPromotions gets the promotion ads running now
 func (h *PromotionHandler) GetPromotions(c *gin.Context) {
 	promotions, err := h.promotionService.GetPromotions()
 	if err != nil {
@@ -30,3 +33,89 @@ func (h *PromotionHandler) GetPromotions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.SuccessResponse(promotions))
 }
+
+// GetAdminPromotions gets all promotions, optionally of one ?status= (admin)
+func (h *PromotionHandler) GetAdminPromotions(c *gin.Context) {
+	var status *int
+	if statusStr := c.Query("status"); statusStr != "" {
+		if s, err := strconv.Atoi(statusStr); err == nil {
+			status = &s
+		}
+	}
+
+	promotions, err := h.promotionService.GetAdminPromotions(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(promotions))
+}
+
+// CreatePromotion creates a promotion (admin)
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req request.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(req)
+	if err != nil {
+		handlePromotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(promotion))
+}
+
+// UpdatePromotion updates a promotion (admin)
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(id, req)
+	if err != nil {
+		handlePromotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(promotion))
+}
+
+// DeletePromotion deletes a promotion (admin)
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.promotionService.DeletePromotion(id); err != nil {
+		handlePromotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handlePromotionError 促销业务错误返回400，资源不存在返回404，其余返回500
+func handlePromotionError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidPromotion:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
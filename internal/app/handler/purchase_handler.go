@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseHandler handles supplier and purchase order API endpoints
+type PurchaseHandler struct {
+	purchaseService *service.PurchaseService
+}
+
+// NewPurchaseHandler creates a new purchase handler
+func NewPurchaseHandler() *PurchaseHandler {
+	return &PurchaseHandler{
+		purchaseService: service.NewPurchaseService(),
+	}
+}
+
+// GetSuppliers gets the suppliers, optionally of one ?status= (admin)
+func (h *PurchaseHandler) GetSuppliers(c *gin.Context) {
+	var status *int
+	if statusStr := c.Query("status"); statusStr != "" {
+		if s, err := strconv.Atoi(statusStr); err == nil {
+			status = &s
+		}
+	}
+
+	suppliers, err := h.purchaseService.GetSuppliers(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(suppliers))
+}
+
+// CreateSupplier creates a supplier (admin)
+func (h *PurchaseHandler) CreateSupplier(c *gin.Context) {
+	var req request.SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	supplier, err := h.purchaseService.CreateSupplier(req)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(supplier))
+}
+
+// UpdateSupplier updates a supplier (admin)
+func (h *PurchaseHandler) UpdateSupplier(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	supplier, err := h.purchaseService.UpdateSupplier(id, req)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(supplier))
+}
+
+// GetPurchaseOrders gets purchase orders, optionally of a ?supplier_id= and a ?status= (admin)
+func (h *PurchaseHandler) GetPurchaseOrders(c *gin.Context) {
+	var supplierID uint64
+	if idStr := c.Query("supplier_id"); idStr != "" {
+		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
+			supplierID = id
+		}
+	}
+	var status *int
+	if statusStr := c.Query("status"); statusStr != "" {
+		if s, err := strconv.Atoi(statusStr); err == nil {
+			status = &s
+		}
+	}
+
+	page, pageSize := getPageParams(c)
+	orders, err := h.purchaseService.GetPurchaseOrders(supplierID, status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(orders))
+}
+
+// GetPurchaseOrder gets a purchase order with its items (admin)
+func (h *PurchaseHandler) GetPurchaseOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	order, err := h.purchaseService.GetPurchaseOrder(id)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(order))
+}
+
+// CreatePurchaseOrder creates a draft purchase order (admin)
+func (h *PurchaseHandler) CreatePurchaseOrder(c *gin.Context) {
+	var req request.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	reqUser := middleware.GetRequestUser(c)
+	order, err := h.purchaseService.CreatePurchaseOrder(reqUser.UserID, req)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(order))
+}
+
+// UpdatePurchaseOrder changes a draft purchase order (admin)
+func (h *PurchaseHandler) UpdatePurchaseOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	order, err := h.purchaseService.UpdatePurchaseOrder(id, req)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(order))
+}
+
+// SubmitPurchaseOrder marks a draft purchase order as ordered (admin)
+func (h *PurchaseHandler) SubmitPurchaseOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	order, err := h.purchaseService.SubmitPurchaseOrder(id)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(order))
+}
+
+// CancelPurchaseOrder cancels a purchase order, or closes it when partially received (admin)
+func (h *PurchaseHandler) CancelPurchaseOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	order, err := h.purchaseService.CancelPurchaseOrder(id)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(order))
+}
+
+// ReceivePurchaseOrder receives a delivery of a purchase order into stock (admin)
+func (h *PurchaseHandler) ReceivePurchaseOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.PurchaseReceiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	reqUser := middleware.GetRequestUser(c)
+	order, err := h.purchaseService.ReceivePurchaseOrder(reqUser.UserID, id, req)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(order))
+}
+
+// GetReorderSuggestions gets the products to reorder based on recent sales (admin)
+func (h *PurchaseHandler) GetReorderSuggestions(c *gin.Context) {
+	suggestions, err := h.purchaseService.GetReorderSuggestions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(suggestions))
+}
+
+// handlePurchaseError 采购业务错误返回400，资源不存在返回404，其余返回500
+func handlePurchaseError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidPurchaseOrder,
+		err == pkgerrors.ErrPurchaseOrderStatusInvalid,
+		err == pkgerrors.ErrPurchaseReceiveExceeded,
+		err == pkgerrors.ErrBundleStockDerived:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseLimitHandler handles purchase limit API endpoints
+type PurchaseLimitHandler struct {
+	limitService *service.PurchaseLimitService
+}
+
+// NewPurchaseLimitHandler creates a new purchase limit handler
+func NewPurchaseLimitHandler() *PurchaseLimitHandler {
+	return &PurchaseLimitHandler{
+		limitService: service.NewPurchaseLimitService(),
+	}
+}
+
+// GetLimits gets the purchase limit rules of a product (admin)
+func (h *PurchaseLimitHandler) GetLimits(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	limits, err := h.limitService.GetLimits(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(limits))
+}
+
+// CreateLimit creates a purchase limit rule on a product (admin)
+func (h *PurchaseLimitHandler) CreateLimit(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.PurchaseLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	limit, err := h.limitService.CreateLimit(productID, req)
+	if err != nil {
+		handlePurchaseLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(limit))
+}
+
+// UpdateLimit updates a purchase limit rule (admin)
+func (h *PurchaseLimitHandler) UpdateLimit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	var req request.PurchaseLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	limit, err := h.limitService.UpdateLimit(id, req)
+	if err != nil {
+		handlePurchaseLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(limit))
+}
+
+// DeleteLimit deletes a purchase limit rule (admin)
+func (h *PurchaseLimitHandler) DeleteLimit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := h.limitService.DeleteLimit(id); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(nil))
+}
+
+// handlePurchaseLimitError 限购规则业务错误返回400，资源不存在返回404，其余返回500
+func handlePurchaseLimitError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrInvalidPurchaseLimit:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
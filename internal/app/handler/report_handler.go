@@ -104,3 +104,35 @@ func (h *ReportHandler) ExportProducts(c *gin.Context) {
 		"url": csvURL,
 	}))
 }
+
+// ExportPurchaseOrder exports a purchase order as a PDF or, with ?format=csv, a CSV file (admin)
+func (h *ReportHandler) ExportPurchaseOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	// Set context with timeout
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	var fileURL string
+	switch c.DefaultQuery("format", "pdf") {
+	case "pdf":
+		fileURL, err = h.reportService.GeneratePurchaseOrderPDF(ctx, id)
+	case "csv":
+		fileURL, err = h.reportService.ExportPurchaseOrderToCSV(ctx, id)
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid format"))
+		return
+	}
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(gin.H{
+		"url": fileURL,
+	}))
+}
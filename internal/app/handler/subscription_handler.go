@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/middleware"
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SubscriptionHandler handles recurring subscription API endpoints
+type SubscriptionHandler struct {
+	subscriptionService *service.SubscriptionService
+}
+
+// NewSubscriptionHandler creates a new subscription handler
+func NewSubscriptionHandler() *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: service.NewSubscriptionService(),
+	}
+}
+
+// CreateSubscription creates a subscription plan for the current user
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var req request.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	subscription, err := h.subscriptionService.CreateSubscription(reqUser.UserID, req)
+	if err != nil {
+		handleSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(subscription))
+}
+
+// GetSubscriptions gets the subscriptions of the current user
+func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	page, pageSize := getPageParams(c)
+	pagination, err := h.subscriptionService.GetUserSubscriptions(reqUser.UserID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(pagination))
+}
+
+// GetSubscription gets a subscription of the current user with its deliveries
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	subscription, err := h.subscriptionService.GetSubscription(reqUser.UserID, id)
+	if err != nil {
+		handleSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(subscription))
+}
+
+// PaySubscription pays the prepayment of a prepaid plan
+func (h *SubscriptionHandler) PaySubscription(c *gin.Context) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	// In a real implementation, the prepayment would be confirmed by WeChat Pay
+	// For now, we'll simulate payment success
+	subscription, err := h.subscriptionService.PaySubscription(reqUser.UserID, id)
+	if err != nil {
+		handleSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(subscription))
+}
+
+// PauseSubscription pauses a subscription
+func (h *SubscriptionHandler) PauseSubscription(c *gin.Context) {
+	h.changeSubscription(c, h.subscriptionService.PauseSubscription)
+}
+
+// ResumeSubscription resumes a paused subscription
+func (h *SubscriptionHandler) ResumeSubscription(c *gin.Context) {
+	h.changeSubscription(c, h.subscriptionService.ResumeSubscription)
+}
+
+// SkipNextDelivery skips the next delivery of a subscription
+func (h *SubscriptionHandler) SkipNextDelivery(c *gin.Context) {
+	h.changeSubscription(c, h.subscriptionService.SkipNextDelivery)
+}
+
+// CancelSubscription cancels a subscription and refunds the unused prepayment
+func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
+	h.changeSubscription(c, h.subscriptionService.CancelSubscription)
+}
+
+// changeSubscription 对当前用户的订阅执行操作并返回最新的订阅
+func (h *SubscriptionHandler) changeSubscription(c *gin.Context, change func(userID, id uint64) error) {
+	reqUser := middleware.GetRequestUser(c)
+	if reqUser == nil {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid ID"))
+		return
+	}
+
+	if err := change(reqUser.UserID, id); err != nil {
+		handleSubscriptionError(c, err)
+		return
+	}
+
+	subscription, err := h.subscriptionService.GetSubscription(reqUser.UserID, id)
+	if err != nil {
+		handleSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(subscription))
+}
+
+// handleSubscriptionError 订阅业务错误返回400，资源不存在返回404，其余返回500
+func handleSubscriptionError(c *gin.Context, err error) {
+	switch {
+	case pkgerrors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	case err == pkgerrors.ErrSubscriptionInvalidPlan,
+		err == pkgerrors.ErrSubscriptionStatusInvalid,
+		err == pkgerrors.ErrSubscriptionNotPrepaid:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+}
@@ -23,7 +23,8 @@ func (h *WechatLoginHandler) WechatMiniLogin(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
 		return
 	}
-	token, err := h.wechatLoginService.WechatMiniLogin(code)
+	// 通过分享的小程序码进入时携带scene，首次登录绑定推荐人
+	token, err := h.wechatLoginService.WechatMiniLogin(code, c.Query("scene"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,151 @@
+package job
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/pkg/scheduler"
+	"github.com/colinjuang/shop-go/internal/service"
+)
+
+// RegisterJobs registers all background jobs of the application
+func RegisterJobs(s *scheduler.Scheduler) {
+	pointsService := service.NewPointsService()
+	memberService := service.NewMemberService()
+	flashSaleService := service.NewFlashSaleService()
+	groupBuyService := service.NewGroupBuyService()
+	preSaleService := service.NewPreSaleService()
+	subscriptionService := service.NewSubscriptionService()
+	favoriteService := service.NewFavoriteService()
+	historyService := service.NewHistoryService()
+	recommendationService := service.NewRecommendationService()
+	salesRankingService := service.NewSalesRankingService()
+	productMediaService := service.NewProductMediaService()
+	productScheduleService := service.NewProductScheduleService()
+	inventoryService := service.NewInventoryService()
+	bannerService := service.NewBannerService()
+
+	// 积分过期
+	s.Register(scheduler.Job{
+		Name:    "points_expire",
+		DailyAt: "02:00",
+		Run:     pointsService.ExpirePoints,
+	})
+
+	// 会员等级重新计算
+	s.Register(scheduler.Job{
+		Name:    "member_tier_recalculate",
+		DailyAt: "03:00",
+		Run:     memberService.RecalculateTiers,
+	})
+
+	// 秒杀未支付订单超时取消
+	s.Register(scheduler.Job{
+		Name:     "flash_sale_order_timeout",
+		Interval: time.Minute,
+		Run:      flashSaleService.CancelUnpaidOrders,
+	})
+
+	// 秒杀库存校对
+	s.Register(scheduler.Job{
+		Name:     "flash_sale_stock_sync",
+		Interval: time.Minute,
+		Run:      flashSaleService.SyncStock,
+	})
+
+	// 拼团超时失败、自动退款及未支付参团取消
+	s.Register(scheduler.Job{
+		Name:     "group_buy_expire",
+		Interval: time.Minute,
+		Run:      groupBuyService.ExpireGroups,
+	})
+
+	// 预售定金、尾款逾期关单
+	s.Register(scheduler.Job{
+		Name:     "pre_sale_order_close",
+		Interval: time.Minute,
+		Run:      preSaleService.CloseOverdueOrders,
+	})
+
+	// 预售尾款截止前提醒
+	s.Register(scheduler.Job{
+		Name:     "pre_sale_balance_remind",
+		Interval: 10 * time.Minute,
+		Run:      preSaleService.RemindBalance,
+	})
+
+	// 周期订阅按配送日提前生成配送订单
+	s.Register(scheduler.Job{
+		Name:     "subscription_generate_orders",
+		Interval: time.Hour,
+		Run:      subscriptionService.GenerateOrders,
+	})
+
+	// 收藏商品到货、降价提醒
+	s.Register(scheduler.Job{
+		Name:     "favorite_watch",
+		Interval: 10 * time.Minute,
+		Run:      favoriteService.WatchFavorites,
+	})
+
+	// 浏览足迹同步到MySQL并清理过期记录
+	s.Register(scheduler.Job{
+		Name:     "history_sync",
+		Interval: 5 * time.Minute,
+		Run:      historyService.SyncHistories,
+	})
+
+	// 计算商品共同购买相似度
+	s.Register(scheduler.Job{
+		Name:    "recommendation_build",
+		DailyAt: "04:00",
+		Run:     recommendationService.BuildSimilarities,
+	})
+
+	// 销量排行对账，Redis数据丢失或每天从订单重建一次
+	s.Register(scheduler.Job{
+		Name:     "sales_ranking_reconcile",
+		Interval: 10 * time.Minute,
+		Run:      salesRankingService.ReconcileRankings,
+	})
+
+	// 清理不再被商品图文引用的MinIO对象
+	s.Register(scheduler.Job{
+		Name:    "media_gc",
+		DailyAt: "04:30",
+		Run:     productMediaService.CollectGarbage,
+	})
+
+	// 执行到期的商品定时上下架和调价
+	s.Register(scheduler.Job{
+		Name:     "product_schedule_apply",
+		Interval: time.Minute,
+		Run:      productScheduleService.ApplyDueSchedules,
+	})
+
+	// 低库存提醒管理员
+	s.Register(scheduler.Job{
+		Name:     "low_stock_alert",
+		Interval: 5 * time.Minute,
+		Run:      inventoryService.SendLowStockAlerts,
+	})
+
+	// 到期库存批次报废
+	s.Register(scheduler.Job{
+		Name:    "stock_batch_expire",
+		DailyAt: "00:10",
+		Run:     inventoryService.WriteOffExpiredBatches,
+	})
+
+	// 轮播图曝光和点击计数同步到MySQL
+	s.Register(scheduler.Job{
+		Name:     "banner_stats_sync",
+		Interval: 10 * time.Minute,
+		Run:      bannerService.SyncStats,
+	})
+
+	// 秒杀下单队列消费，每个副本都运行
+	s.RegisterWorker(scheduler.Worker{
+		Name: "flash_sale_order",
+		Run:  flashSaleService.ConsumeOrders,
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/colinjuang/shop-go/internal/app/response"
 	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/constant"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
@@ -27,6 +28,7 @@ type UserClaim struct {
 	City     string `json:"city"`
 	Province string `json:"province"`
 	District string `json:"district"`
+	Role     int    `json:"role"`
 }
 
 // AuthClaims represents JWT claims
@@ -129,6 +131,42 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware 可选登录，携带有效token时设置请求用户，否则按游客继续处理
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := ParseToken(strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		var user UserClaim
+		if err := json.Unmarshal([]byte(claims.AnyJson), &user); err == nil {
+			SetRequestUser(c, &user)
+		}
+		c.Next()
+	}
+}
+
+// AdminMiddleware 管理员权限校验，需在 AuthMiddleware 之后使用
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := GetRequestUser(c)
+		if user == nil || user.Role != constant.UserRoleAdmin {
+			c.JSON(403, response.ErrorResponse(403, "Forbidden"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // SetRequestUser 设置请求用户到上下文
 func SetRequestUser(c *gin.Context, user *UserClaim) {
 	c.Set(RequestUserKey, user)
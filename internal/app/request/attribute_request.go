@@ -0,0 +1,22 @@
+package request
+
+// AttributeValueRequest 属性值请求，id 为空时新增
+type AttributeValueRequest struct {
+	ID    uint64 `json:"id"`
+	Value string `json:"value" binding:"required"`
+}
+
+// AttributeRequest 属性创建和更新请求，values 按数组顺序排列，更新时未列出的值连同商品标签一起删除
+type AttributeRequest struct {
+	Code        string                  `json:"code" binding:"required"`
+	Name        string                  `json:"name" binding:"required"`
+	CategoryID  uint64                  `json:"categoryId"` // 0表示适用于全部分类
+	MultiValued bool                    `json:"multiValued"`
+	SortOrder   int                     `json:"sortOrder"`
+	Values      []AttributeValueRequest `json:"values" binding:"dive"`
+}
+
+// ProductAttributesRequest 设置商品属性值请求，替换商品原有的全部属性值
+type ProductAttributesRequest struct {
+	ValueIDs []uint64 `json:"valueIds"`
+}
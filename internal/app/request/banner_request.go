@@ -1,5 +1,18 @@
 package request
 
+import "time"
+
+// BannerRequest 轮播图创建和更新请求
 type BannerRequest struct {
-	ID uint64 `json:"id"`
+	Title     string     `json:"title" binding:"required"`
+	ImageUrl  string     `json:"imageUrl" binding:"required"`          // 上传接口返回的对象名或文件URL
+	LinkType  int        `json:"linkType" binding:"oneof=0 1 2 3 4 5"` // 0: 不跳转, 1: 商品, 2: 分类, 3: 促销活动, 4: 小程序页面, 5: 其他小程序
+	LinkValue string     `json:"linkValue"`                            // 商品、分类、促销ID或页面路径
+	LinkAppID string     `json:"linkAppId"`                            // 跳转其他小程序时的AppID
+	StartAt   *time.Time `json:"startAt"`                              // 为空表示立即展示
+	EndAt     *time.Time `json:"endAt"`                                // 为空表示不结束
+	Audience  int        `json:"audience" binding:"oneof=0 1 2"`       // 0: 全部用户, 1: 新用户, 2: 会员
+	Cities    []string   `json:"cities"`                               // 为空表示不限城市
+	Status    *int       `json:"status" binding:"omitempty,oneof=0 1"` // 为空时启用
+	SortOrder int        `json:"sortOrder"`
 }
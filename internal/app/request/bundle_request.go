@@ -0,0 +1,12 @@
+package request
+
+// BundleItemRequest 组合商品组件请求
+type BundleItemRequest struct {
+	ProductID uint64 `json:"productID" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// SetBundleItemsRequest 设置组合商品组件请求
+type SetBundleItemsRequest struct {
+	Items []BundleItemRequest `json:"items" binding:"required,min=1,dive"`
+}
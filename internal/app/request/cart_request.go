@@ -3,6 +3,13 @@ package request
 type AddToCartRequest struct {
 	ProductID uint64 `json:"productId" binding:"required"`
 	Quantity  int    `json:"quantity" binding:"required"`
+	Blessing  string `json:"blessing" binding:"max=200"`
+}
+
+// UpdateCartQuantityRequest 修改购物车商品数量
+type UpdateCartQuantityRequest struct {
+	ID       uint64 `json:"id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,gte=1"`
 }
 
 type UpdateCartStatusRequest struct {
@@ -1,10 +1,31 @@
 package request
 
+// CategoryRequest 分类创建和更新请求，更新时不修改父分类，移动分类使用 CategoryMoveRequest
 type CategoryRequest struct {
-	ID        uint64 `json:"id"`
-	Name      string `json:"name"`
-	ParentID  uint64 `json:"parentId"`
-	Level     int    `json:"level"`
+	Name      string `json:"name" binding:"required"`
+	ParentID  uint64 `json:"parentId"` // 父分类ID，0表示一级分类
 	ImageUrl  string `json:"imageUrl"`
 	SortOrder int    `json:"sortOrder"`
 }
+
+// CategoryMoveRequest 移动分类请求，子孙分类和商品随之移动
+type CategoryMoveRequest struct {
+	ParentID  uint64 `json:"parentId"`  // 新的父分类ID，0表示移为一级分类
+	SortOrder *int   `json:"sortOrder"` // 在新父分类下的排序，为空时排在最后
+}
+
+// CategorySortRequest 同一父分类下的子分类排序请求
+type CategorySortRequest struct {
+	ParentID uint64   `json:"parentId"`                     // 0表示一级分类
+	IDs      []uint64 `json:"ids" binding:"required,min=1"` // 按新顺序列出全部子分类
+}
+
+// CategoryMergeRequest 合并分类请求，本分类的商品和子分类并入目标分类后删除本分类
+type CategoryMergeRequest struct {
+	TargetID uint64 `json:"targetId" binding:"required"`
+}
+
+// ProductCategoryRequest 修改商品所属分类请求
+type ProductCategoryRequest struct {
+	CategoryID uint64 `json:"categoryId" binding:"required"`
+}
@@ -0,0 +1,24 @@
+package request
+
+// CommissionRuleRequest 佣金规则请求，指定商品或分类二选一
+type CommissionRuleRequest struct {
+	ProductID  uint64  `json:"productID" binding:"required_without=CategoryID"`
+	CategoryID uint64  `json:"categoryID" binding:"required_without=ProductID"`
+	Level1Rate float64 `json:"level1Rate" binding:"min=0,max=1"`
+	Level2Rate float64 `json:"level2Rate" binding:"min=0,max=1"`
+}
+
+// ReferralClickRequest 分享点击上报请求
+type ReferralClickRequest struct {
+	Scene string `json:"scene" binding:"required"`
+}
+
+// WithdrawRequest 提现申请请求
+type WithdrawRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// WithdrawalReviewRequest 提现审核请求
+type WithdrawalReviewRequest struct {
+	Remark string `json:"remark"`
+}
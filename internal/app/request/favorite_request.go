@@ -0,0 +1,6 @@
+package request
+
+// AddFavoriteRequest 添加收藏请求
+type AddFavoriteRequest struct {
+	ProductID uint64 `json:"productID" binding:"required"`
+}
@@ -0,0 +1,27 @@
+package request
+
+import "time"
+
+// FlashSaleRequest 秒杀活动请求
+type FlashSaleRequest struct {
+	ProductID    uint64    `json:"productID" binding:"required"`
+	Title        string    `json:"title"`
+	DealPrice    float64   `json:"dealPrice" binding:"required,gt=0"`
+	Quantity     int       `json:"quantity" binding:"required,min=1"`
+	LimitPerUser int       `json:"limitPerUser" binding:"min=0"` // 0表示不限购
+	StartTime    time.Time `json:"startTime" binding:"required"`
+	EndTime      time.Time `json:"endTime" binding:"required,gtfield=StartTime"`
+}
+
+// FlashSaleBuyRequest 秒杀抢购请求
+type FlashSaleBuyRequest struct {
+	AddressID uint64 `json:"addressID" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+	Blessing  string `json:"blessing"`
+	Remark    string `json:"remark"`
+}
+
+// FlashSaleStatusRequest 秒杀活动状态请求
+type FlashSaleStatusRequest struct {
+	Status int `json:"status" binding:"oneof=0 1"` // 1: enabled, 0: disabled
+}
@@ -0,0 +1,26 @@
+package request
+
+import "time"
+
+// GroupBuyCampaignRequest 拼团活动请求
+type GroupBuyCampaignRequest struct {
+	ProductID  uint64    `json:"productID" binding:"required"`
+	Title      string    `json:"title"`
+	GroupPrice float64   `json:"groupPrice" binding:"required,gt=0"`
+	GroupSize  int       `json:"groupSize" binding:"required,min=2"`
+	TimeLimit  int       `json:"timeLimit" binding:"required,min=1"` // 成团时限（分钟）
+	StartTime  time.Time `json:"startTime" binding:"required"`
+	EndTime    time.Time `json:"endTime" binding:"required,gtfield=StartTime"`
+}
+
+// GroupBuyCampaignStatusRequest 拼团活动状态请求
+type GroupBuyCampaignStatusRequest struct {
+	Status int `json:"status" binding:"oneof=0 1"` // 1: enabled, 0: disabled
+}
+
+// GroupBuyJoinRequest 开团/参团请求，每人限购一件
+type GroupBuyJoinRequest struct {
+	AddressID uint64 `json:"addressID" binding:"required"`
+	Blessing  string `json:"blessing"`
+	Remark    string `json:"remark"`
+}
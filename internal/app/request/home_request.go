@@ -0,0 +1,14 @@
+package request
+
+// HomeSectionRequest 首页区块配置
+type HomeSectionRequest struct {
+	Key     string `json:"key" binding:"required,oneof=banners categories promotions recommend hot"`
+	Title   string `json:"title"`
+	Visible bool   `json:"visible"`
+	Limit   int    `json:"limit" binding:"gte=0,lte=50"` // 展示数量，0表示默认数量
+}
+
+// HomeSectionsRequest 首页区块配置请求，按数组顺序展示，未列出的区块隐藏
+type HomeSectionsRequest struct {
+	Sections []HomeSectionRequest `json:"sections" binding:"required,dive"`
+}
@@ -0,0 +1,21 @@
+package request
+
+// InventoryAdjustRequest 库存调整请求
+type InventoryAdjustRequest struct {
+	Type     int    `json:"type" binding:"required,oneof=4 5 6"` // 4: 人工调整, 5: 盘点, 6: 损耗报废
+	Quantity int    `json:"quantity"`                            // 人工调整为增减数量，盘点为实盘在库数量，损耗为报废数量
+	Remark   string `json:"remark"`
+}
+
+// StockAlertRequest 库存预警设置请求
+type StockAlertRequest struct {
+	Threshold int `json:"threshold" binding:"min=0"` // 可售库存不高于该值时提醒
+}
+
+// StockBatchRequest 库存批次入库请求
+type StockBatchRequest struct {
+	ReceivedAt    string `json:"receivedAt" binding:"required"` // 入库日期，格式 2006-01-02
+	ShelfLifeDays int    `json:"shelfLifeDays" binding:"required,min=1"`
+	Quantity      int    `json:"quantity" binding:"required,min=1"`
+	Remark        string `json:"remark"`
+}
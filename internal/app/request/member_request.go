@@ -0,0 +1,16 @@
+package request
+
+// MemberTierRequest 会员等级请求
+type MemberTierRequest struct {
+	Name         string  `json:"name" binding:"required"`
+	Level        int     `json:"level" binding:"required,min=1"`
+	MinSpend     float64 `json:"minSpend" binding:"min=0"`
+	DiscountRate float64 `json:"discountRate" binding:"required,gt=0,lte=1"`
+	FreeShipping bool    `json:"freeShipping"`
+}
+
+// MemberPriceRequest 商品会员价请求
+type MemberPriceRequest struct {
+	TierID uint64  `json:"tierID" binding:"required"`
+	Price  float64 `json:"price" binding:"required,gt=0"`
+}
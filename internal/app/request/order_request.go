@@ -2,15 +2,39 @@ package request
 
 // OrderRequest represents the order creation request
 type CreateOrderRequest struct {
-	CartIDs     []uint64 `json:"cartIDs"`
-	AddressID   uint64   `json:"addressID" binding:"required"`
-	PaymentType int      `json:"paymentType" binding:"required"`
+	CartIDs      []uint64 `json:"cartIDs"`
+	AddressID    uint64   `json:"addressID" binding:"required"`
+	PaymentType  int      `json:"paymentType" binding:"required"`
+	Points       int      `json:"points" binding:"min=0"` // 使用积分抵扣
+	DeliveryDate string   `json:"deliveryDate"`           // 期望配送日期，格式 2006-01-02，为空表示尽快配送
+}
+
+// CheckoutCartRequest 结算购物车中选中的商品
+type CheckoutCartRequest struct {
+	AddressID    uint64 `json:"addressID" binding:"required"`
+	PaymentType  int    `json:"paymentType" binding:"required"`
+	Points       int    `json:"points" binding:"min=0"` // 使用积分抵扣
+	DeliveryDate string `json:"deliveryDate"`           // 期望配送日期，格式 2006-01-02，为空表示尽快配送
 }
 
 type CreateOrderAndPayRequest struct {
-	AddressID uint64 `json:"addressID" binding:"required"`
-	ProductID uint64 `json:"productID" binding:"required"`
-	Quantity  int    `json:"quantity" binding:"required"`
-	Blessing  string `json:"blessing"`
-	Remark    string `json:"remark"`
+	AddressID    uint64 `json:"addressID" binding:"required"`
+	ProductID    uint64 `json:"productID" binding:"required"`
+	Quantity     int    `json:"quantity" binding:"required"`
+	Blessing     string `json:"blessing"`
+	Remark       string `json:"remark"`
+	Points       int    `json:"points" binding:"min=0"` // 使用积分抵扣
+	DeliveryDate string `json:"deliveryDate"`           // 期望配送日期，格式 2006-01-02，为空表示尽快配送
+}
+
+// RefundItemRequest 部分退款的订单项及数量
+type RefundItemRequest struct {
+	OrderItemID uint64 `json:"orderItemID" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"required,min=1"`
+}
+
+// RefundOrderItemsRequest 按订单项部分退款请求，组合商品可整套退或只退其中的组件
+type RefundOrderItemsRequest struct {
+	Items  []RefundItemRequest `json:"items" binding:"required,min=1,dive"`
+	Reason string              `json:"reason"`
 }
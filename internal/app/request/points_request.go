@@ -0,0 +1,14 @@
+package request
+
+// AdjustPointsRequest 管理员调整积分请求
+type AdjustPointsRequest struct {
+	UserID uint64 `json:"userID" binding:"required"`
+	Change int    `json:"change" binding:"required"` // 正数为增加，负数为扣减
+	Remark string `json:"remark" binding:"required"`
+}
+
+// PointsRuleRequest 分类积分规则请求
+type PointsRuleRequest struct {
+	CategoryID uint64  `json:"categoryID" binding:"required"`
+	EarnRate   float64 `json:"earnRate" binding:"min=0"`
+}
@@ -0,0 +1,30 @@
+package request
+
+import "time"
+
+// PreSaleRequest 预售活动请求
+type PreSaleRequest struct {
+	ProductID     uint64    `json:"productID" binding:"required"`
+	Title         string    `json:"title"`
+	PreSalePrice  float64   `json:"preSalePrice" binding:"required,gt=0"`
+	Deposit       float64   `json:"deposit" binding:"required,gt=0,ltfield=PreSalePrice"`
+	DepositStart  time.Time `json:"depositStart" binding:"required"`
+	DepositEnd    time.Time `json:"depositEnd" binding:"required,gtfield=DepositStart"`
+	BalanceStart  time.Time `json:"balanceStart" binding:"required"`
+	BalanceEnd    time.Time `json:"balanceEnd" binding:"required,gtfield=BalanceStart"`
+	ShipAt        time.Time `json:"shipAt"`
+	ForfeitPolicy string    `json:"forfeitPolicy" binding:"omitempty,oneof=forfeit refund"` // 为空时使用全局配置
+}
+
+// PreSaleStatusRequest 预售活动状态请求
+type PreSaleStatusRequest struct {
+	Status int `json:"status" binding:"oneof=0 1"` // 1: enabled, 0: disabled
+}
+
+// PreSaleOrderRequest 预售下单请求
+type PreSaleOrderRequest struct {
+	AddressID uint64 `json:"addressID" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+	Blessing  string `json:"blessing"`
+	Remark    string `json:"remark"`
+}
@@ -0,0 +1,29 @@
+package request
+
+// GalleryItemRequest 相册项请求，url 为上传接口返回的对象名或文件URL
+type GalleryItemRequest struct {
+	Type int    `json:"type" binding:"required,oneof=1 2"` // 1: 图片, 2: 视频
+	Url  string `json:"url" binding:"required"`
+}
+
+// SetGalleryRequest 设置商品相册请求，按数组顺序排列
+type SetGalleryRequest struct {
+	Items []GalleryItemRequest `json:"items" binding:"dive"`
+}
+
+// SortGalleryRequest 相册排序请求
+type SortGalleryRequest struct {
+	IDs []uint64 `json:"ids" binding:"required,min=1"` // 全部相册项ID，按新的顺序排列
+}
+
+// DetailBlockRequest 详情内容块请求
+type DetailBlockRequest struct {
+	Type    int    `json:"type" binding:"required,oneof=1 3"` // 1: 图片, 3: 富文本
+	Url     string `json:"url"`                               // 图片块的对象名或文件URL
+	Content string `json:"content"`                           // 文本块HTML，保存时清理
+}
+
+// SetDetailRequest 设置商品详情内容请求，按数组顺序排列
+type SetDetailRequest struct {
+	Blocks []DetailBlockRequest `json:"blocks" binding:"dive"`
+}
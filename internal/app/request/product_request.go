@@ -9,7 +9,6 @@ type ProductRequest struct {
 	SaleCount      int     `json:"saleCount"`
 	StockCount     int     `json:"stockCount"`
 	CategoryID     uint64  `json:"categoryID"`
-	SubCategoryID  uint64  `json:"subCategoryID"`
 	Material       string  `json:"material"`
 	Packing        string  `json:"packing"`
 	ImageUrl       string  `json:"imageUrl"`
@@ -0,0 +1,12 @@
+package request
+
+import "time"
+
+// ProductScheduleRequest 商品定时计划请求
+type ProductScheduleRequest struct {
+	Action int        `json:"action" binding:"required,oneof=1 2 3"` // 1: 上架, 2: 下架, 3: 调价
+	RunAt  time.Time  `json:"runAt" binding:"required"`
+	Price  float64    `json:"price"` // 调价后的价格
+	EndAt  *time.Time `json:"endAt"` // 调价结束时间，到时恢复原价，为空表示长期生效
+	Remark string     `json:"remark"`
+}
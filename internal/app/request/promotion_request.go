@@ -0,0 +1,26 @@
+package request
+
+import "time"
+
+// PromotionRequest 促销活动创建和更新请求
+type PromotionRequest struct {
+	Title           string     `json:"title" binding:"required"`
+	ImageUrl        string     `json:"imageUrl"` // 首页广告图片，上传接口返回的对象名或文件URL，为空时不在首页展示
+	Description     string     `json:"description"`
+	Scope           int        `json:"scope" binding:"oneof=0 1 2"`          // 0: 全部商品, 1: 指定分类, 2: 指定商品
+	CategoryIDs     []uint64   `json:"categoryIDs"`                          // 指定分类时必填，包括子孙分类的商品
+	ProductIDs      []uint64   `json:"productIDs"`                           // 指定商品时必填
+	RuleType        int        `json:"ruleType" binding:"oneof=0 1 2 3 4"`   // 0: 仅广告, 1: 满减, 2: 满折, 3: 买N送M, 4: 第二件半价
+	Threshold       float64    `json:"threshold" binding:"gte=0"`            // 满减、满折的门槛金额，满折为0表示不设门槛
+	Discount        float64    `json:"discount" binding:"gte=0"`             // 满减金额
+	Rate            float64    `json:"rate" binding:"gte=0"`                 // 满折折扣率，大于0小于1，如0.8为八折
+	BuyQty          int        `json:"buyQty" binding:"gte=0"`               // 买N送M的N
+	FreeQty         int        `json:"freeQty" binding:"gte=0"`              // 买N送M的M
+	Repeatable      bool       `json:"repeatable"`                           // 满减是否每满门槛减一次
+	Exclusive       bool       `json:"exclusive"`                            // 不与其他促销活动同时享受
+	CouponStackable bool       `json:"couponStackable"`                      // 可与优惠券叠加使用
+	Priority        int        `json:"priority"`                             // 叠加时优先级高的先计算
+	StartAt         *time.Time `json:"startAt"`                              // 为空表示立即开始
+	EndAt           *time.Time `json:"endAt"`                                // 为空表示不结束
+	Status          *int       `json:"status" binding:"omitempty,oneof=0 1"` // 为空时启用
+}
@@ -0,0 +1,11 @@
+package request
+
+import "time"
+
+// PurchaseLimitRequest 商品限购规则请求
+type PurchaseLimitRequest struct {
+	Period      int        `json:"period" binding:"min=0,max=3"` // 0: 累计, 1: 每天, 2: 每周, 3: 每月
+	MaxQuantity int        `json:"maxQuantity" binding:"required,min=1"`
+	StartAt     *time.Time `json:"startAt"`
+	EndAt       *time.Time `json:"endAt"`
+}
@@ -0,0 +1,43 @@
+package request
+
+// SupplierRequest 供应商创建和更新请求
+type SupplierRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Contact  string `json:"contact"`
+	Phone    string `json:"phone"`
+	WechatID string `json:"wechatID"`
+	Address  string `json:"address"`
+	LeadDays int    `json:"leadDays" binding:"min=0"`             // 下单到到货的天数，0表示使用默认值
+	Status   *int   `json:"status" binding:"omitempty,oneof=0 1"` // 0: 停用, 1: 启用，创建时默认启用
+	Remark   string `json:"remark"`
+}
+
+// PurchaseOrderRequest 采购单创建和草稿修改请求
+type PurchaseOrderRequest struct {
+	SupplierID uint64                     `json:"supplierID" binding:"required"`
+	ExpectedAt string                     `json:"expectedAt"` // 预计到货日期，格式 2006-01-02
+	Remark     string                     `json:"remark"`
+	Items      []PurchaseOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// PurchaseOrderItemRequest 采购单明细
+type PurchaseOrderItemRequest struct {
+	ProductID     uint64  `json:"productID" binding:"required"`
+	Quantity      int     `json:"quantity" binding:"required,min=1"`
+	UnitCost      float64 `json:"unitCost" binding:"min=0"`
+	ShelfLifeDays int     `json:"shelfLifeDays" binding:"min=0"` // 保质天数，大于0时到货按批次入库
+}
+
+// PurchaseReceiveRequest 采购单到货入库请求，可只收部分明细或部分数量
+type PurchaseReceiveRequest struct {
+	ReceivedAt string                       `json:"receivedAt"` // 到货日期，格式 2006-01-02，默认当天
+	Remark     string                       `json:"remark"`
+	Items      []PurchaseReceiveItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// PurchaseReceiveItemRequest 到货明细
+type PurchaseReceiveItemRequest struct {
+	ItemID        uint64 `json:"itemID" binding:"required"` // 采购单明细ID
+	Quantity      int    `json:"quantity" binding:"required,min=1"`
+	ShelfLifeDays *int   `json:"shelfLifeDays" binding:"omitempty,min=0"` // 本次到货的保质天数，为空时使用明细的保质天数
+}
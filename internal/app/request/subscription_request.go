@@ -0,0 +1,17 @@
+package request
+
+// SubscriptionRequest 创建周期订阅请求，指定商品或花艺师自选分类二选一
+type SubscriptionRequest struct {
+	ProductID       uint64  `json:"productID" binding:"required_without=CategoryID"`
+	CategoryID      uint64  `json:"categoryID" binding:"required_without=ProductID"`
+	Budget          float64 `json:"budget" binding:"omitempty,gt=0"` // 花艺师自选时每束的预算
+	Quantity        int     `json:"quantity" binding:"required,min=1"`
+	Frequency       string  `json:"frequency" binding:"required,oneof=weekly biweekly"`
+	Weekday         int     `json:"weekday" binding:"min=0,max=6"` // 配送日，0为周日
+	DeliverySlot    string  `json:"deliverySlot"`
+	AddressID       uint64  `json:"addressID" binding:"required"`
+	TotalDeliveries int     `json:"totalDeliveries" binding:"required,min=1,max=104"`
+	Prepaid         bool    `json:"prepaid"`
+	Blessing        string  `json:"blessing"`
+	Remark          string  `json:"remark"`
+}
@@ -0,0 +1,32 @@
+package response
+
+// AttributeValueResponse 属性值
+type AttributeValueResponse struct {
+	ID    uint64 `json:"id"`
+	Value string `json:"value"`
+}
+
+// ProductAttributeResponse 商品的一个属性及其值
+type ProductAttributeResponse struct {
+	ID     uint64                   `json:"id"`
+	Code   string                   `json:"code"`
+	Name   string                   `json:"name"`
+	Values []AttributeValueResponse `json:"values"`
+}
+
+// FacetValueResponse 筛选项的一个值
+type FacetValueResponse struct {
+	ID       uint64 `json:"id"`
+	Value    string `json:"value"`
+	Count    int64  `json:"count"`    // 选中该值后的商品数，同一属性的其他已选值不计入条件
+	Selected bool   `json:"selected"` // 是否已选中
+}
+
+// AttributeFacetResponse 商品列表的一个属性筛选项
+type AttributeFacetResponse struct {
+	ID          uint64               `json:"id"`
+	Code        string               `json:"code"`
+	Name        string               `json:"name"`
+	MultiValued bool                 `json:"multiValued"`
+	Values      []FacetValueResponse `json:"values"`
+}
@@ -6,8 +6,28 @@ type BannerResponse struct {
 	ID        uint64    `json:"id"`
 	Title     string    `json:"title"`
 	ImageUrl  string    `json:"imageUrl"`
-	ProductID uint64    `json:"productId"`
+	LinkType  int       `json:"linkType"` // 0: 不跳转, 1: 商品, 2: 分类, 3: 促销活动, 4: 小程序页面, 5: 其他小程序
+	LinkValue string    `json:"linkValue,omitempty"`
+	LinkAppID string    `json:"linkAppId,omitempty"`
+	ProductID uint64    `json:"productId"` // 跳转商品时的商品ID，兼容旧版前端
 	SortOrder int       `json:"sortOrder"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// BannerDailyStatResponse 轮播图一天的曝光和点击
+type BannerDailyStatResponse struct {
+	Date        string  `json:"date"`
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	ClickRate   float64 `json:"clickRate"` // 点击率，点击数除以曝光数
+}
+
+// BannerStatsResponse 轮播图在日期范围内的统计
+type BannerStatsResponse struct {
+	BannerID    uint64                    `json:"bannerId"`
+	Impressions int64                     `json:"impressions"`
+	Clicks      int64                     `json:"clicks"`
+	ClickRate   float64                   `json:"clickRate"`
+	Days        []BannerDailyStatResponse `json:"days"`
+}
@@ -0,0 +1,11 @@
+package response
+
+// BundleItemResponse 组合商品组件
+type BundleItemResponse struct {
+	ProductID  uint64  `json:"productID"`
+	Name       string  `json:"name"`
+	ImageUrl   string  `json:"imageUrl"`
+	Price      float64 `json:"price"`
+	Quantity   int     `json:"quantity"`
+	StockCount int     `json:"stockCount"`
+}
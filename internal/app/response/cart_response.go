@@ -9,13 +9,33 @@ package response
 // }
 
 type CartResponse struct {
-	ID         uint64  `json:"id"`
-	ProductID  uint64  `json:"productId"`
-	Quantity   int     `json:"quantity"`
-	Selected   bool    `json:"selected"`
-	Blessing   string  `json:"blessing"`
-	Name       string  `json:"name"`
-	Price      float64 `json:"price"`
-	ImageUrl   string  `json:"imageUrl"`
-	StockCount int     `json:"stockCount"`
+	ID                uint64  `json:"id"`
+	ProductID         uint64  `json:"productId"`
+	Quantity          int     `json:"quantity"`
+	Selected          bool    `json:"selected"`
+	Blessing          string  `json:"blessing"`
+	Name              string  `json:"name"`
+	Price             float64 `json:"price"`      // 当前价格
+	AddedPrice        float64 `json:"addedPrice"` // 加购时的价格，升级前加购的商品为0
+	PriceDelta        float64 `json:"priceDelta"` // 当前价格减加购价格，正数为涨价，负数为降价
+	ImageUrl          string  `json:"imageUrl"`
+	StockCount        int     `json:"stockCount"`
+	Valid             bool    `json:"valid"`             // 商品在售且库存足够，可以下单
+	OffSale           bool    `json:"offSale"`           // 商品已下架或已删除
+	OutOfStock        bool    `json:"outOfStock"`        // 商品已售罄
+	InsufficientStock bool    `json:"insufficientStock"` // 库存少于购物车数量，减少数量后可购买
+}
+
+// CartSummaryResponse 购物车选中商品的结算金额，与提交订单使用同一计价
+type CartSummaryResponse struct {
+	SelectedCount          int                        `json:"selectedCount"`          // 参与结算的购物车项数
+	SelectedQuantity       int                        `json:"selectedQuantity"`       // 参与结算的商品件数
+	InvalidCount           int                        `json:"invalidCount"`           // 选中但已下架或售罄的购物车项数，不参与结算
+	InsufficientStockCount int                        `json:"insufficientStockCount"` // 选中但库存少于购物车数量的项数，不参与结算，减少数量后可结算
+	TotalAmount            float64                    `json:"totalAmount"`            // 商品原价合计
+	MemberDiscount         float64                    `json:"memberDiscount"`
+	PromotionDiscount      float64                    `json:"promotionDiscount"`
+	ShippingFee            float64                    `json:"shippingFee"`
+	PaymentAmount          float64                    `json:"paymentAmount"` // 应付金额，含运费，未扣除积分抵扣
+	Promotions             []AppliedPromotionResponse `json:"promotions"`
 }
@@ -6,16 +6,26 @@ type CategoryResponse struct {
 	ID        uint64    `json:"id"`
 	Name      string    `json:"name"`
 	ParentID  uint64    `json:"parentId"`
+	Level     int       `json:"level"` // 层级，一级分类为1
 	ImageUrl  string    `json:"imageUrl"`
 	SortOrder int       `json:"sortOrder"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// CategoryTreeResponse 分类树节点，子分类可以继续包含子分类
 type CategoryTreeResponse struct {
-	ID        uint64             `json:"id"`
-	Name      string             `json:"name"`
-	ImageUrl  string             `json:"imageUrl"`
-	SortOrder int                `json:"sortOrder"`
-	Children  []CategoryResponse `json:"children"`
+	ID        uint64                  `json:"id"`
+	Name      string                  `json:"name"`
+	ParentID  uint64                  `json:"parentId"`
+	Level     int                     `json:"level"`
+	ImageUrl  string                  `json:"imageUrl"`
+	SortOrder int                     `json:"sortOrder"`
+	Children  []*CategoryTreeResponse `json:"children"`
+}
+
+// CategoryBreadcrumb 面包屑中的一个分类，面包屑从一级分类排到商品所属分类
+type CategoryBreadcrumb struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
 }
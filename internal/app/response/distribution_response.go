@@ -0,0 +1,43 @@
+package response
+
+import "time"
+
+// DistributionShareResponse 分销分享参数
+type DistributionShareResponse struct {
+	Scene string `json:"scene"` // 小程序码scene参数
+	Path  string `json:"path"`
+}
+
+// DistributionStatsResponse 推荐人统计
+type DistributionStatsResponse struct {
+	Clicks         int64   `json:"clicks"`         // 分享点击数
+	Invitees       int64   `json:"invitees"`       // 邀请用户数
+	Orders         int64   `json:"orders"`         // 产生佣金的订单数，不含已取消
+	HeldAmount     float64 `json:"heldAmount"`     // 待结算佣金
+	SettledAmount  float64 `json:"settledAmount"`  // 已结算佣金
+	ReversedAmount float64 `json:"reversedAmount"` // 退款冲回佣金
+	Wallet         float64 `json:"wallet"`         // 钱包余额
+}
+
+// CommissionResponse 佣金记录
+type CommissionResponse struct {
+	ID         uint64     `json:"id"`
+	OrderNo    string     `json:"orderNo"`
+	Level      int        `json:"level"`
+	BaseAmount float64    `json:"baseAmount"`
+	Amount     float64    `json:"amount"`
+	Status     int        `json:"status"`
+	SettledAt  *time.Time `json:"settledAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// WalletLedgerResponse 钱包流水
+type WalletLedgerResponse struct {
+	ID        uint64    `json:"id"`
+	Type      int       `json:"type"`
+	TypeDesc  string    `json:"typeDesc"`
+	Change    float64   `json:"change"`
+	Balance   float64   `json:"balance"`
+	Remark    string    `json:"remark"`
+	CreatedAt time.Time `json:"createdAt"`
+}
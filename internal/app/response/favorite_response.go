@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+// FavoriteResponse 收藏的商品
+type FavoriteResponse struct {
+	ProductID    uint64    `json:"productID"`
+	Name         string    `json:"name"`
+	ImageUrl     string    `json:"imageUrl"`
+	SavedPrice   float64   `json:"savedPrice"` // 收藏时的价格
+	Price        float64   `json:"price"`      // 当前价格
+	PriceDropped bool      `json:"priceDropped"`
+	StockCount   int       `json:"stockCount"`
+	Status       int       `json:"status"` // 1: on sale, 0: off sale
+	CreatedAt    time.Time `json:"createdAt"`
+}
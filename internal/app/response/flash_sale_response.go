@@ -0,0 +1,39 @@
+package response
+
+import "time"
+
+// 抢购结果状态
+const (
+	// FlashSaleResultQueued 排队中
+	FlashSaleResultQueued = "queued"
+	// FlashSaleResultSuccess 抢购成功，已生成待支付订单
+	FlashSaleResultSuccess = "success"
+	// FlashSaleResultFailed 抢购失败
+	FlashSaleResultFailed = "failed"
+)
+
+// FlashSaleResponse 秒杀活动
+type FlashSaleResponse struct {
+	ID           uint64    `json:"id"`
+	ProductID    uint64    `json:"productID"`
+	ProductName  string    `json:"productName"`
+	ImageUrl     string    `json:"imageUrl"`
+	Title        string    `json:"title"`
+	Price        float64   `json:"price"`
+	DealPrice    float64   `json:"dealPrice"`
+	Quantity     int       `json:"quantity"`
+	Remaining    int       `json:"remaining"`
+	LimitPerUser int       `json:"limitPerUser"`
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+	Started      bool      `json:"started"`
+}
+
+// FlashSaleResultResponse 抢购结果
+type FlashSaleResultResponse struct {
+	RequestID string `json:"requestID"`
+	Status    string `json:"status"`
+	OrderID   uint64 `json:"orderID,omitempty"`
+	OrderNo   string `json:"orderNo,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
@@ -0,0 +1,66 @@
+package response
+
+import "time"
+
+// GroupBuyCampaignResponse 拼团活动
+type GroupBuyCampaignResponse struct {
+	ID          uint64                       `json:"id"`
+	ProductID   uint64                       `json:"productID"`
+	ProductName string                       `json:"productName"`
+	ImageUrl    string                       `json:"imageUrl"`
+	Title       string                       `json:"title"`
+	Price       float64                      `json:"price"`
+	GroupPrice  float64                      `json:"groupPrice"`
+	GroupSize   int                          `json:"groupSize"`
+	TimeLimit   int                          `json:"timeLimit"`
+	StartTime   time.Time                    `json:"startTime"`
+	EndTime     time.Time                    `json:"endTime"`
+	Status      int                          `json:"status"`
+	OpenGroups  []GroupBuyGroupBriefResponse `json:"openGroups,omitempty"` // 可直接参与的团
+}
+
+// GroupBuyGroupBriefResponse 拼团中的团摘要
+type GroupBuyGroupBriefResponse struct {
+	ID           uint64    `json:"id"`
+	LeaderName   string    `json:"leaderName"`
+	LeaderAvatar string    `json:"leaderAvatar"`
+	Remaining    int       `json:"remaining"` // 还差人数
+	ExpireAt     time.Time `json:"expireAt"`
+}
+
+// GroupBuyMemberResponse 团成员
+type GroupBuyMemberResponse struct {
+	UserID   uint64 `json:"userID"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+	IsLeader bool   `json:"isLeader"`
+	Paid     bool   `json:"paid"`
+}
+
+// GroupBuyGroupResponse 团详情
+type GroupBuyGroupResponse struct {
+	ID           uint64                   `json:"id"`
+	Campaign     GroupBuyCampaignResponse `json:"campaign"`
+	RequiredSize int                      `json:"requiredSize"`
+	PaidCount    int                      `json:"paidCount"`
+	Remaining    int                      `json:"remaining"` // 还差人数
+	Status       int                      `json:"status"`    // 1: 拼团中, 2: 成功, 3: 失败
+	ExpireAt     time.Time                `json:"expireAt"`
+	Members      []GroupBuyMemberResponse `json:"members"`
+	Joined       bool                     `json:"joined"`               // 当前用户是否已参团
+	ShareScene   string                   `json:"shareScene,omitempty"` // 小程序码scene参数
+	SharePath    string                   `json:"sharePath,omitempty"`  // 分享卡片路径
+}
+
+// GroupBuyOrderResponse 开团/参团结果
+type GroupBuyOrderResponse struct {
+	GroupID uint64 `json:"groupID"`
+	OrderID uint64 `json:"orderID"`
+	OrderNo string `json:"orderNo"`
+}
+
+// GroupBuyShareResponse 分享参数解析结果
+type GroupBuyShareResponse struct {
+	GroupID   uint64 `json:"groupID"`
+	InviterID uint64 `json:"inviterID"`
+}
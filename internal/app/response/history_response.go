@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+// HistoryResponse 浏览记录
+type HistoryResponse struct {
+	ProductID  uint64    `json:"productID"`
+	Name       string    `json:"name"`
+	ImageUrl   string    `json:"imageUrl"`
+	Price      float64   `json:"price"`
+	StockCount int       `json:"stockCount"`
+	Status     int       `json:"status"` // 1: on sale, 0: off sale
+	ViewedAt   time.Time `json:"viewedAt"`
+}
@@ -0,0 +1,22 @@
+package response
+
+const (
+	// HomeSectionStatusOK 区块加载成功
+	HomeSectionStatusOK = "ok"
+	// HomeSectionStatusError 区块加载失败或超时，data为空，其余区块照常返回
+	HomeSectionStatusError = "error"
+)
+
+// HomeResponse 首页聚合数据，区块按后台配置的顺序排列，隐藏的区块不返回
+type HomeResponse struct {
+	Sections []HomeSectionResponse `json:"sections"`
+}
+
+// HomeSectionResponse 首页区块
+type HomeSectionResponse struct {
+	Key    string      `json:"key"`
+	Title  string      `json:"title"`
+	Status string      `json:"status"` // ok: 正常, error: 加载失败
+	Error  string      `json:"error,omitempty"`
+	Data   interface{} `json:"data"`
+}
@@ -0,0 +1,27 @@
+package response
+
+// InventoryResponse 商品库存概况
+type InventoryResponse struct {
+	ProductID uint64 `json:"productID"`
+	Name      string `json:"name"`
+	Available int    `json:"available"` // 可售库存
+	Reserved  int    `json:"reserved"`  // 已下单未发货占用的数量
+	OnHand    int    `json:"onHand"`    // 在库数量，可售与占用之和
+	Threshold *int   `json:"threshold"` // 库存预警阈值，未设置时为空
+	LowStock  bool   `json:"lowStock"`
+}
+
+// ExpectedWastageResponse 某天预计报废的商品数量，按当前剩余且之后不再售出计算
+type ExpectedWastageResponse struct {
+	Date      string `json:"date"` // 到期日，格式 2006-01-02
+	ProductID uint64 `json:"productID"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+}
+
+// ProductStockResponse 商品在配送日期可售的库存
+type ProductStockResponse struct {
+	ProductID    uint64 `json:"productID"`
+	DeliveryDate string `json:"deliveryDate"`
+	StockCount   int    `json:"stockCount"`
+}
@@ -0,0 +1,26 @@
+package response
+
+// MemberTierResponse 会员等级
+type MemberTierResponse struct {
+	ID           uint64  `json:"id"`
+	Name         string  `json:"name"`
+	Level        int     `json:"level"`
+	MinSpend     float64 `json:"minSpend"`
+	DiscountRate float64 `json:"discountRate"`
+	FreeShipping bool    `json:"freeShipping"`
+}
+
+// MemberInfoResponse 用户会员信息
+type MemberInfoResponse struct {
+	Tier      *MemberTierResponse `json:"tier"`      // 当前等级，非会员为空
+	Spend     float64             `json:"spend"`     // 近12个月已完成订单消费
+	NextTier  *MemberTierResponse `json:"nextTier"`  // 下一等级，已是最高等级为空
+	NextSpend float64             `json:"nextSpend"` // 距离下一等级还需消费
+}
+
+// MemberPriceResponse 商品会员价
+type MemberPriceResponse struct {
+	TierID   uint64  `json:"tierID"`
+	TierName string  `json:"tierName"`
+	Price    float64 `json:"price"`
+}
@@ -3,19 +3,43 @@ package response
 import "time"
 
 type OrderDetailResponse struct {
-	OrderID     uint64              `json:"orderID"`
-	OrderNo     string              `json:"orderNo"`
-	TotalAmount float64             `json:"totalAmount"`
-	OrderItem   []OrderItemResponse `json:"orderItem"`
-	Address     AddressResponse     `json:"address"`
+	OrderID           uint64                     `json:"orderID"`
+	OrderNo           string                     `json:"orderNo"`
+	TotalAmount       float64                    `json:"totalAmount"`
+	MemberDiscount    float64                    `json:"memberDiscount"`
+	PromotionDiscount float64                    `json:"promotionDiscount"`
+	ShippingFee       float64                    `json:"shippingFee"`
+	PaymentAmount     float64                    `json:"paymentAmount"`
+	RefundedAmount    float64                    `json:"refundedAmount"` // 已部分退款金额
+	Status            int                        `json:"status"`
+	OrderItem         []OrderItemResponse        `json:"orderItem"`
+	Address           AddressResponse            `json:"address"`
+	Payments          []OrderPaymentResponse     `json:"payments,omitempty"`   // 分阶段支付记录，如预售定金和尾款
+	Promotions        []AppliedPromotionResponse `json:"promotions,omitempty"` // 享受的促销活动，同一活动的各项优惠合计
+}
+
+// OrderPaymentResponse 分阶段支付记录
+type OrderPaymentResponse struct {
+	Stage    int        `json:"stage"` // 1: 定金, 2: 尾款
+	Amount   float64    `json:"amount"`
+	Status   int        `json:"status"` // 0: 待支付, 1: 已支付, 2: 已退款, 3: 定金不退, 4: 已关闭
+	DueStart time.Time  `json:"dueStart"`
+	DueEnd   time.Time  `json:"dueEnd"`
+	PaidAt   *time.Time `json:"paidAt"`
 }
 
 type OrderItemResponse struct {
-	ProductID uint64  `json:"productID"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
-	Name      string  `json:"name"`
-	ImageUrl  string  `json:"imageUrl"`
+	ID                uint64              `json:"id"`
+	ProductID         uint64              `json:"productID"`
+	Quantity          int                 `json:"quantity"`
+	Price             float64             `json:"price"`
+	OriginalPrice     float64             `json:"originalPrice"`
+	Name              string              `json:"name"`
+	ImageUrl          string              `json:"imageUrl"`
+	IsBundle          bool                `json:"isBundle"`
+	RefundedQty       int                 `json:"refundedQty"`
+	PromotionDiscount float64             `json:"promotionDiscount"`    // 整行的促销优惠合计
+	Components        []OrderItemResponse `json:"components,omitempty"` // 组合商品的组件行
 }
 
 type CreateOrderResponse struct {
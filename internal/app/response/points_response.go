@@ -0,0 +1,24 @@
+package response
+
+import "time"
+
+// PointsBalanceResponse 积分余额
+type PointsBalanceResponse struct {
+	Points         int     `json:"points"`
+	ExpiringPoints int     `json:"expiringPoints"` // 30天内将过期的积分
+	RedeemRate     int     `json:"redeemRate"`     // 多少积分抵扣1元
+	MaxRedeemRatio float64 `json:"maxRedeemRatio"` // 单笔订单最多抵扣比例
+}
+
+// PointsLedgerResponse 积分流水
+type PointsLedgerResponse struct {
+	ID        uint64     `json:"id"`
+	Type      int        `json:"type"`
+	TypeDesc  string     `json:"typeDesc"`
+	Change    int        `json:"change"`
+	Balance   int        `json:"balance"`
+	OrderID   uint64     `json:"orderID"`
+	ExpireAt  *time.Time `json:"expireAt"`
+	Remark    string     `json:"remark"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,32 @@
+package response
+
+import "time"
+
+// PreSaleResponse 预售活动
+type PreSaleResponse struct {
+	ID           uint64    `json:"id"`
+	ProductID    uint64    `json:"productID"`
+	ProductName  string    `json:"productName"`
+	ImageUrl     string    `json:"imageUrl"`
+	Title        string    `json:"title"`
+	Price        float64   `json:"price"`
+	PreSalePrice float64   `json:"preSalePrice"`
+	Deposit      float64   `json:"deposit"`
+	Balance      float64   `json:"balance"` // 每件尾款，不含运费
+	DepositStart time.Time `json:"depositStart"`
+	DepositEnd   time.Time `json:"depositEnd"`
+	BalanceStart time.Time `json:"balanceStart"`
+	BalanceEnd   time.Time `json:"balanceEnd"`
+	ShipAt       time.Time `json:"shipAt"`
+	Status       int       `json:"status"`
+}
+
+// PreSaleOrderResponse 预售下单结果
+type PreSaleOrderResponse struct {
+	OrderID      uint64    `json:"orderID"`
+	OrderNo      string    `json:"orderNo"`
+	Deposit      float64   `json:"deposit"` // 应付定金
+	Balance      float64   `json:"balance"` // 应付尾款，含运费
+	BalanceStart time.Time `json:"balanceStart"`
+	BalanceEnd   time.Time `json:"balanceEnd"`
+}
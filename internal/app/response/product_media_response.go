@@ -0,0 +1,16 @@
+package response
+
+// GalleryItemResponse 商品相册项
+type GalleryItemResponse struct {
+	ID        uint64 `json:"id"`
+	Type      int    `json:"type"` // 1: 图片, 2: 视频
+	Url       string `json:"url"`
+	SortOrder int    `json:"sortOrder"`
+}
+
+// DetailBlockResponse 商品详情内容块
+type DetailBlockResponse struct {
+	Type    int    `json:"type"`              // 1: 图片, 3: 富文本
+	Url     string `json:"url,omitempty"`     // 图片块的图片地址
+	Content string `json:"content,omitempty"` // 文本块HTML
+}
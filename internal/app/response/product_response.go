@@ -3,22 +3,40 @@ package response
 import "time"
 
 type ProductResponse struct {
-	ID             uint64    `json:"id"`
-	Name           string    `json:"name"`
-	FloralLanguage string    `json:"floralLanguage"`
-	Price          float64   `json:"price"`
-	MarketPrice    float64   `json:"marketPrice"`
-	SaleCount      int       `json:"saleCount"`
-	StockCount     int       `json:"stockCount"`
-	CategoryID     uint64    `json:"categoryID"`
-	SubCategoryID  uint64    `json:"subCategoryID"`
-	Material       string    `json:"material"`
-	Packing        string    `json:"packing"`
-	ImageUrl       string    `json:"imageUrl"`
-	Status         int       `json:"status"` // 1: on sale, 0: off sale
-	Recommend      bool      `json:"recommend"`
-	SortOrder      int       `json:"sortOrder"`
-	ApplyUser      string    `json:"applyUser"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	ID             uint64                     `json:"id"`
+	Name           string                     `json:"name"`
+	FloralLanguage string                     `json:"floralLanguage"`
+	Price          float64                    `json:"price"`
+	MarketPrice    float64                    `json:"marketPrice"`
+	MemberPrice    *float64                   `json:"memberPrice,omitempty"` // 当前用户会员价，非会员或未登录时为空
+	MemberPrices   []MemberPriceResponse      `json:"memberPrices,omitempty"`
+	SaleCount      int                        `json:"saleCount"`
+	StockCount     int                        `json:"stockCount"`
+	CategoryID     uint64                     `json:"categoryID"`
+	Material       string                     `json:"material"`
+	Packing        string                     `json:"packing"`
+	ImageUrl       string                     `json:"imageUrl"`
+	Status         int                        `json:"status"` // 1: on sale, 0: off sale
+	IsBundle       bool                       `json:"isBundle"`
+	Breadcrumbs    []CategoryBreadcrumb       `json:"breadcrumbs,omitempty"`    // 从一级分类到所属分类的路径，仅详情返回
+	Attributes     []ProductAttributeResponse `json:"attributes,omitempty"`     // 场合、送礼对象、颜色等属性，仅详情返回
+	Gallery        []GalleryItemResponse      `json:"gallery,omitempty"`        // 相册，仅详情返回
+	Detail         []DetailBlockResponse      `json:"detail,omitempty"`         // 图文详情，仅详情返回
+	BundleItems    []BundleItemResponse       `json:"bundleItems,omitempty"`    // 组合商品组件，仅详情返回
+	PurchaseLimits []PurchaseLimitResponse    `json:"purchaseLimits,omitempty"` // 当前生效的限购规则，仅详情返回
+	FavoriteCount  int64                      `json:"favoriteCount"`            // 收藏人数，仅详情返回
+	Favorited      bool                       `json:"favorited"`                // 当前用户是否已收藏，仅详情返回
+	AlsoBought     []*ProductResponse         `json:"alsoBought,omitempty"`     // 买了又买，仅详情返回
+	Recommend      bool                       `json:"recommend"`
+	SortOrder      int                        `json:"sortOrder"`
+	ApplyUser      string                     `json:"applyUser"`
+	CreatedAt      time.Time                  `json:"createdAt"`
+	UpdatedAt      time.Time                  `json:"updatedAt"`
+}
+
+// SalesRankingResponse 销量排行
+type SalesRankingResponse struct {
+	Rank    int              `json:"rank"`
+	Sales   int              `json:"sales"` // 窗口内销量
+	Product *ProductResponse `json:"product"`
 }
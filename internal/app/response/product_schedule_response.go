@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+// PriceAtResponse 商品在某一时间生效的价格
+type PriceAtResponse struct {
+	ProductID   uint64     `json:"productID"`
+	At          time.Time  `json:"at"`
+	Price       float64    `json:"price"`
+	EffectiveAt *time.Time `json:"effectiveAt"` // 该价格的生效时间，没有变更记录时为空
+	ScheduleID  uint64     `json:"scheduleID"`  // 来自定时调价时为计划ID
+}
@@ -3,10 +3,32 @@ package response
 import "time"
 
 type PromotionResponse struct {
-	ID            uint64    `json:"id"`
-	Title         string    `json:"title"`
-	ImageUrl      string    `json:"imageUrl"`
-	SubCategoryID uint64    `json:"subCategoryId"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            uint64     `json:"id"`
+	Title         string     `json:"title"`
+	ImageUrl      string     `json:"imageUrl"`
+	Description   string     `json:"description"`
+	Scope         int        `json:"scope"`         // 0: 全部商品, 1: 指定分类, 2: 指定商品
+	CategoryIDs   []uint64   `json:"categoryIDs"`   // 指定分类时的分类
+	ProductIDs    []uint64   `json:"productIDs"`    // 指定商品时的商品
+	SubCategoryID uint64     `json:"subCategoryId"` // 指定分类时的第一个分类，兼容旧版前端
+	RuleType      int        `json:"ruleType"`      // 0: 仅广告, 1: 满减, 2: 满折, 3: 买N送M, 4: 第二件半价
+	Threshold     float64    `json:"threshold"`
+	Discount      float64    `json:"discount"`
+	Rate          float64    `json:"rate"`
+	BuyQty        int        `json:"buyQty"`
+	FreeQty       int        `json:"freeQty"`
+	Repeatable    bool       `json:"repeatable"`
+	Exclusive     bool       `json:"exclusive"`
+	StartAt       *time.Time `json:"startAt"`
+	EndAt         *time.Time `json:"endAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// AppliedPromotionResponse 订单享受的促销活动及优惠金额
+type AppliedPromotionResponse struct {
+	PromotionID uint64  `json:"promotionID"`
+	Title       string  `json:"title"`
+	RuleType    int     `json:"ruleType"`
+	Discount    float64 `json:"discount"`
 }
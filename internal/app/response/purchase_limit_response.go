@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+// PurchaseLimitResponse 商品限购规则
+type PurchaseLimitResponse struct {
+	Period      int        `json:"period"` // 0: 累计, 1: 每天, 2: 每周, 3: 每月
+	MaxQuantity int        `json:"maxQuantity"`
+	StartAt     *time.Time `json:"startAt"`
+	EndAt       *time.Time `json:"endAt"`
+	Desc        string     `json:"desc"` // 如"每人每天限购5件"
+}
@@ -0,0 +1,15 @@
+package response
+
+// ReorderSuggestionResponse 按近期销量计算的补货建议
+type ReorderSuggestionResponse struct {
+	ProductID      uint64  `json:"productID"`
+	Name           string  `json:"name"`
+	Sold           int     `json:"sold"`           // 统计窗口内的销量
+	DailySales     float64 `json:"dailySales"`     // 日均销量
+	Available      int     `json:"available"`      // 可售库存
+	Incoming       int     `json:"incoming"`       // 已下单未到货的数量
+	LeadDays       int     `json:"leadDays"`       // 计算时使用的到货天数
+	DaysOfStock    float64 `json:"daysOfStock"`    // 可售库存按日均销量可售天数
+	SuggestedQty   int     `json:"suggestedQty"`   // 建议补货数量
+	LastSupplierID uint64  `json:"lastSupplierID"` // 最近一次采购该商品的供应商，没有采购记录时为0
+}
@@ -24,6 +24,15 @@ func ErrorResponse(code int, message string) Response {
 	}
 }
 
+// ErrorResponseWithData returns an error response carrying details for the client to display
+func ErrorResponseWithData(code int, message string, data interface{}) Response {
+	return Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}
+}
+
 // TokenExpiredResponse returns a token expired response
 func TokenExpiredResponse() Response {
 	return Response{
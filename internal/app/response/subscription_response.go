@@ -0,0 +1,37 @@
+package response
+
+import "time"
+
+// SubscriptionResponse 周期订阅
+type SubscriptionResponse struct {
+	ID               uint64                         `json:"id"`
+	SubscriptionNo   string                         `json:"subscriptionNo"`
+	ProductID        uint64                         `json:"productID"`
+	ProductName      string                         `json:"productName"`
+	ImageUrl         string                         `json:"imageUrl"`
+	CategoryID       uint64                         `json:"categoryID"`
+	Budget           float64                        `json:"budget"`
+	Quantity         int                            `json:"quantity"`
+	Frequency        string                         `json:"frequency"`
+	Weekday          int                            `json:"weekday"`
+	DeliverySlot     string                         `json:"deliverySlot"`
+	AddressID        uint64                         `json:"addressID"`
+	TotalDeliveries  int                            `json:"totalDeliveries"`
+	DeliveredCount   int                            `json:"deliveredCount"`
+	NextDeliveryDate string                         `json:"nextDeliveryDate"` // yyyy-MM-dd
+	Prepaid          bool                           `json:"prepaid"`
+	DeliveryAmount   float64                        `json:"deliveryAmount"` // 每次配送金额，含运费
+	PaidAmount       float64                        `json:"paidAmount"`
+	Balance          float64                        `json:"balance"`
+	Status           int                            `json:"status"`
+	CreatedAt        time.Time                      `json:"createdAt"`
+	Deliveries       []SubscriptionDeliveryResponse `json:"deliveries,omitempty"`
+}
+
+// SubscriptionDeliveryResponse 订阅配送记录
+type SubscriptionDeliveryResponse struct {
+	Sequence     int     `json:"sequence"`
+	DeliveryDate string  `json:"deliveryDate"`
+	OrderID      uint64  `json:"orderID"`
+	Amount       float64 `json:"amount"` // 从预付金额中扣除的金额
+}
@@ -46,6 +46,8 @@ func RegisterRouter(router *gin.Engine) {
 
 	// 登录
 	apiv1.RegisterLoginApi(router)
+	// 首页
+	apiv1.RegisterHomeApi(router)
 	// 轮播图
 	apiv1.RegisterBannerApi(router)
 	// 分类
@@ -66,4 +68,36 @@ func RegisterRouter(router *gin.Engine) {
 	apiv1.RegisterCartApi(router)
 	// 订单
 	apiv1.RegisterOrderApi(router)
+	// 积分
+	apiv1.RegisterPointsApi(router)
+	// 会员
+	apiv1.RegisterMemberApi(router)
+	// 秒杀
+	apiv1.RegisterFlashSaleApi(router)
+	// 拼团
+	apiv1.RegisterGroupBuyApi(router)
+	// 预售
+	apiv1.RegisterPreSaleApi(router)
+	// 周期订阅
+	apiv1.RegisterSubscriptionApi(router)
+	// 分销
+	apiv1.RegisterDistributionApi(router)
+	// 组合商品
+	apiv1.RegisterBundleApi(router)
+	// 限购
+	apiv1.RegisterPurchaseLimitApi(router)
+	// 收藏
+	apiv1.RegisterFavoriteApi(router)
+	// 浏览足迹
+	apiv1.RegisterHistoryApi(router)
+	// 商品图文
+	apiv1.RegisterProductMediaApi(router)
+	// 商品定时计划
+	apiv1.RegisterProductScheduleApi(router)
+	// 库存
+	apiv1.RegisterInventoryApi(router)
+	// 采购
+	apiv1.RegisterPurchaseApi(router)
+	// 商品属性
+	apiv1.RegisterAttributeApi(router)
 }
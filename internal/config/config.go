@@ -15,6 +15,17 @@ type Config struct {
 	Wechat       WechatConfig            `mapstructure:"wechat"`
 	Upload       UploadConfig            `mapstructure:"upload"`
 	Logger       LoggerConfig            `mapstructure:"logger"`
+	Points       PointsConfig            `mapstructure:"points"`
+	Shipping     ShippingConfig          `mapstructure:"shipping"`
+	FlashSale    FlashSaleConfig         `mapstructure:"flash_sale"`
+	GroupBuy     GroupBuyConfig          `mapstructure:"group_buy"`
+	PreSale      PreSaleConfig           `mapstructure:"pre_sale"`
+	Subscription SubscriptionConfig      `mapstructure:"subscription"`
+	Distribution DistributionConfig      `mapstructure:"distribution"`
+	History      HistoryConfig           `mapstructure:"history"`
+	Recommend    RecommendConfig         `mapstructure:"recommend"`
+	Media        MediaConfig             `mapstructure:"media"`
+	Purchase     PurchaseConfig          `mapstructure:"purchase"`
 }
 
 // LoggerConfig represents logger configuration
@@ -84,8 +95,31 @@ type WechatConfig struct {
 
 // UploadConfig represents file upload configuration
 type UploadConfig struct {
-	SavePath string `mapstructure:"save_path"`
-	MaxSize  int64  `mapstructure:"max_size"` // in bytes
+	SavePath     string `mapstructure:"save_path"`
+	MaxSize      int64  `mapstructure:"max_size"`       // in bytes
+	MaxVideoSize int64  `mapstructure:"max_video_size"` // 商品视频大小上限，in bytes
+}
+
+// PointsConfig represents loyalty points configuration
+type PointsConfig struct {
+	DefaultEarnRate float64 `mapstructure:"default_earn_rate"` // 每消费1元获得的积分（分类未配置时使用）
+	FirstOrderBonus int     `mapstructure:"first_order_bonus"` // 首单奖励积分
+	RedeemRate      int     `mapstructure:"redeem_rate"`       // 多少积分抵扣1元
+	MaxRedeemRatio  float64 `mapstructure:"max_redeem_ratio"`  // 单笔订单最多抵扣支付金额的比例
+	MaxRedeemPoints int     `mapstructure:"max_redeem_points"` // 单笔订单最多使用的积分，0表示不限制
+	ExpireMonths    int     `mapstructure:"expire_months"`     // 积分有效期（月）
+}
+
+// ShippingConfig represents shipping fee configuration
+type ShippingConfig struct {
+	Fee           float64 `mapstructure:"fee"`            // 基础运费
+	FreeThreshold float64 `mapstructure:"free_threshold"` // 满额包邮门槛，0表示不启用
+}
+
+// FlashSaleConfig represents flash sale configuration
+type FlashSaleConfig struct {
+	PayTimeout int `mapstructure:"pay_timeout"` // 秒杀订单支付超时时间（分钟），超时自动取消并释放名额
+	ResultTTL  int `mapstructure:"result_ttl"`  // 抢购结果保留时间（分钟）
 }
 
 // LoadConfig loads configuration from config file
@@ -117,3 +151,56 @@ func GetConfig() *Config {
 	// 所有路径都加载失败
 	panic("Failed to load configuration: " + err.Error())
 }
+
+// GroupBuyConfig represents group-buy configuration
+type GroupBuyConfig struct {
+	PayTimeout int    `mapstructure:"pay_timeout"` // 参团订单支付超时时间（分钟），超时自动取消并释放名额
+	SharePath  string `mapstructure:"share_path"`  // 小程序拼团详情页路径
+}
+
+// PreSaleConfig represents pre-sale configuration
+type PreSaleConfig struct {
+	ForfeitPolicy string `mapstructure:"forfeit_policy"` // 尾款逾期未付时定金的默认处理方式：forfeit 不退，refund 退回
+	RemindBefore  int    `mapstructure:"remind_before"`  // 尾款截止前多少小时提醒支付
+}
+
+// SubscriptionConfig represents recurring subscription configuration
+type SubscriptionConfig struct {
+	LeadDays int `mapstructure:"lead_days"` // 提前多少天生成配送订单
+}
+
+// DistributionConfig represents referral commission configuration
+type DistributionConfig struct {
+	Level1Rate  float64 `mapstructure:"level1_rate"`  // 默认一级佣金比例，未配置商品或分类规则时使用
+	Level2Rate  float64 `mapstructure:"level2_rate"`  // 默认二级佣金比例
+	TwoLevel    bool    `mapstructure:"two_level"`    // 是否启用二级分佣
+	MinWithdraw float64 `mapstructure:"min_withdraw"` // 最低提现金额
+	SharePath   string  `mapstructure:"share_path"`   // 小程序商品详情页路径
+}
+
+// HistoryConfig represents browsing history configuration
+type HistoryConfig struct {
+	MaxItems      int `mapstructure:"max_items"`      // Redis中每个用户保留的最近浏览商品数
+	TTLDays       int `mapstructure:"ttl_days"`       // Redis浏览记录的过期天数，过期后从MySQL读取
+	RetentionDays int `mapstructure:"retention_days"` // MySQL浏览记录保留天数
+}
+
+// RecommendConfig represents product recommendation configuration
+type RecommendConfig struct {
+	WindowDays   int `mapstructure:"window_days"`    // 计算共同购买时统计最近多少天的订单
+	HalfLifeDays int `mapstructure:"half_life_days"` // 购买记录权重减半的天数
+	TopN         int `mapstructure:"top_n"`          // 每个商品保存的相似商品数
+}
+
+// MediaConfig represents product gallery and detail content configuration
+type MediaConfig struct {
+	MaxGalleryImages int `mapstructure:"max_gallery_images"` // 相册最多图片数，另可有一个视频
+	GCGraceHours     int `mapstructure:"gc_grace_hours"`     // 对象不再被引用多少小时后从MinIO删除
+}
+
+// PurchaseConfig represents purchase order and reorder suggestion configuration
+type PurchaseConfig struct {
+	VelocityDays int `mapstructure:"velocity_days"` // 按最近多少天的销量计算日均销量
+	LeadDays     int `mapstructure:"lead_days"`     // 供应商未设置时默认的下单到到货天数
+	CoverDays    int `mapstructure:"cover_days"`    // 到货后库存需要覆盖的销售天数
+}
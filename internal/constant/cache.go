@@ -16,16 +16,20 @@ const (
 
 // 首页相关缓存键
 const (
-	// 首页轮播图
+	// 首页轮播图，当前展示时间段内的启用轮播图，缓存到下一个开始或结束时间
 	HomeBanners = HomePrefix + "banners"
-	// 首页分类
+	// 轮播图每日曝光和点击计数，哈希，键为 前缀+日期，字段为 轮播图ID:view 或 轮播图ID:click
+	BannerStats = "banner:stats:"
+	// 首页分类，一级分类，分类变更时清除
 	HomeCategories = HomePrefix + "categories"
-	// 首页促销信息
+	// 首页促销广告，当前进行中且有广告图片的促销活动，后台修改时清除
 	HomePromotions = HomePrefix + "promotions"
-	// 首页推荐商品
+	// 首页推荐商品，游客共用，登录用户为个性化推荐，键为 前缀+":"+用户ID
 	HomeRecommendProducts = HomePrefix + "recommend_products"
-	// 首页热门商品
+	// 首页热门商品，按游客价格缓存，读取后填充当前用户的会员价
 	HomeHotProducts = HomePrefix + "hot_products"
+	// 首页区块配置，后台修改时清除
+	HomeSections = HomePrefix + "sections"
 )
 
 // 商品相关缓存键
@@ -83,3 +87,59 @@ func WithID(key string, id interface{}) string {
 func WithPage(key string, page, size int) string {
 	return key + "page:%d:size:%d"
 }
+
+// 会员相关缓存键
+const (
+	// 会员等级列表
+	MemberTiers = "member:tiers"
+)
+
+// 秒杀相关缓存键
+const (
+	// 秒杀活动信息
+	FlashSaleInfo = "flash_sale:info:"
+	// 秒杀剩余库存
+	FlashSaleStock = "flash_sale:stock:"
+	// 秒杀用户已购数量
+	FlashSaleBought = "flash_sale:bought:"
+	// 秒杀下单队列
+	FlashSaleQueue = "flash_sale:queue"
+	// 秒杀处理中队列
+	FlashSaleProcessing = "flash_sale:processing"
+	// 秒杀请求开始处理的时间，按请求内容记录
+	FlashSaleProcessingAt = "flash_sale:processing_at"
+	// 秒杀抢购结果
+	FlashSaleResult = "flash_sale:result:"
+)
+
+// 浏览记录相关缓存键
+const (
+	// 用户浏览记录，有序集合，分值为浏览时间
+	HistoryUser = "history:user:"
+	// 浏览记录待同步到MySQL的用户集合
+	HistoryDirty = "history:dirty"
+)
+
+// 销量排行相关缓存键
+const (
+	// 每小时销量桶，有序集合，键为 前缀+范围+":"+小时数，范围为all或分类ID
+	SalesRankingBucket = "sales_ranking:bucket:"
+	// 滑动窗口排行，由窗口内的小时桶合并，短时缓存
+	SalesRankingWindow = "sales_ranking:window:"
+	// 排行已从订单重建的标记，过期或丢失后重建
+	SalesRankingBuilt = "sales_ranking:built"
+)
+
+// 商品属性相关缓存键
+const (
+	// 全部属性及属性值，属性变更时清除
+	AttributeList = "attribute:list"
+	// 商品列表筛选项计数，键为 前缀+筛选条件，短时缓存
+	AttributeFacets = "attribute:facets:"
+)
+
+// 促销活动相关缓存键
+const (
+	// 当前生效的促销活动及其适用范围，首页广告和下单计价共用，缓存到下一个开始或结束时间
+	ActivePromotions = "promotion:active"
+)
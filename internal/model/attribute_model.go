@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Attribute represents an admin-defined browse axis such as occasion, recipient or flower color.
+// It applies to its category and all descendants; CategoryID 0 applies to every category.
+type Attribute struct {
+	ID          uint64           `json:"id" gorm:"column:id;primaryKey"`
+	Code        string           `json:"code" gorm:"column:code;uniqueIndex;not null"` // 如 occasion、recipient、color
+	Name        string           `json:"name" gorm:"column:name;not null"`             // 展示名称，如 场合
+	CategoryID  uint64           `json:"categoryID" gorm:"column:category_id;index;default:0"`
+	MultiValued bool             `json:"multiValued" gorm:"column:multi_valued;default:true"` // 商品是否可以有多个值
+	SortOrder   int              `json:"sortOrder" gorm:"column:sort_order;default:0"`
+	Values      []AttributeValue `json:"values" gorm:"foreignKey:AttributeID"`
+	CreatedAt   time.Time        `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt   time.Time        `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// AttributeValue represents a value of an attribute, e.g. birthday for occasion
+type AttributeValue struct {
+	ID          uint64    `json:"id" gorm:"column:id;primaryKey"`
+	AttributeID uint64    `json:"attributeID" gorm:"column:attribute_id;index;not null"`
+	Value       string    `json:"value" gorm:"column:value;not null"`
+	SortOrder   int       `json:"sortOrder" gorm:"column:sort_order;default:0"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// ProductAttributeValue tags a product with an attribute value
+type ProductAttributeValue struct {
+	ID          uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID   uint64    `json:"productID" gorm:"column:product_id;uniqueIndex:idx_product_value;not null"`
+	AttributeID uint64    `json:"attributeID" gorm:"column:attribute_id;index;not null"`
+	ValueID     uint64    `json:"valueID" gorm:"column:value_id;uniqueIndex:idx_product_value;index;not null"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
+}
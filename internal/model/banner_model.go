@@ -4,13 +4,62 @@ import (
 	"time"
 )
 
-// Banner represents a banner for homepage carousel
+const (
+	// BannerLinkNone 不跳转
+	BannerLinkNone = 0
+	// BannerLinkProduct 跳转商品详情，LinkValue为商品ID
+	BannerLinkProduct = 1
+	// BannerLinkCategory 跳转分类商品列表，LinkValue为分类ID
+	BannerLinkCategory = 2
+	// BannerLinkPromotion 跳转促销活动页，LinkValue为促销ID
+	BannerLinkPromotion = 3
+	// BannerLinkPage 跳转本小程序页面，LinkValue为页面路径
+	BannerLinkPage = 4
+	// BannerLinkMiniProgram 跳转其他小程序，LinkAppID为小程序AppID，LinkValue为页面路径，可为空
+	BannerLinkMiniProgram = 5
+)
+
+const (
+	// BannerAudienceAll 全部用户
+	BannerAudienceAll = 0
+	// BannerAudienceNewUser 还没有付款订单的用户，包括游客
+	BannerAudienceNewUser = 1
+	// BannerAudienceMember 有会员等级的用户
+	BannerAudienceMember = 2
+)
+
+const (
+	// BannerStatusDisabled 停用
+	BannerStatusDisabled = 0
+	// BannerStatusEnabled 启用
+	BannerStatusEnabled = 1
+)
+
+// Banner represents a banner for homepage carousel. It is shown within its time window to its audience,
+// and links to a product, category, promotion page or mini-program page.
 type Banner struct {
-	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
-	Title     string    `json:"title" gorm:"column:title;not null"`
-	ImageUrl  string    `json:"imageUrl" gorm:"column:image_url;not null"`
-	ProductID uint64    `json:"productID" gorm:"column:product_id"`
-	SortOrder int       `json:"sortOrder" gorm:"column:sort_order;default:0"`
-	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
-	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at;type:datetime"`
+	ID        uint64     `json:"id" gorm:"column:id;primaryKey"`
+	Title     string     `json:"title" gorm:"column:title;not null"`
+	ImageUrl  string     `json:"imageUrl" gorm:"column:image_url;not null"`
+	LinkType  int        `json:"linkType" gorm:"column:link_type;default:0"`  // 0: 不跳转, 1: 商品, 2: 分类, 3: 促销活动, 4: 小程序页面, 5: 其他小程序
+	LinkValue string     `json:"linkValue" gorm:"column:link_value"`          // 商品、分类、促销ID或页面路径
+	LinkAppID string     `json:"linkAppID" gorm:"column:link_app_id"`         // 跳转其他小程序时的AppID
+	StartAt   *time.Time `json:"startAt" gorm:"column:start_at;index"`        // 开始展示时间，为空表示立即
+	EndAt     *time.Time `json:"endAt" gorm:"column:end_at;index"`            // 结束展示时间，为空表示不结束
+	Audience  int        `json:"audience" gorm:"column:audience;default:0"`   // 0: 全部用户, 1: 新用户, 2: 会员
+	Cities    string     `json:"cities" gorm:"column:cities"`                 // 展示的城市，逗号分隔，为空表示不限
+	Status    int        `json:"status" gorm:"column:status;index;default:1"` // 0: 停用, 1: 启用
+	SortOrder int        `json:"sortOrder" gorm:"column:sort_order;default:0"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"column:created_at;type:datetime"`
+	UpdatedAt time.Time  `json:"updatedAt" gorm:"column:updated_at;type:datetime"`
+}
+
+// BannerDailyStat represents the impressions and clicks of a banner on a day
+type BannerDailyStat struct {
+	ID          uint64    `json:"id" gorm:"column:id;primaryKey"`
+	BannerID    uint64    `json:"bannerID" gorm:"column:banner_id;uniqueIndex:idx_banner_date;not null"`
+	Date        time.Time `json:"date" gorm:"column:date;type:date;uniqueIndex:idx_banner_date;not null"`
+	Impressions int64     `json:"impressions" gorm:"column:impressions;default:0"` // 曝光次数，返回给前端展示即计一次
+	Clicks      int64     `json:"clicks" gorm:"column:clicks;default:0"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updated_at"`
 }
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// BundleItem represents a component product and its quantity in a bundle product
+type BundleItem struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	BundleID  uint64    `json:"bundleID" gorm:"column:bundle_id;index;not null"`
+	ProductID uint64    `json:"productID" gorm:"column:product_id;index;not null"`
+	Quantity  int       `json:"quantity" gorm:"column:quantity;not null"` // 每件组合商品包含的数量
+	SortOrder int       `json:"sortOrder" gorm:"column:sort_order;default:0"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
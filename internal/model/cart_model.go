@@ -5,13 +5,14 @@ import (
 )
 
 type Cart struct {
-	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
-	UserID    uint64    `json:"userID" gorm:"column:user_id;index;not null"`
-	ProductID uint64    `json:"productID" gorm:"column:product_id;index;not null"`
-	Quantity  int       `json:"quantity" gorm:"default:1"`
-	Selected  bool      `json:"selected" gorm:"default:true"`
-	Blessing  string    `json:"blessing" gorm:"column:blessing"`
-	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
-	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
-	Product   Product   `json:"product"`
+	ID         uint64    `json:"id" gorm:"column:id;primaryKey"`
+	UserID     uint64    `json:"userID" gorm:"column:user_id;index;not null"`
+	ProductID  uint64    `json:"productID" gorm:"column:product_id;index;not null"`
+	Quantity   int       `json:"quantity" gorm:"default:1"`
+	Selected   bool      `json:"selected" gorm:"default:true"`
+	Blessing   string    `json:"blessing" gorm:"column:blessing"`                         // 祝福语，同一商品祝福语不同时为不同的购物车项
+	AddedPrice float64   `json:"addedPrice" gorm:"column:added_price;type:decimal(10,2)"` // 加购时的商品价格，用于提示降价或涨价
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" gorm:"column:updated_at"`
+	Product    Product   `json:"product"`
 }
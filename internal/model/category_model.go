@@ -2,11 +2,14 @@ package model
 
 import "time"
 
-// Category represents a product category
+// Category represents a product category. Categories nest to any depth; Path lists the IDs from the
+// root category down to the category itself, e.g. "/1/5/12/", so that a subtree is a path prefix.
 type Category struct {
 	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
 	Name      string    `json:"name" gorm:"column:name;not null"`
 	ParentID  uint64    `json:"parentID" gorm:"column:parent_id;index"`
+	Path      string    `json:"path" gorm:"column:path;index"`       // 从一级分类到本分类的ID路径，如 /1/5/12/
+	Level     int       `json:"level" gorm:"column:level;default:1"` // 层级，一级分类为1
 	ImageUrl  string    `json:"imageUrl" gorm:"column:image_url"`
 	SortOrder int       `json:"sortOrder" gorm:"column:sort_order;default:0"`
 	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
@@ -0,0 +1,114 @@
+package model
+
+import "time"
+
+const (
+	// CommissionStatusHeld 已冻结，待订单完成
+	CommissionStatusHeld = 0
+	// CommissionStatusSettled 已结算到钱包
+	CommissionStatusSettled = 1
+	// CommissionStatusReversed 订单退款，已冲回
+	CommissionStatusReversed = 2
+	// CommissionStatusCancelled 订单取消，不结算
+	CommissionStatusCancelled = 3
+)
+
+const (
+	// WalletTypeCommission 佣金入账
+	WalletTypeCommission = 1
+	// WalletTypeCommissionReversal 退款扣回佣金
+	WalletTypeCommissionReversal = 2
+	// WalletTypeWithdraw 申请提现
+	WalletTypeWithdraw = 3
+	// WalletTypeWithdrawReturn 提现驳回退回
+	WalletTypeWithdrawReturn = 4
+)
+
+// WalletTypeDesc 钱包流水类型描述
+var WalletTypeDesc = map[int]string{
+	WalletTypeCommission:         "佣金入账",
+	WalletTypeCommissionReversal: "退款扣回",
+	WalletTypeWithdraw:           "申请提现",
+	WalletTypeWithdrawReturn:     "提现退回",
+}
+
+const (
+	// WithdrawalStatusPending 待审核
+	WithdrawalStatusPending = 0
+	// WithdrawalStatusApproved 已通过并打款
+	WithdrawalStatusApproved = 1
+	// WithdrawalStatusRejected 已驳回，金额退回钱包
+	WithdrawalStatusRejected = 2
+)
+
+// Referral represents the referrer a user was brought in by, bound at first login
+type Referral struct {
+	ID               uint64    `json:"id" gorm:"column:id;primaryKey"`
+	UserID           uint64    `json:"userID" gorm:"column:user_id;uniqueIndex;not null"`
+	ReferrerID       uint64    `json:"referrerID" gorm:"column:referrer_id;index;not null"`               // 一级推荐人
+	ParentReferrerID uint64    `json:"parentReferrerID" gorm:"column:parent_referrer_id;index;default:0"` // 二级推荐人，即推荐人的推荐人
+	Scene            string    `json:"scene" gorm:"column:scene"`
+	CreatedAt        time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// ReferralClick represents a visit through a referrer's share
+type ReferralClick struct {
+	ID         uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ReferrerID uint64    `json:"referrerID" gorm:"column:referrer_id;index;not null"`
+	ProductID  uint64    `json:"productID" gorm:"column:product_id;default:0"`
+	VisitorID  uint64    `json:"visitorID" gorm:"column:visitor_id;default:0"` // 访客用户ID，未登录为0
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// CommissionRule represents the commission rates of a product or a category.
+// Product rules take precedence over category rules.
+type CommissionRule struct {
+	ID         uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID  uint64    `json:"productID" gorm:"column:product_id;uniqueIndex:idx_product_category;default:0"`
+	CategoryID uint64    `json:"categoryID" gorm:"column:category_id;uniqueIndex:idx_product_category;default:0"`
+	Level1Rate float64   `json:"level1Rate" gorm:"column:level1_rate;type:decimal(5,4);not null"`  // 一级佣金比例
+	Level2Rate float64   `json:"level2Rate" gorm:"column:level2_rate;type:decimal(5,4);default:0"` // 二级佣金比例
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// Commission represents the commission of a referrer on an order
+type Commission struct {
+	ID         uint64     `json:"id" gorm:"column:id;primaryKey"`
+	OrderID    uint64     `json:"orderID" gorm:"column:order_id;uniqueIndex:idx_order_level;not null"`
+	Level      int        `json:"level" gorm:"column:level;uniqueIndex:idx_order_level;not null"` // 1: 一级, 2: 二级
+	ReferrerID uint64     `json:"referrerID" gorm:"column:referrer_id;index;not null"`
+	BuyerID    uint64     `json:"buyerID" gorm:"column:buyer_id;not null"`
+	OrderNo    string     `json:"orderNo" gorm:"column:order_no"`
+	BaseAmount float64    `json:"baseAmount" gorm:"column:base_amount;type:decimal(10,2)"` // 计佣金额，商品实付金额
+	Amount     float64    `json:"amount" gorm:"column:amount;type:decimal(10,2)"`
+	Status     int        `json:"status" gorm:"column:status;default:0"`
+	SettledAt  *time.Time `json:"settledAt" gorm:"column:settled_at"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// WalletLedger represents a single change of a user's wallet balance
+type WalletLedger struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	UserID    uint64    `json:"userID" gorm:"column:user_id;index;not null"`
+	Type      int       `json:"type" gorm:"column:type;not null"`
+	Change    float64   `json:"change" gorm:"column:change;type:decimal(10,2);not null"`   // 变动金额，正数为增加，负数为减少
+	Balance   float64   `json:"balance" gorm:"column:balance;type:decimal(10,2);not null"` // 变动后余额
+	RefID     uint64    `json:"refID" gorm:"column:ref_id"`                                // 关联业务ID，佣金ID或提现ID
+	Remark    string    `json:"remark" gorm:"column:remark"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// Withdrawal represents a request to withdraw the wallet balance, reviewed by an admin
+type Withdrawal struct {
+	ID         uint64     `json:"id" gorm:"column:id;primaryKey"`
+	UserID     uint64     `json:"userID" gorm:"column:user_id;index;not null"`
+	Amount     float64    `json:"amount" gorm:"column:amount;type:decimal(10,2);not null"`
+	Status     int        `json:"status" gorm:"column:status;index;default:0"`
+	ReviewerID uint64     `json:"reviewerID" gorm:"column:reviewer_id;default:0"`
+	Remark     string     `json:"remark" gorm:"column:remark"` // 审核备注
+	ReviewedAt *time.Time `json:"reviewedAt" gorm:"column:reviewed_at"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
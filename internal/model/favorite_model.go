@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Favorite represents a product saved by a user for later, watched for restocks and price drops
+type Favorite struct {
+	ID            uint64    `json:"id" gorm:"column:id;primaryKey"`
+	UserID        uint64    `json:"userID" gorm:"column:user_id;uniqueIndex:idx_user_product;not null"`
+	ProductID     uint64    `json:"productID" gorm:"column:product_id;uniqueIndex:idx_user_product;index;not null"`
+	SavedPrice    float64   `json:"savedPrice" gorm:"column:saved_price;type:decimal(10,2);not null"`        // 收藏时的价格
+	OutOfStock    bool      `json:"outOfStock" gorm:"column:out_of_stock;default:false"`                     // 上次检查时缺货，恢复有货时提醒
+	NotifiedPrice float64   `json:"notifiedPrice" gorm:"column:notified_price;type:decimal(10,2);default:0"` // 已提醒过的降价价格，再降到更低时才再次提醒
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"column:updated_at"`
+	Product       Product   `json:"product"`
+}
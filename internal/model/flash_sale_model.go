@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+const (
+	// FlashSaleStatusDisabled 已停用
+	FlashSaleStatusDisabled = 0
+	// FlashSaleStatusEnabled 已启用
+	FlashSaleStatusEnabled = 1
+)
+
+const (
+	// FlashSaleOrderStatusCreated 已生成订单
+	FlashSaleOrderStatusCreated = 1
+	// FlashSaleOrderStatusCancelled 订单已取消，名额已释放
+	FlashSaleOrderStatusCancelled = 2
+)
+
+// FlashSale represents a limited-quantity deal of a product within a time window
+type FlashSale struct {
+	ID           uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID    uint64    `json:"productID" gorm:"column:product_id;index;not null"`
+	Title        string    `json:"title" gorm:"column:title"`
+	DealPrice    float64   `json:"dealPrice" gorm:"column:deal_price;type:decimal(10,2);not null"` // 秒杀价
+	Quantity     int       `json:"quantity" gorm:"column:quantity;not null"`                       // 秒杀总数量
+	Sold         int       `json:"sold" gorm:"column:sold;default:0"`                              // 已生成订单的数量，以数据库为准
+	LimitPerUser int       `json:"limitPerUser" gorm:"column:limit_per_user;default:1"`            // 每人限购数量，0表示不限
+	StartTime    time.Time `json:"startTime" gorm:"column:start_time;index;not null"`
+	EndTime      time.Time `json:"endTime" gorm:"column:end_time;index;not null"`
+	Status       int       `json:"status" gorm:"column:status;default:1"` // 1: enabled, 0: disabled
+	CreatedAt    time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// FlashSaleOrder represents an order created from a flash sale purchase
+type FlashSaleOrder struct {
+	ID          uint64    `json:"id" gorm:"column:id;primaryKey"`
+	FlashSaleID uint64    `json:"flashSaleID" gorm:"column:flash_sale_id;index:idx_sale_user;not null"`
+	UserID      uint64    `json:"userID" gorm:"column:user_id;index:idx_sale_user;not null"`
+	RequestID   string    `json:"requestID" gorm:"column:request_id;uniqueIndex;not null"` // 抢购请求ID，用于幂等
+	OrderID     uint64    `json:"orderID" gorm:"column:order_id;index;not null"`
+	Quantity    int       `json:"quantity" gorm:"column:quantity;not null"`
+	Status      int       `json:"status" gorm:"column:status;default:1"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
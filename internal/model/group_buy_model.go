@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+const (
+	// GroupBuyCampaignStatusDisabled 已停用
+	GroupBuyCampaignStatusDisabled = 0
+	// GroupBuyCampaignStatusEnabled 已启用
+	GroupBuyCampaignStatusEnabled = 1
+)
+
+const (
+	// GroupBuyGroupStatusOpen 拼团中
+	GroupBuyGroupStatusOpen = 1
+	// GroupBuyGroupStatusSuccess 拼团成功
+	GroupBuyGroupStatusSuccess = 2
+	// GroupBuyGroupStatusFailed 拼团失败
+	GroupBuyGroupStatusFailed = 3
+)
+
+const (
+	// GroupBuyParticipantStatusJoined 已参团，待支付
+	GroupBuyParticipantStatusJoined = 1
+	// GroupBuyParticipantStatusPaid 已支付
+	GroupBuyParticipantStatusPaid = 2
+	// GroupBuyParticipantStatusRefunded 已退款
+	GroupBuyParticipantStatusRefunded = 3
+	// GroupBuyParticipantStatusCancelled 未支付已取消，名额已释放
+	GroupBuyParticipantStatusCancelled = 4
+)
+
+// GroupBuyCampaign represents a group-buy deal of a product
+type GroupBuyCampaign struct {
+	ID         uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID  uint64    `json:"productID" gorm:"column:product_id;index;not null"`
+	Title      string    `json:"title" gorm:"column:title"`
+	GroupPrice float64   `json:"groupPrice" gorm:"column:group_price;type:decimal(10,2);not null"` // 拼团价
+	GroupSize  int       `json:"groupSize" gorm:"column:group_size;not null"`                      // 成团人数
+	TimeLimit  int       `json:"timeLimit" gorm:"column:time_limit;not null"`                      // 成团时限（分钟），从开团开始计算
+	StartTime  time.Time `json:"startTime" gorm:"column:start_time;index;not null"`
+	EndTime    time.Time `json:"endTime" gorm:"column:end_time;index;not null"`
+	Status     int       `json:"status" gorm:"column:status;default:1"` // 1: enabled, 0: disabled
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// GroupBuyGroup represents a group opened by a leader under a campaign
+type GroupBuyGroup struct {
+	ID           uint64     `json:"id" gorm:"column:id;primaryKey"`
+	CampaignID   uint64     `json:"campaignID" gorm:"column:campaign_id;index;not null"`
+	LeaderID     uint64     `json:"leaderID" gorm:"column:leader_id;index;not null"`
+	RequiredSize int        `json:"requiredSize" gorm:"column:required_size;not null"` // 开团时的成团人数
+	PaidCount    int        `json:"paidCount" gorm:"column:paid_count;default:0"`      // 已支付人数
+	Status       int        `json:"status" gorm:"column:status;index:idx_status_expire;default:1"`
+	ExpireAt     time.Time  `json:"expireAt" gorm:"column:expire_at;index:idx_status_expire;not null"`
+	CompletedAt  *time.Time `json:"completedAt" gorm:"column:completed_at"` // 成团或失败时间
+	CreatedAt    time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// GroupBuyParticipant represents a user that joined a group with an order
+type GroupBuyParticipant struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	GroupID   uint64    `json:"groupID" gorm:"column:group_id;index:idx_group_user;not null"`
+	UserID    uint64    `json:"userID" gorm:"column:user_id;index:idx_group_user;not null"`
+	OrderID   uint64    `json:"orderID" gorm:"column:order_id;uniqueIndex;not null"`
+	IsLeader  bool      `json:"isLeader" gorm:"column:is_leader;default:false"`
+	Status    int       `json:"status" gorm:"column:status;index;default:1"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
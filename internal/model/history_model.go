@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// BrowseHistory represents the last time a user viewed a product, mirrored from Redis for longer retention
+type BrowseHistory struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	UserID    uint64    `json:"userID" gorm:"column:user_id;uniqueIndex:idx_user_product;index:idx_user_viewed;not null"`
+	ProductID uint64    `json:"productID" gorm:"column:product_id;uniqueIndex:idx_user_product;not null"`
+	ViewedAt  time.Time `json:"viewedAt" gorm:"column:viewed_at;index:idx_user_viewed;not null"` // 最近浏览时间
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const (
+	// HomeSectionBanners 轮播图
+	HomeSectionBanners = "banners"
+	// HomeSectionCategories 一级分类
+	HomeSectionCategories = "categories"
+	// HomeSectionPromotions 促销活动广告
+	HomeSectionPromotions = "promotions"
+	// HomeSectionRecommend 推荐商品，登录用户为个性化推荐
+	HomeSectionRecommend = "recommend"
+	// HomeSectionHot 最近7天的热销商品
+	HomeSectionHot = "hot"
+)
+
+// HomeSection represents the config of a section of the home page. Sections without a config are shown
+// in the default order.
+type HomeSection struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	Key       string    `json:"key" gorm:"column:section_key;uniqueIndex;not null"` // banners, categories, promotions, recommend, hot
+	Title     string    `json:"title" gorm:"column:title"`                          // 区块标题，为空时前端使用默认标题
+	Visible   bool      `json:"visible" gorm:"column:visible"`
+	Limit     int       `json:"limit" gorm:"column:limit_count;default:0"` // 展示数量，0表示默认数量
+	SortOrder int       `json:"sortOrder" gorm:"column:sort_order;default:0"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
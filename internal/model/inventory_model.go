@@ -0,0 +1,92 @@
+package model
+
+import "time"
+
+const (
+	// InventoryMovementSale 下单扣减
+	InventoryMovementSale = 1
+	// InventoryMovementCancelRelease 订单取消或关闭归还
+	InventoryMovementCancelRelease = 2
+	// InventoryMovementRefundReturn 退款退回
+	InventoryMovementRefundReturn = 3
+	// InventoryMovementAdjustment 人工调整
+	InventoryMovementAdjustment = 4
+	// InventoryMovementStockTake 盘点
+	InventoryMovementStockTake = 5
+	// InventoryMovementWastage 损耗报废，含批次过期报废
+	InventoryMovementWastage = 6
+	// InventoryMovementReceive 批次或采购到货入库
+	InventoryMovementReceive = 7
+)
+
+const (
+	// InventoryRefOrder 库存变动关联的单据类型：订单
+	InventoryRefOrder = "order"
+	// InventoryRefBatch 库存变动关联的单据类型：库存批次
+	InventoryRefBatch = "batch"
+	// InventoryRefPurchaseOrder 库存变动关联的单据类型：采购单
+	InventoryRefPurchaseOrder = "purchase_order"
+)
+
+// InventoryMovementDesc 库存变动类型描述
+var InventoryMovementDesc = map[int]string{
+	InventoryMovementSale:          "下单扣减",
+	InventoryMovementCancelRelease: "取消归还",
+	InventoryMovementRefundReturn:  "退款退回",
+	InventoryMovementAdjustment:    "人工调整",
+	InventoryMovementStockTake:     "盘点",
+	InventoryMovementWastage:       "损耗报废",
+	InventoryMovementReceive:       "入库",
+}
+
+// InventoryMovement represents one change of a product's available stock in the inventory ledger
+type InventoryMovement struct {
+	ID         uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID  uint64    `json:"productID" gorm:"column:product_id;index:idx_inventory_product,priority:1;not null"`
+	Type       int       `json:"type" gorm:"column:type;not null"`         // 1: 下单扣减, 2: 取消归还, 3: 退款退回, 4: 人工调整, 5: 盘点, 6: 损耗报废, 7: 入库
+	Quantity   int       `json:"quantity" gorm:"column:quantity;not null"` // 变动数量，减少为负
+	StockAfter int       `json:"stockAfter" gorm:"column:stock_after"`     // 变动后的可售库存
+	ActorID    uint64    `json:"actorID" gorm:"column:actor_id;default:0"` // 操作人用户ID，系统任务为0
+	RefType    string    `json:"refType" gorm:"column:ref_type"`           // 关联单据类型，如order
+	RefID      uint64    `json:"refID" gorm:"column:ref_id;default:0"`     // 关联单据ID
+	Remark     string    `json:"remark" gorm:"column:remark"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at;index:idx_inventory_product,priority:2"`
+}
+
+// StockAlert represents the low-stock threshold of a product and whether staff have been alerted
+type StockAlert struct {
+	ID        uint64     `json:"id" gorm:"column:id;primaryKey"`
+	ProductID uint64     `json:"productID" gorm:"column:product_id;uniqueIndex;not null"`
+	Threshold int        `json:"threshold" gorm:"column:threshold;not null"` // 可售库存不高于该值时提醒
+	AlertedAt *time.Time `json:"alertedAt" gorm:"column:alerted_at"`         // 已提醒的时间，库存回到阈值以上后清空
+	CreatedAt time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// StockBatch represents a received batch of a perishable product. Batches are sold first-expiring-first-out,
+// and the remaining quantity is written off as wastage when the batch expires.
+// Stock not covered by any batch is treated as non-perishable and sold last.
+type StockBatch struct {
+	ID            uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID     uint64    `json:"productID" gorm:"column:product_id;index:idx_stock_batch_product,priority:1;not null"`
+	ReceivedAt    time.Time `json:"receivedAt" gorm:"column:received_at;type:date;not null"`                               // 入库日期
+	ShelfLifeDays int       `json:"shelfLifeDays" gorm:"column:shelf_life_days;not null"`                                  // 保质天数
+	ExpiresAt     time.Time `json:"expiresAt" gorm:"column:expires_at;type:date;index:idx_stock_batch_product,priority:2"` // 到期日，当天起不可售
+	Quantity      int       `json:"quantity" gorm:"column:quantity;not null"`                                              // 入库数量
+	Remaining     int       `json:"remaining" gorm:"column:remaining;not null"`                                            // 剩余可售数量
+	Remark        string    `json:"remark" gorm:"column:remark"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// StockBatchAllocation records how many units of a batch an order took, so that cancellations and refunds
+// return them to the same batch
+type StockBatchAllocation struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	BatchID   uint64    `json:"batchID" gorm:"column:batch_id;index;not null"`
+	ProductID uint64    `json:"productID" gorm:"column:product_id;not null"`
+	RefType   string    `json:"refType" gorm:"column:ref_type;index:idx_stock_allocation_ref,priority:1"`
+	RefID     uint64    `json:"refID" gorm:"column:ref_id;index:idx_stock_allocation_ref,priority:2"`
+	Quantity  int       `json:"quantity" gorm:"column:quantity;not null"` // 仍占用的数量，归还后减少
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// MemberTier represents a membership tier such as silver, gold or platinum
+type MemberTier struct {
+	ID           uint64    `json:"id" gorm:"column:id;primaryKey"`
+	Name         string    `json:"name" gorm:"column:name;not null"`
+	Level        int       `json:"level" gorm:"column:level;uniqueIndex;not null"`                      // 等级，数值越大等级越高
+	MinSpend     float64   `json:"minSpend" gorm:"column:min_spend;type:decimal(10,2);not null"`        // 近12个月已完成订单消费门槛
+	DiscountRate float64   `json:"discountRate" gorm:"column:discount_rate;type:decimal(4,2);not null"` // 会员折扣，如0.95表示95折
+	FreeShipping bool      `json:"freeShipping" gorm:"column:free_shipping;default:false"`              // 是否包邮
+	CreatedAt    time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// ProductMemberPrice represents a fixed member price of a product for a tier
+type ProductMemberPrice struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID uint64    `json:"productID" gorm:"column:product_id;uniqueIndex:idx_product_tier;not null"`
+	TierID    uint64    `json:"tierID" gorm:"column:tier_id;uniqueIndex:idx_product_tier;not null"`
+	Price     float64   `json:"price" gorm:"column:price;type:decimal(10,2);not null"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
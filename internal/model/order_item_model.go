@@ -4,14 +4,46 @@ import "time"
 
 // OrderItem represents an item in an order
 type OrderItem struct {
+	ID                uint64               `json:"id" gorm:"column:id;primaryKey"`
+	OrderID           uint64               `json:"orderID" gorm:"column:order_id;index;not null"`
+	ProductID         uint64               `json:"productID" gorm:"column:product_id;index;not null"`
+	Quantity          int                  `json:"quantity" gorm:"column:quantity;not null"`
+	Price             float64              `json:"price" gorm:"column:price;type:decimal(10,2);not null"`         // 成交单价
+	OriginalPrice     float64              `json:"originalPrice" gorm:"column:original_price;type:decimal(10,2)"` // 商品原价
+	Name              string               `json:"name" gorm:"column:name;not null"`
+	ImageUrl          string               `json:"image" gorm:"column:image_url"`
+	Blessing          string               `json:"blessing" gorm:"column:blessing"`
+	IsBundle          bool                 `json:"isBundle" gorm:"column:is_bundle;default:false"`                                  // 组合商品行，库存由组件行扣减
+	ParentID          uint64               `json:"parentID" gorm:"column:parent_id;index;default:0"`                                // 组件行所属的组合商品行，0表示非组件行
+	Allocated         float64              `json:"allocated" gorm:"column:allocated;type:decimal(10,2)"`                            // 组件行分摊的组合商品成交金额，组件行单价为0
+	RefundedQty       int                  `json:"refundedQty" gorm:"column:refunded_qty;default:0"`                                // 已部分退款数量
+	PromotionDiscount float64              `json:"promotionDiscount" gorm:"column:promotion_discount;type:decimal(10,2);default:0"` // 整行的促销优惠合计，成交小计为单价乘数量减去该金额
+	CartID            uint64               `json:"cartID" gorm:"column:cart_id;default:0"`                                          // 来源购物车项，付款后从购物车扣除，0表示不是从购物车下单
+	Components        []OrderItem          `json:"components,omitempty" gorm:"-"`                                                   // 下单时展开的组件行
+	Promotions        []OrderItemPromotion `json:"promotions,omitempty" gorm:"-"`                                                   // 下单时享受的促销活动
+	CreatedAt         time.Time            `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt         time.Time            `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// OrderRefund represents a partial refund of some items of an order
+type OrderRefund struct {
 	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
 	OrderID   uint64    `json:"orderID" gorm:"column:order_id;index;not null"`
-	ProductID uint64    `json:"productID" gorm:"column:product_id;index;not null"`
-	Quantity  int       `json:"quantity" gorm:"column:quantity;not null"`
-	Price     float64   `json:"price" gorm:"column:price;type:decimal(10,2);not null"`
-	Name      string    `json:"name" gorm:"column:name;not null"`
-	ImageUrl  string    `json:"image" gorm:"column:image_url"`
-	Blessing  string    `json:"blessing" gorm:"column:blessing"`
+	RefundNo  string    `json:"refundNo" gorm:"column:refund_no;uniqueIndex;not null"`
+	Amount    float64   `json:"amount" gorm:"column:amount;type:decimal(10,2);not null"`
+	Reason    string    `json:"reason" gorm:"column:reason"`
 	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
-	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// OrderItemPromotion represents a promotion applied to an order item and the discount it gave the item.
+// A partial refund returns the discount in proportion to the refunded quantity.
+type OrderItemPromotion struct {
+	ID          uint64    `json:"id" gorm:"column:id;primaryKey"`
+	OrderID     uint64    `json:"orderID" gorm:"column:order_id;index;not null"`
+	OrderItemID uint64    `json:"orderItemID" gorm:"column:order_item_id;index;not null"`
+	PromotionID uint64    `json:"promotionID" gorm:"column:promotion_id;index;not null"`
+	Title       string    `json:"title" gorm:"column:title"`        // 下单时的活动标题
+	RuleType    int       `json:"ruleType" gorm:"column:rule_type"` // 下单时的活动规则类型
+	Discount    float64   `json:"discount" gorm:"column:discount;type:decimal(10,2);not null"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
 }
@@ -15,25 +15,41 @@ const (
 	OrderStatusCompleted = 3
 	// OrderStatusCancelled is the status for cancelled orders
 	OrderStatusCancelled = 4
+	// OrderStatusRefunded is the status for refunded orders
+	OrderStatusRefunded = 5
+	// OrderStatusGrouping is the status for paid group-buy orders waiting for the group to fill
+	OrderStatusGrouping = 6
+	// OrderStatusDepositPaid is the status for pre-orders whose deposit is paid and balance is due
+	OrderStatusDepositPaid = 7
 )
 
 // Order represents an order
 type Order struct {
-	ID            uint64    `json:"id" gorm:"column:id;primaryKey"`
-	UserID        uint64    `json:"userID" gorm:"column:user_id;index;not null"`
-	OrderNo       string    `json:"orderNo" gorm:"column:order_no;uniqueIndex;not null"`
-	TotalAmount   float64   `json:"totalAmount" gorm:"column:total_amount;type:decimal(10,2);not null"`     // 总金额
-	PaymentAmount float64   `json:"paymentAmount" gorm:"column:payment_amount;type:decimal(10,2);not null"` // 支付金额
-	Status        int       `json:"status" gorm:"column:status;default:0"`
-	PaymentTime   time.Time `json:"paymentTime" gorm:"column:payment_time"`
-	AddressID     uint64    `json:"addressID" gorm:"column:address_id"`
-	ReceiverName  string    `json:"receiverName" gorm:"column:receiver_name"`
-	ReceiverPhone string    `json:"receiverPhone" gorm:"column:receiver_phone"`
-	Address       string    `json:"address" gorm:"column:address"`
-	PaymentType   int       `json:"paymentType" gorm:"default:1"` // 1: wechat
-	Remark        string    `json:"remark" gorm:"column:remark"`
-	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
-	UpdatedAt     time.Time `json:"updatedAt" gorm:"column:updated_at"`
+	ID                uint64     `json:"id" gorm:"column:id;primaryKey"`
+	UserID            uint64     `json:"userID" gorm:"column:user_id;index;not null"`
+	OrderNo           string     `json:"orderNo" gorm:"column:order_no;uniqueIndex;not null"`
+	TotalAmount       float64    `json:"totalAmount" gorm:"column:total_amount;type:decimal(10,2);not null"`              // 总金额
+	PaymentAmount     float64    `json:"paymentAmount" gorm:"column:payment_amount;type:decimal(10,2);not null"`          // 支付金额
+	MemberDiscount    float64    `json:"memberDiscount" gorm:"column:member_discount;type:decimal(10,2);default:0"`       // 会员优惠金额
+	PromotionDiscount float64    `json:"promotionDiscount" gorm:"column:promotion_discount;type:decimal(10,2);default:0"` // 促销活动优惠金额
+	ShippingFee       float64    `json:"shippingFee" gorm:"column:shipping_fee;type:decimal(10,2);default:0"`             // 运费
+	PointsUsed        int        `json:"pointsUsed" gorm:"column:points_used;default:0"`                                  // 使用积分
+	PointsDiscount    float64    `json:"pointsDiscount" gorm:"column:points_discount;type:decimal(10,2);default:0"`       // 积分抵扣金额
+	RefundedAmount    float64    `json:"refundedAmount" gorm:"column:refunded_amount;type:decimal(10,2);default:0"`       // 已部分退款金额
+	Status            int        `json:"status" gorm:"column:status;default:0"`
+	PaymentTime       time.Time  `json:"paymentTime" gorm:"column:payment_time"`
+	CompletedAt       *time.Time `json:"completedAt" gorm:"column:completed_at"`
+	AddressID         uint64     `json:"addressID" gorm:"column:address_id"`
+	ReceiverName      string     `json:"receiverName" gorm:"column:receiver_name"`
+	ReceiverPhone     string     `json:"receiverPhone" gorm:"column:receiver_phone"`
+	Address           string     `json:"address" gorm:"column:address"`
+	GroupID           uint64     `json:"groupID" gorm:"column:group_id;index;default:0"`      // 拼团ID，0表示非拼团订单
+	PreSaleID         uint64     `json:"preSaleID" gorm:"column:pre_sale_id;index;default:0"` // 预售活动ID，0表示非预售订单
+	DeliveryDate      *time.Time `json:"deliveryDate" gorm:"column:delivery_date;type:date"`  // 期望配送日期，为空表示尽快配送
+	PaymentType       int        `json:"paymentType" gorm:"default:1"`                        // 1: wechat
+	Remark            string     `json:"remark" gorm:"column:remark"`
+	CreatedAt         time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt         time.Time  `json:"updatedAt" gorm:"column:updated_at"`
 }
 
 type OrderWithOrderItem struct {
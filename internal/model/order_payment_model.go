@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// 支付阶段
+const (
+	// PaymentStageDeposit 定金
+	PaymentStageDeposit = 1
+	// PaymentStageBalance 尾款
+	PaymentStageBalance = 2
+)
+
+const (
+	// OrderPaymentStatusPending 待支付
+	OrderPaymentStatusPending = 0
+	// OrderPaymentStatusPaid 已支付
+	OrderPaymentStatusPaid = 1
+	// OrderPaymentStatusRefunded 已退款
+	OrderPaymentStatusRefunded = 2
+	// OrderPaymentStatusForfeited 定金不退
+	OrderPaymentStatusForfeited = 3
+	// OrderPaymentStatusCancelled 未支付已关闭
+	OrderPaymentStatusCancelled = 4
+)
+
+// OrderPayment represents one payment of an order paid in stages, e.g. the deposit and balance of a pre-order
+type OrderPayment struct {
+	ID         uint64     `json:"id" gorm:"column:id;primaryKey"`
+	OrderID    uint64     `json:"orderID" gorm:"column:order_id;index;not null"`
+	PaymentNo  string     `json:"paymentNo" gorm:"column:payment_no;uniqueIndex;not null"` // 支付单号，作为支付渠道的商户订单号
+	Stage      int        `json:"stage" gorm:"column:stage;index:idx_stage_status_due;not null"`
+	Amount     float64    `json:"amount" gorm:"column:amount;type:decimal(10,2);not null"`
+	Status     int        `json:"status" gorm:"column:status;index:idx_stage_status_due;default:0"`
+	DueStart   time.Time  `json:"dueStart" gorm:"column:due_start"`                                 // 支付开始时间
+	DueEnd     time.Time  `json:"dueEnd" gorm:"column:due_end;index:idx_stage_status_due;not null"` // 支付截止时间
+	PaidAt     *time.Time `json:"paidAt" gorm:"column:paid_at"`
+	RemindedAt *time.Time `json:"remindedAt" gorm:"column:reminded_at"` // 催付提醒时间
+	CreatedAt  time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+const (
+	// PointsTypeOrder 订单完成获得积分
+	PointsTypeOrder = 1
+	// PointsTypeFirstOrder 首单奖励积分
+	PointsTypeFirstOrder = 3
+	// PointsTypeRefund 退款扣回积分
+	PointsTypeRefund = 4
+	// PointsTypeRedeem 下单抵扣积分
+	PointsTypeRedeem = 5
+	// PointsTypeRedeemReturn 订单取消/退款退还抵扣积分
+	PointsTypeRedeemReturn = 6
+	// PointsTypeExpire 积分过期
+	PointsTypeExpire = 7
+	// PointsTypeAdjust 管理员调整
+	PointsTypeAdjust = 8
+)
+
+// PointsTypeDesc 积分流水类型描述
+var PointsTypeDesc = map[int]string{
+	PointsTypeOrder:        "订单完成",
+	PointsTypeFirstOrder:   "首单奖励",
+	PointsTypeRefund:       "退款扣回",
+	PointsTypeRedeem:       "下单抵扣",
+	PointsTypeRedeemReturn: "抵扣退还",
+	PointsTypeExpire:       "积分过期",
+	PointsTypeAdjust:       "人工调整",
+}
+
+// PointsLedger represents a single change of a user's points balance
+type PointsLedger struct {
+	ID         uint64     `json:"id" gorm:"column:id;primaryKey"`
+	UserID     uint64     `json:"userID" gorm:"column:user_id;index;not null"`
+	Type       int        `json:"type" gorm:"column:type;not null"`
+	Change     int        `json:"change" gorm:"column:change;not null"`   // 积分变动，正数为增加，负数为减少
+	Balance    int        `json:"balance" gorm:"column:balance;not null"` // 变动后余额
+	Remaining  int        `json:"remaining" gorm:"column:remaining;default:0"`
+	ExpireAt   *time.Time `json:"expireAt" gorm:"column:expire_at;index"`
+	OrderID    uint64     `json:"orderID" gorm:"column:order_id;index"`
+	OperatorID uint64     `json:"operatorID" gorm:"column:operator_id"`
+	Remark     string     `json:"remark" gorm:"column:remark"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"column:created_at"`
+}
+
+// PointsRule represents the earn rate of a category
+type PointsRule struct {
+	ID         uint64    `json:"id" gorm:"column:id;primaryKey"`
+	CategoryID uint64    `json:"categoryID" gorm:"column:category_id;uniqueIndex;not null"`
+	EarnRate   float64   `json:"earnRate" gorm:"column:earn_rate;type:decimal(10,2);not null"` // 每消费1元获得的积分
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
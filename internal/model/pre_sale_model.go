@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+const (
+	// PreSaleStatusDisabled 已停用
+	PreSaleStatusDisabled = 0
+	// PreSaleStatusEnabled 已启用
+	PreSaleStatusEnabled = 1
+)
+
+// 尾款逾期未付时定金的处理方式
+const (
+	// PreSaleForfeitPolicyForfeit 定金不退
+	PreSaleForfeitPolicyForfeit = "forfeit"
+	// PreSaleForfeitPolicyRefund 定金原路退回
+	PreSaleForfeitPolicyRefund = "refund"
+)
+
+// PreSale represents a pre-order campaign of a product, paid as a deposit and a balance
+type PreSale struct {
+	ID            uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID     uint64    `json:"productID" gorm:"column:product_id;index;not null"`
+	Title         string    `json:"title" gorm:"column:title"`
+	PreSalePrice  float64   `json:"preSalePrice" gorm:"column:pre_sale_price;type:decimal(10,2);not null"` // 预售价，含定金
+	Deposit       float64   `json:"deposit" gorm:"column:deposit;type:decimal(10,2);not null"`             // 每件定金
+	DepositStart  time.Time `json:"depositStart" gorm:"column:deposit_start;not null"`
+	DepositEnd    time.Time `json:"depositEnd" gorm:"column:deposit_end;index;not null"`
+	BalanceStart  time.Time `json:"balanceStart" gorm:"column:balance_start;not null"`
+	BalanceEnd    time.Time `json:"balanceEnd" gorm:"column:balance_end;not null"`
+	ShipAt        time.Time `json:"shipAt" gorm:"column:ship_at"`               // 预计发货时间
+	ForfeitPolicy string    `json:"forfeitPolicy" gorm:"column:forfeit_policy"` // 尾款逾期定金处理方式，为空时使用全局配置
+	Status        int       `json:"status" gorm:"column:status;default:1"`      // 1: enabled, 0: disabled
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
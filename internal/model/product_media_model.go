@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	// MediaTypeImage 图片
+	MediaTypeImage = 1
+	// MediaTypeVideo 视频
+	MediaTypeVideo = 2
+	// MediaTypeText 富文本，仅详情内容块使用
+	MediaTypeText = 3
+)
+
+// ProductGalleryItem represents an image or the short video in the ordered gallery of a product
+type ProductGalleryItem struct {
+	ID         uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID  uint64    `json:"productID" gorm:"column:product_id;index;not null"`
+	Type       int       `json:"type" gorm:"column:type;not null"`                    // 1: 图片, 2: 视频
+	ObjectName string    `json:"objectName" gorm:"column:object_name;index;not null"` // MinIO对象名
+	SortOrder  int       `json:"sortOrder" gorm:"column:sort_order;default:0"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// ProductDetailBlock represents an image or text block of the rich detail body of a product
+type ProductDetailBlock struct {
+	ID         uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID  uint64    `json:"productID" gorm:"column:product_id;index;not null"`
+	Type       int       `json:"type" gorm:"column:type;not null"`           // 1: 图片, 3: 富文本
+	ObjectName string    `json:"objectName" gorm:"column:object_name;index"` // 图片块的MinIO对象名
+	Content    string    `json:"content" gorm:"column:content;type:text"`    // 文本块清理后的HTML
+	SortOrder  int       `json:"sortOrder" gorm:"column:sort_order;default:0"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// MediaObject tracks an uploaded MinIO object used by product galleries and detail bodies.
+// Objects no longer referenced are deleted from MinIO after a grace period.
+type MediaObject struct {
+	ID             uint64     `json:"id" gorm:"column:id;primaryKey"`
+	ObjectName     string     `json:"objectName" gorm:"column:object_name;uniqueIndex;not null"`
+	UnreferencedAt *time.Time `json:"unreferencedAt" gorm:"column:unreferenced_at;index"` // 不再被引用的时间，被引用时为空
+	CreatedAt      time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
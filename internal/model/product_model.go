@@ -13,12 +13,13 @@ type Product struct {
 	MarketPrice    float64   `json:"marketPrice" gorm:"column:market_price;type:decimal(10,2)"`
 	SaleCount      int       `json:"saleCount" gorm:"column:sale_count;default:0"`
 	StockCount     int       `json:"stockCount" gorm:"column:stock_count;default:0"`
-	CategoryID     uint64    `json:"categoryID" gorm:"column:category_id;index"`
-	SubCategoryID  uint64    `json:"subCategoryID" gorm:"column:sub_category_id;index"`
+	CategoryID     uint64    `json:"categoryID" gorm:"column:category_id;index"`     // 所属分类，可以是任意层级
+	CategoryPath   string    `json:"categoryPath" gorm:"column:category_path;index"` // 所属分类的路径，与分类的path一致，用于查询分类及其子孙分类下的商品
 	Material       string    `json:"material" gorm:"column:material"`
 	Packing        string    `json:"packing" gorm:"column:packing"`
 	ImageUrl       string    `json:"imageUrl" gorm:"column:image_url"`
-	Status         int       `json:"status" gorm:"column:status;default:1"` // 1: on sale, 0: off sale
+	Status         int       `json:"status" gorm:"column:status;default:1"`          // 1: on sale, 0: off sale
+	IsBundle       bool      `json:"isBundle" gorm:"column:is_bundle;default:false"` // 组合商品，库存由组件库存决定
 	Recommend      bool      `json:"recommend" gorm:"column:recommend;default:false"`
 	SortOrder      int       `json:"sortOrder" gorm:"column:sort_order;default:0"`
 	ApplyUser      string    `json:"applyUser" gorm:"column:apply_user"`
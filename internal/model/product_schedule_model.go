@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+const (
+	// ProductScheduleActionOnSale 定时上架
+	ProductScheduleActionOnSale = 1
+	// ProductScheduleActionOffSale 定时下架
+	ProductScheduleActionOffSale = 2
+	// ProductScheduleActionPrice 定时调价
+	ProductScheduleActionPrice = 3
+)
+
+const (
+	// ProductScheduleStatusPending 待执行
+	ProductScheduleStatusPending = 0
+	// ProductScheduleStatusApplied 已执行
+	ProductScheduleStatusApplied = 1
+	// ProductScheduleStatusCancelled 已取消
+	ProductScheduleStatusCancelled = 2
+)
+
+// ProductSchedule represents a scheduled change of a product's sale status or price
+type ProductSchedule struct {
+	ID            uint64     `json:"id" gorm:"column:id;primaryKey"`
+	ProductID     uint64     `json:"productID" gorm:"column:product_id;index;not null"`
+	Action        int        `json:"action" gorm:"column:action;not null"`                          // 1: 上架, 2: 下架, 3: 调价
+	Price         float64    `json:"price" gorm:"column:price;type:decimal(10,2);default:0"`        // 调价后的价格，恢复计划执行时填入实际恢复的价格
+	RevertOf      uint64     `json:"revertOf" gorm:"column:revert_of;index;default:0"`              // 时段调价结束时恢复原价的计划，对应开始调价的计划ID
+	PreviousPrice float64    `json:"previousPrice" gorm:"column:previous_price;type:decimal(10,2)"` // 执行前的价格
+	RunAt         time.Time  `json:"runAt" gorm:"column:run_at;index:idx_product_schedule_due,priority:2;not null"`
+	Status        int        `json:"status" gorm:"column:status;index:idx_product_schedule_due,priority:1;default:0"` // 0: 待执行, 1: 已执行, 2: 已取消
+	AppliedAt     *time.Time `json:"appliedAt" gorm:"column:applied_at"`
+	Remark        string     `json:"remark" gorm:"column:remark"`
+	CreatedAt     time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt     time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// PriceHistory represents a price a product was sold at from EffectiveAt until the next entry
+type PriceHistory struct {
+	ID            uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID     uint64    `json:"productID" gorm:"column:product_id;index:idx_price_history_product,priority:1;not null"`
+	Price         float64   `json:"price" gorm:"column:price;type:decimal(10,2)"`
+	PreviousPrice float64   `json:"previousPrice" gorm:"column:previous_price;type:decimal(10,2)"` // 变更前的价格
+	EffectiveAt   time.Time `json:"effectiveAt" gorm:"column:effective_at;index:idx_price_history_product,priority:2;not null"`
+	ScheduleID    uint64    `json:"scheduleID" gorm:"column:schedule_id;default:0"` // 来自定时调价时为计划ID
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
+}
@@ -2,12 +2,66 @@ package model
 
 import "time"
 
-// Promotion represents a promotion activity
+const (
+	// PromotionScopeAll 全部商品
+	PromotionScopeAll = 0
+	// PromotionScopeCategory 指定分类及其子孙分类的商品
+	PromotionScopeCategory = 1
+	// PromotionScopeProduct 指定商品
+	PromotionScopeProduct = 2
+)
+
+const (
+	// PromotionRuleNone 只作为首页广告展示，不计算优惠
+	PromotionRuleNone = 0
+	// PromotionRuleReduction 满减：满Threshold元减Discount元，Repeatable时每满Threshold元减一次
+	PromotionRuleReduction = 1
+	// PromotionRulePercentage 满折：满Threshold元按Rate折扣
+	PromotionRulePercentage = 2
+	// PromotionRuleBuyFree 买BuyQty件送FreeQty件，价格最低的商品免费
+	PromotionRuleBuyFree = 3
+	// PromotionRuleSecondHalf 同一商品第二件半价
+	PromotionRuleSecondHalf = 4
+)
+
+const (
+	// PromotionStatusDisabled 停用
+	PromotionStatusDisabled = 0
+	// PromotionStatusEnabled 启用
+	PromotionStatusEnabled = 1
+)
+
+// Promotion represents a promotion activity. Within its time window it gives a discount on the products in
+// its scope at checkout, and is shown as an ad on the home page when it has an image.
 type Promotion struct {
-	ID            uint64    `json:"id" gorm:"column:id;primaryKey"`
-	Title         string    `json:"title" gorm:"column:title;not null"`
-	ImageUrl      string    `json:"imageUrl" gorm:"column:image_url;not null"`
-	SubCategoryID uint64    `json:"subCategoryID" gorm:"column:sub_category_id;not null"`
-	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
-	UpdatedAt     time.Time `json:"updatedAt" gorm:"column:updated_at"`
+	ID              uint64     `json:"id" gorm:"column:id;primaryKey"`
+	Title           string     `json:"title" gorm:"column:title;not null"`
+	ImageUrl        string     `json:"imageUrl" gorm:"column:image_url"` // 首页广告图片，为空时不展示
+	Description     string     `json:"description" gorm:"column:description"`
+	Scope           int        `json:"scope" gorm:"column:scope;default:0"`                            // 0: 全部商品, 1: 指定分类, 2: 指定商品
+	RuleType        int        `json:"ruleType" gorm:"column:rule_type;default:0"`                     // 0: 仅广告, 1: 满减, 2: 满折, 3: 买N送M, 4: 第二件半价
+	Threshold       float64    `json:"threshold" gorm:"column:threshold;type:decimal(10,2);default:0"` // 满减、满折的门槛金额
+	Discount        float64    `json:"discount" gorm:"column:discount;type:decimal(10,2);default:0"`   // 满减金额
+	Rate            float64    `json:"rate" gorm:"column:rate;type:decimal(4,2);default:0"`            // 满折折扣率，如0.8为八折
+	BuyQty          int        `json:"buyQty" gorm:"column:buy_qty;default:0"`                         // 买N送M的N
+	FreeQty         int        `json:"freeQty" gorm:"column:free_qty;default:0"`                       // 买N送M的M
+	Repeatable      bool       `json:"repeatable" gorm:"column:repeatable;default:false"`              // 满减是否每满门槛减一次
+	Exclusive       bool       `json:"exclusive" gorm:"column:exclusive;default:false"`                // 不与其他促销活动同时享受
+	CouponStackable bool       `json:"couponStackable" gorm:"column:coupon_stackable;default:false"`   // 可与优惠券叠加使用
+	Priority        int        `json:"priority" gorm:"column:priority;default:0"`                      // 叠加时优先级高的先计算
+	StartAt         *time.Time `json:"startAt" gorm:"column:start_at;index"`                           // 开始时间，为空表示立即
+	EndAt           *time.Time `json:"endAt" gorm:"column:end_at;index"`                               // 结束时间，为空表示不结束
+	Status          int        `json:"status" gorm:"column:status;index;default:1"`                    // 0: 停用, 1: 启用
+	CategoryIDs     []uint64   `json:"categoryIDs" gorm:"-"`                                           // 指定分类时的分类
+	ProductIDs      []uint64   `json:"productIDs" gorm:"-"`                                            // 指定商品时的商品
+	CreatedAt       time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt       time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// PromotionScope represents a category or product a promotion applies to
+type PromotionScope struct {
+	ID          uint64 `json:"id" gorm:"column:id;primaryKey"`
+	PromotionID uint64 `json:"promotionID" gorm:"column:promotion_id;index;not null"`
+	CategoryID  uint64 `json:"categoryID" gorm:"column:category_id;index;default:0"` // 指定分类时为分类ID，否则为0
+	ProductID   uint64 `json:"productID" gorm:"column:product_id;index;default:0"`   // 指定商品时为商品ID，否则为0
 }
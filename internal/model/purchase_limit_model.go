@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	// PurchaseLimitPeriodTotal 活动期内累计限购，未设置开始时间时为终身限购
+	PurchaseLimitPeriodTotal = 0
+	// PurchaseLimitPeriodDay 每天限购
+	PurchaseLimitPeriodDay = 1
+	// PurchaseLimitPeriodWeek 每周限购，自然周从周一开始
+	PurchaseLimitPeriodWeek = 2
+	// PurchaseLimitPeriodMonth 每月限购
+	PurchaseLimitPeriodMonth = 3
+)
+
+// PurchaseLimitPeriodDesc 限购周期描述，用于提示用户
+var PurchaseLimitPeriodDesc = map[int]string{
+	PurchaseLimitPeriodTotal: "每人",
+	PurchaseLimitPeriodDay:   "每人每天",
+	PurchaseLimitPeriodWeek:  "每人每周",
+	PurchaseLimitPeriodMonth: "每人每月",
+}
+
+// PurchaseLimit represents a rule limiting how many units of a product one user can buy within a period
+type PurchaseLimit struct {
+	ID          uint64     `json:"id" gorm:"column:id;primaryKey"`
+	ProductID   uint64     `json:"productID" gorm:"column:product_id;index;not null"`
+	Period      int        `json:"period" gorm:"column:period;default:0"`           // 0: 累计, 1: 每天, 2: 每周, 3: 每月
+	MaxQuantity int        `json:"maxQuantity" gorm:"column:max_quantity;not null"` // 周期内最多购买数量
+	StartAt     *time.Time `json:"startAt" gorm:"column:start_at"`                  // 生效开始时间，为空表示立即生效
+	EndAt       *time.Time `json:"endAt" gorm:"column:end_at"`                      // 生效结束时间，为空表示长期有效
+	CreatedAt   time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+const (
+	// SupplierStatusDisabled 停用，不能新建采购单
+	SupplierStatusDisabled = 0
+	// SupplierStatusEnabled 启用
+	SupplierStatusEnabled = 1
+)
+
+const (
+	// PurchaseOrderStatusDraft 草稿，可修改
+	PurchaseOrderStatusDraft = 0
+	// PurchaseOrderStatusOrdered 已下单，等待到货
+	PurchaseOrderStatusOrdered = 1
+	// PurchaseOrderStatusPartiallyReceived 部分到货
+	PurchaseOrderStatusPartiallyReceived = 2
+	// PurchaseOrderStatusReceived 全部到货
+	PurchaseOrderStatusReceived = 3
+	// PurchaseOrderStatusCancelled 未到货时取消
+	PurchaseOrderStatusCancelled = 4
+	// PurchaseOrderStatusClosed 部分到货后关闭，剩余数量不再到货
+	PurchaseOrderStatusClosed = 5
+)
+
+// PurchaseOrderStatusDesc 采购单状态描述
+var PurchaseOrderStatusDesc = map[int]string{
+	PurchaseOrderStatusDraft:             "草稿",
+	PurchaseOrderStatusOrdered:           "已下单",
+	PurchaseOrderStatusPartiallyReceived: "部分到货",
+	PurchaseOrderStatusReceived:          "已到货",
+	PurchaseOrderStatusCancelled:         "已取消",
+	PurchaseOrderStatusClosed:            "已关闭",
+}
+
+// Supplier represents a grower or wholesaler that stock is purchased from
+type Supplier struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	Name      string    `json:"name" gorm:"column:name;not null"`
+	Contact   string    `json:"contact" gorm:"column:contact"` // 联系人
+	Phone     string    `json:"phone" gorm:"column:phone"`
+	WechatID  string    `json:"wechatID" gorm:"column:wechat_id"` // 微信号，目前通过微信下单
+	Address   string    `json:"address" gorm:"column:address"`
+	LeadDays  int       `json:"leadDays" gorm:"column:lead_days;default:0"` // 下单到到货的天数，0表示使用默认值
+	Status    int       `json:"status" gorm:"column:status;default:1"`      // 0: 停用, 1: 启用
+	Remark    string    `json:"remark" gorm:"column:remark"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// PurchaseOrder represents stock ordered from a supplier. It is received in one or more deliveries,
+// each putting the received units into stock through the inventory ledger.
+type PurchaseOrder struct {
+	ID          uint64              `json:"id" gorm:"column:id;primaryKey"`
+	PONo        string              `json:"poNo" gorm:"column:po_no;uniqueIndex;not null"`
+	SupplierID  uint64              `json:"supplierID" gorm:"column:supplier_id;index;not null"`
+	Status      int                 `json:"status" gorm:"column:status;index;default:0"`                         // 0: 草稿, 1: 已下单, 2: 部分到货, 3: 已到货, 4: 已取消, 5: 已关闭
+	ExpectedAt  *time.Time          `json:"expectedAt" gorm:"column:expected_at;type:date"`                      // 预计到货日期
+	TotalAmount float64             `json:"totalAmount" gorm:"column:total_amount;type:decimal(10,2);default:0"` // 采购金额，数量乘以单价之和
+	Remark      string              `json:"remark" gorm:"column:remark"`
+	CreatedBy   uint64              `json:"createdBy" gorm:"column:created_by;default:0"`
+	OrderedAt   *time.Time          `json:"orderedAt" gorm:"column:ordered_at"` // 提交下单的时间
+	Supplier    *Supplier           `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+	Items       []PurchaseOrderItem `json:"items" gorm:"foreignKey:PurchaseOrderID"`
+	CreatedAt   time.Time           `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt   time.Time           `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// PurchaseOrderItem represents a product and its ordered and received quantities in a purchase order
+type PurchaseOrderItem struct {
+	ID              uint64    `json:"id" gorm:"column:id;primaryKey"`
+	PurchaseOrderID uint64    `json:"purchaseOrderID" gorm:"column:purchase_order_id;index;not null"`
+	ProductID       uint64    `json:"productID" gorm:"column:product_id;index;not null"`
+	Name            string    `json:"name" gorm:"column:name"` // 下单时的商品名称
+	Quantity        int       `json:"quantity" gorm:"column:quantity;not null"`
+	ReceivedQty     int       `json:"receivedQty" gorm:"column:received_qty;default:0"`
+	UnitCost        float64   `json:"unitCost" gorm:"column:unit_cost;type:decimal(10,2);default:0"` // 采购单价
+	ShelfLifeDays   int       `json:"shelfLifeDays" gorm:"column:shelf_life_days;default:0"`         // 保质天数，大于0时按批次入库
+	CreatedAt       time.Time `json:"createdAt" gorm:"column:created_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// ProductSimilarity represents how often a product is bought together with another product,
+// computed offline from order history
+type ProductSimilarity struct {
+	ID        uint64    `json:"id" gorm:"column:id;primaryKey"`
+	ProductID uint64    `json:"productID" gorm:"column:product_id;uniqueIndex:idx_product_similar;not null"`
+	SimilarID uint64    `json:"similarID" gorm:"column:similar_id;uniqueIndex:idx_product_similar;not null"` // 相似商品ID
+	Score     float64   `json:"score" gorm:"column:score;not null"`                                          // 相似度，越大越相似
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// 配送频率
+const (
+	// SubscriptionFrequencyWeekly 每周
+	SubscriptionFrequencyWeekly = "weekly"
+	// SubscriptionFrequencyBiweekly 每两周
+	SubscriptionFrequencyBiweekly = "biweekly"
+)
+
+const (
+	// SubscriptionStatusPendingPayment 预付订阅待支付
+	SubscriptionStatusPendingPayment = 0
+	// SubscriptionStatusActive 配送中
+	SubscriptionStatusActive = 1
+	// SubscriptionStatusPaused 已暂停
+	SubscriptionStatusPaused = 2
+	// SubscriptionStatusCancelled 已取消
+	SubscriptionStatusCancelled = 3
+	// SubscriptionStatusCompleted 已全部配送
+	SubscriptionStatusCompleted = 4
+)
+
+// Subscription represents a standing order delivered on a weekly or biweekly schedule.
+// It is either a fixed product or a florist's choice from a category within a budget.
+type Subscription struct {
+	ID               uint64     `json:"id" gorm:"column:id;primaryKey"`
+	SubscriptionNo   string     `json:"subscriptionNo" gorm:"column:subscription_no;uniqueIndex;not null"` // 订阅单号，预付时作为支付单号
+	UserID           uint64     `json:"userID" gorm:"column:user_id;index;not null"`
+	ProductID        uint64     `json:"productID" gorm:"column:product_id;default:0"`   // 指定商品，0表示花艺师自选
+	CategoryID       uint64     `json:"categoryID" gorm:"column:category_id;default:0"` // 花艺师自选的分类
+	Budget           float64    `json:"budget" gorm:"column:budget;type:decimal(10,2);default:0"`
+	Quantity         int        `json:"quantity" gorm:"column:quantity;not null"`
+	Frequency        string     `json:"frequency" gorm:"column:frequency;not null"`
+	Weekday          int        `json:"weekday" gorm:"column:weekday;not null"`   // 配送日，0为周日
+	DeliverySlot     string     `json:"deliverySlot" gorm:"column:delivery_slot"` // 配送时段，如 09:00-12:00
+	AddressID        uint64     `json:"addressID" gorm:"column:address_id;not null"`
+	Blessing         string     `json:"blessing" gorm:"column:blessing"`
+	Remark           string     `json:"remark" gorm:"column:remark"`
+	TotalDeliveries  int        `json:"totalDeliveries" gorm:"column:total_deliveries;not null"`
+	DeliveredCount   int        `json:"deliveredCount" gorm:"column:delivered_count;default:0"` // 已生成订单的次数
+	NextDeliveryDate time.Time  `json:"nextDeliveryDate" gorm:"column:next_delivery_date;type:date;index:idx_status_next"`
+	Prepaid          bool       `json:"prepaid" gorm:"column:prepaid;default:false"`                       // 是否预付
+	UnitPrice        float64    `json:"unitPrice" gorm:"column:unit_price;type:decimal(10,2)"`             // 预付时锁定的单价
+	DeliveryAmount   float64    `json:"deliveryAmount" gorm:"column:delivery_amount;type:decimal(10,2)"`   // 每次配送金额，含运费
+	PaidAmount       float64    `json:"paidAmount" gorm:"column:paid_amount;type:decimal(10,2);default:0"` // 预付金额
+	Balance          float64    `json:"balance" gorm:"column:balance;type:decimal(10,2);default:0"`        // 预付剩余金额
+	Status           int        `json:"status" gorm:"column:status;index:idx_status_next;default:0"`
+	PaidAt           *time.Time `json:"paidAt" gorm:"column:paid_at"`
+	CreatedAt        time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt        time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+// SubscriptionDelivery links a delivery of a subscription to its generated order
+type SubscriptionDelivery struct {
+	ID             uint64    `json:"id" gorm:"column:id;primaryKey"`
+	SubscriptionID uint64    `json:"subscriptionID" gorm:"column:subscription_id;uniqueIndex:idx_subscription_seq;not null"`
+	Sequence       int       `json:"sequence" gorm:"column:sequence;uniqueIndex:idx_subscription_seq;not null"` // 第几次配送，保证每次只生成一个订单
+	DeliveryDate   time.Time `json:"deliveryDate" gorm:"column:delivery_date;type:date;not null"`
+	OrderID        uint64    `json:"orderID" gorm:"column:order_id;index;not null"`
+	Amount         float64   `json:"amount" gorm:"column:amount;type:decimal(10,2)"` // 从预付金额中扣除的金额
+	CreatedAt      time.Time `json:"createdAt" gorm:"column:created_at"`
+}
@@ -16,6 +16,10 @@ type User struct {
 	City      string    `json:"city" gorm:"column:city"`
 	Province  string    `json:"province" gorm:"column:province"`
 	District  string    `json:"district" gorm:"column:district"`
+	Role      int       `json:"role" gorm:"column:role;default:1"`                        // 1: 普通用户, 2: 管理员
+	Points    int       `json:"points" gorm:"column:points;default:0"`                    // 积分余额
+	TierID    uint64    `json:"tierID" gorm:"column:tier_id;default:0"`                   // 会员等级，0表示非会员
+	Wallet    float64   `json:"wallet" gorm:"column:wallet;type:decimal(10,2);default:0"` // 钱包余额（分销佣金）
 	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
 	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
 }
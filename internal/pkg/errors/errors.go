@@ -15,20 +15,153 @@ var (
 	ErrForbidden    = errors.New("forbidden operation")
 
 	// 业务逻辑错误
-	ErrOutOfStock    = errors.New("product out of stock")
-	ErrPaymentFailed = errors.New("payment failed")
-	ErrInvalidInput  = errors.New("invalid input")
+	ErrOutOfStock     = errors.New("product out of stock")
+	ErrProductOffSale = errors.New("product is off sale")
+	ErrPaymentFailed  = errors.New("payment failed")
+	ErrInvalidInput   = errors.New("invalid input")
+
+	// 积分相关错误
+	ErrInsufficientPoints = errors.New("insufficient points")
+	ErrPointsExceedLimit  = errors.New("points exceed the redeem limit of this order")
+
+	// 订单状态错误
+	ErrOrderStatusInvalid   = errors.New("order status does not allow this operation")
+	ErrCartCheckoutPending  = errors.New("cart items are already in an unpaid order")
+	ErrPaymentWindowNotOpen = errors.New("payment window is not open yet")
+	ErrPaymentWindowClosed  = errors.New("payment window has closed")
+
+	// 秒杀相关错误
+	ErrFlashSaleNotStarted    = errors.New("flash sale has not started")
+	ErrFlashSaleEnded         = errors.New("flash sale has ended")
+	ErrFlashSaleSoldOut       = errors.New("flash sale is sold out")
+	ErrFlashSaleLimitExceeded = errors.New("flash sale purchase limit exceeded")
+	ErrFlashSaleStarted       = errors.New("flash sale has started and cannot be modified")
+
+	// 拼团相关错误
+	ErrGroupBuyNotStarted    = errors.New("group buy has not started")
+	ErrGroupBuyEnded         = errors.New("group buy has ended")
+	ErrGroupBuyGroupClosed   = errors.New("group is no longer open")
+	ErrGroupBuyGroupFull     = errors.New("group is full")
+	ErrGroupBuyAlreadyJoined = errors.New("already joined this group")
+	ErrGroupBuyStarted       = errors.New("group buy has started and cannot be modified")
+	ErrGroupBuyInvalidScene  = errors.New("invalid share scene")
+
+	// 预售相关错误
+	ErrPreSaleNotStarted      = errors.New("pre-sale deposit stage has not started")
+	ErrPreSaleEnded           = errors.New("pre-sale deposit stage has ended")
+	ErrPreSaleStarted         = errors.New("pre-sale has started and cannot be modified")
+	ErrPreSaleInvalidSchedule = errors.New("pre-sale deposit and balance windows are invalid")
+
+	// 周期订阅相关错误
+	ErrSubscriptionInvalidPlan   = errors.New("invalid subscription plan")
+	ErrSubscriptionStatusInvalid = errors.New("subscription status does not allow this operation")
+	ErrSubscriptionNotPrepaid    = errors.New("subscription is not prepaid")
+
+	// 分销相关错误
+	ErrInvalidReferralScene    = errors.New("invalid referral scene")
+	ErrInvalidCommissionRule   = errors.New("invalid commission rule")
+	ErrInsufficientBalance     = errors.New("insufficient wallet balance")
+	ErrWithdrawBelowMinimum    = errors.New("withdrawal amount is below the minimum")
+	ErrWithdrawalStatusInvalid = errors.New("withdrawal has already been reviewed")
+
+	// 组合商品相关错误
+	ErrInvalidBundleItems      = errors.New("bundle components must be existing non-bundle products other than the bundle itself")
+	ErrRefundQuantityExceeded  = errors.New("refund quantity exceeds the refundable quantity")
+	ErrPartialRefundNotAllowed = errors.New("this order does not support partial refunds")
+
+	// 限购相关错误
+	ErrPurchaseLimitExceeded = errors.New("purchase limit exceeded")
+	ErrInvalidPurchaseLimit  = errors.New("invalid purchase limit")
+
+	// 销量排行相关错误
+	ErrInvalidRankingWindow = errors.New("invalid ranking window")
+
+	// 商品图文相关错误
+	ErrInvalidMedia = errors.New("invalid product gallery or detail content")
+
+	// 商品定时计划相关错误
+	ErrInvalidProductSchedule       = errors.New("invalid product schedule")
+	ErrProductScheduleStatusInvalid = errors.New("only pending schedules can be cancelled")
+
+	// 库存相关错误
+	ErrInvalidInventoryAdjustment = errors.New("invalid inventory adjustment")
+	ErrBundleStockDerived         = errors.New("bundle stock is derived from its components")
+	ErrInvalidDeliveryDate        = errors.New("invalid delivery date")
+
+	// 轮播图相关错误
+	ErrInvalidBanner = errors.New("invalid banner image, link or schedule")
+
+	// 分类相关错误
+	ErrInvalidCategory  = errors.New("invalid category operation")
+	ErrCategoryNotEmpty = errors.New("category still has subcategories or products")
+
+	// 商品属性相关错误
+	ErrInvalidAttributeValue = errors.New("invalid attribute value")
+	ErrAttributeCodeExists   = errors.New("attribute code already exists")
+
+	// 采购相关错误
+	ErrInvalidPurchaseOrder       = errors.New("invalid purchase order")
+	ErrPurchaseOrderStatusInvalid = errors.New("purchase order status does not allow this operation")
+	ErrPurchaseReceiveExceeded    = errors.New("received quantity exceeds the outstanding quantity")
+
+	// 促销活动相关错误
+	ErrInvalidPromotion = errors.New("invalid promotion rule, scope or schedule")
+
+	// 首页相关错误
+	ErrInvalidHomeSection = errors.New("duplicate home section")
 )
 
 // 特定资源错误
 var (
-	ErrUserNotFound    = fmt.Errorf("user not found: %w", ErrNotFound)
-	ErrProductNotFound = fmt.Errorf("product not found: %w", ErrNotFound)
-	ErrOrderNotFound   = fmt.Errorf("order not found: %w", ErrNotFound)
-	ErrCartNotFound    = fmt.Errorf("cart item not found: %w", ErrNotFound)
-	ErrAddressNotFound = fmt.Errorf("address not found: %w", ErrNotFound)
+	ErrUserNotFound            = fmt.Errorf("user not found: %w", ErrNotFound)
+	ErrProductNotFound         = fmt.Errorf("product not found: %w", ErrNotFound)
+	ErrOrderNotFound           = fmt.Errorf("order not found: %w", ErrNotFound)
+	ErrCartNotFound            = fmt.Errorf("cart item not found: %w", ErrNotFound)
+	ErrAddressNotFound         = fmt.Errorf("address not found: %w", ErrNotFound)
+	ErrTierNotFound            = fmt.Errorf("member tier not found: %w", ErrNotFound)
+	ErrFlashSaleNotFound       = fmt.Errorf("flash sale not found: %w", ErrNotFound)
+	ErrFlashSaleResultNotFound = fmt.Errorf("flash sale result not found: %w", ErrNotFound)
+	ErrGroupBuyNotFound        = fmt.Errorf("group buy not found: %w", ErrNotFound)
+	ErrGroupNotFound           = fmt.Errorf("group not found: %w", ErrNotFound)
+	ErrPreSaleNotFound         = fmt.Errorf("pre-sale not found: %w", ErrNotFound)
+	ErrSubscriptionNotFound    = fmt.Errorf("subscription not found: %w", ErrNotFound)
+	ErrWithdrawalNotFound      = fmt.Errorf("withdrawal not found: %w", ErrNotFound)
+	ErrPurchaseLimitNotFound   = fmt.Errorf("purchase limit not found: %w", ErrNotFound)
+	ErrProductScheduleNotFound = fmt.Errorf("product schedule not found: %w", ErrNotFound)
+	ErrSupplierNotFound        = fmt.Errorf("supplier not found: %w", ErrNotFound)
+	ErrPurchaseOrderNotFound   = fmt.Errorf("purchase order not found: %w", ErrNotFound)
+	ErrCategoryNotFound        = fmt.Errorf("category not found: %w", ErrNotFound)
+	ErrAttributeNotFound       = fmt.Errorf("attribute not found: %w", ErrNotFound)
+	ErrBannerNotFound          = fmt.Errorf("banner not found: %w", ErrNotFound)
+	ErrPromotionNotFound       = fmt.Errorf("promotion not found: %w", ErrNotFound)
 )
 
+// PurchaseLimitError 超出商品限购时返回，携带小程序展示所需的限购信息
+type PurchaseLimitError struct {
+	ProductID   uint64 `json:"productID"`
+	ProductName string `json:"productName"`
+	Period      int    `json:"period"`    // 0: 累计, 1: 每天, 2: 每周, 3: 每月
+	Limit       int    `json:"limit"`     // 周期内限购数量
+	Purchased   int    `json:"purchased"` // 周期内已购买数量（含购物车中的数量）
+	Remaining   int    `json:"remaining"` // 还可购买数量
+	Message     string `json:"message"`   // 展示给用户的提示
+}
+
+func (e *PurchaseLimitError) Error() string {
+	return fmt.Sprintf("product %d purchase limit %d exceeded, %d remaining: %s", e.ProductID, e.Limit, e.Remaining, ErrPurchaseLimitExceeded)
+}
+
+func (e *PurchaseLimitError) Unwrap() error {
+	return ErrPurchaseLimitExceeded
+}
+
+// AsPurchaseLimitError 提取限购错误
+func AsPurchaseLimitError(err error) (*PurchaseLimitError, bool) {
+	var limitErr *PurchaseLimitError
+	ok := errors.As(err, &limitErr)
+	return limitErr, ok
+}
+
 // 错误检查辅助函数
 func IsNotFound(err error) bool {
 	return errors.Is(err, ErrNotFound)
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"sync"
+
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+)
+
+// 通知类型
+const (
+	// TypeTierChanged 会员等级变更
+	TypeTierChanged = "tier_changed"
+	// TypeGroupBuySuccess 拼团成功
+	TypeGroupBuySuccess = "group_buy_success"
+	// TypeGroupBuyFailed 拼团失败，已退款
+	TypeGroupBuyFailed = "group_buy_failed"
+	// TypeBalanceDue 预售尾款即将截止
+	TypeBalanceDue = "balance_due"
+	// TypePreOrderClosed 预售尾款逾期，订单已关闭
+	TypePreOrderClosed = "pre_order_closed"
+	// TypeSubscriptionOrder 订阅配送订单已生成
+	TypeSubscriptionOrder = "subscription_order"
+	// TypeWithdrawalReviewed 提现审核结果
+	TypeWithdrawalReviewed = "withdrawal_reviewed"
+	// TypeBackInStock 收藏商品到货
+	TypeBackInStock = "back_in_stock"
+	// TypePriceDrop 收藏商品降价
+	TypePriceDrop = "price_drop"
+	// TypeLowStock 商品库存不足，通知管理员
+	TypeLowStock = "low_stock"
+)
+
+// Message represents a notification sent to a user
+type Message struct {
+	UserID  uint64            `json:"userID"`
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Content string            `json:"content"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Channel delivers notifications, e.g. WeChat subscribe messages, SMS or in-app inbox
+type Channel interface {
+	// Name returns the name of the channel
+	Name() string
+	// Send delivers a message
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	channels []Channel
+	mu       sync.RWMutex
+)
+
+// Register adds a notification channel
+func Register(ch Channel) {
+	mu.Lock()
+	defer mu.Unlock()
+	channels = append(channels, ch)
+}
+
+// Send delivers a message through all registered channels.
+// A failing channel does not stop the others; the error is logged.
+func Send(ctx context.Context, msg Message) {
+	mu.RLock()
+	registered := channels
+	mu.RUnlock()
+
+	if len(registered) == 0 {
+		registered = []Channel{LogChannel{}}
+	}
+
+	for _, ch := range registered {
+		if err := ch.Send(ctx, msg); err != nil {
+			logger.Warnf("Failed to send %s notification to user %d via %s: %v", msg.Type, msg.UserID, ch.Name(), err)
+		}
+	}
+}
+
+// LogChannel writes notifications to the application log, used when no channel is registered
+type LogChannel struct{}
+
+// Name returns the name of the channel
+func (LogChannel) Name() string {
+	return "log"
+}
+
+// Send writes the message to the log
+func (LogChannel) Send(ctx context.Context, msg Message) error {
+	logger.Infof("Notify user %d [%s] %s: %s", msg.UserID, msg.Type, msg.Title, msg.Content)
+	return nil
+}
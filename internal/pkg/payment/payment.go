@@ -0,0 +1,64 @@
+package payment
+
+import (
+	"context"
+	"sync"
+
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+)
+
+// RefundRequest represents a refund of a paid order
+type RefundRequest struct {
+	OrderNo      string  // 商户订单号
+	RefundNo     string  // 商户退款单号，同一退款单号重复提交不会重复退款
+	TotalAmount  float64 // 订单支付金额
+	RefundAmount float64 // 退款金额
+	Reason       string
+}
+
+// Provider is a payment provider such as WeChat Pay
+type Provider interface {
+	// Name returns the name of the provider
+	Name() string
+	// Refund refunds a paid order; it must be idempotent on RefundNo
+	Refund(ctx context.Context, req RefundRequest) error
+}
+
+var (
+	provider Provider = SimulatedProvider{}
+	mu       sync.RWMutex
+)
+
+// SetProvider sets the payment provider
+func SetProvider(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	provider = p
+}
+
+// Refund refunds a paid order through the current provider
+func Refund(ctx context.Context, req RefundRequest) error {
+	mu.RLock()
+	p := provider
+	mu.RUnlock()
+
+	if err := p.Refund(ctx, req); err != nil {
+		return err
+	}
+	logger.Infof("Refunded %.2f of order %s via %s, refund no %s", req.RefundAmount, req.OrderNo, p.Name(), req.RefundNo)
+	return nil
+}
+
+// SimulatedProvider only logs refunds, matching the simulated payment flow used until WeChat Pay is integrated
+type SimulatedProvider struct{}
+
+// Name returns the name of the provider
+func (SimulatedProvider) Name() string {
+	return "simulated"
+}
+
+// Refund logs the refund
+func (SimulatedProvider) Refund(ctx context.Context, req RefundRequest) error {
+	logger.Warnf("Simulated refund of order %s: %.2f (%s)", req.OrderNo, req.RefundAmount, req.Reason)
+	return nil
+}
@@ -86,6 +86,11 @@ func (c *Client) HashGet(ctx context.Context, key, field string) (string, error)
 	return c.client.HGet(ctx, c.prefixKey(key), field).Result()
 }
 
+// HashDelete deletes hash fields
+func (c *Client) HashDelete(ctx context.Context, key string, fields ...string) error {
+	return c.client.HDel(ctx, c.prefixKey(key), fields...).Err()
+}
+
 // HashGetAll gets all fields in a hash
 func (c *Client) HashGetAll(ctx context.Context, key string) (map[string]string, error) {
 	return c.client.HGetAll(ctx, c.prefixKey(key)).Result()
@@ -102,6 +107,95 @@ func (c *Client) SetNX(ctx context.Context, key string, value interface{}, expir
 	return c.client.SetNX(ctx, c.prefixKey(key), value, expiration).Result()
 }
 
+// IncrBy increments the integer value of a key
+func (c *Client) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	return c.client.IncrBy(ctx, c.prefixKey(key), value).Result()
+}
+
+// HashIncrBy increments the integer value of a hash field
+func (c *Client) HashIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	return c.client.HIncrBy(ctx, c.prefixKey(key), field, incr).Result()
+}
+
+// Expire sets the expiration of a key
+func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return c.client.Expire(ctx, c.prefixKey(key), expiration).Err()
+}
+
+// ListPush appends values to the tail of a list
+func (c *Client) ListPush(ctx context.Context, key string, values ...interface{}) error {
+	return c.client.RPush(ctx, c.prefixKey(key), values...).Err()
+}
+
+// ListRange gets the elements of a list in the given range
+func (c *Client) ListRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return c.client.LRange(ctx, c.prefixKey(key), start, stop).Result()
+}
+
+// ListLen gets the length of a list
+func (c *Client) ListLen(ctx context.Context, key string) (int64, error) {
+	return c.client.LLen(ctx, c.prefixKey(key)).Result()
+}
+
+// ListRemove removes count occurrences of value from a list
+func (c *Client) ListRemove(ctx context.Context, key string, count int64, value interface{}) error {
+	return c.client.LRem(ctx, c.prefixKey(key), count, value).Err()
+}
+
+// ListBlockingMove pops the head of source and pushes it to the tail of destination,
+// blocking up to timeout; returns ErrNil when the source stays empty
+func (c *Client) ListBlockingMove(ctx context.Context, source, destination string, timeout time.Duration) (string, error) {
+	return c.client.BLMove(ctx, c.prefixKey(source), c.prefixKey(destination), "LEFT", "RIGHT", timeout).Result()
+}
+
+// SortedSetRevRangeWithScores gets the members of a sorted set with their scores, from the highest score
+func (c *Client) SortedSetRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]Z, error) {
+	return c.client.ZRevRangeWithScores(ctx, c.prefixKey(key), start, stop).Result()
+}
+
+// SortedSetCard gets the number of members of a sorted set
+func (c *Client) SortedSetCard(ctx context.Context, key string) (int64, error) {
+	return c.client.ZCard(ctx, c.prefixKey(key)).Result()
+}
+
+// SortedSetRemove removes members from a sorted set
+func (c *Client) SortedSetRemove(ctx context.Context, key string, members ...interface{}) error {
+	return c.client.ZRem(ctx, c.prefixKey(key), members...).Err()
+}
+
+// SetAdd adds members to a set
+func (c *Client) SetAdd(ctx context.Context, key string, members ...interface{}) error {
+	return c.client.SAdd(ctx, c.prefixKey(key), members...).Err()
+}
+
+// SetPop removes and returns up to count random members of a set
+func (c *Client) SetPop(ctx context.Context, key string, count int64) ([]string, error) {
+	return c.client.SPopN(ctx, c.prefixKey(key), count).Result()
+}
+
+// RunScript runs a Lua script, the keys are prefixed before being passed to the script
+func (c *Client) RunScript(ctx context.Context, script *Script, keys []string, args ...interface{}) (interface{}, error) {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefixKey(key)
+	}
+	return script.Run(ctx, c.client, prefixed, args...).Result()
+}
+
+// Script is a Lua script executed with EVALSHA, falling back to EVAL
+type Script = redis.Script
+
+// NewScript creates a Lua script
+func NewScript(src string) *Script {
+	return redis.NewScript(src)
+}
+
+// Z is a sorted set member with its score
+type Z = redis.Z
+
+// ErrNil is returned when a key or list element does not exist
+var ErrNil = redis.Nil
+
 // Close closes the Redis client
 func (c *Client) Close() error {
 	return c.client.Close()
@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+)
+
+// Job represents a background job
+type Job struct {
+	// Name is the unique name of the job, also used as the distributed lock key
+	Name string
+	// Interval runs the job periodically; ignored when DailyAt is set
+	Interval time.Duration
+	// DailyAt runs the job once a day at the given local time, e.g. "03:00"
+	DailyAt string
+	// Run is the job function
+	Run func(ctx context.Context) error
+}
+
+// Worker represents a long-running background worker such as a queue consumer.
+// Unlike jobs, workers run on every replica and are restarted if they return or panic.
+type Worker struct {
+	// Name is the name of the worker
+	Name string
+	// Run blocks until ctx is cancelled
+	Run func(ctx context.Context)
+}
+
+// Scheduler runs registered jobs in the background.
+// Each run is guarded by a Redis lock so that only one replica executes a job at a time.
+type Scheduler struct {
+	jobs    []Job
+	workers []Worker
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a new scheduler
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job to the scheduler
+func (s *Scheduler) Register(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// RegisterWorker adds a worker to the scheduler
+func (s *Scheduler) RegisterWorker(worker Worker) {
+	s.workers = append(s.workers, worker)
+}
+
+// Start starts all registered jobs and workers
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+
+	for _, worker := range s.workers {
+		s.wg.Add(1)
+		go func(worker Worker) {
+			defer s.wg.Done()
+			s.work(ctx, worker)
+		}(worker)
+	}
+}
+
+// Stop stops all jobs and waits for running jobs to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	for {
+		wait, err := nextRun(job, time.Now())
+		if err != nil {
+			logger.Errorf("Invalid job %s: %v", job.Name, err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+			s.run(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) work(ctx context.Context, worker Worker) {
+	for {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf("Worker %s panicked: %v", worker.Name, r)
+				}
+			}()
+			worker.Run(ctx)
+		}()
+
+		// 非正常退出时稍后重启
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	period := job.Interval
+	if job.DailyAt != "" {
+		period = 24 * time.Hour
+	}
+
+	// 锁的有效期略短于执行周期且不主动释放，保证多副本下每个周期只执行一次
+	expiry := period - time.Second
+	if expiry < time.Second {
+		expiry = time.Second
+	}
+
+	lock := redis.NewLock("scheduler:"+job.Name, expiry)
+	acquired, err := lock.TryAcquire(ctx)
+	if err != nil {
+		logger.Warnf("Failed to acquire lock for job %s: %v", job.Name, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		logger.Errorf("Job %s failed: %v", job.Name, err)
+		return
+	}
+	logger.Infof("Job %s finished in %s", job.Name, time.Since(start))
+}
+
+// nextRun 计算距离下次执行的时间
+func nextRun(job Job, now time.Time) (time.Duration, error) {
+	if job.DailyAt == "" {
+		if job.Interval <= 0 {
+			return 0, fmt.Errorf("interval must be positive")
+		}
+		return job.Interval, nil
+	}
+
+	at, err := time.ParseInLocation("15:04", job.DailyAt, now.Location())
+	if err != nil {
+		return 0, err
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next.Sub(now), nil
+}
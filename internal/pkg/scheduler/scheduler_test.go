@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2024, 2, 14, 10, 30, 0, 0, time.Local)
+
+	t.Run("固定间隔", func(t *testing.T) {
+		wait, err := nextRun(Job{Interval: 5 * time.Minute}, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if wait != 5*time.Minute {
+			t.Errorf("期望5分钟，实际%v", wait)
+		}
+	})
+
+	t.Run("当天稍后执行", func(t *testing.T) {
+		wait, err := nextRun(Job{DailyAt: "12:00"}, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if wait != 90*time.Minute {
+			t.Errorf("期望90分钟，实际%v", wait)
+		}
+	})
+
+	t.Run("已过时间则次日执行", func(t *testing.T) {
+		wait, err := nextRun(Job{DailyAt: "03:00"}, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if wait != 16*time.Hour+30*time.Minute {
+			t.Errorf("期望16小时30分钟，实际%v", wait)
+		}
+	})
+
+	t.Run("无效配置", func(t *testing.T) {
+		if _, err := nextRun(Job{}, now); err == nil {
+			t.Error("期望间隔为0时返回错误")
+		}
+		if _, err := nextRun(Job{DailyAt: "25:00"}, now); err == nil {
+			t.Error("期望无效时间返回错误")
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// AttributeRepository 商品属性仓库
+type AttributeRepository struct {
+	db *gorm.DB
+}
+
+// NewAttributeRepository
+func NewAttributeRepository(db *gorm.DB) *AttributeRepository {
+	return &AttributeRepository{
+		db: db,
+	}
+}
+
+// ValueCount 属性值及带有该值的商品数
+type ValueCount struct {
+	ValueID uint64
+	Count   int64
+}
+
+// GetAttributes 获取全部属性及属性值，按排序排列
+func (r *AttributeRepository) GetAttributes() ([]model.Attribute, error) {
+	var attributes []model.Attribute
+	err := r.db.Preload("Values", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).Order("sort_order ASC, id ASC").Find(&attributes).Error
+	return attributes, err
+}
+
+// GetAttributeByID 获取属性及属性值
+func (r *AttributeRepository) GetAttributeByID(id uint64) (*model.Attribute, error) {
+	var attribute model.Attribute
+	result := r.db.Preload("Values", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).First(&attribute, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &attribute, nil
+}
+
+// CodeExists 属性编码是否已被其他属性使用
+func (r *AttributeRepository) CodeExists(code string, excludeID uint64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Attribute{}).Where("code = ? AND id <> ?", code, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateAttribute 创建属性及属性值
+func (r *AttributeRepository) CreateAttribute(attribute *model.Attribute) error {
+	return r.db.Create(attribute).Error
+}
+
+// UpdateAttribute 更新属性
+func (r *AttributeRepository) UpdateAttribute(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&model.Attribute{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// CreateAttributeValue 创建属性值
+func (r *AttributeRepository) CreateAttributeValue(value *model.AttributeValue) error {
+	return r.db.Create(value).Error
+}
+
+// UpdateAttributeValue 更新属性值的名称和排序
+func (r *AttributeRepository) UpdateAttributeValue(id uint64, value string, sortOrder int) error {
+	return r.db.Model(&model.AttributeValue{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"value":      value,
+		"sort_order": sortOrder,
+	}).Error
+}
+
+// DeleteAttributeValues 删除属性值及商品上的这些值
+func (r *AttributeRepository) DeleteAttributeValues(ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.Where("value_id IN ?", ids).Delete(&model.ProductAttributeValue{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("id IN ?", ids).Delete(&model.AttributeValue{}).Error
+}
+
+// DeleteAttribute 删除属性、属性值及商品上的该属性
+func (r *AttributeRepository) DeleteAttribute(id uint64) error {
+	if err := r.db.Where("attribute_id = ?", id).Delete(&model.ProductAttributeValue{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("attribute_id = ?", id).Delete(&model.AttributeValue{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&model.Attribute{}, "id = ?", id).Error
+}
+
+// GetProductAttributeValues 获取商品的属性值
+func (r *AttributeRepository) GetProductAttributeValues(productID uint64) ([]model.ProductAttributeValue, error) {
+	var values []model.ProductAttributeValue
+	err := r.db.Where("product_id = ?", productID).Order("id ASC").Find(&values).Error
+	return values, err
+}
+
+// GetProductIDsByAttribute 获取带有某个属性值的全部商品ID
+func (r *AttributeRepository) GetProductIDsByAttribute(attributeID uint64) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.Model(&model.ProductAttributeValue{}).
+		Where("attribute_id = ?", attributeID).
+		Distinct().Pluck("product_id", &ids).Error
+	return ids, err
+}
+
+// ReplaceProductAttributeValues 替换商品的全部属性值
+func (r *AttributeRepository) ReplaceProductAttributeValues(productID uint64, values []model.ProductAttributeValue) error {
+	if err := r.db.Where("product_id = ?", productID).Delete(&model.ProductAttributeValue{}).Error; err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return r.db.Create(&values).Error
+}
+
+// CountProductsByValue 统计符合筛选条件的商品中带有属性各个值的商品数
+func (r *AttributeRepository) CountProductsByValue(attributeID uint64, categoryID *uint64, recommend *bool, valueGroups [][]uint64) ([]ValueCount, error) {
+	var counts []ValueCount
+	products := filterProducts(r.db.Model(&model.Product{}).Select("products.id"), categoryID, recommend, valueGroups)
+	err := r.db.Model(&model.ProductAttributeValue{}).
+		Select("value_id, COUNT(DISTINCT product_id) AS count").
+		Where("attribute_id = ? AND product_id IN (?)", attributeID, products).
+		Group("value_id").
+		Scan(&counts).Error
+	return counts, err
+}
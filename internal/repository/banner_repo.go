@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/colinjuang/shop-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BannerRepository 轮播图仓库
@@ -17,12 +20,74 @@ func NewBannerRepository(db *gorm.DB) *BannerRepository {
 	}
 }
 
-// GetBanners 获取所有轮播图
-func (r *BannerRepository) GetBanners() ([]model.Banner, error) {
+// GetBanners 获取所有轮播图，可按状态筛选
+func (r *BannerRepository) GetBanners(status *int) ([]model.Banner, error) {
 	var banners []model.Banner
-	result := r.db.Order("sort_order ASC").Find(&banners)
+	query := r.db.Model(&model.Banner{})
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	result := query.Order("sort_order ASC, id ASC").Find(&banners)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return banners, nil
 }
+
+// GetUnfinishedBanners 获取启用且尚未结束展示的轮播图，包括还未开始的
+func (r *BannerRepository) GetUnfinishedBanners(now time.Time) ([]model.Banner, error) {
+	var banners []model.Banner
+	err := r.db.Where("status = ? AND (end_at IS NULL OR end_at > ?)", model.BannerStatusEnabled, now).
+		Order("sort_order ASC, id ASC").
+		Find(&banners).Error
+	return banners, err
+}
+
+// GetBannerByID 获取轮播图
+func (r *BannerRepository) GetBannerByID(id uint64) (*model.Banner, error) {
+	var banner model.Banner
+	result := r.db.First(&banner, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &banner, nil
+}
+
+// CreateBanner 创建轮播图
+func (r *BannerRepository) CreateBanner(banner *model.Banner) error {
+	return r.db.Create(banner).Error
+}
+
+// UpdateBanner 更新轮播图
+func (r *BannerRepository) UpdateBanner(banner *model.Banner) error {
+	return r.db.Save(banner).Error
+}
+
+// DeleteBanner 删除轮播图，统计数据保留
+func (r *BannerRepository) DeleteBanner(id uint64) error {
+	return r.db.Delete(&model.Banner{}, "id = ?", id).Error
+}
+
+// UpsertDailyStats 保存轮播图每日曝光和点击数，已存在时保留较大值，Redis计数丢失后不会覆盖已同步的数据
+func (r *BannerRepository) UpsertDailyStats(stats []model.BannerDailyStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "banner_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"impressions": gorm.Expr("GREATEST(impressions, VALUES(impressions))"),
+			"clicks":      gorm.Expr("GREATEST(clicks, VALUES(clicks))"),
+			"updated_at":  gorm.Expr("VALUES(updated_at)"),
+		}),
+	}).Create(&stats).Error
+}
+
+// GetDailyStats 获取轮播图在日期范围内的每日统计，按日期排列
+func (r *BannerRepository) GetDailyStats(bannerID uint64, start, end time.Time) ([]model.BannerDailyStat, error) {
+	var stats []model.BannerDailyStat
+	err := r.db.Where("banner_id = ? AND date >= ? AND date <= ?", bannerID, start, end).
+		Order("date ASC").
+		Find(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// BundleRepository 组合商品仓库
+type BundleRepository struct {
+	db *gorm.DB
+}
+
+// NewBundleRepository
+func NewBundleRepository(db *gorm.DB) *BundleRepository {
+	return &BundleRepository{
+		db: db,
+	}
+}
+
+// GetBundleItems 获取组合商品的组件
+func (r *BundleRepository) GetBundleItems(bundleID uint64) ([]model.BundleItem, error) {
+	return r.GetBundleItemsByBundleIDs([]uint64{bundleID})
+}
+
+// GetBundleItemsByBundleIDs 批量获取组合商品的组件
+func (r *BundleRepository) GetBundleItemsByBundleIDs(bundleIDs []uint64) ([]model.BundleItem, error) {
+	var items []model.BundleItem
+	if len(bundleIDs) == 0 {
+		return items, nil
+	}
+	if err := r.db.Where("bundle_id IN ?", bundleIDs).Order("sort_order ASC, id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ReplaceBundleItems 替换组合商品的组件，需在事务中调用
+func (r *BundleRepository) ReplaceBundleItems(bundleID uint64, items []model.BundleItem) error {
+	if err := r.DeleteBundleItems(bundleID); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Create(&items).Error
+}
+
+// DeleteBundleItems 删除组合商品的组件
+func (r *BundleRepository) DeleteBundleItems(bundleID uint64) error {
+	return r.db.Delete(&model.BundleItem{}, "bundle_id = ?", bundleID).Error
+}
+
+// IsBundleComponent 判断商品是否为某个组合商品的组件
+func (r *BundleRepository) IsBundleComponent(productID uint64) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.BundleItem{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -17,29 +17,39 @@ func NewCartRepository(db *gorm.DB) *CartRepository {
 	}
 }
 
-// AddToCart 添加商品到购物车
-func (r *CartRepository) AddToCart(userID uint64, productID uint64, quantity int) error {
+// AddToCart 添加商品到购物车，同一商品且祝福语相同时合并数量，price 为首次加购时的商品价格
+func (r *CartRepository) AddToCart(userID uint64, productID uint64, quantity int, blessing string, price float64) error {
 	// 检查商品是否已存在
-	var existingItem model.Cart
-	result := r.db.Where("user_id = ? AND product_id = ?", userID, productID).First(&existingItem)
-
-	if result.Error == nil {
-		// 如果商品已存在，则更新数量
+	existingItem, err := r.GetCartItem(userID, productID, blessing)
+	if err == nil {
+		// 如果商品已存在，则更新数量，保留首次加购时的价格，以便提示之后的价格变化
 		existingItem.Quantity += quantity
-		return r.db.Save(&existingItem).Error
+		return r.db.Save(existingItem).Error
 	}
 
 	// 如果商品不存在，则添加新商品
 	cartItem := model.Cart{
-		UserID:    userID,
-		ProductID: productID,
-		Quantity:  quantity,
-		Selected:  true,
+		UserID:     userID,
+		ProductID:  productID,
+		Quantity:   quantity,
+		Selected:   true,
+		Blessing:   blessing,
+		AddedPrice: price,
 	}
 
 	return r.db.Create(&cartItem).Error
 }
 
+// GetCartItem 获取用户同一商品且祝福语相同的购物车项
+func (r *CartRepository) GetCartItem(userID uint64, productID uint64, blessing string) (*model.Cart, error) {
+	var cart model.Cart
+	result := r.db.Where("user_id = ? AND product_id = ? AND blessing = ?", userID, productID, blessing).First(&cart)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &cart, nil
+}
+
 // GetCart 获取用户所有购物车商品
 func (r *CartRepository) GetCart(userID uint64) ([]model.Cart, error) {
 	var cart []model.Cart
@@ -60,11 +70,33 @@ func (r *CartRepository) UpdateAllCartStatus(userID uint64, selected bool) error
 	return r.db.Model(&model.Cart{}).Where("user_id = ?", userID).Update("selected", selected).Error
 }
 
+// UpdateCartQuantity 更新购物车商品数量
+func (r *CartRepository) UpdateCartQuantity(id uint64, quantity int) error {
+	return r.db.Model(&model.Cart{}).Where("id = ?", id).Update("quantity", quantity).Error
+}
+
 // DeleteCart 删除购物车商品
 func (r *CartRepository) DeleteCart(id uint64) error {
 	return r.db.Delete(&model.Cart{}, "id = ?", id).Error
 }
 
+// DeductCart 扣减用户购物车商品的数量，不足扣减时删除，购物车商品已删除时忽略
+func (r *CartRepository) DeductCart(userID uint64, id uint64, quantity int) error {
+	if err := r.db.Where("id = ? AND user_id = ? AND quantity <= ?", id, userID, quantity).Delete(&model.Cart{}).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&model.Cart{}).Where("id = ? AND user_id = ?", id, userID).
+		Update("quantity", gorm.Expr("quantity - ?", quantity)).Error
+}
+
+// DeleteCarts 批量删除用户的购物车商品
+func (r *CartRepository) DeleteCarts(userID uint64, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("user_id = ? AND id IN ?", userID, ids).Delete(&model.Cart{}).Error
+}
+
 // GetCartByID 获取购物车商品
 func (r *CartRepository) GetCartByID(id uint64) (*model.Cart, error) {
 	var cart model.Cart
@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/colinjuang/shop-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CategoryRepository 分类仓库
@@ -17,10 +18,10 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 	}
 }
 
-// GetCategories 获取所有分类
+// GetCategories 获取所有分类，按层级和排序排列
 func (r *CategoryRepository) GetCategories() ([]model.Category, error) {
 	var categories []model.Category
-	result := r.db.Find(&categories)
+	result := r.db.Order("level ASC, sort_order ASC, id ASC").Find(&categories)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -30,9 +31,157 @@ func (r *CategoryRepository) GetCategories() ([]model.Category, error) {
 // GetCategoriesByParentID 获取父ID分类
 func (r *CategoryRepository) GetCategoriesByParentID(parentID uint64) ([]model.Category, error) {
 	var categories []model.Category
-	result := r.db.Where("parent_id = ?", parentID).Find(&categories)
+	result := r.db.Where("parent_id = ?", parentID).Order("sort_order ASC, id ASC").Find(&categories)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return categories, nil
 }
+
+// GetCategoryByID 获取分类
+func (r *CategoryRepository) GetCategoryByID(id uint64) (*model.Category, error) {
+	var category model.Category
+	result := r.db.First(&category, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &category, nil
+}
+
+// LockCategories 按ID顺序加行锁获取分类，用于移动和合并时防止并发修改同一子树
+func (r *CategoryRepository) LockCategories(ids []uint64) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id ASC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// CreateCategory 创建分类
+func (r *CategoryRepository) CreateCategory(category *model.Category) error {
+	return r.db.Create(category).Error
+}
+
+// UpdateCategory 更新分类
+func (r *CategoryRepository) UpdateCategory(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&model.Category{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteCategory 删除分类
+func (r *CategoryRepository) DeleteCategory(id uint64) error {
+	return r.db.Delete(&model.Category{}, "id = ?", id).Error
+}
+
+// GetMaxSortOrder 获取父分类下子分类的最大排序值，没有子分类时返回-1
+func (r *CategoryRepository) GetMaxSortOrder(parentID uint64) (int, error) {
+	var maxSort *int
+	err := r.db.Model(&model.Category{}).Where("parent_id = ?", parentID).
+		Select("MAX(sort_order)").Scan(&maxSort).Error
+	if err != nil || maxSort == nil {
+		return -1, err
+	}
+	return *maxSort, nil
+}
+
+// CountChildren 统计分类的直接子分类数量
+func (r *CategoryRepository) CountChildren(id uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// CountProducts 统计直接属于分类的商品数量
+func (r *CategoryRepository) CountProducts(id uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Product{}).Where("category_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// MoveChildren 将分类的直接子分类移到另一个父分类下，排序值加上sortOffset排在原有子分类之后
+func (r *CategoryRepository) MoveChildren(fromID, toID uint64, sortOffset int) error {
+	return r.db.Model(&model.Category{}).Where("parent_id = ?", fromID).Updates(map[string]interface{}{
+		"parent_id":  toID,
+		"sort_order": gorm.Expr("sort_order + ?", sortOffset),
+	}).Error
+}
+
+// ReplacePathPrefix 将路径以oldPrefix开头的分类改为以newPrefix开头，层级随之增减，用于移动子树
+func (r *CategoryRepository) ReplacePathPrefix(oldPrefix, newPrefix string, levelDelta int) error {
+	return r.db.Model(&model.Category{}).
+		Where("path LIKE ?", oldPrefix+"%").
+		Updates(map[string]interface{}{
+			"path":  gorm.Expr("CONCAT(?, SUBSTRING(path, ?))", newPrefix, len(oldPrefix)+1),
+			"level": gorm.Expr("level + ?", levelDelta),
+		}).Error
+}
+
+// ReplaceProductPathPrefix 将分类路径以oldPrefix开头的商品改为以newPrefix开头
+func (r *CategoryRepository) ReplaceProductPathPrefix(oldPrefix, newPrefix string) error {
+	return r.db.Model(&model.Product{}).
+		Where("category_path LIKE ?", oldPrefix+"%").
+		Update("category_path", gorm.Expr("CONCAT(?, SUBSTRING(category_path, ?))", newPrefix, len(oldPrefix)+1)).Error
+}
+
+// SyncProductPaths 将商品的分类路径修正为所属分类当前的路径
+func (r *CategoryRepository) SyncProductPaths() (int64, error) {
+	result := r.db.Exec("UPDATE products JOIN categories ON categories.id = products.category_id " +
+		"SET products.category_path = categories.path WHERE products.category_path IS NULL OR products.category_path <> categories.path")
+	return result.RowsAffected, result.Error
+}
+
+// GetProductIDsUnderPath 获取分类路径以 prefix 开头的商品ID，即分类及其子孙分类下的商品
+func (r *CategoryRepository) GetProductIDsUnderPath(prefix string) ([]uint64, error) {
+	var productIDs []uint64
+	err := r.db.Model(&model.Product{}).Where("category_path LIKE ?", prefix+"%").Pluck("id", &productIDs).Error
+	return productIDs, err
+}
+
+// MoveProducts 将直接属于分类的商品移到另一个分类
+func (r *CategoryRepository) MoveProducts(fromID, toID uint64) error {
+	return r.db.Model(&model.Product{}).Where("category_id = ?", fromID).Update("category_id", toID).Error
+}
+
+// SetProductCategory 修改商品所属分类及分类路径
+func (r *CategoryRepository) SetProductCategory(productID, categoryID uint64, path string) error {
+	return r.db.Model(&model.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"category_id":   categoryID,
+		"category_path": path,
+	}).Error
+}
+
+// ReassignCategoryReferences 将订阅、促销、商品属性中对分类的引用改为目标分类。积分和佣金规则在目标分类没有规则时转给目标分类，
+// 否则删除，目标分类的规则优先
+func (r *CategoryRepository) ReassignCategoryReferences(fromID, toID uint64) error {
+	if err := r.db.Model(&model.Subscription{}).Where("category_id = ?", fromID).Update("category_id", toID).Error; err != nil {
+		return err
+	}
+	if err := r.db.Model(&model.PromotionScope{}).Where("category_id = ?", fromID).Update("category_id", toID).Error; err != nil {
+		return err
+	}
+	if err := r.db.Model(&model.Attribute{}).Where("category_id = ?", fromID).Update("category_id", toID).Error; err != nil {
+		return err
+	}
+
+	var count int64
+	if err := r.db.Model(&model.PointsRule{}).Where("category_id = ?", toID).Count(&count).Error; err != nil {
+		return err
+	}
+	pointsRules := r.db.Model(&model.PointsRule{}).Where("category_id = ?", fromID)
+	if count > 0 {
+		if err := pointsRules.Delete(&model.PointsRule{}).Error; err != nil {
+			return err
+		}
+	} else if err := pointsRules.Update("category_id", toID).Error; err != nil {
+		return err
+	}
+
+	if err := r.db.Model(&model.CommissionRule{}).Where("product_id = 0 AND category_id = ?", toID).Count(&count).Error; err != nil {
+		return err
+	}
+	commissionRules := r.db.Model(&model.CommissionRule{}).Where("product_id = 0 AND category_id = ?", fromID)
+	if count > 0 {
+		return commissionRules.Delete(&model.CommissionRule{}).Error
+	}
+	return commissionRules.Update("category_id", toID).Error
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CommissionSummary 推荐人按状态汇总的佣金
+type CommissionSummary struct {
+	Status int
+	Orders int64
+	Amount float64
+}
+
+// DistributionRepository 分销仓库
+type DistributionRepository struct {
+	db *gorm.DB
+}
+
+// NewDistributionRepository
+func NewDistributionRepository(db *gorm.DB) *DistributionRepository {
+	return &DistributionRepository{
+		db: db,
+	}
+}
+
+// CreateReferral 绑定推荐关系
+func (r *DistributionRepository) CreateReferral(referral *model.Referral) error {
+	return r.db.Create(referral).Error
+}
+
+// GetReferralByUserID 获取用户的推荐关系，不存在时返回nil
+func (r *DistributionRepository) GetReferralByUserID(userID uint64) (*model.Referral, error) {
+	var referral model.Referral
+	err := r.db.Where("user_id = ?", userID).First(&referral).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &referral, nil
+}
+
+// CountReferrals 统计推荐人直接邀请的用户数
+func (r *DistributionRepository) CountReferrals(referrerID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Referral{}).Where("referrer_id = ?", referrerID).Count(&count).Error
+	return count, err
+}
+
+// CreateClick 记录分享点击
+func (r *DistributionRepository) CreateClick(click *model.ReferralClick) error {
+	return r.db.Create(click).Error
+}
+
+// CountClicks 统计推荐人的分享点击数
+func (r *DistributionRepository) CountClicks(referrerID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ReferralClick{}).Where("referrer_id = ?", referrerID).Count(&count).Error
+	return count, err
+}
+
+// GetRules 获取所有佣金规则
+func (r *DistributionRepository) GetRules() ([]model.CommissionRule, error) {
+	var rules []model.CommissionRule
+	if err := r.db.Order("product_id ASC, category_id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetMatchingRules 获取商品规则及分类规则
+func (r *DistributionRepository) GetMatchingRules(productIDs, categoryIDs []uint64) ([]model.CommissionRule, error) {
+	var rules []model.CommissionRule
+	err := r.db.Where("product_id IN ? OR (product_id = 0 AND category_id IN ?)", productIDs, categoryIDs).
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SaveRule 保存佣金规则，同一商品或分类已存在则更新
+func (r *DistributionRepository) SaveRule(rule *model.CommissionRule) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "category_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"level1_rate", "level2_rate", "updated_at"}),
+	}).Create(rule).Error
+}
+
+// DeleteRule 删除佣金规则
+func (r *DistributionRepository) DeleteRule(id uint64) error {
+	return r.db.Delete(&model.CommissionRule{}, "id = ?", id).Error
+}
+
+// CreateCommissions 创建佣金记录
+func (r *DistributionRepository) CreateCommissions(commissions []model.Commission) error {
+	return r.db.Create(&commissions).Error
+}
+
+// GetOrderCommissions 获取订单指定状态的佣金记录并加行锁，需在事务中调用
+func (r *DistributionRepository) GetOrderCommissions(orderID uint64, statuses ...int) ([]model.Commission, error) {
+	var commissions []model.Commission
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status IN ?", orderID, statuses).
+		Find(&commissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return commissions, nil
+}
+
+// UpdateCommission 更新佣金记录
+func (r *DistributionRepository) UpdateCommission(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&model.Commission{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// GetCommissionsByReferrer 分页获取推荐人的佣金记录
+func (r *DistributionRepository) GetCommissionsByReferrer(referrerID uint64, page, pageSize int) ([]model.Commission, int64, error) {
+	var commissions []model.Commission
+	var count int64
+
+	query := r.db.Model(&model.Commission{}).Where("referrer_id = ?", referrerID)
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&commissions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return commissions, count, nil
+}
+
+// SummarizeCommissions 按状态汇总推荐人的佣金订单数和金额
+func (r *DistributionRepository) SummarizeCommissions(referrerID uint64) ([]CommissionSummary, error) {
+	var summaries []CommissionSummary
+	err := r.db.Model(&model.Commission{}).
+		Select("status, COUNT(DISTINCT order_id) AS orders, COALESCE(SUM(amount), 0) AS amount").
+		Where("referrer_id = ?", referrerID).
+		Group("status").
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// CreateWalletLedger 创建钱包流水
+func (r *DistributionRepository) CreateWalletLedger(ledger *model.WalletLedger) error {
+	return r.db.Create(ledger).Error
+}
+
+// GetWalletLedgers 分页获取用户钱包流水
+func (r *DistributionRepository) GetWalletLedgers(userID uint64, page, pageSize int) ([]model.WalletLedger, int64, error) {
+	var ledgers []model.WalletLedger
+	var count int64
+
+	query := r.db.Model(&model.WalletLedger{}).Where("user_id = ?", userID)
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&ledgers).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ledgers, count, nil
+}
+
+// CreateWithdrawal 创建提现申请
+func (r *DistributionRepository) CreateWithdrawal(withdrawal *model.Withdrawal) error {
+	return r.db.Create(withdrawal).Error
+}
+
+// LockWithdrawal 获取提现申请并加行锁，需在事务中调用
+func (r *DistributionRepository) LockWithdrawal(id uint64) (*model.Withdrawal, error) {
+	var withdrawal model.Withdrawal
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&withdrawal, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &withdrawal, nil
+}
+
+// UpdateWithdrawal 更新提现申请
+func (r *DistributionRepository) UpdateWithdrawal(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&model.Withdrawal{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// GetWithdrawals 分页获取提现申请，userID 为0时不按用户过滤
+func (r *DistributionRepository) GetWithdrawals(userID uint64, status *int, page, pageSize int) ([]model.Withdrawal, int64, error) {
+	var withdrawals []model.Withdrawal
+	var count int64
+
+	query := r.db.Model(&model.Withdrawal{})
+
+	// 应用过滤
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&withdrawals).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return withdrawals, count, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FavoriteRepository 收藏仓库
+type FavoriteRepository struct {
+	db *gorm.DB
+}
+
+// NewFavoriteRepository
+func NewFavoriteRepository(db *gorm.DB) *FavoriteRepository {
+	return &FavoriteRepository{
+		db: db,
+	}
+}
+
+// AddFavorite 添加收藏，已收藏时保留原收藏价格
+func (r *FavoriteRepository) AddFavorite(favorite *model.Favorite) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Product").Create(favorite).Error
+}
+
+// DeleteFavorite 取消收藏
+func (r *FavoriteRepository) DeleteFavorite(userID, productID uint64) error {
+	return r.db.Delete(&model.Favorite{}, "user_id = ? AND product_id = ?", userID, productID).Error
+}
+
+// GetUserFavorites 分页获取用户收藏
+func (r *FavoriteRepository) GetUserFavorites(userID uint64, page, pageSize int) ([]model.Favorite, int64, error) {
+	var favorites []model.Favorite
+	var count int64
+
+	query := r.db.Model(&model.Favorite{}).Where("user_id = ?", userID)
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Preload("Product").Order("id DESC").Find(&favorites).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return favorites, count, nil
+}
+
+// IsFavorite 判断用户是否收藏了商品
+func (r *FavoriteRepository) IsFavorite(userID, productID uint64) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Favorite{}).Where("user_id = ? AND product_id = ?", userID, productID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CountByProductID 统计商品的收藏人数
+func (r *FavoriteRepository) CountByProductID(productID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Favorite{}).Where("product_id = ?", productID).Count(&count).Error
+	return count, err
+}
+
+// GetFavoritesAfterID 按ID顺序分批获取收藏及商品，用于后台巡检
+func (r *FavoriteRepository) GetFavoritesAfterID(lastID uint64, limit int) ([]model.Favorite, error) {
+	var favorites []model.Favorite
+	err := r.db.Where("id > ?", lastID).Preload("Product").Order("id ASC").Limit(limit).Find(&favorites).Error
+	if err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+// UpdateFavorite 更新收藏字段
+func (r *FavoriteRepository) UpdateFavorite(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&model.Favorite{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FlashSaleRepository 秒杀仓库
+type FlashSaleRepository struct {
+	db *gorm.DB
+}
+
+// NewFlashSaleRepository
+func NewFlashSaleRepository(db *gorm.DB) *FlashSaleRepository {
+	return &FlashSaleRepository{
+		db: db,
+	}
+}
+
+// GetFlashSaleByID 获取秒杀活动
+func (r *FlashSaleRepository) GetFlashSaleByID(id uint64) (*model.FlashSale, error) {
+	var sale model.FlashSale
+	if err := r.db.First(&sale, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &sale, nil
+}
+
+// GetActiveFlashSales 获取进行中及即将开始的秒杀活动
+func (r *FlashSaleRepository) GetActiveFlashSales(now time.Time) ([]model.FlashSale, error) {
+	var sales []model.FlashSale
+	err := r.db.Where("status = ? AND end_time > ?", model.FlashSaleStatusEnabled, now).
+		Order("start_time ASC").
+		Find(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
+
+// GetFlashSales 分页获取秒杀活动
+func (r *FlashSaleRepository) GetFlashSales(page, pageSize int) ([]model.FlashSale, int64, error) {
+	var sales []model.FlashSale
+	var count int64
+
+	query := r.db.Model(&model.FlashSale{})
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Order("start_time DESC").Offset(offset).Limit(pageSize).Find(&sales).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return sales, count, nil
+}
+
+// CreateFlashSale 创建秒杀活动
+func (r *FlashSaleRepository) CreateFlashSale(sale *model.FlashSale) error {
+	return r.db.Create(sale).Error
+}
+
+// UpdateFlashSale 更新秒杀活动配置，已售数量只通过 IncreaseSold/DecreaseSold 修改
+func (r *FlashSaleRepository) UpdateFlashSale(sale *model.FlashSale) error {
+	return r.db.Model(sale).
+		Select("product_id", "title", "deal_price", "quantity", "limit_per_user", "start_time", "end_time", "status").
+		Updates(sale).Error
+}
+
+// UpdateFlashSaleStatus 更新秒杀活动状态
+func (r *FlashSaleRepository) UpdateFlashSaleStatus(id uint64, status int) error {
+	return r.db.Model(&model.FlashSale{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// IncreaseSold 增加已售数量，超过活动数量时不更新并返回false
+func (r *FlashSaleRepository) IncreaseSold(id uint64, quantity int) (bool, error) {
+	result := r.db.Model(&model.FlashSale{}).
+		Where("id = ? AND sold + ? <= quantity", id, quantity).
+		Update("sold", gorm.Expr("sold + ?", quantity))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DecreaseSold 减少已售数量
+func (r *FlashSaleRepository) DecreaseSold(id uint64, quantity int) error {
+	return r.db.Model(&model.FlashSale{}).
+		Where("id = ? AND sold >= ?", id, quantity).
+		Update("sold", gorm.Expr("sold - ?", quantity)).Error
+}
+
+// GetUserPurchased 统计用户在秒杀活动中已购买的数量
+func (r *FlashSaleRepository) GetUserPurchased(saleID, userID uint64) (int, error) {
+	var total int
+	err := r.db.Model(&model.FlashSaleOrder{}).
+		Where("flash_sale_id = ? AND user_id = ? AND status = ?", saleID, userID, model.FlashSaleOrderStatusCreated).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// UserPurchased 用户已购买数量
+type UserPurchased struct {
+	UserID   uint64
+	Quantity int
+}
+
+// GetPurchasedByUsers 统计秒杀活动中每个用户已购买的数量
+func (r *FlashSaleRepository) GetPurchasedByUsers(saleID uint64) ([]UserPurchased, error) {
+	var purchased []UserPurchased
+	err := r.db.Model(&model.FlashSaleOrder{}).
+		Select("user_id, SUM(quantity) AS quantity").
+		Where("flash_sale_id = ? AND status = ?", saleID, model.FlashSaleOrderStatusCreated).
+		Group("user_id").
+		Scan(&purchased).Error
+	return purchased, err
+}
+
+// CreateFlashSaleOrder 创建秒杀订单记录
+func (r *FlashSaleRepository) CreateFlashSaleOrder(order *model.FlashSaleOrder) error {
+	return r.db.Create(order).Error
+}
+
+// GetFlashSaleOrderByRequestID 根据抢购请求ID获取秒杀订单记录，不存在时返回nil
+func (r *FlashSaleRepository) GetFlashSaleOrderByRequestID(requestID string) (*model.FlashSaleOrder, error) {
+	var order model.FlashSaleOrder
+	err := r.db.Where("request_id = ?", requestID).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CancelFlashSaleOrder 将订单对应的秒杀记录标记为已取消，不存在或已取消时返回nil
+func (r *FlashSaleRepository) CancelFlashSaleOrder(orderID uint64) (*model.FlashSaleOrder, error) {
+	var order model.FlashSaleOrder
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", orderID, model.FlashSaleOrderStatusCreated).
+		First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	order.Status = model.FlashSaleOrderStatusCancelled
+	if err := r.db.Model(&order).Update("status", order.Status).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetUnpaidFlashSaleOrders 获取指定时间前创建且仍未支付的秒杀订单
+func (r *FlashSaleRepository) GetUnpaidFlashSaleOrders(before time.Time, limit int) ([]model.FlashSaleOrder, error) {
+	var orders []model.FlashSaleOrder
+	err := r.db.Model(&model.FlashSaleOrder{}).
+		Joins("JOIN orders ON orders.id = flash_sale_orders.order_id").
+		Where("flash_sale_orders.status = ? AND orders.status = ? AND flash_sale_orders.created_at < ?",
+			model.FlashSaleOrderStatusCreated, model.OrderStatusPending, before).
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
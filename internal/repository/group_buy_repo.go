@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GroupBuyRepository 拼团仓库
+type GroupBuyRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupBuyRepository
+func NewGroupBuyRepository(db *gorm.DB) *GroupBuyRepository {
+	return &GroupBuyRepository{
+		db: db,
+	}
+}
+
+// GetCampaignByID 获取拼团活动
+func (r *GroupBuyRepository) GetCampaignByID(id uint64) (*model.GroupBuyCampaign, error) {
+	var campaign model.GroupBuyCampaign
+	if err := r.db.First(&campaign, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// GetActiveCampaigns 获取进行中的拼团活动
+func (r *GroupBuyRepository) GetActiveCampaigns(now time.Time) ([]model.GroupBuyCampaign, error) {
+	var campaigns []model.GroupBuyCampaign
+	err := r.db.Where("status = ? AND start_time <= ? AND end_time > ?", model.GroupBuyCampaignStatusEnabled, now, now).
+		Order("start_time DESC").
+		Find(&campaigns).Error
+	if err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+// GetCampaigns 分页获取拼团活动
+func (r *GroupBuyRepository) GetCampaigns(page, pageSize int) ([]model.GroupBuyCampaign, int64, error) {
+	var campaigns []model.GroupBuyCampaign
+	var count int64
+
+	query := r.db.Model(&model.GroupBuyCampaign{})
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Order("start_time DESC").Offset(offset).Limit(pageSize).Find(&campaigns).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return campaigns, count, nil
+}
+
+// CreateCampaign 创建拼团活动
+func (r *GroupBuyRepository) CreateCampaign(campaign *model.GroupBuyCampaign) error {
+	return r.db.Create(campaign).Error
+}
+
+// UpdateCampaign 更新拼团活动配置
+func (r *GroupBuyRepository) UpdateCampaign(campaign *model.GroupBuyCampaign) error {
+	return r.db.Model(campaign).
+		Select("product_id", "title", "group_price", "group_size", "time_limit", "start_time", "end_time").
+		Updates(campaign).Error
+}
+
+// UpdateCampaignStatus 更新拼团活动状态
+func (r *GroupBuyRepository) UpdateCampaignStatus(id uint64, status int) error {
+	return r.db.Model(&model.GroupBuyCampaign{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// CreateGroup 开团
+func (r *GroupBuyRepository) CreateGroup(group *model.GroupBuyGroup) error {
+	return r.db.Create(group).Error
+}
+
+// GetGroupByID 获取团
+func (r *GroupBuyRepository) GetGroupByID(id uint64) (*model.GroupBuyGroup, error) {
+	var group model.GroupBuyGroup
+	if err := r.db.First(&group, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// LockGroup 获取团并加行锁，需在事务中调用
+func (r *GroupBuyRepository) LockGroup(id uint64) (*model.GroupBuyGroup, error) {
+	var group model.GroupBuyGroup
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&group, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// GetOpenGroups 获取活动下未过期的拼团中的团，按即将到期排序
+func (r *GroupBuyRepository) GetOpenGroups(campaignID uint64, now time.Time, limit int) ([]model.GroupBuyGroup, error) {
+	var groups []model.GroupBuyGroup
+	err := r.db.Where("campaign_id = ? AND status = ? AND expire_at > ?", campaignID, model.GroupBuyGroupStatusOpen, now).
+		Order("expire_at ASC").
+		Limit(limit).
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetExpiredOpenGroups 获取已过期但仍在拼团中的团
+func (r *GroupBuyRepository) GetExpiredOpenGroups(now time.Time, limit int) ([]model.GroupBuyGroup, error) {
+	var groups []model.GroupBuyGroup
+	err := r.db.Where("status = ? AND expire_at <= ?", model.GroupBuyGroupStatusOpen, now).
+		Limit(limit).
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// UpdateGroupPaidCount 更新团的已支付人数
+func (r *GroupBuyRepository) UpdateGroupPaidCount(id uint64, delta int) error {
+	return r.db.Model(&model.GroupBuyGroup{}).
+		Where("id = ? AND paid_count + ? >= 0", id, delta).
+		Update("paid_count", gorm.Expr("paid_count + ?", delta)).Error
+}
+
+// FinishGroup 将拼团中的团标记为成功或失败，团已结束时返回false
+func (r *GroupBuyRepository) FinishGroup(id uint64, status int) (bool, error) {
+	result := r.db.Model(&model.GroupBuyGroup{}).
+		Where("id = ? AND status = ?", id, model.GroupBuyGroupStatusOpen).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"completed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateParticipant 创建参团记录
+func (r *GroupBuyRepository) CreateParticipant(participant *model.GroupBuyParticipant) error {
+	return r.db.Create(participant).Error
+}
+
+// GetActiveParticipants 获取团内已参团及已支付的成员
+func (r *GroupBuyRepository) GetActiveParticipants(groupID uint64) ([]model.GroupBuyParticipant, error) {
+	var participants []model.GroupBuyParticipant
+	err := r.db.Where("group_id = ? AND status IN ?", groupID,
+		[]int{model.GroupBuyParticipantStatusJoined, model.GroupBuyParticipantStatusPaid}).
+		Order("id ASC").
+		Find(&participants).Error
+	if err != nil {
+		return nil, err
+	}
+	return participants, nil
+}
+
+// GetParticipantsByStatus 获取团内指定状态的成员
+func (r *GroupBuyRepository) GetParticipantsByStatus(groupID uint64, status int) ([]model.GroupBuyParticipant, error) {
+	var participants []model.GroupBuyParticipant
+	err := r.db.Where("group_id = ? AND status = ?", groupID, status).Find(&participants).Error
+	if err != nil {
+		return nil, err
+	}
+	return participants, nil
+}
+
+// GetParticipantByOrderID 根据订单获取参团记录并加行锁，不存在时返回nil
+func (r *GroupBuyRepository) GetParticipantByOrderID(orderID uint64) (*model.GroupBuyParticipant, error) {
+	var participant model.GroupBuyParticipant
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ?", orderID).
+		First(&participant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// UpdateParticipantStatus 更新参团记录状态
+func (r *GroupBuyRepository) UpdateParticipantStatus(id uint64, status int) error {
+	return r.db.Model(&model.GroupBuyParticipant{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// GetParticipantsToRefund 获取失败团中已支付待退款的成员
+func (r *GroupBuyRepository) GetParticipantsToRefund(limit int) ([]model.GroupBuyParticipant, error) {
+	var participants []model.GroupBuyParticipant
+	err := r.db.Model(&model.GroupBuyParticipant{}).
+		Joins("JOIN group_buy_groups ON group_buy_groups.id = group_buy_participants.group_id").
+		Where("group_buy_groups.status = ? AND group_buy_participants.status = ?",
+			model.GroupBuyGroupStatusFailed, model.GroupBuyParticipantStatusPaid).
+		Limit(limit).
+		Find(&participants).Error
+	if err != nil {
+		return nil, err
+	}
+	return participants, nil
+}
+
+// GetUnpaidParticipants 获取支付超时或所在团已失败的未支付成员
+func (r *GroupBuyRepository) GetUnpaidParticipants(before time.Time, limit int) ([]model.GroupBuyParticipant, error) {
+	var participants []model.GroupBuyParticipant
+	err := r.db.Model(&model.GroupBuyParticipant{}).
+		Joins("JOIN group_buy_groups ON group_buy_groups.id = group_buy_participants.group_id").
+		Where("group_buy_participants.status = ? AND (group_buy_participants.created_at < ? OR group_buy_groups.status = ?)",
+			model.GroupBuyParticipantStatusJoined, before, model.GroupBuyGroupStatusFailed).
+		Limit(limit).
+		Find(&participants).Error
+	if err != nil {
+		return nil, err
+	}
+	return participants, nil
+}
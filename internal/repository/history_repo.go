@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HistoryRepository 浏览记录仓库
+type HistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewHistoryRepository
+func NewHistoryRepository(db *gorm.DB) *HistoryRepository {
+	return &HistoryRepository{
+		db: db,
+	}
+}
+
+// UpsertHistories 批量保存浏览记录，已存在时保留较晚的浏览时间
+func (r *HistoryRepository) UpsertHistories(histories []model.BrowseHistory) error {
+	if len(histories) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "product_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"viewed_at":  gorm.Expr("GREATEST(viewed_at, VALUES(viewed_at))"),
+			"updated_at": gorm.Expr("VALUES(updated_at)"),
+		}),
+	}).Create(&histories).Error
+}
+
+// GetUserHistories 按浏览时间倒序分页获取用户浏览记录
+func (r *HistoryRepository) GetUserHistories(userID uint64, page, pageSize int) ([]model.BrowseHistory, int64, error) {
+	var histories []model.BrowseHistory
+	var count int64
+
+	query := r.db.Model(&model.BrowseHistory{}).Where("user_id = ?", userID)
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("viewed_at DESC").Find(&histories).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return histories, count, nil
+}
+
+// DeleteHistory 删除用户的一条浏览记录
+func (r *HistoryRepository) DeleteHistory(userID, productID uint64) error {
+	return r.db.Delete(&model.BrowseHistory{}, "user_id = ? AND product_id = ?", userID, productID).Error
+}
+
+// ClearHistories 清空用户浏览记录
+func (r *HistoryRepository) ClearHistories(userID uint64) error {
+	return r.db.Delete(&model.BrowseHistory{}, "user_id = ?", userID).Error
+}
+
+// DeleteBefore 删除早于指定时间的浏览记录
+func (r *HistoryRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("viewed_at < ?", before).Delete(&model.BrowseHistory{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HomeRepository 首页配置仓库
+type HomeRepository struct {
+	db *gorm.DB
+}
+
+// NewHomeRepository
+func NewHomeRepository(db *gorm.DB) *HomeRepository {
+	return &HomeRepository{
+		db: db,
+	}
+}
+
+// GetSections 获取首页区块配置
+func (r *HomeRepository) GetSections() ([]model.HomeSection, error) {
+	var sections []model.HomeSection
+	err := r.db.Order("sort_order ASC, id ASC").Find(&sections).Error
+	return sections, err
+}
+
+// SaveSections 按区块保存首页配置，已有配置的区块覆盖
+func (r *HomeRepository) SaveSections(sections []model.HomeSection) error {
+	if len(sections) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "section_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "visible", "limit_count", "sort_order", "updated_at"}),
+	}).Create(&sections).Error
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InventoryRepository 库存流水及库存预警仓库
+type InventoryRepository struct {
+	db *gorm.DB
+}
+
+// NewInventoryRepository
+func NewInventoryRepository(db *gorm.DB) *InventoryRepository {
+	return &InventoryRepository{
+		db: db,
+	}
+}
+
+// ProductQuantity 按商品汇总的数量
+type ProductQuantity struct {
+	ProductID uint64
+	Quantity  int
+}
+
+// LowStockProduct 可售库存不高于预警阈值的商品
+type LowStockProduct struct {
+	AlertID    uint64
+	ProductID  uint64
+	Name       string
+	StockCount int
+	Threshold  int
+}
+
+// ExpectedWastage 商品某天到期的批次剩余数量
+type ExpectedWastage struct {
+	ProductID uint64
+	Name      string
+	ExpiresAt time.Time
+	Quantity  int
+}
+
+// ReservedStatuses 占用库存的订单状态：已下单扣减库存但尚未发货
+var ReservedStatuses = []int{model.OrderStatusPending, model.OrderStatusDepositPaid, model.OrderStatusPaid, model.OrderStatusGrouping}
+
+// CreateMovement 记录库存流水
+func (r *InventoryRepository) CreateMovement(movement *model.InventoryMovement) error {
+	return r.db.Create(movement).Error
+}
+
+// GetMovements 分页获取商品库存流水，最近的在前，movementType 为nil时获取全部类型
+func (r *InventoryRepository) GetMovements(productID uint64, movementType *int, page, pageSize int) ([]model.InventoryMovement, int64, error) {
+	var movements []model.InventoryMovement
+	var count int64
+
+	query := r.db.Model(&model.InventoryMovement{}).Where("product_id = ?", productID)
+	if movementType != nil {
+		query = query.Where("type = ?", *movementType)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&movements).Error; err != nil {
+		return nil, 0, err
+	}
+	return movements, count, nil
+}
+
+// GetReservedQuantities 统计商品被未发货订单占用的数量，扣除已退款的数量，组合商品按组件行统计
+func (r *InventoryRepository) GetReservedQuantities(productIDs []uint64) ([]ProductQuantity, error) {
+	var reserved []ProductQuantity
+	if len(productIDs) == 0 {
+		return reserved, nil
+	}
+	err := r.db.Model(&model.OrderItem{}).
+		Select("order_items.product_id, SUM(order_items.quantity - order_items.refunded_qty) AS quantity").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.status IN ? AND order_items.is_bundle = ? AND order_items.product_id IN ?", ReservedStatuses, false, productIDs).
+		Group("order_items.product_id").
+		Scan(&reserved).Error
+	return reserved, err
+}
+
+// GetStockAlert 获取商品库存预警设置，未设置时返回nil
+func (r *InventoryRepository) GetStockAlert(productID uint64) (*model.StockAlert, error) {
+	var alerts []model.StockAlert
+	if err := r.db.Where("product_id = ?", productID).Limit(1).Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+	return &alerts[0], nil
+}
+
+// UpsertStockAlert 设置商品库存预警阈值，并重新开始提醒
+func (r *InventoryRepository) UpsertStockAlert(productID uint64, threshold int) error {
+	alert := model.StockAlert{
+		ProductID: productID,
+		Threshold: threshold,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"threshold": threshold, "alerted_at": nil, "updated_at": time.Now()}),
+	}).Create(&alert).Error
+}
+
+// DeleteStockAlert 删除商品库存预警
+func (r *InventoryRepository) DeleteStockAlert(productID uint64) error {
+	return r.db.Delete(&model.StockAlert{}, "product_id = ?", productID).Error
+}
+
+// GetLowStockProducts 获取可售库存不高于阈值的商品，unalerted 为true时只获取尚未提醒的
+func (r *InventoryRepository) GetLowStockProducts(unalerted bool, limit int) ([]LowStockProduct, error) {
+	var products []LowStockProduct
+	query := r.db.Model(&model.StockAlert{}).
+		Select("stock_alerts.id AS alert_id, products.id AS product_id, products.name, products.stock_count, stock_alerts.threshold").
+		Joins("JOIN products ON products.id = stock_alerts.product_id").
+		Where("products.stock_count <= stock_alerts.threshold AND products.is_bundle = ?", false)
+	if unalerted {
+		query = query.Where("stock_alerts.alerted_at IS NULL")
+	}
+	err := query.Order("products.stock_count ASC, products.id ASC").Limit(limit).Scan(&products).Error
+	return products, err
+}
+
+// MarkStockAlerted 记录已提醒
+func (r *InventoryRepository) MarkStockAlerted(alertIDs []uint64, at time.Time) error {
+	if len(alertIDs) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.StockAlert{}).Where("id IN ?", alertIDs).Update("alerted_at", at).Error
+}
+
+// ResetRecoveredAlerts 清空库存已回到阈值以上的提醒记录，再次低于阈值时重新提醒
+func (r *InventoryRepository) ResetRecoveredAlerts() error {
+	return r.db.Model(&model.StockAlert{}).
+		Where("alerted_at IS NOT NULL AND threshold < (SELECT stock_count FROM products WHERE products.id = stock_alerts.product_id)").
+		Update("alerted_at", nil).Error
+}
+
+// CreateBatch 创建库存批次
+func (r *InventoryRepository) CreateBatch(batch *model.StockBatch) error {
+	return r.db.Create(batch).Error
+}
+
+// GetBatchByID 获取库存批次
+func (r *InventoryRepository) GetBatchByID(id uint64) (*model.StockBatch, error) {
+	var batch model.StockBatch
+	result := r.db.First(&batch, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &batch, nil
+}
+
+// GetLiveBatches 获取商品有剩余的批次，先到期的在前
+func (r *InventoryRepository) GetLiveBatches(productID uint64) ([]model.StockBatch, error) {
+	var batches []model.StockBatch
+	err := r.db.Where("product_id = ? AND remaining > 0", productID).Order("expires_at ASC, id ASC").Find(&batches).Error
+	return batches, err
+}
+
+// IncreaseBatchRemaining 增加批次剩余数量，扣减时为负数
+func (r *InventoryRepository) IncreaseBatchRemaining(id uint64, quantity int) error {
+	return r.db.Model(&model.StockBatch{}).Where("id = ?", id).
+		Update("remaining", gorm.Expr("remaining + ?", quantity)).Error
+}
+
+// ClearBatchRemaining 将批次剩余数量清零，仅当剩余数量未变化时更新，返回是否更新成功
+func (r *InventoryRepository) ClearBatchRemaining(id uint64, remaining int) (bool, error) {
+	result := r.db.Model(&model.StockBatch{}).Where("id = ? AND remaining = ?", id, remaining).Update("remaining", 0)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetExpiredBatches 获取到期日不晚于指定日期且仍有剩余的批次
+func (r *InventoryRepository) GetExpiredBatches(date time.Time, limit int) ([]model.StockBatch, error) {
+	var batches []model.StockBatch
+	err := r.db.Where("expires_at <= ? AND remaining > 0", date).Order("expires_at ASC, id ASC").Limit(limit).Find(&batches).Error
+	return batches, err
+}
+
+// GetExpiringQuantities 统计商品在指定日期及之前到期的批次剩余数量
+func (r *InventoryRepository) GetExpiringQuantities(productIDs []uint64, date time.Time) ([]ProductQuantity, error) {
+	var quantities []ProductQuantity
+	if len(productIDs) == 0 {
+		return quantities, nil
+	}
+	err := r.db.Model(&model.StockBatch{}).
+		Select("product_id, SUM(remaining) AS quantity").
+		Where("product_id IN ? AND expires_at <= ? AND remaining > 0", productIDs, date).
+		Group("product_id").
+		Scan(&quantities).Error
+	return quantities, err
+}
+
+// GetExpectedWastage 按商品和到期日统计指定日期前到期的批次剩余数量
+func (r *InventoryRepository) GetExpectedWastage(before time.Time) ([]ExpectedWastage, error) {
+	var wastage []ExpectedWastage
+	err := r.db.Model(&model.StockBatch{}).
+		Select("stock_batches.product_id, products.name, stock_batches.expires_at, SUM(stock_batches.remaining) AS quantity").
+		Joins("JOIN products ON products.id = stock_batches.product_id").
+		Where("stock_batches.expires_at < ? AND stock_batches.remaining > 0", before).
+		Group("stock_batches.product_id, products.name, stock_batches.expires_at").
+		Order("stock_batches.expires_at ASC, quantity DESC").
+		Scan(&wastage).Error
+	return wastage, err
+}
+
+// CreateAllocation 记录单据占用的批次数量
+func (r *InventoryRepository) CreateAllocation(allocation *model.StockBatchAllocation) error {
+	return r.db.Create(allocation).Error
+}
+
+// GetAllocations 获取单据仍占用的商品批次，最近占用的在前
+func (r *InventoryRepository) GetAllocations(refType string, refID, productID uint64) ([]model.StockBatchAllocation, error) {
+	var allocations []model.StockBatchAllocation
+	err := r.db.Where("ref_type = ? AND ref_id = ? AND product_id = ? AND quantity > 0", refType, refID, productID).
+		Order("id DESC").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// DecreaseAllocation 减少单据占用的批次数量
+func (r *InventoryRepository) DecreaseAllocation(id uint64, quantity int) error {
+	return r.db.Model(&model.StockBatchAllocation{}).Where("id = ?", id).
+		Update("quantity", gorm.Expr("quantity - ?", quantity)).Error
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MemberRepository 会员仓库
+type MemberRepository struct {
+	db *gorm.DB
+}
+
+// NewMemberRepository
+func NewMemberRepository(db *gorm.DB) *MemberRepository {
+	return &MemberRepository{
+		db: db,
+	}
+}
+
+// GetTiers 获取所有会员等级，按等级从低到高排序
+func (r *MemberRepository) GetTiers() ([]model.MemberTier, error) {
+	var tiers []model.MemberTier
+	if err := r.db.Order("level ASC").Find(&tiers).Error; err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+// GetTierByID 获取会员等级
+func (r *MemberRepository) GetTierByID(id uint64) (*model.MemberTier, error) {
+	var tier model.MemberTier
+	if err := r.db.First(&tier, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &tier, nil
+}
+
+// CreateTier 创建会员等级
+func (r *MemberRepository) CreateTier(tier *model.MemberTier) error {
+	return r.db.Create(tier).Error
+}
+
+// UpdateTier 更新会员等级
+func (r *MemberRepository) UpdateTier(tier *model.MemberTier) error {
+	return r.db.Save(tier).Error
+}
+
+// DeleteTier 删除会员等级及其会员价
+func (r *MemberRepository) DeleteTier(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.ProductMemberPrice{}, "tier_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.MemberTier{}, "id = ?", id).Error
+	})
+}
+
+// GetMemberPricesByProductIDs 批量获取商品会员价
+func (r *MemberRepository) GetMemberPricesByProductIDs(productIDs []uint64) ([]model.ProductMemberPrice, error) {
+	var prices []model.ProductMemberPrice
+	if len(productIDs) == 0 {
+		return prices, nil
+	}
+	if err := r.db.Where("product_id IN ?", productIDs).Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// SaveMemberPrice 保存商品会员价，已存在则更新
+func (r *MemberRepository) SaveMemberPrice(price *model.ProductMemberPrice) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "tier_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "updated_at"}),
+	}).Create(price).Error
+}
+
+// DeleteMemberPrice 删除商品会员价
+func (r *MemberRepository) DeleteMemberPrice(productID, tierID uint64) error {
+	return r.db.Delete(&model.ProductMemberPrice{}, "product_id = ? AND tier_id = ?", productID, tierID).Error
+}
+
+// GetUserSpend 统计用户在指定时间后已完成订单的消费金额（不含运费）
+func (r *MemberRepository) GetUserSpend(userID uint64, since time.Time) (float64, error) {
+	var spend float64
+	err := r.db.Model(&model.Order{}).
+		Where("user_id = ? AND status = ? AND completed_at >= ?", userID, model.OrderStatusCompleted, since).
+		Select("COALESCE(SUM(payment_amount - shipping_fee), 0)").
+		Scan(&spend).Error
+	return spend, err
+}
+
+// UserSpend 用户消费统计
+type UserSpend struct {
+	UserID uint64
+	Spend  float64
+}
+
+// GetSpendByUsers 分批统计用户在指定时间后已完成订单的消费金额，包含当前有会员等级的用户
+func (r *MemberRepository) GetSpendByUsers(since time.Time, afterUserID uint64, limit int) ([]UserSpend, error) {
+	var spends []UserSpend
+	err := r.db.Model(&model.User{}).
+		Select("users.id AS user_id, COALESCE(SUM(orders.payment_amount - orders.shipping_fee), 0) AS spend").
+		Joins("LEFT JOIN orders ON orders.user_id = users.id AND orders.status = ? AND orders.completed_at >= ?", model.OrderStatusCompleted, since).
+		Where("users.id > ?", afterUserID).
+		Where("users.tier_id > 0 OR orders.id IS NOT NULL").
+		Group("users.id").
+		Order("users.id ASC").
+		Limit(limit).
+		Scan(&spends).Error
+	return spends, err
+}
+
+// UpdateUserTier 更新用户会员等级
+func (r *MemberRepository) UpdateUserTier(userID, tierID uint64) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userID).Update("tier_id", tierID).Error
+}
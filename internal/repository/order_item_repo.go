@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/colinjuang/shop-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderItemRepository struct {
@@ -27,3 +28,64 @@ func (r *OrderItemRepository) GetOrderItemsByOrderID(orderID uint64) ([]model.Or
 	}
 	return orderItems, nil
 }
+
+// GetOrderItemsForUpdate 获取订单项并加行锁，需在事务中调用
+func (r *OrderItemRepository) GetOrderItemsForUpdate(orderID uint64) ([]model.OrderItem, error) {
+	var orderItems []model.OrderItem
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).Find(&orderItems)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return orderItems, nil
+}
+
+// IncreaseRefundedQty 增加订单项已退款数量，超过购买数量时不更新并返回false
+func (r *OrderItemRepository) IncreaseRefundedQty(id uint64, quantity int) (bool, error) {
+	result := r.db.Model(&model.OrderItem{}).
+		Where("id = ? AND refunded_qty + ? <= quantity", id, quantity).
+		Update("refunded_qty", gorm.Expr("refunded_qty + ?", quantity))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateOrderRefund 创建部分退款记录
+func (r *OrderItemRepository) CreateOrderRefund(refund *model.OrderRefund) error {
+	return r.db.Create(refund).Error
+}
+
+// CountOrderRefunds 统计订单的部分退款次数
+func (r *OrderItemRepository) CountOrderRefunds(orderID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.OrderRefund{}).Where("order_id = ?", orderID).Count(&count).Error
+	return count, err
+}
+
+// CreateOrderItemPromotions 保存订单项享受的促销活动
+func (r *OrderItemRepository) CreateOrderItemPromotions(promotions []model.OrderItemPromotion) error {
+	if len(promotions) == 0 {
+		return nil
+	}
+	return r.db.Create(&promotions).Error
+}
+
+// GetOrderItemPromotions 获取订单各项享受的促销活动
+func (r *OrderItemRepository) GetOrderItemPromotions(orderID uint64) ([]model.OrderItemPromotion, error) {
+	var promotions []model.OrderItemPromotion
+	err := r.db.Where("order_id = ?", orderID).Order("id ASC").Find(&promotions).Error
+	return promotions, err
+}
+
+// GetPendingCartIDs 获取已被用户待付款订单占用的购物车项
+func (r *OrderItemRepository) GetPendingCartIDs(userID uint64, cartIDs []uint64) ([]uint64, error) {
+	var ids []uint64
+	if len(cartIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&model.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.cart_id IN ?", userID, model.OrderStatusPending, cartIDs).
+		Distinct().Pluck("order_items.cart_id", &ids).Error
+	return ids, err
+}
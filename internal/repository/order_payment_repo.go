@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// OrderPaymentRepository 分阶段支付记录仓库
+type OrderPaymentRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderPaymentRepository
+func NewOrderPaymentRepository(db *gorm.DB) *OrderPaymentRepository {
+	return &OrderPaymentRepository{
+		db: db,
+	}
+}
+
+// CreateOrderPayments 批量创建支付记录
+func (r *OrderPaymentRepository) CreateOrderPayments(payments []model.OrderPayment) error {
+	return r.db.Create(&payments).Error
+}
+
+// GetOrderPayments 获取订单的支付记录，按阶段排序
+func (r *OrderPaymentRepository) GetOrderPayments(orderID uint64) ([]model.OrderPayment, error) {
+	var payments []model.OrderPayment
+	if err := r.db.Where("order_id = ?", orderID).Order("stage ASC").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// GetOrderPaymentByStage 获取订单指定阶段的支付记录
+func (r *OrderPaymentRepository) GetOrderPaymentByStage(orderID uint64, stage int) (*model.OrderPayment, error) {
+	var payment model.OrderPayment
+	if err := r.db.Where("order_id = ? AND stage = ?", orderID, stage).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// UpdatePaymentStatusFrom 仅当支付记录处于 from 状态时更新，支付成功时记录支付时间
+func (r *OrderPaymentRepository) UpdatePaymentStatusFrom(id uint64, from, status int) (bool, error) {
+	updates := map[string]interface{}{
+		"status": status,
+	}
+	if status == model.OrderPaymentStatusPaid {
+		updates["paid_at"] = time.Now()
+	}
+
+	result := r.db.Model(&model.OrderPayment{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkReminded 记录催付提醒时间
+func (r *OrderPaymentRepository) MarkReminded(id uint64) error {
+	return r.db.Model(&model.OrderPayment{}).Where("id = ?", id).Update("reminded_at", time.Now()).Error
+}
+
+// GetOverduePayments 获取已过支付截止时间仍未支付、且订单处于指定状态的支付记录
+func (r *OrderPaymentRepository) GetOverduePayments(stage, orderStatus int, now time.Time, limit int) ([]model.OrderPayment, error) {
+	var payments []model.OrderPayment
+	err := r.db.Model(&model.OrderPayment{}).
+		Joins("JOIN orders ON orders.id = order_payments.order_id").
+		Where("order_payments.stage = ? AND order_payments.status = ? AND order_payments.due_end <= ? AND orders.status = ?",
+			stage, model.OrderPaymentStatusPending, now, orderStatus).
+		Limit(limit).
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// GetPaymentsToRemind 获取支付窗口已开始、将在 until 前截止且尚未提醒的尾款记录
+func (r *OrderPaymentRepository) GetPaymentsToRemind(now, until time.Time, limit int) ([]model.OrderPayment, error) {
+	var payments []model.OrderPayment
+	err := r.db.Model(&model.OrderPayment{}).
+		Joins("JOIN orders ON orders.id = order_payments.order_id").
+		Where("order_payments.stage = ? AND order_payments.status = ? AND order_payments.reminded_at IS NULL", model.PaymentStageBalance, model.OrderPaymentStatusPending).
+		Where("order_payments.due_start <= ? AND order_payments.due_end > ? AND order_payments.due_end <= ?", now, now, until).
+		Where("orders.status = ?", model.OrderStatusDepositPaid).
+		Limit(limit).
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
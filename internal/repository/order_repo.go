@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"slices"
 	"time"
 
 	"github.com/colinjuang/shop-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // OrderRepository 订单仓库
@@ -37,6 +39,16 @@ func (r *OrderRepository) GetOrderByID(id uint64) (*model.Order, error) {
 	return &order, nil
 }
 
+// LockOrder 获取订单并加行锁，需在事务中调用
+func (r *OrderRepository) LockOrder(id uint64) (*model.Order, error) {
+	var order model.Order
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &order, nil
+}
+
 // GetOrderByID 获取订单
 func (r *OrderRepository) GetOrderAndOrderItemByID(id uint64) (*model.OrderWithOrderItem, error) {
 	var order model.OrderWithOrderItem
@@ -105,3 +117,53 @@ func (r *OrderRepository) GetOrdersByUserID(userID uint64, page, pageSize int, s
 
 	return orders, count, nil
 }
+
+// HasPaidOrder 用户是否有过付清的订单，已退款的订单也算
+func (r *OrderRepository) HasPaidOrder(userID uint64) (bool, error) {
+	var count int64
+	statuses := append([]int{model.OrderStatusRefunded}, SalesCountedStatuses...)
+	err := r.db.Model(&model.Order{}).Where("user_id = ? AND status IN ?", userID, statuses).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateOrderStatusFrom 仅当订单处于指定状态时更新状态，返回是否更新成功
+func (r *OrderRepository) UpdateOrderStatusFrom(id uint64, from []int, status int) (bool, error) {
+	updates := map[string]interface{}{
+		"status": status,
+	}
+
+	// 订单付清时添加支付时间，预售订单以尾款支付时间为准
+	paying := slices.Contains(from, model.OrderStatusPending) || slices.Contains(from, model.OrderStatusDepositPaid)
+	if (status == model.OrderStatusPaid || status == model.OrderStatusGrouping) && paying {
+		updates["payment_time"] = time.Now()
+	}
+
+	// 如果已完成，则添加完成时间
+	if status == model.OrderStatusCompleted {
+		updates["completed_at"] = time.Now()
+	}
+
+	result := r.db.Model(&model.Order{}).Where("id = ? AND status IN ?", id, from).Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseGroupOrders 拼团成功后将拼团中的订单转为已支付，进入发货流程
+func (r *OrderRepository) ReleaseGroupOrders(groupID uint64) error {
+	return r.db.Model(&model.Order{}).
+		Where("group_id = ? AND status = ?", groupID, model.OrderStatusGrouping).
+		Update("status", model.OrderStatusPaid).Error
+}
+
+// UpdateOrder 更新订单字段
+func (r *OrderRepository) UpdateOrder(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&model.Order{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// AddRefundedAmount 累加订单已部分退款金额
+func (r *OrderRepository) AddRefundedAmount(id uint64, amount float64) error {
+	return r.db.Model(&model.Order{}).Where("id = ?", id).
+		Update("refunded_amount", gorm.Expr("refunded_amount + ?", amount)).Error
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PointsRepository 积分仓库
+type PointsRepository struct {
+	db *gorm.DB
+}
+
+// NewPointsRepository
+func NewPointsRepository(db *gorm.DB) *PointsRepository {
+	return &PointsRepository{
+		db: db,
+	}
+}
+
+// CreateLedger 创建积分流水
+func (r *PointsRepository) CreateLedger(ledger *model.PointsLedger) error {
+	return r.db.Create(ledger).Error
+}
+
+// GetLedgersByUserID 分页获取用户积分流水
+func (r *PointsRepository) GetLedgersByUserID(userID uint64, page, pageSize int) ([]model.PointsLedger, int64, error) {
+	var ledgers []model.PointsLedger
+	var count int64
+
+	query := r.db.Model(&model.PointsLedger{}).Where("user_id = ?", userID)
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&ledgers).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ledgers, count, nil
+}
+
+// GetAvailableLedgers 获取用户未用完的积分流水，按过期时间先后排序并加锁
+func (r *PointsRepository) GetAvailableLedgers(userID uint64) ([]model.PointsLedger, error) {
+	var ledgers []model.PointsLedger
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND remaining > 0", userID).
+		Order("expire_at ASC, id ASC").
+		Find(&ledgers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ledgers, nil
+}
+
+// GetLedgersByOrderID 获取订单相关的积分流水
+func (r *PointsRepository) GetLedgersByOrderID(orderID uint64, types ...int) ([]model.PointsLedger, error) {
+	var ledgers []model.PointsLedger
+	query := r.db.Where("order_id = ?", orderID)
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+	if err := query.Find(&ledgers).Error; err != nil {
+		return nil, err
+	}
+	return ledgers, nil
+}
+
+// ExistsLedger 检查指定类型的积分流水是否已存在
+func (r *PointsRepository) ExistsLedger(userID uint64, ledgerType int, orderID uint64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.PointsLedger{}).
+		Where("user_id = ? AND type = ? AND order_id = ?", userID, ledgerType, orderID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateLedgerRemaining 更新积分流水剩余可用积分
+func (r *PointsRepository) UpdateLedgerRemaining(id uint64, remaining int) error {
+	return r.db.Model(&model.PointsLedger{}).Where("id = ?", id).Update("remaining", remaining).Error
+}
+
+// GetExpiredUserIDs 获取存在已过期未用完积分的用户
+func (r *PointsRepository) GetExpiredUserIDs(now time.Time, limit int) ([]uint64, error) {
+	var userIDs []uint64
+	err := r.db.Model(&model.PointsLedger{}).
+		Where("remaining > 0 AND expire_at <= ?", now).
+		Distinct("user_id").
+		Limit(limit).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// GetExpiredLedgers 获取用户已过期未用完的积分流水并加锁
+func (r *PointsRepository) GetExpiredLedgers(userID uint64, now time.Time) ([]model.PointsLedger, error) {
+	var ledgers []model.PointsLedger
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND remaining > 0 AND expire_at <= ?", userID, now).
+		Find(&ledgers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ledgers, nil
+}
+
+// SumExpiringPoints 统计用户在指定时间前将过期的积分
+func (r *PointsRepository) SumExpiringPoints(userID uint64, before time.Time) (int, error) {
+	var total int
+	err := r.db.Model(&model.PointsLedger{}).
+		Where("user_id = ? AND remaining > 0 AND expire_at <= ?", userID, before).
+		Select("COALESCE(SUM(remaining), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// GetRules 获取所有积分规则
+func (r *PointsRepository) GetRules() ([]model.PointsRule, error) {
+	var rules []model.PointsRule
+	if err := r.db.Order("category_id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetRulesByCategoryIDs 获取分类积分规则
+func (r *PointsRepository) GetRulesByCategoryIDs(categoryIDs []uint64) ([]model.PointsRule, error) {
+	var rules []model.PointsRule
+	if err := r.db.Where("category_id IN ?", categoryIDs).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SaveRule 保存分类积分规则，已存在则更新
+func (r *PointsRepository) SaveRule(rule *model.PointsRule) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"earn_rate", "updated_at"}),
+	}).Create(rule).Error
+}
+
+// DeleteRule 删除分类积分规则
+func (r *PointsRepository) DeleteRule(categoryID uint64) error {
+	return r.db.Delete(&model.PointsRule{}, "category_id = ?", categoryID).Error
+}
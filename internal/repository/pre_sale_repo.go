@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// PreSaleRepository 预售仓库
+type PreSaleRepository struct {
+	db *gorm.DB
+}
+
+// NewPreSaleRepository
+func NewPreSaleRepository(db *gorm.DB) *PreSaleRepository {
+	return &PreSaleRepository{
+		db: db,
+	}
+}
+
+// GetPreSaleByID 获取预售活动
+func (r *PreSaleRepository) GetPreSaleByID(id uint64) (*model.PreSale, error) {
+	var preSale model.PreSale
+	if err := r.db.First(&preSale, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &preSale, nil
+}
+
+// GetActivePreSales 获取定金阶段进行中及即将开始的预售活动
+func (r *PreSaleRepository) GetActivePreSales(now time.Time) ([]model.PreSale, error) {
+	var preSales []model.PreSale
+	err := r.db.Where("status = ? AND deposit_end > ?", model.PreSaleStatusEnabled, now).
+		Order("deposit_start ASC").
+		Find(&preSales).Error
+	if err != nil {
+		return nil, err
+	}
+	return preSales, nil
+}
+
+// GetPreSales 分页获取预售活动
+func (r *PreSaleRepository) GetPreSales(page, pageSize int) ([]model.PreSale, int64, error) {
+	var preSales []model.PreSale
+	var count int64
+
+	query := r.db.Model(&model.PreSale{})
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Order("deposit_start DESC").Offset(offset).Limit(pageSize).Find(&preSales).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return preSales, count, nil
+}
+
+// CreatePreSale 创建预售活动
+func (r *PreSaleRepository) CreatePreSale(preSale *model.PreSale) error {
+	return r.db.Create(preSale).Error
+}
+
+// UpdatePreSale 更新预售活动配置
+func (r *PreSaleRepository) UpdatePreSale(preSale *model.PreSale) error {
+	return r.db.Model(preSale).
+		Select("product_id", "title", "pre_sale_price", "deposit", "deposit_start", "deposit_end",
+			"balance_start", "balance_end", "ship_at", "forfeit_policy").
+		Updates(preSale).Error
+}
+
+// UpdatePreSaleStatus 更新预售活动状态
+func (r *PreSaleRepository) UpdatePreSaleStatus(id uint64, status int) error {
+	return r.db.Model(&model.PreSale{}).Where("id = ?", id).Update("status", status).Error
+}
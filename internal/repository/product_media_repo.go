@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProductMediaRepository 商品图文仓库
+type ProductMediaRepository struct {
+	db *gorm.DB
+}
+
+// NewProductMediaRepository
+func NewProductMediaRepository(db *gorm.DB) *ProductMediaRepository {
+	return &ProductMediaRepository{
+		db: db,
+	}
+}
+
+// GetGalleryItems 获取商品相册，按排序
+func (r *ProductMediaRepository) GetGalleryItems(productID uint64) ([]model.ProductGalleryItem, error) {
+	var items []model.ProductGalleryItem
+	err := r.db.Where("product_id = ?", productID).Order("sort_order ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// ReplaceGalleryItems 替换商品相册
+func (r *ProductMediaRepository) ReplaceGalleryItems(productID uint64, items []model.ProductGalleryItem) error {
+	if err := r.db.Delete(&model.ProductGalleryItem{}, "product_id = ?", productID).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Create(&items).Error
+}
+
+// UpdateGallerySortOrder 更新相册项排序
+func (r *ProductMediaRepository) UpdateGallerySortOrder(id uint64, sortOrder int) error {
+	return r.db.Model(&model.ProductGalleryItem{}).Where("id = ?", id).Update("sort_order", sortOrder).Error
+}
+
+// GetDetailBlocks 获取商品详情内容块，按排序
+func (r *ProductMediaRepository) GetDetailBlocks(productID uint64) ([]model.ProductDetailBlock, error) {
+	var blocks []model.ProductDetailBlock
+	err := r.db.Where("product_id = ?", productID).Order("sort_order ASC, id ASC").Find(&blocks).Error
+	return blocks, err
+}
+
+// ReplaceDetailBlocks 替换商品详情内容块
+func (r *ProductMediaRepository) ReplaceDetailBlocks(productID uint64, blocks []model.ProductDetailBlock) error {
+	if err := r.db.Delete(&model.ProductDetailBlock{}, "product_id = ?", productID).Error; err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	return r.db.Create(&blocks).Error
+}
+
+// UpdateProductImage 更新商品主图
+func (r *ProductMediaRepository) UpdateProductImage(productID uint64, objectName string) error {
+	return r.db.Model(&model.Product{}).Where("id = ?", productID).Update("image_url", objectName).Error
+}
+
+// MarkReferenced 记录对象被引用，未记录过的对象一并登记
+func (r *ProductMediaRepository) MarkReferenced(objectNames []string) error {
+	return r.upsertMediaObjects(objectNames, nil)
+}
+
+// MarkUnreferenced 记录对象不再被引用的时间
+func (r *ProductMediaRepository) MarkUnreferenced(objectNames []string, at time.Time) error {
+	return r.upsertMediaObjects(objectNames, &at)
+}
+
+// upsertMediaObjects 登记对象并设置不再被引用的时间
+func (r *ProductMediaRepository) upsertMediaObjects(objectNames []string, unreferencedAt *time.Time) error {
+	if len(objectNames) == 0 {
+		return nil
+	}
+	objects := make([]model.MediaObject, len(objectNames))
+	for i, objectName := range objectNames {
+		objects[i] = model.MediaObject{ObjectName: objectName, UnreferencedAt: unreferencedAt}
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "object_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"unreferenced_at", "updated_at"}),
+	}).Create(&objects).Error
+}
+
+// GetUnreferencedObjects 分批获取在指定时间前已不再被引用的对象
+func (r *ProductMediaRepository) GetUnreferencedObjects(before time.Time, afterID uint64, limit int) ([]model.MediaObject, error) {
+	var objects []model.MediaObject
+	err := r.db.Where("id > ? AND unreferenced_at IS NOT NULL AND unreferenced_at < ?", afterID, before).
+		Order("id ASC").
+		Limit(limit).
+		Find(&objects).Error
+	return objects, err
+}
+
+// IsObjectReferenced 判断对象是否仍被商品主图、相册或详情内容引用
+func (r *ProductMediaRepository) IsObjectReferenced(objectName string) (bool, error) {
+	queries := []*gorm.DB{
+		r.db.Model(&model.ProductGalleryItem{}).Where("object_name = ?", objectName),
+		r.db.Model(&model.ProductDetailBlock{}).Where("object_name = ?", objectName),
+		r.db.Model(&model.Product{}).Where("image_url = ?", objectName),
+		r.db.Model(&model.Banner{}).Where("image_url = ?", objectName),
+		r.db.Model(&model.Promotion{}).Where("image_url = ?", objectName),
+	}
+	for _, query := range queries {
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteUnreferencedObject 删除在指定时间前已不再被引用的对象记录，期间重新被引用时不删除，返回是否删除
+func (r *ProductMediaRepository) DeleteUnreferencedObject(id uint64, before time.Time) (bool, error) {
+	result := r.db.Where("id = ? AND unreferenced_at IS NOT NULL AND unreferenced_at < ?", id, before).
+		Delete(&model.MediaObject{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
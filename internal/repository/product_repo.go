@@ -3,8 +3,12 @@ package repository
 import (
 	"github.com/colinjuang/shop-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// inCategoryTree 筛选属于分类及其子孙分类的商品，参数为分类ID
+const inCategoryTree = "category_path LIKE (SELECT CONCAT(path, '%') FROM categories WHERE id = ?)"
+
 // ProductRepository 商品仓库
 type ProductRepository struct {
 	db *gorm.DB
@@ -27,24 +31,40 @@ func (r *ProductRepository) GetProductByID(id uint64) (*model.Product, error) {
 	return &product, nil
 }
 
-// GetProducts 获取商品
-func (r *ProductRepository) GetProducts(page, pageSize int, categoryID *uint64, hot, recommend *bool) ([]model.Product, int64, error) {
+// filterProducts 按分类（含子孙分类）、推荐和属性值筛选商品。valueGroups 每组为同一属性的值，
+// 组内满足其一即可，各组都要满足
+func filterProducts(query *gorm.DB, categoryID *uint64, recommend *bool, valueGroups [][]uint64) *gorm.DB {
+	if categoryID != nil {
+		query = query.Where(inCategoryTree, *categoryID)
+	}
+	if recommend != nil {
+		query = query.Where("recommend = ?", *recommend)
+	}
+	for _, valueIDs := range valueGroups {
+		query = query.Where("products.id IN (SELECT product_id FROM product_attribute_values WHERE value_id IN ?)", valueIDs)
+	}
+	return query
+}
+
+// GetProducts 获取商品，categoryID 包含其子孙分类，productIDs 不为nil时只获取其中的商品并按其顺序排列，
+// valueGroups 为属性值筛选条件
+func (r *ProductRepository) GetProducts(page, pageSize int, categoryID *uint64, productIDs []uint64, recommend *bool, valueGroups [][]uint64) ([]model.Product, int64, error) {
 	var products []model.Product
 	var count int64
 
-	query := r.db.Model(&model.Product{})
-
 	// 应用过滤
-	if categoryID != nil {
-		query = query.Where("category_id = ?", *categoryID)
-	}
+	query := filterProducts(r.db.Model(&model.Product{}), categoryID, recommend, valueGroups)
 
-	if hot != nil {
-		query = query.Where("sale_count > 100")
-	}
-
-	if recommend != nil {
-		query = query.Where("recommend = ?", *recommend)
+	if productIDs != nil {
+		if len(productIDs) == 0 {
+			return products, 0, nil
+		}
+		ids := make([]interface{}, len(productIDs))
+		for i, id := range productIDs {
+			ids[i] = id
+		}
+		query = query.Where("id IN ?", productIDs).
+			Order(clause.OrderBy{Expression: clause.Expr{SQL: "FIELD(id, ?)", Vars: []interface{}{ids}, WithoutParentheses: true}})
 	}
 
 	// 获取总数
@@ -61,8 +81,80 @@ func (r *ProductRepository) GetProducts(page, pageSize int, categoryID *uint64,
 	return products, count, nil
 }
 
-// UpdateProductStock 更新商品库存
-func (r *ProductRepository) UpdateProductStock(id uint64, stock int) error {
-	// 减去库存
-	return r.db.Model(&model.Product{}).Where("id = ?", id).Update("stock_count", gorm.Expr("stock_count - ?", stock)).Error
+// DecreaseProductStock 扣减商品库存，库存不足时不更新并返回false
+func (r *ProductRepository) DecreaseProductStock(id uint64, quantity int) (bool, error) {
+	result := r.db.Model(&model.Product{}).
+		Where("id = ? AND stock_count >= ?", id, quantity).
+		Update("stock_count", gorm.Expr("stock_count - ?", quantity))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// IncreaseProductStock 增加商品库存（取消或退款时归还库存）
+func (r *ProductRepository) IncreaseProductStock(id uint64, stock int) error {
+	return r.db.Model(&model.Product{}).Where("id = ?", id).Update("stock_count", gorm.Expr("stock_count + ?", stock)).Error
+}
+
+// GetProductStock 获取商品当前可售库存
+func (r *ProductRepository) GetProductStock(id uint64) (int, error) {
+	var stock int
+	err := r.db.Model(&model.Product{}).Where("id = ?", id).Select("stock_count").Scan(&stock).Error
+	return stock, err
+}
+
+// GetProductsByIDs 批量获取商品
+func (r *ProductRepository) GetProductsByIDs(ids []uint64) ([]model.Product, error) {
+	var products []model.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	result := r.db.Where("id IN ?", ids).Find(&products)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return products, nil
+}
+
+// GetBestProductWithinBudget 获取分类及其子孙分类下在售、有货且不超过预算的最高价商品，不存在时返回nil
+func (r *ProductRepository) GetBestProductWithinBudget(categoryID uint64, budget float64, quantity int) (*model.Product, error) {
+	var products []model.Product
+	err := r.db.Where(inCategoryTree, categoryID).
+		Where("status = 1 AND is_bundle = ? AND stock_count >= ? AND price <= ?", false, quantity, budget).
+		Order("price DESC, sale_count DESC").
+		Limit(1).
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, nil
+	}
+	return &products[0], nil
+}
+
+// SetProductBundle 设置商品是否为组合商品
+func (r *ProductRepository) SetProductBundle(id uint64, isBundle bool) error {
+	return r.db.Model(&model.Product{}).Where("id = ?", id).Update("is_bundle", isBundle).Error
+}
+
+// LockProduct 获取商品并加行锁，需在事务中调用
+func (r *ProductRepository) LockProduct(id uint64) (*model.Product, error) {
+	var product model.Product
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &product, nil
+}
+
+// UpdateProductStatus 更新商品上下架状态
+func (r *ProductRepository) UpdateProductStatus(id uint64, status int) error {
+	return r.db.Model(&model.Product{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// UpdateProductPrice 更新商品价格
+func (r *ProductRepository) UpdateProductPrice(id uint64, price float64) error {
+	return r.db.Model(&model.Product{}).Where("id = ?", id).Update("price", price).Error
 }
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// ProductScheduleRepository 商品定时计划仓库
+type ProductScheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewProductScheduleRepository
+func NewProductScheduleRepository(db *gorm.DB) *ProductScheduleRepository {
+	return &ProductScheduleRepository{
+		db: db,
+	}
+}
+
+// CreateSchedule 创建定时计划
+func (r *ProductScheduleRepository) CreateSchedule(schedule *model.ProductSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+// GetScheduleByID 获取定时计划
+func (r *ProductScheduleRepository) GetScheduleByID(id uint64) (*model.ProductSchedule, error) {
+	var schedule model.ProductSchedule
+	result := r.db.First(&schedule, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &schedule, nil
+}
+
+// GetSchedulesByProductID 获取商品的定时计划，按执行时间倒序
+func (r *ProductScheduleRepository) GetSchedulesByProductID(productID uint64) ([]model.ProductSchedule, error) {
+	var schedules []model.ProductSchedule
+	err := r.db.Where("product_id = ?", productID).Order("run_at DESC, id DESC").Find(&schedules).Error
+	return schedules, err
+}
+
+// GetDueSchedules 获取已到执行时间的待执行计划，按执行时间先后排列
+func (r *ProductScheduleRepository) GetDueSchedules(now time.Time, limit int) ([]model.ProductSchedule, error) {
+	var schedules []model.ProductSchedule
+	err := r.db.Where("status = ? AND run_at <= ?", model.ProductScheduleStatusPending, now).
+		Order("run_at ASC, id ASC").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// UpdateScheduleStatusFrom 仅当计划处于指定状态时更新状态，返回是否更新成功
+func (r *ProductScheduleRepository) UpdateScheduleStatusFrom(id uint64, from, status int, updates map[string]interface{}) (bool, error) {
+	values := map[string]interface{}{
+		"status": status,
+	}
+	for key, value := range updates {
+		values[key] = value
+	}
+	result := r.db.Model(&model.ProductSchedule{}).Where("id = ? AND status = ?", id, from).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CancelRevertSchedules 取消调价计划对应的待执行恢复计划
+func (r *ProductScheduleRepository) CancelRevertSchedules(scheduleID uint64) error {
+	return r.db.Model(&model.ProductSchedule{}).
+		Where("revert_of = ? AND status = ?", scheduleID, model.ProductScheduleStatusPending).
+		Update("status", model.ProductScheduleStatusCancelled).Error
+}
+
+// CreatePriceHistory 记录价格变更
+func (r *ProductScheduleRepository) CreatePriceHistory(history *model.PriceHistory) error {
+	return r.db.Create(history).Error
+}
+
+// GetPriceHistories 分页获取商品价格变更记录，最近的在前
+func (r *ProductScheduleRepository) GetPriceHistories(productID uint64, page, pageSize int) ([]model.PriceHistory, int64, error) {
+	var histories []model.PriceHistory
+	var count int64
+
+	query := r.db.Model(&model.PriceHistory{}).Where("product_id = ?", productID)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("effective_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&histories).Error; err != nil {
+		return nil, 0, err
+	}
+	return histories, count, nil
+}
+
+// GetLatestPriceBefore 获取指定时间及之前最后一次价格变更，不存在时返回nil
+func (r *ProductScheduleRepository) GetLatestPriceBefore(productID uint64, at time.Time) (*model.PriceHistory, error) {
+	var histories []model.PriceHistory
+	err := r.db.Where("product_id = ? AND effective_at <= ?", productID, at).
+		Order("effective_at DESC, id DESC").
+		Limit(1).
+		Find(&histories).Error
+	if err != nil || len(histories) == 0 {
+		return nil, err
+	}
+	return &histories[0], nil
+}
+
+// GetFirstPriceAfter 获取指定时间之后第一次价格变更，不存在时返回nil
+func (r *ProductScheduleRepository) GetFirstPriceAfter(productID uint64, at time.Time) (*model.PriceHistory, error) {
+	var histories []model.PriceHistory
+	err := r.db.Where("product_id = ? AND effective_at > ?", productID, at).
+		Order("effective_at ASC, id ASC").
+		Limit(1).
+		Find(&histories).Error
+	if err != nil || len(histories) == 0 {
+		return nil, err
+	}
+	return &histories[0], nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/colinjuang/shop-go/internal/model"
 	"gorm.io/gorm"
 )
@@ -17,12 +19,114 @@ func NewPromotionRepository(db *gorm.DB) *PromotionRepository {
 	}
 }
 
-// GetPromotions 获取所有促销
-func (r *PromotionRepository) GetPromotions() ([]model.Promotion, error) {
+// GetPromotions 获取所有促销，可按状态筛选
+func (r *PromotionRepository) GetPromotions(status *int) ([]model.Promotion, error) {
 	var promotions []model.Promotion
-	result := r.db.Find(&promotions)
+	query := r.db.Model(&model.Promotion{})
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	result := query.Order("priority DESC, id ASC").Find(&promotions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return promotions, r.fillScopes(promotions)
+}
+
+// GetUnfinishedPromotions 获取启用且尚未结束的促销，包括还未开始的
+func (r *PromotionRepository) GetUnfinishedPromotions(now time.Time) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	err := r.db.Where("status = ? AND (end_at IS NULL OR end_at > ?)", model.PromotionStatusEnabled, now).
+		Order("priority DESC, id ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, r.fillScopes(promotions)
+}
+
+// GetPromotionByID 获取促销
+func (r *PromotionRepository) GetPromotionByID(id uint64) (*model.Promotion, error) {
+	var promotion model.Promotion
+	result := r.db.First(&promotion, "id = ?", id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	return promotions, nil
+	promotions := []model.Promotion{promotion}
+	if err := r.fillScopes(promotions); err != nil {
+		return nil, err
+	}
+	return &promotions[0], nil
+}
+
+// CreatePromotion 创建促销及其适用范围
+func (r *PromotionRepository) CreatePromotion(promotion *model.Promotion) error {
+	if err := r.db.Create(promotion).Error; err != nil {
+		return err
+	}
+	return r.saveScopes(promotion)
+}
+
+// UpdatePromotion 更新促销，适用范围整体替换
+func (r *PromotionRepository) UpdatePromotion(promotion *model.Promotion) error {
+	if err := r.db.Save(promotion).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("promotion_id = ?", promotion.ID).Delete(&model.PromotionScope{}).Error; err != nil {
+		return err
+	}
+	return r.saveScopes(promotion)
+}
+
+// DeletePromotion 删除促销及其适用范围，已下单的优惠记录保留
+func (r *PromotionRepository) DeletePromotion(id uint64) error {
+	if err := r.db.Where("promotion_id = ?", id).Delete(&model.PromotionScope{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&model.Promotion{}, id).Error
+}
+
+// saveScopes 保存促销适用的分类或商品
+func (r *PromotionRepository) saveScopes(promotion *model.Promotion) error {
+	var scopes []model.PromotionScope
+	for _, categoryID := range promotion.CategoryIDs {
+		scopes = append(scopes, model.PromotionScope{PromotionID: promotion.ID, CategoryID: categoryID})
+	}
+	for _, productID := range promotion.ProductIDs {
+		scopes = append(scopes, model.PromotionScope{PromotionID: promotion.ID, ProductID: productID})
+	}
+	if len(scopes) == 0 {
+		return nil
+	}
+	return r.db.Create(&scopes).Error
+}
+
+// fillScopes 填充促销适用的分类和商品
+func (r *PromotionRepository) fillScopes(promotions []model.Promotion) error {
+	if len(promotions) == 0 {
+		return nil
+	}
+	ids := make([]uint64, len(promotions))
+	for i, promotion := range promotions {
+		ids[i] = promotion.ID
+	}
+
+	var scopes []model.PromotionScope
+	if err := r.db.Where("promotion_id IN ?", ids).Order("id ASC").Find(&scopes).Error; err != nil {
+		return err
+	}
+	indexes := make(map[uint64]int, len(promotions))
+	for i, promotion := range promotions {
+		indexes[promotion.ID] = i
+	}
+	for _, scope := range scopes {
+		promotion := &promotions[indexes[scope.PromotionID]]
+		if scope.CategoryID > 0 {
+			promotion.CategoryIDs = append(promotion.CategoryIDs, scope.CategoryID)
+		}
+		if scope.ProductID > 0 {
+			promotion.ProductIDs = append(promotion.ProductIDs, scope.ProductID)
+		}
+	}
+	return nil
 }
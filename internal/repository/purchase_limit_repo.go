@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// PurchaseLimitRepository 限购规则仓库
+type PurchaseLimitRepository struct {
+	db *gorm.DB
+}
+
+// NewPurchaseLimitRepository
+func NewPurchaseLimitRepository(db *gorm.DB) *PurchaseLimitRepository {
+	return &PurchaseLimitRepository{
+		db: db,
+	}
+}
+
+// CreateLimit 创建限购规则
+func (r *PurchaseLimitRepository) CreateLimit(limit *model.PurchaseLimit) error {
+	return r.db.Create(limit).Error
+}
+
+// GetLimitByID 获取限购规则，不存在时返回nil
+func (r *PurchaseLimitRepository) GetLimitByID(id uint64) (*model.PurchaseLimit, error) {
+	var limit model.PurchaseLimit
+	if err := r.db.First(&limit, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// UpdateLimit 更新限购规则
+func (r *PurchaseLimitRepository) UpdateLimit(limit *model.PurchaseLimit) error {
+	return r.db.Save(limit).Error
+}
+
+// DeleteLimit 删除限购规则
+func (r *PurchaseLimitRepository) DeleteLimit(id uint64) error {
+	return r.db.Delete(&model.PurchaseLimit{}, id).Error
+}
+
+// GetLimitsByProductID 获取商品的全部限购规则
+func (r *PurchaseLimitRepository) GetLimitsByProductID(productID uint64) ([]model.PurchaseLimit, error) {
+	var limits []model.PurchaseLimit
+	if err := r.db.Where("product_id = ?", productID).Order("period ASC, id ASC").Find(&limits).Error; err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// GetActiveLimits 获取商品当前生效的限购规则
+func (r *PurchaseLimitRepository) GetActiveLimits(productIDs []uint64, now time.Time) ([]model.PurchaseLimit, error) {
+	var limits []model.PurchaseLimit
+	if len(productIDs) == 0 {
+		return limits, nil
+	}
+	err := r.db.Where("product_id IN ?", productIDs).
+		Where("(start_at IS NULL OR start_at <= ?) AND (end_at IS NULL OR end_at > ?)", now, now).
+		Find(&limits).Error
+	if err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// SumPurchasedQuantity 统计用户自指定时间起在未取消订单中购买商品的数量，组合商品的组件行不计入
+func (r *PurchaseLimitRepository) SumPurchasedQuantity(userID, productID uint64, since time.Time) (int, error) {
+	var total int
+	err := r.db.Table("order_items").
+		Select("COALESCE(SUM(order_items.quantity), 0)").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.status <> ? AND orders.created_at >= ?", userID, model.OrderStatusCancelled, since).
+		Where("order_items.product_id = ? AND order_items.parent_id = 0", productID).
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PurchaseRepository 供应商及采购单仓库
+type PurchaseRepository struct {
+	db *gorm.DB
+}
+
+// NewPurchaseRepository
+func NewPurchaseRepository(db *gorm.DB) *PurchaseRepository {
+	return &PurchaseRepository{
+		db: db,
+	}
+}
+
+// ProductSupplier 商品最近一次采购的供应商
+type ProductSupplier struct {
+	ProductID  uint64
+	SupplierID uint64
+}
+
+// OpenPurchaseStatuses 尚有数量待到货的采购单状态
+var OpenPurchaseStatuses = []int{model.PurchaseOrderStatusOrdered, model.PurchaseOrderStatusPartiallyReceived}
+
+// CreateSupplier 创建供应商
+func (r *PurchaseRepository) CreateSupplier(supplier *model.Supplier) error {
+	return r.db.Create(supplier).Error
+}
+
+// UpdateSupplier 更新供应商
+func (r *PurchaseRepository) UpdateSupplier(supplier *model.Supplier) error {
+	return r.db.Save(supplier).Error
+}
+
+// GetSupplierByID 获取供应商
+func (r *PurchaseRepository) GetSupplierByID(id uint64) (*model.Supplier, error) {
+	var supplier model.Supplier
+	result := r.db.First(&supplier, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &supplier, nil
+}
+
+// GetSuppliers 获取供应商列表，可按状态筛选
+func (r *PurchaseRepository) GetSuppliers(status *int) ([]model.Supplier, error) {
+	var suppliers []model.Supplier
+	query := r.db.Model(&model.Supplier{})
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	err := query.Order("id ASC").Find(&suppliers).Error
+	return suppliers, err
+}
+
+// CreatePurchaseOrder 创建采购单及明细
+func (r *PurchaseRepository) CreatePurchaseOrder(order *model.PurchaseOrder) error {
+	return r.db.Create(order).Error
+}
+
+// GetPurchaseOrderByID 获取采购单及供应商、明细
+func (r *PurchaseRepository) GetPurchaseOrderByID(id uint64) (*model.PurchaseOrder, error) {
+	var order model.PurchaseOrder
+	result := r.db.Preload("Supplier").Preload("Items").First(&order, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &order, nil
+}
+
+// LockPurchaseOrder 加行锁获取采购单及明细，用于到货入库和状态变更
+func (r *PurchaseRepository) LockPurchaseOrder(id uint64) (*model.PurchaseOrder, error) {
+	var order model.PurchaseOrder
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if err := r.db.Where("purchase_order_id = ?", id).Order("id ASC").Find(&order.Items).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetPurchaseOrders 分页获取采购单，可按供应商和状态筛选，最新的在前
+func (r *PurchaseRepository) GetPurchaseOrders(supplierID uint64, status *int, page, pageSize int) ([]model.PurchaseOrder, int64, error) {
+	var orders []model.PurchaseOrder
+	var total int64
+
+	query := r.db.Model(&model.PurchaseOrder{})
+	if supplierID > 0 {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Supplier").Preload("Items").
+		Order("id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&orders).Error
+	return orders, total, err
+}
+
+// UpdatePurchaseOrder 更新草稿采购单的信息
+func (r *PurchaseRepository) UpdatePurchaseOrder(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&model.PurchaseOrder{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ReplacePurchaseOrderItems 替换采购单的全部明细
+func (r *PurchaseRepository) ReplacePurchaseOrderItems(orderID uint64, items []model.PurchaseOrderItem) error {
+	if err := r.db.Where("purchase_order_id = ?", orderID).Delete(&model.PurchaseOrderItem{}).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Create(&items).Error
+}
+
+// UpdatePurchaseOrderStatusFrom 仅当采购单处于指定状态之一时更新状态，返回是否更新成功
+func (r *PurchaseRepository) UpdatePurchaseOrderStatusFrom(id uint64, from []int, status int, updates map[string]interface{}) (bool, error) {
+	values := map[string]interface{}{
+		"status": status,
+	}
+	for key, value := range updates {
+		values[key] = value
+	}
+	result := r.db.Model(&model.PurchaseOrder{}).Where("id = ? AND status IN ?", id, from).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// IncreaseReceivedQty 增加明细的到货数量，超出采购数量时不更新，返回是否更新成功
+func (r *PurchaseRepository) IncreaseReceivedQty(itemID uint64, quantity int) (bool, error) {
+	result := r.db.Model(&model.PurchaseOrderItem{}).
+		Where("id = ? AND received_qty + ? <= quantity", itemID, quantity).
+		Update("received_qty", gorm.Expr("received_qty + ?", quantity))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetIncomingQuantities 统计已下单未到货的商品数量，productIDs为空时统计全部商品
+func (r *PurchaseRepository) GetIncomingQuantities(productIDs []uint64) ([]ProductQuantity, error) {
+	var quantities []ProductQuantity
+	query := r.db.Model(&model.PurchaseOrderItem{}).
+		Select("purchase_order_items.product_id, SUM(purchase_order_items.quantity - purchase_order_items.received_qty) AS quantity").
+		Joins("JOIN purchase_orders ON purchase_orders.id = purchase_order_items.purchase_order_id").
+		Where("purchase_orders.status IN ?", OpenPurchaseStatuses)
+	if len(productIDs) > 0 {
+		query = query.Where("purchase_order_items.product_id IN ?", productIDs)
+	}
+	err := query.Group("purchase_order_items.product_id").
+		Having("quantity > 0").
+		Scan(&quantities).Error
+	return quantities, err
+}
+
+// GetStockSales 统计指定时间后付清订单消耗库存的商品数量，扣除已退款的数量。
+// 组合商品按组件行统计，组合商品行本身不占库存不计入
+func (r *PurchaseRepository) GetStockSales(since time.Time) ([]ProductQuantity, error) {
+	var quantities []ProductQuantity
+	err := r.db.Model(&model.OrderItem{}).
+		Select("order_items.product_id, SUM(order_items.quantity - order_items.refunded_qty) AS quantity").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.status IN ? AND orders.payment_time >= ? AND order_items.is_bundle = ?", SalesCountedStatuses, since, false).
+		Group("order_items.product_id").
+		Having("quantity > 0").
+		Scan(&quantities).Error
+	return quantities, err
+}
+
+// GetLastSuppliers 获取商品最近一次已下单采购的供应商
+func (r *PurchaseRepository) GetLastSuppliers(productIDs []uint64) ([]ProductSupplier, error) {
+	var suppliers []ProductSupplier
+	if len(productIDs) == 0 {
+		return suppliers, nil
+	}
+	err := r.db.Model(&model.PurchaseOrderItem{}).
+		Select("purchase_order_items.product_id, purchase_orders.supplier_id").
+		Joins("JOIN purchase_orders ON purchase_orders.id = purchase_order_items.purchase_order_id").
+		Where("purchase_order_items.product_id IN ? AND purchase_orders.status NOT IN ?", productIDs,
+			[]int{model.PurchaseOrderStatusDraft, model.PurchaseOrderStatusCancelled}).
+		Order("purchase_orders.id DESC").
+		Scan(&suppliers).Error
+	return suppliers, err
+}
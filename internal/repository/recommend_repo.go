@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// RecommendRepository 商品推荐仓库
+type RecommendRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendRepository
+func NewRecommendRepository(db *gorm.DB) *RecommendRepository {
+	return &RecommendRepository{
+		db: db,
+	}
+}
+
+// PurchaseRecord 购买记录
+type PurchaseRecord struct {
+	ID        uint64
+	UserID    uint64
+	ProductID uint64
+	CreatedAt time.Time
+}
+
+// purchasedStatuses 计入购买记录的订单状态
+var purchasedStatuses = []int{model.OrderStatusPaid, model.OrderStatusShipped, model.OrderStatusCompleted}
+
+// GetPurchasesAfterID 分批获取指定时间后已支付订单的购买记录，组合商品的组件行不计入
+func (r *RecommendRepository) GetPurchasesAfterID(since time.Time, afterID uint64, limit int) ([]PurchaseRecord, error) {
+	var records []PurchaseRecord
+	err := r.db.Model(&model.OrderItem{}).
+		Select("order_items.id, orders.user_id, order_items.product_id, orders.created_at").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("order_items.id > ? AND order_items.parent_id = 0", afterID).
+		Where("orders.status IN ? AND orders.created_at >= ?", purchasedStatuses, since).
+		Order("order_items.id ASC").
+		Limit(limit).
+		Scan(&records).Error
+	return records, err
+}
+
+// GetUserPurchasedProductIDs 获取用户最近购买过的商品ID，按最近购买时间倒序
+func (r *RecommendRepository) GetUserPurchasedProductIDs(userID uint64, limit int) ([]uint64, error) {
+	var productIDs []uint64
+	err := r.db.Model(&model.OrderItem{}).
+		Select("order_items.product_id").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.status IN ? AND order_items.parent_id = 0", userID, purchasedStatuses).
+		Group("order_items.product_id").
+		Order("MAX(orders.created_at) DESC").
+		Limit(limit).
+		Pluck("order_items.product_id", &productIDs).Error
+	return productIDs, err
+}
+
+// ReplaceSimilarities 用新计算的结果替换全部商品相似度
+func (r *RecommendRepository) ReplaceSimilarities(similarities []model.ProductSimilarity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.ProductSimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(similarities, 1000).Error
+	})
+}
+
+// GetSimilarities 获取商品的相似商品，按相似度降序
+func (r *RecommendRepository) GetSimilarities(productID uint64, limit int) ([]model.ProductSimilarity, error) {
+	var similarities []model.ProductSimilarity
+	err := r.db.Where("product_id = ?", productID).
+		Order("score DESC").
+		Limit(limit).
+		Find(&similarities).Error
+	return similarities, err
+}
+
+// GetSimilaritiesByProductIDs 批量获取多个商品的相似商品
+func (r *RecommendRepository) GetSimilaritiesByProductIDs(productIDs []uint64) ([]model.ProductSimilarity, error) {
+	var similarities []model.ProductSimilarity
+	if len(productIDs) == 0 {
+		return similarities, nil
+	}
+	err := r.db.Where("product_id IN ?", productIDs).Find(&similarities).Error
+	return similarities, err
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// SalesRepository 销量统计仓库
+type SalesRepository struct {
+	db *gorm.DB
+}
+
+// NewSalesRepository
+func NewSalesRepository(db *gorm.DB) *SalesRepository {
+	return &SalesRepository{
+		db: db,
+	}
+}
+
+// HourlySales 商品每小时销量
+type HourlySales struct {
+	ProductID uint64
+	Hour      int64 // 支付时间的Unix小时数
+	Quantity  int
+}
+
+// SalesCountedStatuses 计入销量的订单状态，拼团中的订单已付款，退款时扣回
+var SalesCountedStatuses = []int{model.OrderStatusPaid, model.OrderStatusGrouping, model.OrderStatusShipped, model.OrderStatusCompleted}
+
+// GetHourlySales 按支付时间的小时统计指定时间后付清订单的商品销量，扣除已部分退款的数量，组合商品的组件行不计入
+func (r *SalesRepository) GetHourlySales(since time.Time) ([]HourlySales, error) {
+	var sales []HourlySales
+	err := r.db.Model(&model.OrderItem{}).
+		Select("order_items.product_id, FLOOR(UNIX_TIMESTAMP(orders.payment_time) / 3600) AS hour, "+
+			"SUM(order_items.quantity - order_items.refunded_qty) AS quantity").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.status IN ? AND orders.payment_time >= ? AND order_items.parent_id = 0", SalesCountedStatuses, since).
+		Group("order_items.product_id, hour").
+		Having("quantity > 0").
+		Scan(&sales).Error
+	return sales, err
+}
+
+// IncreaseSaleCount 增加商品销量，退款时为负数
+func (r *SalesRepository) IncreaseSaleCount(productID uint64, quantity int) error {
+	return r.db.Model(&model.Product{}).Where("id = ?", productID).
+		Update("sale_count", gorm.Expr("GREATEST(sale_count + ?, 0)", quantity)).Error
+}
+
+// GetCategoryIDs 获取全部分类ID
+func (r *SalesRepository) GetCategoryIDs() ([]uint64, error) {
+	var categoryIDs []uint64
+	err := r.db.Model(&model.Category{}).Pluck("id", &categoryIDs).Error
+	return categoryIDs, err
+}
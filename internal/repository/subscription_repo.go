@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubscriptionRepository 周期订阅仓库
+type SubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewSubscriptionRepository
+func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		db: db,
+	}
+}
+
+// CreateSubscription 创建订阅
+func (r *SubscriptionRepository) CreateSubscription(subscription *model.Subscription) error {
+	return r.db.Create(subscription).Error
+}
+
+// GetSubscriptionByID 获取订阅
+func (r *SubscriptionRepository) GetSubscriptionByID(id uint64) (*model.Subscription, error) {
+	var subscription model.Subscription
+	if err := r.db.First(&subscription, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetSubscriptionByIDAndUserID 获取用户的订阅
+func (r *SubscriptionRepository) GetSubscriptionByIDAndUserID(id, userID uint64) (*model.Subscription, error) {
+	var subscription model.Subscription
+	if err := r.db.First(&subscription, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// LockSubscription 获取订阅并加行锁，需在事务中调用
+func (r *SubscriptionRepository) LockSubscription(id uint64) (*model.Subscription, error) {
+	var subscription model.Subscription
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetUserSubscriptions 分页获取用户的订阅
+func (r *SubscriptionRepository) GetUserSubscriptions(userID uint64, page, pageSize int) ([]model.Subscription, int64, error) {
+	var subscriptions []model.Subscription
+	var count int64
+
+	query := r.db.Model(&model.Subscription{}).Where("user_id = ?", userID)
+
+	// 获取总数
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页结果
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&subscriptions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return subscriptions, count, nil
+}
+
+// GetDueSubscriptions 获取下次配送日期不晚于 until 的配送中订阅
+func (r *SubscriptionRepository) GetDueSubscriptions(until time.Time, limit int) ([]model.Subscription, error) {
+	var subscriptions []model.Subscription
+	err := r.db.Where("status = ? AND next_delivery_date <= ? AND delivered_count < total_deliveries",
+		model.SubscriptionStatusActive, until).
+		Order("next_delivery_date ASC").
+		Limit(limit).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription 更新订阅字段
+func (r *SubscriptionRepository) UpdateSubscription(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&model.Subscription{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateSubscriptionStatusFrom 仅当订阅处于 from 中的状态时更新，返回是否更新成功
+func (r *SubscriptionRepository) UpdateSubscriptionStatusFrom(id uint64, from []int, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&model.Subscription{}).Where("id = ? AND status IN ?", id, from).Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateDelivery 创建配送记录
+func (r *SubscriptionRepository) CreateDelivery(delivery *model.SubscriptionDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// GetDeliveries 获取订阅的配送记录
+func (r *SubscriptionRepository) GetDeliveries(subscriptionID uint64) ([]model.SubscriptionDelivery, error) {
+	var deliveries []model.SubscriptionDelivery
+	if err := r.db.Where("subscription_id = ?", subscriptionID).Order("sequence ASC").Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetDeliveryByOrderID 根据订单获取配送记录，不存在时返回nil
+func (r *SubscriptionRepository) GetDeliveryByOrderID(orderID uint64) (*model.SubscriptionDelivery, error) {
+	var delivery model.SubscriptionDelivery
+	err := r.db.Where("order_id = ?", orderID).First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/colinjuang/shop-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository 用户仓库
@@ -47,6 +48,33 @@ func (r *UserRepository) GetUserByID(id uint64) (*model.User, error) {
 	return &user, nil
 }
 
+// GetUserByIDForUpdate 获取用户并加行锁，需在事务中使用
+func (r *UserRepository) GetUserByIDForUpdate(id uint64) (*model.User, error) {
+	var user model.User
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// GetUsersByIDs 批量获取用户
+func (r *UserRepository) GetUsersByIDs(ids []uint64) ([]model.User, error) {
+	var users []model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UpdateUserPoints 更新用户积分余额
+func (r *UserRepository) UpdateUserPoints(id uint64, points int) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Update("points", points).Error
+}
+
 // GetUserByUsername 获取用户
 func (r *UserRepository) GetUserByUsername(username string) (*model.User, error) {
 	var count int64
@@ -61,3 +89,15 @@ func (r *UserRepository) GetUserByUsername(username string) (*model.User, error)
 	}
 	return &user, nil
 }
+
+// UpdateUserWallet 更新用户钱包余额
+func (r *UserRepository) UpdateUserWallet(id uint64, wallet float64) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Update("wallet", wallet).Error
+}
+
+// GetUserIDsByRole 获取指定角色的全部用户ID
+func (r *UserRepository) GetUserIDsByRole(role int) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.Model(&model.User{}).Where("role = ?", role).Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,488 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	categoryutils "github.com/colinjuang/shop-go/internal/utils/category"
+	"gorm.io/gorm"
+)
+
+const (
+	// attributeCacheTTL 属性缓存时间，属性变更时主动清除，过期只作为兜底
+	attributeCacheTTL = 24 * time.Hour
+	// facetCacheTTL 筛选项计数缓存时间，商品标签和上下架变化在此时间内生效
+	facetCacheTTL = 2 * time.Minute
+)
+
+// AttributeService handles product attributes such as occasion, recipient and flower color.
+// Admins define attributes and their values per category, tag products with values, and buyers
+// filter the product list by values with a count of matching products for each value.
+type AttributeService struct {
+	db            *gorm.DB
+	attributeRepo *repository.AttributeRepository
+	categoryRepo  *repository.CategoryRepository
+	productRepo   *repository.ProductRepository
+	cacheService  *redis.CacheService
+}
+
+// NewAttributeService creates a new attribute service
+func NewAttributeService() *AttributeService {
+	server := server.GetServer()
+	return &AttributeService{
+		db:            server.DB,
+		attributeRepo: repository.NewAttributeRepository(server.DB),
+		categoryRepo:  repository.NewCategoryRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		cacheService:  redis.NewCacheService(),
+	}
+}
+
+// GetAttributes gets all attributes with their values
+func (s *AttributeService) GetAttributes() ([]model.Attribute, error) {
+	ctx := context.Background()
+
+	var attributes []model.Attribute
+	if err := s.cacheService.GetObject(ctx, constant.AttributeList, &attributes); err == nil {
+		return attributes, nil
+	}
+
+	attributes, err := s.attributeRepo.GetAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheService.Set(ctx, constant.AttributeList, attributes, attributeCacheTTL); err != nil {
+		logger.Warnf("Failed to cache attributes: %v", err)
+	}
+	return attributes, nil
+}
+
+// GetCategoryAttributes gets the attributes that apply to a category: those defined on the category,
+// on one of its ancestors or on every category. Without a category only the latter apply.
+func (s *AttributeService) GetCategoryAttributes(categoryID *uint64) ([]model.Attribute, error) {
+	attributes, err := s.GetAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	applicable := map[uint64]bool{0: true}
+	if categoryID != nil {
+		category, err := s.categoryRepo.GetCategoryByID(*categoryID)
+		if err != nil {
+			return nil, pkgerrors.ErrCategoryNotFound
+		}
+		for _, id := range categoryutils.PathIDs(category.Path) {
+			applicable[id] = true
+		}
+	}
+
+	result := make([]model.Attribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		if applicable[attribute.CategoryID] {
+			result = append(result, attribute)
+		}
+	}
+	return result, nil
+}
+
+// CreateAttribute creates an attribute with its values (admin)
+func (s *AttributeService) CreateAttribute(req request.AttributeRequest) (*model.Attribute, error) {
+	if err := s.validateAttribute(0, req); err != nil {
+		return nil, err
+	}
+
+	attribute := &model.Attribute{
+		Code:        req.Code,
+		Name:        req.Name,
+		CategoryID:  req.CategoryID,
+		MultiValued: req.MultiValued,
+		SortOrder:   req.SortOrder,
+	}
+	for i, value := range req.Values {
+		if value.ID > 0 {
+			return nil, pkgerrors.ErrInvalidAttributeValue
+		}
+		attribute.Values = append(attribute.Values, model.AttributeValue{Value: value.Value, SortOrder: i})
+	}
+
+	if err := s.attributeRepo.CreateAttribute(attribute); err != nil {
+		return nil, err
+	}
+
+	s.invalidateAttributeCache()
+	return attribute, nil
+}
+
+// UpdateAttribute updates an attribute and its values (admin). Values with an ID are kept and renamed,
+// values without one are added, and values left out are removed from the attribute and from every product.
+func (s *AttributeService) UpdateAttribute(id uint64, req request.AttributeRequest) (*model.Attribute, error) {
+	attribute, err := s.attributeRepo.GetAttributeByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrAttributeNotFound
+	}
+	if err := s.validateAttribute(id, req); err != nil {
+		return nil, err
+	}
+
+	// 带有该属性的商品详情里会展示属性名和值，更新后清掉它们的缓存
+	productIDs, err := s.attributeRepo.GetProductIDsByAttribute(id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[uint64]bool, len(attribute.Values))
+	for _, value := range attribute.Values {
+		existing[value.ID] = true
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		attributeRepo := repository.NewAttributeRepository(tx)
+
+		if err := attributeRepo.UpdateAttribute(id, map[string]interface{}{
+			"code":         req.Code,
+			"name":         req.Name,
+			"category_id":  req.CategoryID,
+			"multi_valued": req.MultiValued,
+			"sort_order":   req.SortOrder,
+		}); err != nil {
+			return err
+		}
+
+		kept := make(map[uint64]bool, len(req.Values))
+		for i, value := range req.Values {
+			if value.ID == 0 {
+				if err := attributeRepo.CreateAttributeValue(&model.AttributeValue{
+					AttributeID: id,
+					Value:       value.Value,
+					SortOrder:   i,
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			if !existing[value.ID] || kept[value.ID] {
+				return pkgerrors.ErrInvalidAttributeValue
+			}
+			kept[value.ID] = true
+			if err := attributeRepo.UpdateAttributeValue(value.ID, value.Value, i); err != nil {
+				return err
+			}
+		}
+
+		var removed []uint64
+		for _, value := range attribute.Values {
+			if !kept[value.ID] {
+				removed = append(removed, value.ID)
+			}
+		}
+		return attributeRepo.DeleteAttributeValues(removed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateAttributeCache()
+	for _, productID := range productIDs {
+		invalidateProductCache(context.Background(), s.cacheService, productID)
+	}
+	return s.attributeRepo.GetAttributeByID(id)
+}
+
+// DeleteAttribute deletes an attribute, its values and the product tags using them (admin)
+func (s *AttributeService) DeleteAttribute(id uint64) error {
+	if _, err := s.attributeRepo.GetAttributeByID(id); err != nil {
+		return pkgerrors.ErrAttributeNotFound
+	}
+	// 删除前取出打了标签的商品，删除后这些商品详情不再展示该属性
+	productIDs, err := s.attributeRepo.GetProductIDsByAttribute(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return repository.NewAttributeRepository(tx).DeleteAttribute(id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateAttributeCache()
+	for _, productID := range productIDs {
+		invalidateProductCache(context.Background(), s.cacheService, productID)
+	}
+	return nil
+}
+
+// GetProductAttributes gets the attribute values of a product, limited to the attributes of its category
+func (s *AttributeService) GetProductAttributes(productID, categoryID uint64) ([]response.ProductAttributeResponse, error) {
+	attributes, err := s.GetCategoryAttributes(&categoryID)
+	if err != nil {
+		// 分类已删除时只返回适用于全部分类的属性
+		if !pkgerrors.IsNotFound(err) {
+			return nil, err
+		}
+		attributes, err = s.GetCategoryAttributes(nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	values, err := s.attributeRepo.GetProductAttributeValues(productID)
+	if err != nil {
+		return nil, err
+	}
+	tagged := make(map[uint64]bool, len(values))
+	for _, value := range values {
+		tagged[value.ValueID] = true
+	}
+
+	result := []response.ProductAttributeResponse{}
+	for _, attribute := range attributes {
+		item := response.ProductAttributeResponse{
+			ID:   attribute.ID,
+			Code: attribute.Code,
+			Name: attribute.Name,
+		}
+		for _, value := range attribute.Values {
+			if tagged[value.ID] {
+				item.Values = append(item.Values, response.AttributeValueResponse{ID: value.ID, Value: value.Value})
+			}
+		}
+		if len(item.Values) > 0 {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// SetProductAttributes replaces the attribute values of a product (admin). Every value must belong to an
+// attribute of the product's category, and a single-valued attribute takes at most one value.
+func (s *AttributeService) SetProductAttributes(productID uint64, req request.ProductAttributesRequest) ([]response.ProductAttributeResponse, error) {
+	product, err := s.productRepo.GetProductByID(productID)
+	if err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+
+	attributes, err := s.GetCategoryAttributes(&product.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	valueAttributes := make(map[uint64]*model.Attribute)
+	for i := range attributes {
+		for _, value := range attributes[i].Values {
+			valueAttributes[value.ID] = &attributes[i]
+		}
+	}
+
+	values := make([]model.ProductAttributeValue, 0, len(req.ValueIDs))
+	seen := make(map[uint64]bool, len(req.ValueIDs))
+	perAttribute := make(map[uint64]int)
+	for _, valueID := range req.ValueIDs {
+		attribute, ok := valueAttributes[valueID]
+		if !ok {
+			return nil, pkgerrors.ErrInvalidAttributeValue
+		}
+		if seen[valueID] {
+			continue
+		}
+		seen[valueID] = true
+		perAttribute[attribute.ID]++
+		if !attribute.MultiValued && perAttribute[attribute.ID] > 1 {
+			return nil, pkgerrors.ErrInvalidAttributeValue
+		}
+		values = append(values, model.ProductAttributeValue{
+			ProductID:   productID,
+			AttributeID: attribute.ID,
+			ValueID:     valueID,
+		})
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return repository.NewAttributeRepository(tx).ReplaceProductAttributeValues(productID, values)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateProductCache(context.Background(), s.cacheService, productID)
+	return s.GetProductAttributes(productID, product.CategoryID)
+}
+
+// GroupValues groups selected attribute values by attribute for filtering the product list.
+// Values of the same attribute match any of them; different attributes must all match. Unknown values are ignored.
+func (s *AttributeService) GroupValues(valueIDs []uint64) ([][]uint64, error) {
+	if len(valueIDs) == 0 {
+		return nil, nil
+	}
+	attributes, err := s.GetAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := groupAttributeValues(attributes, valueIDs)
+	valueGroups := make([][]uint64, 0, len(groups))
+	for _, attribute := range attributes {
+		if group, ok := groups[attribute.ID]; ok {
+			valueGroups = append(valueGroups, group)
+		}
+	}
+	return valueGroups, nil
+}
+
+// GetFacets gets the attribute filters of the product list with the number of products for each value.
+// The count of a value applies the category, recommend and selected values of the other attributes,
+// so that selecting another value of the same attribute widens the list by that count.
+func (s *AttributeService) GetFacets(categoryID *uint64, recommend *bool, valueIDs []uint64) ([]*response.AttributeFacetResponse, error) {
+	ctx := context.Background()
+	cacheKey := facetCacheKey(categoryID, recommend, valueIDs)
+
+	var facets []*response.AttributeFacetResponse
+	if err := s.cacheService.GetObject(ctx, cacheKey, &facets); err == nil {
+		return facets, nil
+	}
+
+	allAttributes, err := s.GetAttributes()
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := s.GetCategoryAttributes(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	groups := groupAttributeValues(allAttributes, valueIDs)
+
+	facets = make([]*response.AttributeFacetResponse, 0, len(attributes))
+	for _, attribute := range attributes {
+		// 同一属性的已选值不作为条件
+		var valueGroups [][]uint64
+		for _, other := range allAttributes {
+			if group, ok := groups[other.ID]; ok && other.ID != attribute.ID {
+				valueGroups = append(valueGroups, group)
+			}
+		}
+		counts, err := s.attributeRepo.CountProductsByValue(attribute.ID, categoryID, recommend, valueGroups)
+		if err != nil {
+			return nil, err
+		}
+		countMap := make(map[uint64]int64, len(counts))
+		for _, count := range counts {
+			countMap[count.ValueID] = count.Count
+		}
+
+		selected := make(map[uint64]bool)
+		for _, valueID := range groups[attribute.ID] {
+			selected[valueID] = true
+		}
+		facet := &response.AttributeFacetResponse{
+			ID:          attribute.ID,
+			Code:        attribute.Code,
+			Name:        attribute.Name,
+			MultiValued: attribute.MultiValued,
+			Values:      make([]response.FacetValueResponse, 0, len(attribute.Values)),
+		}
+		for _, value := range attribute.Values {
+			facet.Values = append(facet.Values, response.FacetValueResponse{
+				ID:       value.ID,
+				Value:    value.Value,
+				Count:    countMap[value.ID],
+				Selected: selected[value.ID],
+			})
+		}
+		facets = append(facets, facet)
+	}
+
+	if err := s.cacheService.Set(ctx, cacheKey, facets, facetCacheTTL); err != nil {
+		logger.Warnf("Failed to cache facets: %v", err)
+	}
+	return facets, nil
+}
+
+// validateAttribute 校验属性编码唯一、所属分类存在、值不重复
+func (s *AttributeService) validateAttribute(id uint64, req request.AttributeRequest) error {
+	exists, err := s.attributeRepo.CodeExists(req.Code, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return pkgerrors.ErrAttributeCodeExists
+	}
+
+	if req.CategoryID > 0 {
+		if _, err := s.categoryRepo.GetCategoryByID(req.CategoryID); err != nil {
+			return pkgerrors.ErrCategoryNotFound
+		}
+	}
+
+	names := make(map[string]bool, len(req.Values))
+	for _, value := range req.Values {
+		if names[value.Value] {
+			return pkgerrors.ErrInvalidAttributeValue
+		}
+		names[value.Value] = true
+	}
+	return nil
+}
+
+// invalidateAttributeCache 清除属性列表缓存，筛选项计数缓存按短时过期
+func (s *AttributeService) invalidateAttributeCache() {
+	s.cacheService.Delete(context.Background(), constant.AttributeList)
+}
+
+// groupAttributeValues 将属性值按所属属性分组并去重，未知的值忽略
+func groupAttributeValues(attributes []model.Attribute, valueIDs []uint64) map[uint64][]uint64 {
+	valueAttributes := make(map[uint64]uint64)
+	for _, attribute := range attributes {
+		for _, value := range attribute.Values {
+			valueAttributes[value.ID] = attribute.ID
+		}
+	}
+
+	groups := make(map[uint64][]uint64)
+	seen := make(map[uint64]bool, len(valueIDs))
+	for _, valueID := range valueIDs {
+		attributeID, ok := valueAttributes[valueID]
+		if !ok || seen[valueID] {
+			continue
+		}
+		seen[valueID] = true
+		groups[attributeID] = append(groups[attributeID], valueID)
+	}
+	return groups
+}
+
+// facetCacheKey 筛选项计数的缓存键，由分类、推荐和排序后的已选值组成
+func facetCacheKey(categoryID *uint64, recommend *bool, valueIDs []uint64) string {
+	category := "all"
+	if categoryID != nil {
+		category = strconv.FormatUint(*categoryID, 10)
+	}
+	rec := "any"
+	if recommend != nil {
+		rec = strconv.FormatBool(*recommend)
+	}
+
+	sorted := append([]uint64(nil), valueIDs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	values := make([]string, 0, len(sorted))
+	for i, id := range sorted {
+		if i > 0 && id == sorted[i-1] {
+			continue
+		}
+		values = append(values, strconv.FormatUint(id, 10))
+	}
+	return fmt.Sprintf("%s%s:%s:%s", constant.AttributeFacets, category, rec, strings.Join(values, ","))
+}
@@ -2,72 +2,561 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/colinjuang/shop-go/internal/app/request"
 	"github.com/colinjuang/shop-go/internal/app/response"
 	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/pkg/logger"
 	"github.com/colinjuang/shop-go/internal/pkg/minio"
 	"github.com/colinjuang/shop-go/internal/pkg/redis"
 	"github.com/colinjuang/shop-go/internal/repository"
 	"github.com/colinjuang/shop-go/internal/server"
+	"gorm.io/gorm"
 )
 
-// BannerService handles business logic for the banner page
+const (
+	// bannerCacheTTL 轮播图缓存的最长时间，下一个开始或结束时间更早时缓存到该时间
+	bannerCacheTTL = 10 * time.Minute
+	// bannerStatsTTL Redis中每日计数的保留时间，同步任务在此之前写入MySQL
+	bannerStatsTTL = 3 * 24 * time.Hour
+	// bannerStatsDefaultDays 未指定日期范围时统计的天数
+	bannerStatsDefaultDays = 7
+)
+
+// BannerService handles business logic for the banner page. Banners are shown within their time window
+// to their audience; impressions and clicks are counted per day in Redis and synced to MySQL by a job.
 type BannerService struct {
+	db            *gorm.DB
 	bannerRepo    *repository.BannerRepository
 	promotionRepo *repository.PromotionRepository
 	productRepo   *repository.ProductRepository
+	categoryRepo  *repository.CategoryRepository
+	userRepo      *repository.UserRepository
+	orderRepo     *repository.OrderRepository
 	cacheService  *redis.CacheService
+	redisClient   *redis.Client
 }
 
 // NewBannerService creates a new banner service
 func NewBannerService() *BannerService {
 	server := server.GetServer()
 	return &BannerService{
-		bannerRepo:   repository.NewBannerRepository(server.DB),
-		cacheService: redis.NewCacheService(),
+		db:            server.DB,
+		bannerRepo:    repository.NewBannerRepository(server.DB),
+		promotionRepo: repository.NewPromotionRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		categoryRepo:  repository.NewCategoryRepository(server.DB),
+		userRepo:      repository.NewUserRepository(server.DB),
+		orderRepo:     repository.NewOrderRepository(server.DB),
+		cacheService:  redis.NewCacheService(),
+		redisClient:   redis.GetClient(),
 	}
 }
 
-// GetBanners gets all banners
-func (s *BannerService) GetBanners() ([]*response.BannerResponse, error) {
+// bannerViewer 浏览轮播图的用户，新用户在有按新用户投放的轮播图时才查询
+type bannerViewer struct {
+	userID  uint64
+	member  bool
+	city    string
+	newUser *bool
+}
+
+// GetBanners gets the banners shown now to the viewer (userID 0 for guests). The city is where the viewer
+// is browsing from and defaults to the city of the user's profile.
+func (s *BannerService) GetBanners(userID uint64, city string) ([]*response.BannerResponse, error) {
 	ctx := context.Background()
+	now := time.Now()
 
-	// Try to get from cache
-	var bannerResponses []*response.BannerResponse
-	err := s.cacheService.GetObject(ctx, constant.HomeBanners, &bannerResponses)
-	if err == nil {
-		return bannerResponses, nil
+	banners, err := s.getActiveBanners(ctx, now)
+	if err != nil {
+		return nil, err
 	}
 
-	// If not in cache, get from database
-	banners, err := s.bannerRepo.GetBanners()
+	viewer, err := s.getViewer(userID, city)
 	if err != nil {
 		return nil, err
 	}
 
+	bannerResponses := make([]*response.BannerResponse, 0, len(banners))
+	for _, banner := range banners {
+		// 缓存期间可能有轮播图到达结束时间
+		if !bannerActive(&banner, now) || !matchCity(banner.Cities, viewer.city) {
+			continue
+		}
+		ok, err := s.matchAudience(banner.Audience, viewer)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		bannerResponses = append(bannerResponses, toBannerResponse(&banner))
+	}
+
+	s.recordImpressions(ctx, bannerResponses)
+	return bannerResponses, nil
+}
+
+// RecordClick counts a click on a banner for today's stats
+func (s *BannerService) RecordClick(ctx context.Context, bannerID uint64) error {
+	if _, err := s.bannerRepo.GetBannerByID(bannerID); err != nil {
+		return pkgerrors.ErrBannerNotFound
+	}
+
+	key := constant.BannerStats + today().Format(dateLayout)
+	if _, err := s.redisClient.HashIncrBy(ctx, key, fmt.Sprintf("%d:click", bannerID), 1); err != nil {
+		return err
+	}
+	return s.redisClient.Expire(ctx, key, bannerStatsTTL)
+}
+
+// SyncStats writes today's and yesterday's impression and click counts from Redis to MySQL
+func (s *BannerService) SyncStats(ctx context.Context) error {
+	for _, date := range []time.Time{today().AddDate(0, 0, -1), today()} {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		counts, err := s.getCachedStats(ctx, date)
+		if err != nil {
+			return err
+		}
+		stats := make([]model.BannerDailyStat, 0, len(counts))
+		for _, stat := range counts {
+			stats = append(stats, *stat)
+		}
+		if err := s.bannerRepo.UpsertDailyStats(stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBannerStats gets the daily impressions and clicks of a banner between two dates (2006-01-02), the last 7 days by default (admin).
+// Counts not yet synced to MySQL are read from Redis.
+func (s *BannerService) GetBannerStats(ctx context.Context, bannerID uint64, start, end string) (*response.BannerStatsResponse, error) {
+	if _, err := s.bannerRepo.GetBannerByID(bannerID); err != nil {
+		return nil, pkgerrors.ErrBannerNotFound
+	}
+
+	endDate := today()
+	if end != "" {
+		date, err := time.ParseInLocation(dateLayout, end, time.Local)
+		if err != nil {
+			return nil, pkgerrors.ErrInvalidBanner
+		}
+		endDate = date
+	}
+	startDate := endDate.AddDate(0, 0, -(bannerStatsDefaultDays - 1))
+	if start != "" {
+		date, err := time.ParseInLocation(dateLayout, start, time.Local)
+		if err != nil {
+			return nil, pkgerrors.ErrInvalidBanner
+		}
+		startDate = date
+	}
+	if startDate.After(endDate) {
+		return nil, pkgerrors.ErrInvalidBanner
+	}
+
+	stats, err := s.bannerRepo.GetDailyStats(bannerID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	statMap := make(map[string]model.BannerDailyStat, len(stats))
+	for _, stat := range stats {
+		statMap[stat.Date.Format(dateLayout)] = stat
+	}
+
+	// 最近两天的计数可能还没同步
+	for _, date := range []time.Time{today().AddDate(0, 0, -1), today()} {
+		if date.Before(startDate) || date.After(endDate) {
+			continue
+		}
+		counts, err := s.getCachedStats(ctx, date)
+		if err != nil {
+			logger.Warnf("Failed to get cached banner stats: %v", err)
+			continue
+		}
+		if cached, ok := counts[bannerID]; ok {
+			day := date.Format(dateLayout)
+			stat := statMap[day]
+			stat.Impressions = max(stat.Impressions, cached.Impressions)
+			stat.Clicks = max(stat.Clicks, cached.Clicks)
+			statMap[day] = stat
+		}
+	}
+
+	result := &response.BannerStatsResponse{
+		BannerID: bannerID,
+		Days:     []response.BannerDailyStatResponse{},
+	}
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		day := date.Format(dateLayout)
+		stat := statMap[day]
+		result.Impressions += stat.Impressions
+		result.Clicks += stat.Clicks
+		result.Days = append(result.Days, response.BannerDailyStatResponse{
+			Date:        day,
+			Impressions: stat.Impressions,
+			Clicks:      stat.Clicks,
+			ClickRate:   clickRate(stat.Clicks, stat.Impressions),
+		})
+	}
+	result.ClickRate = clickRate(result.Clicks, result.Impressions)
+	return result, nil
+}
+
+// GetAdminBanners gets all banners including scheduled, ended and disabled ones, optionally of one status (admin)
+func (s *BannerService) GetAdminBanners(status *int) ([]model.Banner, error) {
+	banners, err := s.bannerRepo.GetBanners(status)
+	if err != nil {
+		return nil, err
+	}
 	minioClient := minio.GetClient()
-	for i, banner := range banners {
-		banners[i].ImageUrl = minioClient.GetFileURL(banner.ImageUrl)
+	for i := range banners {
+		banners[i].ImageUrl = minioClient.GetFileURL(banners[i].ImageUrl)
+	}
+	return banners, nil
+}
+
+// CreateBanner creates a banner (admin)
+func (s *BannerService) CreateBanner(req request.BannerRequest) (*model.Banner, error) {
+	banner := &model.Banner{}
+	if err := s.fillBanner(banner, req); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewBannerRepository(tx).CreateBanner(banner); err != nil {
+			return err
+		}
+		return trackObjectReferences(repository.NewProductMediaRepository(tx), nil, []string{banner.ImageUrl})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateBanners()
+	return banner, nil
+}
+
+// UpdateBanner updates a banner (admin)
+func (s *BannerService) UpdateBanner(id uint64, req request.BannerRequest) (*model.Banner, error) {
+	banner, err := s.bannerRepo.GetBannerByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrBannerNotFound
+	}
+	oldImage := banner.ImageUrl
+	if err := s.fillBanner(banner, req); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewBannerRepository(tx).UpdateBanner(banner); err != nil {
+			return err
+		}
+		return trackObjectReferences(repository.NewProductMediaRepository(tx), []string{oldImage}, []string{banner.ImageUrl})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateBanners()
+	return banner, nil
+}
+
+// DeleteBanner deletes a banner, keeping its stats (admin)
+func (s *BannerService) DeleteBanner(id uint64) error {
+	banner, err := s.bannerRepo.GetBannerByID(id)
+	if err != nil {
+		return pkgerrors.ErrBannerNotFound
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewBannerRepository(tx).DeleteBanner(id); err != nil {
+			return err
+		}
+		return trackObjectReferences(repository.NewProductMediaRepository(tx), []string{banner.ImageUrl}, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateBanners()
+	return nil
+}
+
+// getActiveBanners 获取当前展示时间段内的启用轮播图，缓存到最近的开始或结束时间，保证按时上下线
+func (s *BannerService) getActiveBanners(ctx context.Context, now time.Time) ([]model.Banner, error) {
+	var banners []model.Banner
+	if err := s.cacheService.GetObject(ctx, constant.HomeBanners, &banners); err == nil {
+		return banners, nil
 	}
-	// Cache for 1 minute
-	err = s.cacheService.Set(ctx, constant.HomeBanners, banners, 1*time.Minute)
+
+	unfinished, err := s.bannerRepo.GetUnfinishedBanners(now)
 	if err != nil {
+		return nil, err
+	}
+
+	minioClient := minio.GetClient()
+	ttl := bannerCacheTTL
+	banners = make([]model.Banner, 0, len(unfinished))
+	for _, banner := range unfinished {
+		for _, boundary := range []*time.Time{banner.StartAt, banner.EndAt} {
+			if boundary != nil && boundary.After(now) && boundary.Sub(now) < ttl {
+				ttl = boundary.Sub(now)
+			}
+		}
+		if !bannerActive(&banner, now) {
+			continue
+		}
+		banner.ImageUrl = minioClient.GetFileURL(banner.ImageUrl)
+		banners = append(banners, banner)
+	}
+
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	if err := s.cacheService.Set(ctx, constant.HomeBanners, banners, ttl); err != nil {
 		logger.Warnf("Failed to cache banners: %v", err)
 	}
+	return banners, nil
+}
+
+// getViewer 获取浏览用户的会员身份和城市，游客视为新用户
+func (s *BannerService) getViewer(userID uint64, city string) (*bannerViewer, error) {
+	viewer := &bannerViewer{userID: userID, city: city}
+	if userID == 0 {
+		newUser := true
+		viewer.newUser = &newUser
+		return viewer, nil
+	}
 
-	bannerResponses = make([]*response.BannerResponse, len(banners))
-	for i, banner := range banners {
-		bannerResponses[i] = &response.BannerResponse{
-			ID:        banner.ID,
-			Title:     banner.Title,
-			ImageUrl:  banner.ImageUrl,
-			ProductID: banner.ProductID,
-			SortOrder: banner.SortOrder,
-			CreatedAt: banner.CreatedAt,
-			UpdatedAt: banner.UpdatedAt,
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	viewer.member = user.TierID > 0
+	if viewer.city == "" {
+		viewer.city = user.City
+	}
+	return viewer, nil
+}
+
+// matchAudience 用户是否属于轮播图的投放人群
+func (s *BannerService) matchAudience(audience int, viewer *bannerViewer) (bool, error) {
+	switch audience {
+	case model.BannerAudienceNewUser:
+		if viewer.newUser == nil {
+			paid, err := s.orderRepo.HasPaidOrder(viewer.userID)
+			if err != nil {
+				return false, err
+			}
+			newUser := !paid
+			viewer.newUser = &newUser
 		}
+		return *viewer.newUser, nil
+	case model.BannerAudienceMember:
+		return viewer.member, nil
+	default:
+		return true, nil
 	}
-	return bannerResponses, nil
+}
+
+// recordImpressions 返回给前端的轮播图各计一次曝光，计数失败不影响展示
+func (s *BannerService) recordImpressions(ctx context.Context, banners []*response.BannerResponse) {
+	if len(banners) == 0 {
+		return
+	}
+	key := constant.BannerStats + today().Format(dateLayout)
+	for _, banner := range banners {
+		if _, err := s.redisClient.HashIncrBy(ctx, key, fmt.Sprintf("%d:view", banner.ID), 1); err != nil {
+			logger.Warnf("Failed to record banner impression: %v", err)
+			return
+		}
+	}
+	if err := s.redisClient.Expire(ctx, key, bannerStatsTTL); err != nil {
+		logger.Warnf("Failed to set banner stats expiration: %v", err)
+	}
+}
+
+// getCachedStats 读取Redis中某天各轮播图的曝光和点击数
+func (s *BannerService) getCachedStats(ctx context.Context, date time.Time) (map[uint64]*model.BannerDailyStat, error) {
+	fields, err := s.redisClient.HashGetAll(ctx, constant.BannerStats+date.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stats := make(map[uint64]*model.BannerDailyStat)
+	for field, value := range fields {
+		idStr, kind, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		bannerID, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		stat, ok := stats[bannerID]
+		if !ok {
+			stat = &model.BannerDailyStat{BannerID: bannerID, Date: date, UpdatedAt: now}
+			stats[bannerID] = stat
+		}
+		switch kind {
+		case "view":
+			stat.Impressions = count
+		case "click":
+			stat.Clicks = count
+		}
+	}
+	return stats, nil
+}
+
+// fillBanner 校验请求并填充轮播图
+func (s *BannerService) fillBanner(banner *model.Banner, req request.BannerRequest) error {
+	objectName, ok := toObjectName(req.ImageUrl)
+	if !ok {
+		return pkgerrors.ErrInvalidBanner
+	}
+	if req.StartAt != nil && req.EndAt != nil && !req.EndAt.After(*req.StartAt) {
+		return pkgerrors.ErrInvalidBanner
+	}
+	if err := s.validateLink(req.LinkType, req.LinkValue, req.LinkAppID); err != nil {
+		return err
+	}
+
+	cities := make([]string, 0, len(req.Cities))
+	for _, city := range req.Cities {
+		if city = strings.TrimSpace(city); city != "" {
+			cities = append(cities, city)
+		}
+	}
+
+	banner.Title = req.Title
+	banner.ImageUrl = objectName
+	banner.LinkType = req.LinkType
+	banner.LinkValue = strings.TrimSpace(req.LinkValue)
+	banner.LinkAppID = strings.TrimSpace(req.LinkAppID)
+	if req.LinkType != model.BannerLinkMiniProgram {
+		banner.LinkAppID = ""
+	}
+	if req.LinkType == model.BannerLinkNone {
+		banner.LinkValue = ""
+	}
+	banner.StartAt = req.StartAt
+	banner.EndAt = req.EndAt
+	banner.Audience = req.Audience
+	banner.Cities = strings.Join(cities, ",")
+	banner.Status = model.BannerStatusEnabled
+	if req.Status != nil {
+		banner.Status = *req.Status
+	}
+	banner.SortOrder = req.SortOrder
+	return nil
+}
+
+// validateLink 校验跳转目标存在：商品、分类、促销按ID查找，页面路径以/开头，其他小程序需要AppID
+func (s *BannerService) validateLink(linkType int, value, appID string) error {
+	value = strings.TrimSpace(value)
+	switch linkType {
+	case model.BannerLinkProduct, model.BannerLinkCategory, model.BannerLinkPromotion:
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return pkgerrors.ErrInvalidBanner
+		}
+		switch linkType {
+		case model.BannerLinkProduct:
+			if _, err := s.productRepo.GetProductByID(id); err != nil {
+				return pkgerrors.ErrProductNotFound
+			}
+		case model.BannerLinkCategory:
+			if _, err := s.categoryRepo.GetCategoryByID(id); err != nil {
+				return pkgerrors.ErrCategoryNotFound
+			}
+		default:
+			if _, err := s.promotionRepo.GetPromotionByID(id); err != nil {
+				return pkgerrors.ErrInvalidBanner
+			}
+		}
+	case model.BannerLinkPage:
+		if !strings.HasPrefix(value, "/") {
+			return pkgerrors.ErrInvalidBanner
+		}
+	case model.BannerLinkMiniProgram:
+		if strings.TrimSpace(appID) == "" {
+			return pkgerrors.ErrInvalidBanner
+		}
+	}
+	return nil
+}
+
+// invalidateBanners 清除首页轮播图缓存
+func (s *BannerService) invalidateBanners() {
+	s.cacheService.Delete(context.Background(), constant.HomeBanners)
+}
+
+// toBannerResponse 转换为轮播图响应
+func toBannerResponse(banner *model.Banner) *response.BannerResponse {
+	bannerResponse := &response.BannerResponse{
+		ID:        banner.ID,
+		Title:     banner.Title,
+		ImageUrl:  banner.ImageUrl,
+		LinkType:  banner.LinkType,
+		LinkValue: banner.LinkValue,
+		LinkAppID: banner.LinkAppID,
+		SortOrder: banner.SortOrder,
+		CreatedAt: banner.CreatedAt,
+		UpdatedAt: banner.UpdatedAt,
+	}
+	if banner.LinkType == model.BannerLinkProduct {
+		bannerResponse.ProductID, _ = strconv.ParseUint(banner.LinkValue, 10, 64)
+	}
+	return bannerResponse
+}
+
+// bannerActive 轮播图当前是否在展示时间段内
+func bannerActive(banner *model.Banner, now time.Time) bool {
+	if banner.StartAt != nil && banner.StartAt.After(now) {
+		return false
+	}
+	return banner.EndAt == nil || banner.EndAt.After(now)
+}
+
+// matchCity 城市是否在投放城市中，未限定城市时都匹配；比较时忽略末尾的“市”，未知城市的用户不匹配限定城市的轮播图
+func matchCity(cities, city string) bool {
+	if cities == "" {
+		return true
+	}
+	city = strings.TrimSuffix(strings.TrimSpace(city), "市")
+	if city == "" {
+		return false
+	}
+	for _, c := range strings.Split(cities, ",") {
+		if strings.TrimSuffix(strings.TrimSpace(c), "市") == city {
+			return true
+		}
+	}
+	return false
+}
+
+// clickRate 点击率，保留四位小数
+func clickRate(clicks, impressions int64) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return math.Round(float64(clicks)/float64(impressions)*10000) / 10000
 }
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	"gorm.io/gorm"
+)
+
+// BundleService handles bundle products made of component products
+type BundleService struct {
+	db            *gorm.DB
+	bundleRepo    *repository.BundleRepository
+	productRepo   *repository.ProductRepository
+	inventoryRepo *repository.InventoryRepository
+	cacheService  *redis.CacheService
+}
+
+// NewBundleService creates a new bundle service
+func NewBundleService() *BundleService {
+	server := server.GetServer()
+	return &BundleService{
+		db:            server.DB,
+		bundleRepo:    repository.NewBundleRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		inventoryRepo: repository.NewInventoryRepository(server.DB),
+		cacheService:  redis.NewCacheService(),
+	}
+}
+
+// GetBundleItems gets the components of a bundle product with their current stock
+func (s *BundleService) GetBundleItems(bundleID uint64) ([]response.BundleItemResponse, error) {
+	items, err := s.bundleRepo.GetBundleItems(bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uint64, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	responses := make([]response.BundleItemResponse, 0, len(items))
+	for _, item := range items {
+		product, ok := productMap[item.ProductID]
+		if !ok {
+			continue
+		}
+		responses = append(responses, response.BundleItemResponse{
+			ProductID:  product.ID,
+			Name:       product.Name,
+			ImageUrl:   product.ImageUrl,
+			Price:      product.Price,
+			Quantity:   item.Quantity,
+			StockCount: product.StockCount,
+		})
+	}
+	return responses, nil
+}
+
+// SetBundleItems turns a product into a bundle of the given components, replacing any previous components (admin).
+// The bundle is priced by its own product price; its stock is derived from the components.
+func (s *BundleService) SetBundleItems(bundleID uint64, req request.SetBundleItemsRequest) error {
+	if _, err := s.productRepo.GetProductByID(bundleID); err != nil {
+		return pkgerrors.ErrProductNotFound
+	}
+
+	// 已是其他组合商品的组件时不能再设为组合商品，避免嵌套
+	isComponent, err := s.bundleRepo.IsBundleComponent(bundleID)
+	if err != nil {
+		return err
+	}
+	if isComponent {
+		return pkgerrors.ErrInvalidBundleItems
+	}
+
+	productIDs := make([]uint64, 0, len(req.Items))
+	seen := make(map[uint64]bool, len(req.Items))
+	for _, item := range req.Items {
+		if item.ProductID == bundleID || seen[item.ProductID] {
+			return pkgerrors.ErrInvalidBundleItems
+		}
+		seen[item.ProductID] = true
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return err
+	}
+	if len(products) != len(productIDs) {
+		return pkgerrors.ErrInvalidBundleItems
+	}
+	for _, product := range products {
+		if product.IsBundle {
+			return pkgerrors.ErrInvalidBundleItems
+		}
+	}
+
+	items := make([]model.BundleItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = model.BundleItem{
+			BundleID:  bundleID,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			SortOrder: i,
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewBundleRepository(tx).ReplaceBundleItems(bundleID, items); err != nil {
+			return err
+		}
+		return repository.NewProductRepository(tx).SetProductBundle(bundleID, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateProductCache(context.Background(), s.cacheService, bundleID)
+	return nil
+}
+
+// RemoveBundle turns a bundle back into a normal product (admin). Existing orders keep their component lines.
+func (s *BundleService) RemoveBundle(bundleID uint64) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewBundleRepository(tx).DeleteBundleItems(bundleID); err != nil {
+			return err
+		}
+		return repository.NewProductRepository(tx).SetProductBundle(bundleID, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateProductCache(context.Background(), s.cacheService, bundleID)
+	return nil
+}
+
+// FillStock replaces the stock of bundle products with the number of complete bundles the component stock allows.
+// Normal products are left unchanged.
+func (s *BundleService) FillStock(products ...*model.Product) error {
+	return s.FillStockOn(nil, products...)
+}
+
+// FillStockOn is FillStock for delivery on deliveryDate: units of batches that expire by then are excluded
+// from the stock of normal products and of bundle components. With a nil date it is the same as FillStock.
+func (s *BundleService) FillStockOn(deliveryDate *time.Time, products ...*model.Product) error {
+	var bundleIDs, productIDs []uint64
+	for _, product := range products {
+		if product.IsBundle {
+			bundleIDs = append(bundleIDs, product.ID)
+		} else {
+			productIDs = append(productIDs, product.ID)
+		}
+	}
+
+	if deliveryDate != nil {
+		expiring, err := s.expiringQuantities(productIDs, *deliveryDate)
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			if !product.IsBundle {
+				product.StockCount = max(product.StockCount-expiring[product.ID], 0)
+			}
+		}
+	}
+	if len(bundleIDs) == 0 {
+		return nil
+	}
+
+	items, err := s.bundleRepo.GetBundleItemsByBundleIDs(bundleIDs)
+	if err != nil {
+		return err
+	}
+	componentIDs := make([]uint64, len(items))
+	for i, item := range items {
+		componentIDs[i] = item.ProductID
+	}
+	components, err := s.productRepo.GetProductsByIDs(componentIDs)
+	if err != nil {
+		return err
+	}
+	var expiring map[uint64]int
+	if deliveryDate != nil {
+		if expiring, err = s.expiringQuantities(componentIDs, *deliveryDate); err != nil {
+			return err
+		}
+	}
+	stocks := make(map[uint64]int, len(components))
+	for _, component := range components {
+		stocks[component.ID] = max(component.StockCount-expiring[component.ID], 0)
+	}
+
+	itemsByBundle := make(map[uint64][]model.BundleItem)
+	for _, item := range items {
+		itemsByBundle[item.BundleID] = append(itemsByBundle[item.BundleID], item)
+	}
+
+	for _, product := range products {
+		if product.IsBundle {
+			product.StockCount = bundleStock(itemsByBundle[product.ID], stocks)
+		}
+	}
+	return nil
+}
+
+// expiringQuantities 商品在配送日期当天及之前到期的批次剩余数量
+func (s *BundleService) expiringQuantities(productIDs []uint64, deliveryDate time.Time) (map[uint64]int, error) {
+	quantities, err := s.inventoryRepo.GetExpiringQuantities(productIDs, dateOf(deliveryDate))
+	if err != nil {
+		return nil, err
+	}
+	expiring := make(map[uint64]int, len(quantities))
+	for _, quantity := range quantities {
+		expiring[quantity.ProductID] = quantity.Quantity
+	}
+	return expiring, nil
+}
+
+// bundleStock 按组件库存计算可组成的组合商品套数，没有组件时为0
+func bundleStock(items []model.BundleItem, stocks map[uint64]int) int {
+	if len(items) == 0 {
+		return 0
+	}
+	stock := -1
+	for _, item := range items {
+		n := stocks[item.ProductID] / item.Quantity
+		if stock < 0 || n < stock {
+			stock = n
+		}
+	}
+	return stock
+}
+
+// expandBundles 为组合商品行生成组件行。组件行单价为0，组合商品行扣除促销优惠后的成交金额按组件原价占比分摊到组件行，
+// 尾差计入最后一个组件，部分退款时按分摊金额退还
+func expandBundles(db *gorm.DB, items []model.OrderItem) error {
+	var bundleIDs []uint64
+	for _, item := range items {
+		if item.IsBundle {
+			bundleIDs = append(bundleIDs, item.ProductID)
+		}
+	}
+	if len(bundleIDs) == 0 {
+		return nil
+	}
+
+	bundleItems, err := repository.NewBundleRepository(db).GetBundleItemsByBundleIDs(bundleIDs)
+	if err != nil {
+		return err
+	}
+	productIDs := make([]uint64, len(bundleItems))
+	itemsByBundle := make(map[uint64][]model.BundleItem)
+	for i, item := range bundleItems {
+		productIDs[i] = item.ProductID
+		itemsByBundle[item.BundleID] = append(itemsByBundle[item.BundleID], item)
+	}
+	products, err := repository.NewProductRepository(db).GetProductsByIDs(productIDs)
+	if err != nil {
+		return err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	for i := range items {
+		if !items[i].IsBundle {
+			continue
+		}
+		parts := itemsByBundle[items[i].ProductID]
+		if len(parts) == 0 {
+			return pkgerrors.ErrOutOfStock
+		}
+
+		var base float64
+		for _, part := range parts {
+			product, ok := productMap[part.ProductID]
+			if !ok {
+				return pkgerrors.ErrProductNotFound
+			}
+			base += product.Price * float64(part.Quantity)
+		}
+
+		amount := priceutils.Round(items[i].Price*float64(items[i].Quantity) - items[i].PromotionDiscount)
+		remaining := amount
+		components := make([]model.OrderItem, len(parts))
+		for j, part := range parts {
+			product := productMap[part.ProductID]
+			allocated := remaining
+			if j < len(parts)-1 {
+				allocated = 0
+				if base > 0 {
+					allocated = min(priceutils.Round(amount*product.Price*float64(part.Quantity)/base), remaining)
+				}
+			}
+			remaining = priceutils.Round(remaining - allocated)
+
+			components[j] = model.OrderItem{
+				ProductID:     product.ID,
+				Quantity:      part.Quantity * items[i].Quantity,
+				Price:         0,
+				OriginalPrice: product.Price,
+				Name:          product.Name,
+				ImageUrl:      product.ImageUrl,
+				Allocated:     allocated,
+			}
+		}
+		items[i].Components = components
+	}
+	return nil
+}
@@ -1,47 +1,80 @@
 package service
 
 import (
+	"errors"
+
 	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/model"
 	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/pkg/minio"
 	"github.com/colinjuang/shop-go/internal/repository"
 	"github.com/colinjuang/shop-go/internal/server"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	"gorm.io/gorm"
 )
 
 // CartService handles business logic for cart items
 type CartService struct {
-	cartRepo    *repository.CartRepository
-	productRepo *repository.ProductRepository
+	db             *gorm.DB
+	cartRepo       *repository.CartRepository
+	productRepo    *repository.ProductRepository
+	bundleService  *BundleService
+	limitService   *PurchaseLimitService
+	pricingService *PricingService
 }
 
 // NewCartService creates a new cart service
 func NewCartService() *CartService {
 	server := server.GetServer()
 	return &CartService{
-		cartRepo:    repository.NewCartRepository(server.DB),
-		productRepo: repository.NewProductRepository(server.DB),
+		db:             server.DB,
+		cartRepo:       repository.NewCartRepository(server.DB),
+		productRepo:    repository.NewProductRepository(server.DB),
+		bundleService:  NewBundleService(),
+		limitService:   NewPurchaseLimitService(),
+		pricingService: NewPricingService(),
 	}
 }
 
-// AddToCart adds a product to the cart
-func (s *CartService) AddToCart(userID uint64, productID uint64, quantity int) error {
+// AddToCart adds a product to the cart. Lines of the same product with different blessings are kept apart.
+func (s *CartService) AddToCart(userID uint64, productID uint64, quantity int, blessing string) error {
 	// 检查商品是否存在
 	product, err := s.productRepo.GetProductByID(productID)
 	if err != nil {
 		return err
 	}
+	if product.Status != 1 {
+		return pkgerrors.ErrProductOffSale
+	}
 
-	// 检查库存
-	if product.StockCount < quantity {
+	// 检查库存，组合商品按组件库存计算，购物车中同一项已有的数量一并计入
+	if err := s.bundleService.FillStock(product); err != nil {
+		return err
+	}
+	total := quantity
+	existing, err := s.cartRepo.GetCartItem(userID, productID, blessing)
+	if err == nil {
+		total += existing.Quantity
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if product.StockCount < total {
 		return pkgerrors.ErrOutOfStock
 	}
 
-	return s.cartRepo.AddToCart(userID, productID, quantity)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// 检查限购，购物车中已有的数量一并计入
+		if err := s.limitService.CheckCart(tx, userID, product, quantity); err != nil {
+			return err
+		}
+
+		return repository.NewCartRepository(tx).AddToCart(userID, productID, quantity, blessing, product.Price)
+	})
 }
 
-// GetCart gets all cart items for a user
+// GetCart gets all cart items for a user with their validity and the price change since they were added
 func (s *CartService) GetCart(userID uint64) ([]response.CartResponse, error) {
-	carts, err := s.cartRepo.GetCart(userID)
+	carts, err := s.getCheckedCart(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -50,14 +83,23 @@ func (s *CartService) GetCart(userID uint64) ([]response.CartResponse, error) {
 	var responses []response.CartResponse
 	for _, item := range carts {
 		response := response.CartResponse{
-			ID:         item.ID,
-			ProductID:  item.ProductID,
-			Quantity:   item.Quantity,
-			Selected:   item.Selected,
-			Name:       item.Product.Name,
-			Price:      item.Product.Price,
-			ImageUrl:   minioClient.GetFileURL(item.Product.ImageUrl),
-			StockCount: item.Product.StockCount,
+			ID:                item.ID,
+			ProductID:         item.ProductID,
+			Quantity:          item.Quantity,
+			Selected:          item.Selected,
+			Blessing:          item.Blessing,
+			Name:              item.Product.Name,
+			Price:             item.Product.Price,
+			AddedPrice:        item.AddedPrice,
+			ImageUrl:          minioClient.GetFileURL(item.Product.ImageUrl),
+			StockCount:        item.Product.StockCount,
+			OffSale:           cartOffSale(&item),
+			OutOfStock:        cartOutOfStock(&item),
+			InsufficientStock: cartInsufficientStock(&item),
+			Valid:             cartCheckoutError(&item) == nil,
+		}
+		if item.AddedPrice > 0 {
+			response.PriceDelta = priceutils.Round(item.Product.Price - item.AddedPrice)
 		}
 		responses = append(responses, response)
 	}
@@ -65,20 +107,114 @@ func (s *CartService) GetCart(userID uint64) ([]response.CartResponse, error) {
 	return responses, nil
 }
 
-// UpdateCartStatus updates the status of a cart item
-func (s *CartService) UpdateCartStatus(userID uint64, productID uint64, selected bool) error {
-	carts, err := s.cartRepo.GetCart(userID)
+// GetCartSummary prices the valid selected cart items with the same pricing as checkout.
+// Selected items that are off sale or sold out are counted but not priced.
+func (s *CartService) GetCartSummary(userID uint64) (*response.CartSummaryResponse, error) {
+	carts, err := s.getCheckedCart(userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	summary := &response.CartSummaryResponse{Promotions: []response.AppliedPromotionResponse{}}
+	var lines []PricingLine
 	for _, item := range carts {
-		if item.ProductID == productID {
-			return s.cartRepo.UpdateCartStatus(item.ID, selected)
+		if !item.Selected {
+			continue
 		}
+		// 与提交订单使用同一校验，不能下单的项目不计价
+		if err := cartCheckoutError(&item); err != nil {
+			if cartInsufficientStock(&item) {
+				summary.InsufficientStockCount++
+			} else {
+				summary.InvalidCount++
+			}
+			continue
+		}
+		summary.SelectedCount++
+		summary.SelectedQuantity += item.Quantity
+		lines = append(lines, PricingLine{Product: item.Product, Quantity: item.Quantity})
+	}
+	if len(lines) == 0 {
+		return summary, nil
 	}
 
-	return pkgerrors.ErrCartNotFound
+	pricing, err := s.pricingService.Calculate(userID, lines)
+	if err != nil {
+		return nil, err
+	}
+	summary.TotalAmount = pricing.TotalAmount
+	summary.MemberDiscount = pricing.MemberDiscount
+	summary.PromotionDiscount = pricing.PromotionDiscount
+	summary.ShippingFee = pricing.ShippingFee
+	summary.PaymentAmount = pricing.PaymentAmount
+
+	// 按活动汇总各商品享受的促销优惠，与订单详情一致
+	var promotions []model.OrderItemPromotion
+	for _, line := range pricing.Lines {
+		for _, promotion := range line.Promotions {
+			promotions = append(promotions, model.OrderItemPromotion{
+				PromotionID: promotion.PromotionID,
+				Title:       promotion.Title,
+				RuleType:    promotion.RuleType,
+				Discount:    promotion.Discount,
+			})
+		}
+	}
+	if len(promotions) > 0 {
+		summary.Promotions = toAppliedPromotionResponses(promotions)
+	}
+	return summary, nil
+}
+
+// UpdateCartQuantity changes the quantity of a cart item, checking the stock and, when increasing, the purchase limits
+func (s *CartService) UpdateCartQuantity(userID uint64, id uint64, quantity int) error {
+	cart, err := s.cartRepo.GetCartByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return pkgerrors.ErrCartNotFound
+		}
+		return err
+	}
+	if cart.UserID != userID {
+		return pkgerrors.ErrCartNotFound
+	}
+
+	if cartOffSale(cart) {
+		return pkgerrors.ErrProductOffSale
+	}
+	if err := s.bundleService.FillStock(&cart.Product); err != nil {
+		return err
+	}
+	if cart.Product.StockCount < quantity {
+		return pkgerrors.ErrOutOfStock
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// 增加数量时检查限购，购物车中已有的数量一并计入
+		if quantity > cart.Quantity {
+			if err := s.limitService.CheckCart(tx, userID, &cart.Product, quantity-cart.Quantity); err != nil {
+				return err
+			}
+		}
+
+		return repository.NewCartRepository(tx).UpdateCartQuantity(id, quantity)
+	})
+}
+
+// UpdateCartStatus selects or deselects a cart item. Lines of the same product with different blessings are selected separately.
+func (s *CartService) UpdateCartStatus(userID uint64, id uint64, selected bool) error {
+	cart, err := s.cartRepo.GetCartByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return pkgerrors.ErrCartNotFound
+		}
+		return err
+	}
+	if cart.UserID != userID {
+		return pkgerrors.ErrCartNotFound
+	}
+
+	return s.cartRepo.UpdateCartStatus(id, selected)
 }
 
 // UpdateAllCartStatus updates the status of all cart items for a user
@@ -106,3 +242,65 @@ func (s *CartService) DeleteCart(userID uint64, id uint64) error {
 
 	return pkgerrors.ErrCartNotFound
 }
+
+// RemoveInvalidCarts deletes the cart items whose product is off sale, deleted or sold out, returning the number removed
+func (s *CartService) RemoveInvalidCarts(userID uint64) (int, error) {
+	carts, err := s.getCheckedCart(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []uint64
+	for _, item := range carts {
+		if cartOffSale(&item) || cartOutOfStock(&item) {
+			ids = append(ids, item.ID)
+		}
+	}
+	if err := s.cartRepo.DeleteCarts(userID, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// getCheckedCart 获取用户购物车并填充商品当前库存，组合商品按组件库存计算
+func (s *CartService) getCheckedCart(userID uint64) ([]model.Cart, error) {
+	carts, err := s.cartRepo.GetCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*model.Product, len(carts))
+	for i := range carts {
+		products[i] = &carts[i].Product
+	}
+	if err := s.bundleService.FillStock(products...); err != nil {
+		return nil, err
+	}
+	return carts, nil
+}
+
+// cartOffSale 商品已下架，或已删除（预加载不到商品）
+func cartOffSale(item *model.Cart) bool {
+	return item.Product.ID == 0 || item.Product.Status != 1
+}
+
+// cartOutOfStock 商品已售罄，需先填充库存
+func cartOutOfStock(item *model.Cart) bool {
+	return item.Product.StockCount <= 0
+}
+
+// cartInsufficientStock 商品有库存但少于购物车数量，减少数量后可下单，需先填充库存
+func cartInsufficientStock(item *model.Cart) bool {
+	return item.Product.StockCount > 0 && item.Product.StockCount < item.Quantity
+}
+
+// cartCheckoutError 购物车项能否下单，购物车列表、结算金额和提交订单共用，需先填充库存
+func cartCheckoutError(item *model.Cart) error {
+	if cartOffSale(item) {
+		return pkgerrors.ErrProductOffSale
+	}
+	if item.Product.StockCount < item.Quantity {
+		return pkgerrors.ErrOutOfStock
+	}
+	return nil
+}
@@ -5,17 +5,28 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/colinjuang/shop-go/internal/app/request"
 	"github.com/colinjuang/shop-go/internal/app/response"
 	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/pkg/logger"
 	"github.com/colinjuang/shop-go/internal/pkg/redis"
 	"github.com/colinjuang/shop-go/internal/repository"
 	"github.com/colinjuang/shop-go/internal/server"
+	categoryutils "github.com/colinjuang/shop-go/internal/utils/category"
+	"gorm.io/gorm"
 )
 
-// CategoryService handles business logic for categories
+// categoryCacheTTL 分类缓存时间，分类变更时主动清除，过期只作为兜底
+const categoryCacheTTL = 24 * time.Hour
+
+// CategoryService handles business logic for categories. Categories nest to any depth; each category
+// and each product keeps the materialized path of its category so a whole subtree can be matched by prefix.
 type CategoryService struct {
+	db           *gorm.DB
 	categoryRepo *repository.CategoryRepository
+	productRepo  *repository.ProductRepository
 	cacheService *redis.CacheService
 }
 
@@ -23,7 +34,9 @@ type CategoryService struct {
 func NewCategoryService() *CategoryService {
 	server := server.GetServer()
 	return &CategoryService{
+		db:           server.DB,
 		categoryRepo: repository.NewCategoryRepository(server.DB),
+		productRepo:  repository.NewProductRepository(server.DB),
 		cacheService: redis.NewCacheService(),
 	}
 }
@@ -45,24 +58,11 @@ func (s *CategoryService) GetCategories() ([]*response.CategoryResponse, error)
 		return nil, err
 	}
 
-	// 缓存1分钟
-	err = s.cacheService.Set(ctx, constant.CategoryList, categories, 1*time.Minute)
+	categoryResponses = toCategoryResponses(categories)
+	err = s.cacheService.Set(ctx, constant.CategoryList, categoryResponses, categoryCacheTTL)
 	if err != nil {
 		logger.Warnf("Failed to cache categories: %v", err)
 	}
-
-	categoryResponses = make([]*response.CategoryResponse, len(categories))
-	for i, category := range categories {
-		categoryResponses[i] = &response.CategoryResponse{
-			ID:        category.ID,
-			Name:      category.Name,
-			ParentID:  category.ParentID,
-			ImageUrl:  category.ImageUrl,
-			SortOrder: category.SortOrder,
-			CreatedAt: category.CreatedAt,
-			UpdatedAt: category.UpdatedAt,
-		}
-	}
 	return categoryResponses, nil
 }
 
@@ -84,29 +84,16 @@ func (s *CategoryService) GetCategoriesByParentID(parentID uint64) ([]*response.
 		return nil, err
 	}
 
-	// Cache for 1 minute
-	err = s.cacheService.Set(ctx, cacheKey, categories, 1*time.Minute)
+	categoryResponses = toCategoryResponses(categories)
+	err = s.cacheService.Set(ctx, cacheKey, categoryResponses, categoryCacheTTL)
 	if err != nil {
 		logger.Warnf("Failed to cache categories: %v", err)
 	}
 
-	categoryResponses = make([]*response.CategoryResponse, len(categories))
-	for i, category := range categories {
-		categoryResponses[i] = &response.CategoryResponse{
-			ID:        category.ID,
-			Name:      category.Name,
-			ParentID:  category.ParentID,
-			ImageUrl:  category.ImageUrl,
-			SortOrder: category.SortOrder,
-			CreatedAt: category.CreatedAt,
-			UpdatedAt: category.UpdatedAt,
-		}
-	}
-
 	return categoryResponses, nil
 }
 
-// GetCategoryTree gets the category tree
+// GetCategoryTree gets the category tree of any depth
 func (s *CategoryService) GetCategoryTree() ([]*response.CategoryTreeResponse, error) {
 	ctx := context.Background()
 	cacheKey := constant.CategoryTree
@@ -124,45 +111,538 @@ func (s *CategoryService) GetCategoryTree() ([]*response.CategoryTreeResponse, e
 		return nil, err
 	}
 
-	// 创建一个快速查找的map
-	treeMap := make(map[uint64]*response.CategoryTreeResponse)
-
 	// 第一遍：创建所有树节点
+	treeMap := make(map[uint64]*response.CategoryTreeResponse, len(categories))
 	for _, category := range categories {
-		if category.ParentID == 0 {
-			node := &response.CategoryTreeResponse{
-				ID:        category.ID,
-				Name:      category.Name,
-				ImageUrl:  category.ImageUrl,
-				SortOrder: category.SortOrder,
-				Children:  []response.CategoryResponse{},
-			}
-			tree = append(tree, node)
-			treeMap[category.ID] = node
+		treeMap[category.ID] = &response.CategoryTreeResponse{
+			ID:        category.ID,
+			Name:      category.Name,
+			ParentID:  category.ParentID,
+			Level:     category.Level,
+			ImageUrl:  category.ImageUrl,
+			SortOrder: category.SortOrder,
+			Children:  []*response.CategoryTreeResponse{},
 		}
 	}
 
-	// 第二遍：使用map进行O(1)父节点查找
+	// 第二遍：挂到父节点下，分类已按排序值排列，子节点顺序随之确定。父分类不存在的当作一级分类
+	tree = []*response.CategoryTreeResponse{}
 	for _, category := range categories {
-		if category.ParentID != 0 {
-			if parent, exists := treeMap[category.ParentID]; exists {
-				parent.Children = append(parent.Children, response.CategoryResponse{
-					ID:        category.ID,
-					Name:      category.Name,
-					ImageUrl:  category.ImageUrl,
-					SortOrder: category.SortOrder,
-					CreatedAt: category.CreatedAt,
-					UpdatedAt: category.UpdatedAt,
-				})
-			}
+		node := treeMap[category.ID]
+		if parent, exists := treeMap[category.ParentID]; exists && category.ParentID != category.ID {
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree = append(tree, node)
 		}
 	}
 
-	// 缓存1分钟
-	err = s.cacheService.Set(ctx, cacheKey, tree, 1*time.Minute)
+	err = s.cacheService.Set(ctx, cacheKey, tree, categoryCacheTTL)
 	if err != nil {
 		logger.Warnf("Failed to cache categories: %v", err)
 	}
 
 	return tree, nil
 }
+
+// GetBreadcrumbs gets the categories from the top level down to the given category
+func (s *CategoryService) GetBreadcrumbs(categoryID uint64) ([]response.CategoryBreadcrumb, error) {
+	categories, err := s.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	categoryMap := make(map[uint64]*response.CategoryResponse, len(categories))
+	for _, category := range categories {
+		categoryMap[category.ID] = category
+	}
+
+	// 沿父分类向上查找，层数不超过分类总数，防止数据异常时死循环
+	breadcrumbs := []response.CategoryBreadcrumb{}
+	for id := categoryID; id != 0 && len(breadcrumbs) < len(categories); {
+		category, ok := categoryMap[id]
+		if !ok {
+			break
+		}
+		breadcrumbs = append(breadcrumbs, response.CategoryBreadcrumb{ID: category.ID, Name: category.Name})
+		id = category.ParentID
+	}
+	for i, j := 0, len(breadcrumbs)-1; i < j; i, j = i+1, j-1 {
+		breadcrumbs[i], breadcrumbs[j] = breadcrumbs[j], breadcrumbs[i]
+	}
+	return breadcrumbs, nil
+}
+
+// CreateCategory creates a category under the given parent, at the top level when the parent is 0 (admin)
+func (s *CategoryService) CreateCategory(req request.CategoryRequest) (*model.Category, error) {
+	category := &model.Category{
+		Name:      req.Name,
+		ParentID:  req.ParentID,
+		ImageUrl:  req.ImageUrl,
+		SortOrder: req.SortOrder,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		categoryRepo := repository.NewCategoryRepository(tx)
+
+		parentPath := categoryutils.RootPath
+		if req.ParentID > 0 {
+			parents, err := categoryRepo.LockCategories([]uint64{req.ParentID})
+			if err != nil {
+				return err
+			}
+			if len(parents) == 0 {
+				return pkgerrors.ErrCategoryNotFound
+			}
+			parentPath = parents[0].Path
+		}
+
+		if err := categoryRepo.CreateCategory(category); err != nil {
+			return err
+		}
+		// 路径包含分类自身的ID，创建后才能确定
+		category.Path = categoryutils.ChildPath(parentPath, category.ID)
+		category.Level = categoryutils.Level(category.Path)
+		return categoryRepo.UpdateCategory(category.ID, map[string]interface{}{
+			"path":  category.Path,
+			"level": category.Level,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCategoryCache(category.ParentID)
+	return category, nil
+}
+
+// UpdateCategory updates the name, image and sort order of a category (admin).
+// The parent is changed with MoveCategory so that the subtree moves along.
+func (s *CategoryService) UpdateCategory(id uint64, req request.CategoryRequest) (*model.Category, error) {
+	category, err := s.categoryRepo.GetCategoryByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrCategoryNotFound
+	}
+
+	category.Name = req.Name
+	category.ImageUrl = req.ImageUrl
+	category.SortOrder = req.SortOrder
+	err = s.categoryRepo.UpdateCategory(id, map[string]interface{}{
+		"name":       category.Name,
+		"image_url":  category.ImageUrl,
+		"sort_order": category.SortOrder,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCategoryCache(category.ParentID)
+	// 改名会影响本分类及子分类下商品详情的面包屑
+	productIDs, err := s.categoryRepo.GetProductIDsUnderPath(category.Path)
+	if err != nil {
+		logger.Warnf("Failed to get products under category %d: %v", id, err)
+	}
+	for _, productID := range productIDs {
+		invalidateProductCache(context.Background(), s.cacheService, productID)
+	}
+	return category, nil
+}
+
+// MoveCategory moves a category with all its descendants and their products under a new parent (admin).
+// A category cannot be moved under itself or one of its descendants.
+func (s *CategoryService) MoveCategory(id uint64, req request.CategoryMoveRequest) (*model.Category, error) {
+	var category *model.Category
+	var oldParentID uint64
+	var productIDs []uint64
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		categoryRepo := repository.NewCategoryRepository(tx)
+
+		locked, err := lockCategoryMap(categoryRepo, id, req.ParentID)
+		if err != nil {
+			return err
+		}
+		category = locked[id]
+		if category == nil {
+			return pkgerrors.ErrCategoryNotFound
+		}
+		oldParentID = category.ParentID
+
+		parentPath := categoryutils.RootPath
+		if req.ParentID > 0 {
+			parent := locked[req.ParentID]
+			if parent == nil {
+				return pkgerrors.ErrCategoryNotFound
+			}
+			if categoryutils.IsWithin(parent.Path, category.Path) {
+				return pkgerrors.ErrInvalidCategory
+			}
+			parentPath = parent.Path
+		}
+
+		sortOrder := 0
+		if req.SortOrder != nil {
+			sortOrder = *req.SortOrder
+		} else if req.ParentID == oldParentID {
+			sortOrder = category.SortOrder
+		} else {
+			maxSort, err := categoryRepo.GetMaxSortOrder(req.ParentID)
+			if err != nil {
+				return err
+			}
+			sortOrder = maxSort + 1
+		}
+
+		newPath := categoryutils.ChildPath(parentPath, id)
+		if newPath != category.Path {
+			// 分类及其子孙分类下商品的分类路径随之改变，改写前记录以便清除商品缓存
+			productIDs, err = categoryRepo.GetProductIDsUnderPath(category.Path)
+			if err != nil {
+				return err
+			}
+			levelDelta := categoryutils.Level(newPath) - category.Level
+			if err := categoryRepo.ReplacePathPrefix(category.Path, newPath, levelDelta); err != nil {
+				return err
+			}
+			if err := categoryRepo.ReplaceProductPathPrefix(category.Path, newPath); err != nil {
+				return err
+			}
+			category.Path = newPath
+			category.Level += levelDelta
+		}
+
+		category.ParentID = req.ParentID
+		category.SortOrder = sortOrder
+		return categoryRepo.UpdateCategory(id, map[string]interface{}{
+			"parent_id":  category.ParentID,
+			"sort_order": category.SortOrder,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCategoryCache(oldParentID, category.ParentID)
+	for _, productID := range productIDs {
+		invalidateProductCache(context.Background(), s.cacheService, productID)
+	}
+	s.invalidateSalesRanking()
+	return category, nil
+}
+
+// SortCategories reorders the children of a parent (admin). The IDs must list every child exactly once.
+func (s *CategoryService) SortCategories(req request.CategorySortRequest) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		categoryRepo := repository.NewCategoryRepository(tx)
+
+		children, err := categoryRepo.GetCategoriesByParentID(req.ParentID)
+		if err != nil {
+			return err
+		}
+		if len(children) != len(req.IDs) {
+			return pkgerrors.ErrInvalidCategory
+		}
+		childIDs := make(map[uint64]bool, len(children))
+		for _, child := range children {
+			childIDs[child.ID] = true
+		}
+		for _, id := range req.IDs {
+			if !childIDs[id] {
+				return pkgerrors.ErrInvalidCategory
+			}
+			// 重复的ID第二次出现时不再匹配
+			delete(childIDs, id)
+		}
+
+		for i, id := range req.IDs {
+			if err := categoryRepo.UpdateCategory(id, map[string]interface{}{"sort_order": i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateCategoryCache(req.ParentID)
+	return nil
+}
+
+// MergeCategory merges a category into a target category and deletes it (admin). Its products, children
+// and the subscriptions, promotions and rules referring to it move to the target.
+func (s *CategoryService) MergeCategory(id uint64, req request.CategoryMergeRequest) (*model.Category, error) {
+	var source, target *model.Category
+	var productIDs []uint64
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		categoryRepo := repository.NewCategoryRepository(tx)
+
+		locked, err := lockCategoryMap(categoryRepo, id, req.TargetID)
+		if err != nil {
+			return err
+		}
+		source, target = locked[id], locked[req.TargetID]
+		if source == nil || target == nil {
+			return pkgerrors.ErrCategoryNotFound
+		}
+		// 目标分类不能是本分类或其子孙分类
+		if categoryutils.IsWithin(target.Path, source.Path) {
+			return pkgerrors.ErrInvalidCategory
+		}
+
+		// 包括子孙分类下的商品，它们的分类路径同样被改写
+		productIDs, err = categoryRepo.GetProductIDsUnderPath(source.Path)
+		if err != nil {
+			return err
+		}
+
+		if err := categoryRepo.DeleteCategory(id); err != nil {
+			return err
+		}
+		// 子分类排在目标分类原有子分类之后
+		maxSort, err := categoryRepo.GetMaxSortOrder(target.ID)
+		if err != nil {
+			return err
+		}
+		if err := categoryRepo.MoveChildren(id, target.ID, maxSort+1); err != nil {
+			return err
+		}
+		// 本分类已删除，前缀替换只影响子孙分类
+		if err := categoryRepo.ReplacePathPrefix(source.Path, target.Path, target.Level-source.Level); err != nil {
+			return err
+		}
+		if err := categoryRepo.ReplaceProductPathPrefix(source.Path, target.Path); err != nil {
+			return err
+		}
+		if err := categoryRepo.MoveProducts(id, target.ID); err != nil {
+			return err
+		}
+		return categoryRepo.ReassignCategoryReferences(id, target.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCategoryCache(source.ParentID, source.ID, target.ID)
+	for _, productID := range productIDs {
+		invalidateProductCache(context.Background(), s.cacheService, productID)
+	}
+	// 本分类的商品属性和促销范围已转给目标分类
+	s.cacheService.Delete(context.Background(), constant.AttributeList)
+	s.cacheService.Delete(context.Background(), constant.ActivePromotions)
+	s.invalidateSalesRanking()
+	return target, nil
+}
+
+// DeleteCategory deletes a category that has no children and no products (admin)
+func (s *CategoryService) DeleteCategory(id uint64) error {
+	var category *model.Category
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		categoryRepo := repository.NewCategoryRepository(tx)
+
+		locked, err := lockCategoryMap(categoryRepo, id)
+		if err != nil {
+			return err
+		}
+		category = locked[id]
+		if category == nil {
+			return pkgerrors.ErrCategoryNotFound
+		}
+
+		children, err := categoryRepo.CountChildren(id)
+		if err != nil {
+			return err
+		}
+		products, err := categoryRepo.CountProducts(id)
+		if err != nil {
+			return err
+		}
+		if children > 0 || products > 0 {
+			return pkgerrors.ErrCategoryNotEmpty
+		}
+		return categoryRepo.DeleteCategory(id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateCategoryCache(category.ParentID, category.ID)
+	return nil
+}
+
+// SetProductCategory moves a product to a category of any level (admin)
+func (s *CategoryService) SetProductCategory(productID uint64, req request.ProductCategoryRequest) error {
+	if _, err := s.productRepo.GetProductByID(productID); err != nil {
+		return pkgerrors.ErrProductNotFound
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		categoryRepo := repository.NewCategoryRepository(tx)
+
+		// 锁住分类，防止同时移动分类导致商品路径过期
+		locked, err := lockCategoryMap(categoryRepo, req.CategoryID)
+		if err != nil {
+			return err
+		}
+		category := locked[req.CategoryID]
+		if category == nil {
+			return pkgerrors.ErrCategoryNotFound
+		}
+		return categoryRepo.SetProductCategory(productID, category.ID, category.Path)
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateProductCache(context.Background(), s.cacheService, productID)
+	s.invalidateSalesRanking()
+	return nil
+}
+
+// RebuildPaths recomputes the path and level of every category from the parent links and then the
+// category path of every product (admin). It repairs data written before paths existed.
+func (s *CategoryService) RebuildPaths() (int64, error) {
+	var updated int64
+	var parentIDs []uint64
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		categoryRepo := repository.NewCategoryRepository(tx)
+
+		categories, err := categoryRepo.GetCategories()
+		if err != nil {
+			return err
+		}
+		categoryMap := make(map[uint64]*model.Category, len(categories))
+		for i := range categories {
+			categoryMap[categories[i].ID] = &categories[i]
+		}
+
+		paths := make(map[uint64]string, len(categories))
+		for _, category := range categories {
+			path := buildCategoryPath(category.ID, categoryMap, paths)
+			level := categoryutils.Level(path)
+			if path == category.Path && level == category.Level {
+				continue
+			}
+			if err := categoryRepo.UpdateCategory(category.ID, map[string]interface{}{
+				"path":  path,
+				"level": level,
+			}); err != nil {
+				return err
+			}
+			parentIDs = append(parentIDs, category.ParentID)
+			updated++
+		}
+
+		products, err := categoryRepo.SyncProductPaths()
+		if err != nil {
+			return err
+		}
+		updated += products
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if updated > 0 {
+		s.invalidateCategoryCache(parentIDs...)
+		s.invalidateSalesRanking()
+	}
+	return updated, nil
+}
+
+// toCategoryResponses 转换为分类响应
+func toCategoryResponses(categories []model.Category) []*response.CategoryResponse {
+	categoryResponses := make([]*response.CategoryResponse, len(categories))
+	for i, category := range categories {
+		categoryResponses[i] = &response.CategoryResponse{
+			ID:        category.ID,
+			Name:      category.Name,
+			ParentID:  category.ParentID,
+			Level:     category.Level,
+			ImageUrl:  category.ImageUrl,
+			SortOrder: category.SortOrder,
+			CreatedAt: category.CreatedAt,
+			UpdatedAt: category.UpdatedAt,
+		}
+	}
+	return categoryResponses
+}
+
+// lockCategoryMap 加行锁获取分类，ID为0的忽略，返回按ID索引的分类
+func lockCategoryMap(categoryRepo *repository.CategoryRepository, ids ...uint64) (map[uint64]*model.Category, error) {
+	lockIDs := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if id > 0 {
+			lockIDs = append(lockIDs, id)
+		}
+	}
+	categories, err := categoryRepo.LockCategories(lockIDs)
+	if err != nil {
+		return nil, err
+	}
+	categoryMap := make(map[uint64]*model.Category, len(categories))
+	for i := range categories {
+		categoryMap[categories[i].ID] = &categories[i]
+	}
+	return categoryMap, nil
+}
+
+// buildCategoryPath 沿父分类计算分类路径，父分类不存在或出现环时从该分类截断为一级分类
+func buildCategoryPath(id uint64, categoryMap map[uint64]*model.Category, paths map[uint64]string) string {
+	if path, ok := paths[id]; ok {
+		return path
+	}
+
+	chain := []uint64{}
+	visited := map[uint64]bool{}
+	parentPath := categoryutils.RootPath
+	for current := id; current != 0; {
+		if path, ok := paths[current]; ok {
+			parentPath = path
+			break
+		}
+		category, ok := categoryMap[current]
+		if !ok || visited[current] {
+			break
+		}
+		visited[current] = true
+		chain = append(chain, current)
+		current = category.ParentID
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		parentPath = categoryutils.ChildPath(parentPath, chain[i])
+		paths[chain[i]] = parentPath
+	}
+	return paths[id]
+}
+
+// closestCategory 在商品分类路径上从商品所属分类向上查找，返回最近的有规则的分类
+func closestCategory[V any](path string, rules map[uint64]V) (uint64, bool) {
+	ids := categoryutils.PathIDs(path)
+	for i := len(ids) - 1; i >= 0; i-- {
+		if _, ok := rules[ids[i]]; ok {
+			return ids[i], true
+		}
+	}
+	return 0, false
+}
+
+// invalidateCategoryCache 清除分类列表、分类树、首页分类以及受影响父分类的子分类缓存
+func (s *CategoryService) invalidateCategoryCache(parentIDs ...uint64) {
+	ctx := context.Background()
+	s.cacheService.Delete(ctx, constant.CategoryList)
+	s.cacheService.Delete(ctx, constant.CategoryTree)
+	s.cacheService.Delete(ctx, constant.HomeCategories)
+	for _, parentID := range parentIDs {
+		s.cacheService.Delete(ctx, fmt.Sprintf(constant.CategoryParentID+":%d", parentID))
+	}
+}
+
+// invalidateSalesRanking 分类变化后商品所在的排行分区随之变化，清除构建标记让对账任务重建排行
+func (s *CategoryService) invalidateSalesRanking() {
+	s.cacheService.Delete(context.Background(), constant.SalesRankingBuilt)
+}
@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/notify"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	categoryutils "github.com/colinjuang/shop-go/internal/utils/category"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	"gorm.io/gorm"
+)
+
+// DistributionService handles referral commissions. A user is bound to the referrer in the mini
+// program scene at first login; orders of the user then earn commission for the referrer, and for
+// the referrer's own referrer when two-level distribution is enabled. Commissions are held until the
+// order completes, settled into the referrer's wallet, and reversed when the order is refunded.
+type DistributionService struct {
+	db               *gorm.DB
+	distributionRepo *repository.DistributionRepository
+	userRepo         *repository.UserRepository
+	config           config.DistributionConfig
+}
+
+// NewDistributionService creates a new distribution service
+func NewDistributionService() *DistributionService {
+	server := server.GetServer()
+	return &DistributionService{
+		db:               server.DB,
+		distributionRepo: repository.NewDistributionRepository(server.DB),
+		userRepo:         repository.NewUserRepository(server.DB),
+		config:           server.GetConfig().Distribution,
+	}
+}
+
+// BindReferral binds a new user to the referrer in the scene of the shared mini program code.
+// A user is bound once and never to themselves.
+func (s *DistributionService) BindReferral(userID uint64, scene string) error {
+	referrerID, _, err := parseReferralScene(scene)
+	if err != nil {
+		return err
+	}
+	if referrerID == userID {
+		return nil
+	}
+
+	existing, err := s.distributionRepo.GetReferralByUserID(userID)
+	if err != nil || existing != nil {
+		return err
+	}
+	if _, err := s.userRepo.GetUserByID(referrerID); err != nil {
+		return pkgerrors.ErrInvalidReferralScene
+	}
+
+	referral := &model.Referral{
+		UserID:     userID,
+		ReferrerID: referrerID,
+		Scene:      scene,
+	}
+	// 推荐人自己的推荐人作为二级推荐人
+	parent, err := s.distributionRepo.GetReferralByUserID(referrerID)
+	if err != nil {
+		return err
+	}
+	if parent != nil && parent.ReferrerID != userID {
+		referral.ParentReferrerID = parent.ReferrerID
+	}
+	return s.distributionRepo.CreateReferral(referral)
+}
+
+// GetShare returns the scene and page of the mini program code for sharing a product
+func (s *DistributionService) GetShare(userID, productID uint64) *response.DistributionShareResponse {
+	return &response.DistributionShareResponse{
+		Scene: referralShareScene(productID, userID),
+		Path:  s.config.SharePath,
+	}
+}
+
+// RecordClick records a visit through a shared mini program code; visits of the referrer are ignored
+func (s *DistributionService) RecordClick(visitorID uint64, scene string) error {
+	referrerID, productID, err := parseReferralScene(scene)
+	if err != nil {
+		return err
+	}
+	if referrerID == visitorID {
+		return nil
+	}
+
+	return s.distributionRepo.CreateClick(&model.ReferralClick{
+		ReferrerID: referrerID,
+		ProductID:  productID,
+		VisitorID:  visitorID,
+	})
+}
+
+// GetStats gets the clicks, invitees, orders and earnings of a referrer
+func (s *DistributionService) GetStats(userID uint64) (*response.DistributionStatsResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, pkgerrors.ErrUserNotFound
+	}
+
+	stats := &response.DistributionStatsResponse{Wallet: user.Wallet}
+	if stats.Clicks, err = s.distributionRepo.CountClicks(userID); err != nil {
+		return nil, err
+	}
+	if stats.Invitees, err = s.distributionRepo.CountReferrals(userID); err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.distributionRepo.SummarizeCommissions(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		switch summary.Status {
+		case model.CommissionStatusHeld:
+			stats.HeldAmount = priceutils.Round(summary.Amount)
+		case model.CommissionStatusSettled:
+			stats.SettledAmount = priceutils.Round(summary.Amount)
+		case model.CommissionStatusReversed:
+			stats.ReversedAmount = priceutils.Round(summary.Amount)
+		}
+		if summary.Status != model.CommissionStatusCancelled {
+			stats.Orders += summary.Orders
+		}
+	}
+	return stats, nil
+}
+
+// GetCommissions gets the commissions of a referrer with pagination
+func (s *DistributionService) GetCommissions(userID uint64, page, pageSize int) (*response.Pagination, error) {
+	commissions, total, err := s.distributionRepo.GetCommissionsByReferrer(userID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]response.CommissionResponse, len(commissions))
+	for i, commission := range commissions {
+		responses[i] = response.CommissionResponse{
+			ID:         commission.ID,
+			OrderNo:    commission.OrderNo,
+			Level:      commission.Level,
+			BaseAmount: commission.BaseAmount,
+			Amount:     commission.Amount,
+			Status:     commission.Status,
+			SettledAt:  commission.SettledAt,
+			CreatedAt:  commission.CreatedAt,
+		}
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, responses)
+	return &pagination, nil
+}
+
+// GetWalletLedgers gets the wallet history of a user with pagination
+func (s *DistributionService) GetWalletLedgers(userID uint64, page, pageSize int) (*response.Pagination, error) {
+	ledgers, total, err := s.distributionRepo.GetWalletLedgers(userID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]response.WalletLedgerResponse, len(ledgers))
+	for i, ledger := range ledgers {
+		responses[i] = response.WalletLedgerResponse{
+			ID:        ledger.ID,
+			Type:      ledger.Type,
+			TypeDesc:  model.WalletTypeDesc[ledger.Type],
+			Change:    ledger.Change,
+			Balance:   ledger.Balance,
+			Remark:    ledger.Remark,
+			CreatedAt: ledger.CreatedAt,
+		}
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, responses)
+	return &pagination, nil
+}
+
+// HoldForOrder creates the held commissions of an order for the buyer's referrers.
+// It must be called inside the order creation transaction after the order has been saved.
+func (s *DistributionService) HoldForOrder(tx *gorm.DB, order *model.Order, items []model.OrderItem) error {
+	distributionRepo := repository.NewDistributionRepository(tx)
+	referral, err := distributionRepo.GetReferralByUserID(order.UserID)
+	if err != nil || referral == nil {
+		return err
+	}
+
+	level1, level2, base, err := s.calculateCommission(tx, order, items)
+	if err != nil {
+		return err
+	}
+
+	var commissions []model.Commission
+	if level1 > 0 {
+		commissions = append(commissions, model.Commission{
+			OrderID:    order.ID,
+			Level:      1,
+			ReferrerID: referral.ReferrerID,
+			BuyerID:    order.UserID,
+			OrderNo:    order.OrderNo,
+			BaseAmount: base,
+			Amount:     level1,
+			Status:     model.CommissionStatusHeld,
+		})
+	}
+	if s.config.TwoLevel && referral.ParentReferrerID > 0 && level2 > 0 {
+		commissions = append(commissions, model.Commission{
+			OrderID:    order.ID,
+			Level:      2,
+			ReferrerID: referral.ParentReferrerID,
+			BuyerID:    order.UserID,
+			OrderNo:    order.OrderNo,
+			BaseAmount: base,
+			Amount:     level2,
+			Status:     model.CommissionStatusHeld,
+		})
+	}
+	if len(commissions) == 0 {
+		return nil
+	}
+	return distributionRepo.CreateCommissions(commissions)
+}
+
+// SettleForOrder credits the held commissions of a completed order to the referrers' wallets
+func (s *DistributionService) SettleForOrder(tx *gorm.DB, order *model.Order) error {
+	distributionRepo := repository.NewDistributionRepository(tx)
+	commissions, err := distributionRepo.GetOrderCommissions(order.ID, model.CommissionStatusHeld)
+	if err != nil {
+		return err
+	}
+
+	for _, commission := range commissions {
+		err := distributionRepo.UpdateCommission(commission.ID, map[string]interface{}{
+			"status":     model.CommissionStatusSettled,
+			"settled_at": time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.apply(tx, &model.WalletLedger{
+			UserID: commission.ReferrerID,
+			Type:   model.WalletTypeCommission,
+			Change: commission.Amount,
+			RefID:  commission.ID,
+			Remark: order.OrderNo,
+		}, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReverseForOrder reverses the commissions of a refunded order. Settled commissions are taken
+// back from the wallet, which may become negative if the balance has been withdrawn.
+func (s *DistributionService) ReverseForOrder(tx *gorm.DB, order *model.Order) error {
+	distributionRepo := repository.NewDistributionRepository(tx)
+	commissions, err := distributionRepo.GetOrderCommissions(order.ID, model.CommissionStatusHeld, model.CommissionStatusSettled)
+	if err != nil {
+		return err
+	}
+
+	for _, commission := range commissions {
+		err := distributionRepo.UpdateCommission(commission.ID, map[string]interface{}{"status": model.CommissionStatusReversed})
+		if err != nil {
+			return err
+		}
+		if commission.Status != model.CommissionStatusSettled {
+			continue
+		}
+
+		err = s.apply(tx, &model.WalletLedger{
+			UserID: commission.ReferrerID,
+			Type:   model.WalletTypeCommissionReversal,
+			Change: -commission.Amount,
+			RefID:  commission.ID,
+			Remark: order.OrderNo,
+		}, true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelForOrder cancels the held commissions of an order closed without payment
+func (s *DistributionService) CancelForOrder(tx *gorm.DB, order *model.Order) error {
+	distributionRepo := repository.NewDistributionRepository(tx)
+	commissions, err := distributionRepo.GetOrderCommissions(order.ID, model.CommissionStatusHeld)
+	if err != nil {
+		return err
+	}
+
+	for _, commission := range commissions {
+		err := distributionRepo.UpdateCommission(commission.ID, map[string]interface{}{"status": model.CommissionStatusCancelled})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Withdraw requests a withdrawal of the wallet balance; the amount is deducted until an admin reviews it
+func (s *DistributionService) Withdraw(userID uint64, req request.WithdrawRequest) (*model.Withdrawal, error) {
+	amount := priceutils.Round(req.Amount)
+	if amount < s.config.MinWithdraw {
+		return nil, pkgerrors.ErrWithdrawBelowMinimum
+	}
+
+	withdrawal := &model.Withdrawal{
+		UserID: userID,
+		Amount: amount,
+		Status: model.WithdrawalStatusPending,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewDistributionRepository(tx).CreateWithdrawal(withdrawal); err != nil {
+			return err
+		}
+		return s.apply(tx, &model.WalletLedger{
+			UserID: userID,
+			Type:   model.WalletTypeWithdraw,
+			Change: -amount,
+			RefID:  withdrawal.ID,
+		}, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+// GetWithdrawals gets withdrawals with pagination, of a user when userID is set (admin lists all)
+func (s *DistributionService) GetWithdrawals(userID uint64, status *int, page, pageSize int) (*response.Pagination, error) {
+	withdrawals, total, err := s.distributionRepo.GetWithdrawals(userID, status, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, withdrawals)
+	return &pagination, nil
+}
+
+// ReviewWithdrawal approves or rejects a pending withdrawal (admin). An approved withdrawal is
+// transferred to the user offline; a rejected one is returned to the wallet.
+func (s *DistributionService) ReviewWithdrawal(reviewerID, id uint64, approve bool, req request.WithdrawalReviewRequest) error {
+	var withdrawal *model.Withdrawal
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		distributionRepo := repository.NewDistributionRepository(tx)
+		var err error
+		withdrawal, err = distributionRepo.LockWithdrawal(id)
+		if err != nil {
+			return pkgerrors.ErrWithdrawalNotFound
+		}
+		if withdrawal.Status != model.WithdrawalStatusPending {
+			return pkgerrors.ErrWithdrawalStatusInvalid
+		}
+
+		withdrawal.Status = model.WithdrawalStatusApproved
+		if !approve {
+			withdrawal.Status = model.WithdrawalStatusRejected
+		}
+		err = distributionRepo.UpdateWithdrawal(withdrawal.ID, map[string]interface{}{
+			"status":      withdrawal.Status,
+			"reviewer_id": reviewerID,
+			"remark":      req.Remark,
+			"reviewed_at": time.Now(),
+		})
+		if err != nil || approve {
+			return err
+		}
+
+		return s.apply(tx, &model.WalletLedger{
+			UserID: withdrawal.UserID,
+			Type:   model.WalletTypeWithdrawReturn,
+			Change: withdrawal.Amount,
+			RefID:  withdrawal.ID,
+			Remark: req.Remark,
+		}, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("您申请提现的 %.2f 元已审核通过，将尽快转账到您的账户", withdrawal.Amount)
+	if !approve {
+		content = fmt.Sprintf("您申请提现的 %.2f 元未通过审核，金额已退回钱包", withdrawal.Amount)
+		if req.Remark != "" {
+			content += "，原因：" + req.Remark
+		}
+	}
+	notify.Send(context.Background(), notify.Message{
+		UserID:  withdrawal.UserID,
+		Type:    notify.TypeWithdrawalReviewed,
+		Title:   "提现审核结果",
+		Content: content,
+		Data:    map[string]string{"withdrawalID": strconv.FormatUint(withdrawal.ID, 10)},
+	})
+	return nil
+}
+
+// GetRules gets all commission rules (admin)
+func (s *DistributionService) GetRules() ([]model.CommissionRule, error) {
+	return s.distributionRepo.GetRules()
+}
+
+// SaveRule creates or updates the commission rates of a product or a category (admin)
+func (s *DistributionService) SaveRule(req request.CommissionRuleRequest) error {
+	if req.Level1Rate+req.Level2Rate >= 1 {
+		return pkgerrors.ErrInvalidCommissionRule
+	}
+
+	rule := &model.CommissionRule{
+		ProductID:  req.ProductID,
+		CategoryID: req.CategoryID,
+		Level1Rate: req.Level1Rate,
+		Level2Rate: req.Level2Rate,
+	}
+	// 商品规则不区分分类
+	if rule.ProductID > 0 {
+		rule.CategoryID = 0
+	}
+	return s.distributionRepo.SaveRule(rule)
+}
+
+// DeleteRule removes a commission rule so the category or default rates apply (admin)
+func (s *DistributionService) DeleteRule(id uint64) error {
+	return s.distributionRepo.DeleteRule(id)
+}
+
+// calculateCommission 按商品、所属分类及由近到远的祖先分类、默认比例的优先级计算订单一级和二级佣金，
+// 以商品实付金额为计佣基数，积分抵扣的部分及运费不计佣
+func (s *DistributionService) calculateCommission(tx *gorm.DB, order *model.Order, items []model.OrderItem) (level1, level2, base float64, err error) {
+	var itemsAmount float64
+	for _, item := range items {
+		itemsAmount += item.Price*float64(item.Quantity) - item.PromotionDiscount
+	}
+	paid := order.PaymentAmount - order.ShippingFee
+	if itemsAmount <= 0 || paid <= 0 {
+		return 0, 0, 0, nil
+	}
+	payRatio := paid / itemsAmount
+
+	productIDs := make([]uint64, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	products, err := repository.NewProductRepository(tx).GetProductsByIDs(productIDs)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	categoryIDs := make([]uint64, 0, len(products)*2)
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+		categoryIDs = append(categoryIDs, categoryutils.PathIDs(product.CategoryPath)...)
+	}
+
+	rules, err := repository.NewDistributionRepository(tx).GetMatchingRules(productIDs, categoryIDs)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	productRules := make(map[uint64]model.CommissionRule, len(rules))
+	categoryRules := make(map[uint64]model.CommissionRule, len(rules))
+	for _, rule := range rules {
+		if rule.ProductID > 0 {
+			productRules[rule.ProductID] = rule
+		} else {
+			categoryRules[rule.CategoryID] = rule
+		}
+	}
+
+	for _, item := range items {
+		rule := model.CommissionRule{Level1Rate: s.config.Level1Rate, Level2Rate: s.config.Level2Rate}
+		product := productMap[item.ProductID]
+		if r, ok := productRules[item.ProductID]; ok {
+			rule = r
+		} else if categoryID, ok := closestCategory(product.CategoryPath, categoryRules); ok {
+			rule = categoryRules[categoryID]
+		}
+
+		amount := (item.Price*float64(item.Quantity) - item.PromotionDiscount) * payRatio
+		base += amount
+		level1 += amount * rule.Level1Rate
+		level2 += amount * rule.Level2Rate
+	}
+
+	return priceutils.Round(level1), priceutils.Round(level2), priceutils.Round(base), nil
+}
+
+// apply 写入钱包流水并更新用户钱包余额；allowNegative 为 false 时余额不足返回错误
+func (s *DistributionService) apply(tx *gorm.DB, ledger *model.WalletLedger, allowNegative bool) error {
+	userRepo := repository.NewUserRepository(tx)
+
+	user, err := userRepo.GetUserByIDForUpdate(ledger.UserID)
+	if err != nil {
+		return err
+	}
+
+	balance := priceutils.Round(user.Wallet + ledger.Change)
+	if balance < 0 && !allowNegative {
+		return pkgerrors.ErrInsufficientBalance
+	}
+
+	ledger.Balance = balance
+	if err := repository.NewDistributionRepository(tx).CreateWalletLedger(ledger); err != nil {
+		return err
+	}
+
+	return userRepo.UpdateUserWallet(ledger.UserID, balance)
+}
+
+// referralShareScene 生成分销小程序码scene参数，长度不超过32个字符
+func referralShareScene(productID, referrerID uint64) string {
+	return fmt.Sprintf("p=%d&u=%d", productID, referrerID)
+}
+
+// parseReferralScene 解析小程序码scene参数中的推荐人，拼团等其他分享码携带的邀请人同样有效
+func parseReferralScene(scene string) (referrerID, productID uint64, err error) {
+	// 小程序码传入的scene需要先解码
+	if decoded, err := url.QueryUnescape(scene); err == nil {
+		scene = decoded
+	}
+	values, err := url.ParseQuery(scene)
+	if err != nil {
+		return 0, 0, pkgerrors.ErrInvalidReferralScene
+	}
+
+	referrerID, err = strconv.ParseUint(values.Get("u"), 10, 64)
+	if err != nil || referrerID == 0 {
+		return 0, 0, pkgerrors.ErrInvalidReferralScene
+	}
+	// 商品可选
+	productID, _ = strconv.ParseUint(values.Get("p"), 10, 64)
+	return referrerID, productID, nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/minio"
+	"github.com/colinjuang/shop-go/internal/pkg/notify"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+)
+
+// favoriteWatchBatch 巡检收藏时每批处理的数量
+const favoriteWatchBatch = 500
+
+// FavoriteService handles favorites and watches them for restocks and price drops
+type FavoriteService struct {
+	favoriteRepo  *repository.FavoriteRepository
+	productRepo   *repository.ProductRepository
+	bundleService *BundleService
+}
+
+// NewFavoriteService creates a new favorite service
+func NewFavoriteService() *FavoriteService {
+	server := server.GetServer()
+	return &FavoriteService{
+		favoriteRepo:  repository.NewFavoriteRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		bundleService: NewBundleService(),
+	}
+}
+
+// AddFavorite saves a product for the user at its current price. Saving an already saved product keeps the original price.
+func (s *FavoriteService) AddFavorite(userID, productID uint64) error {
+	product, err := s.productRepo.GetProductByID(productID)
+	if err != nil {
+		return pkgerrors.ErrProductNotFound
+	}
+	if err := s.bundleService.FillStock(product); err != nil {
+		return err
+	}
+
+	return s.favoriteRepo.AddFavorite(&model.Favorite{
+		UserID:     userID,
+		ProductID:  productID,
+		SavedPrice: product.Price,
+		OutOfStock: product.StockCount <= 0,
+	})
+}
+
+// RemoveFavorite removes a product from the user's favorites
+func (s *FavoriteService) RemoveFavorite(userID, productID uint64) error {
+	return s.favoriteRepo.DeleteFavorite(userID, productID)
+}
+
+// GetFavorites gets the user's favorites with current price and stock
+func (s *FavoriteService) GetFavorites(userID uint64, page, pageSize int) (*response.Pagination, error) {
+	favorites, total, err := s.favoriteRepo.GetUserFavorites(userID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*model.Product, len(favorites))
+	for i := range favorites {
+		products[i] = &favorites[i].Product
+	}
+	if err := s.bundleService.FillStock(products...); err != nil {
+		return nil, err
+	}
+
+	minioClient := minio.GetClient()
+	responses := make([]response.FavoriteResponse, len(favorites))
+	for i, favorite := range favorites {
+		responses[i] = response.FavoriteResponse{
+			ProductID:    favorite.ProductID,
+			Name:         favorite.Product.Name,
+			ImageUrl:     minioClient.GetFileURL(favorite.Product.ImageUrl),
+			SavedPrice:   favorite.SavedPrice,
+			Price:        favorite.Product.Price,
+			PriceDropped: favorite.Product.Price < favorite.SavedPrice,
+			StockCount:   favorite.Product.StockCount,
+			Status:       favorite.Product.Status,
+			CreatedAt:    favorite.CreatedAt,
+		}
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, responses)
+	return &pagination, nil
+}
+
+// GetFavoriteInfo gets the favorite count of a product and whether the viewer (userID 0 for guests) saved it
+func (s *FavoriteService) GetFavoriteInfo(productID, userID uint64) (int64, bool, error) {
+	count, err := s.favoriteRepo.CountByProductID(productID)
+	if err != nil {
+		return 0, false, err
+	}
+	if userID == 0 {
+		return count, false, nil
+	}
+	favorited, err := s.favoriteRepo.IsFavorite(userID, productID)
+	if err != nil {
+		return 0, false, err
+	}
+	return count, favorited, nil
+}
+
+// WatchFavorites checks favorited products and notifies users when a product comes back in stock
+// or its price drops below the saved price. Each restock and each new lower price is notified once.
+func (s *FavoriteService) WatchFavorites(ctx context.Context) error {
+	var lastID uint64
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		favorites, err := s.favoriteRepo.GetFavoritesAfterID(lastID, favoriteWatchBatch)
+		if err != nil {
+			return err
+		}
+		if len(favorites) == 0 {
+			return nil
+		}
+
+		products := make([]*model.Product, len(favorites))
+		for i := range favorites {
+			products[i] = &favorites[i].Product
+		}
+		if err := s.bundleService.FillStock(products...); err != nil {
+			return err
+		}
+
+		for _, favorite := range favorites {
+			if err := s.watchFavorite(ctx, favorite); err != nil {
+				return err
+			}
+		}
+		lastID = favorites[len(favorites)-1].ID
+	}
+}
+
+// watchFavorite 检查单个收藏的到货和降价，发送提醒并记录已提醒的状态
+func (s *FavoriteService) watchFavorite(ctx context.Context, favorite model.Favorite) error {
+	product := favorite.Product
+	if product.ID == 0 {
+		return nil
+	}
+	onSale := product.Status == 1
+	updates := make(map[string]interface{})
+	data := map[string]string{"productID": strconv.FormatUint(product.ID, 10)}
+
+	// 到货提醒
+	inStock := onSale && product.StockCount > 0
+	if favorite.OutOfStock && inStock {
+		notify.Send(ctx, notify.Message{
+			UserID:  favorite.UserID,
+			Type:    notify.TypeBackInStock,
+			Title:   "收藏的商品到货了",
+			Content: fmt.Sprintf("您收藏的「%s」已到货，数量有限，欢迎选购", product.Name),
+			Data:    data,
+		})
+		updates["out_of_stock"] = false
+	} else if !favorite.OutOfStock && !inStock {
+		updates["out_of_stock"] = true
+	}
+
+	// 降价提醒，低于收藏价且低于上次提醒的价格时提醒
+	threshold := favorite.SavedPrice
+	if favorite.NotifiedPrice > 0 {
+		threshold = min(threshold, favorite.NotifiedPrice)
+	}
+	if inStock && product.Price < threshold {
+		notify.Send(ctx, notify.Message{
+			UserID:  favorite.UserID,
+			Type:    notify.TypePriceDrop,
+			Title:   "收藏的商品降价了",
+			Content: fmt.Sprintf("您收藏的「%s」已从 %.2f 元降至 %.2f 元", product.Name, favorite.SavedPrice, product.Price),
+			Data:    data,
+		})
+		updates["notified_price"] = product.Price
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+	return s.favoriteRepo.UpdateFavorite(favorite.ID, updates)
+}
@@ -0,0 +1,785 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	"gorm.io/gorm"
+)
+
+// deductScript 原子扣减秒杀库存并检查限购，成功后写入下单队列。
+// 返回 1 成功，-1 库存未加载，-2 库存不足，-3 超出限购
+var deductScript = redis.NewScript(`
+local stock = redis.call('GET', KEYS[1])
+if not stock then
+	return -1
+end
+local quantity = tonumber(ARGV[2])
+if tonumber(stock) < quantity then
+	return -2
+end
+local limit = tonumber(ARGV[3])
+local bought = tonumber(redis.call('HGET', KEYS[2], ARGV[1]) or '0')
+if limit > 0 and bought + quantity > limit then
+	return -3
+end
+redis.call('DECRBY', KEYS[1], quantity)
+redis.call('HINCRBY', KEYS[2], ARGV[1], quantity)
+redis.call('RPUSH', KEYS[3], ARGV[4])
+redis.call('SET', KEYS[4], ARGV[5], 'EX', ARGV[6])
+return 1
+`)
+
+// syncScript 在没有待处理请求且库存未被改动时修正Redis库存
+var syncScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if redis.call('LLEN', KEYS[2]) > 0 or redis.call('LLEN', KEYS[3]) > 0 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'KEEPTTL')
+return 1
+`)
+
+// flashSaleMessage 下单队列中的抢购请求
+type flashSaleMessage struct {
+	RequestID   string                      `json:"requestID"`
+	FlashSaleID uint64                      `json:"flashSaleID"`
+	UserID      uint64                      `json:"userID"`
+	Request     request.FlashSaleBuyRequest `json:"request"`
+	CreatedAt   int64                       `json:"createdAt"`
+}
+
+// flashSaleResult 缓存的抢购结果
+type flashSaleResult struct {
+	UserID uint64 `json:"userID"`
+	response.FlashSaleResultResponse
+}
+
+// FlashSaleService handles flash sales: Redis stock pre-deduction, the order queue and result polling.
+// Redis only gates traffic; the database sold count is the source of truth and is checked again
+// when the order is created, so a Redis/MySQL mismatch can undersell but never oversell.
+type FlashSaleService struct {
+	db            *gorm.DB
+	flashSaleRepo *repository.FlashSaleRepository
+	productRepo   *repository.ProductRepository
+	addressRepo   *repository.AddressRepository
+	orderService  *OrderService
+	cacheService  *redis.CacheService
+	redisClient   *redis.Client
+	config        config.FlashSaleConfig
+}
+
+// NewFlashSaleService creates a new flash sale service
+func NewFlashSaleService() *FlashSaleService {
+	server := server.GetServer()
+	return &FlashSaleService{
+		db:            server.DB,
+		flashSaleRepo: repository.NewFlashSaleRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		addressRepo:   repository.NewAddressRepository(server.DB),
+		orderService:  NewOrderService(),
+		cacheService:  redis.NewCacheService(),
+		redisClient:   redis.GetClient(),
+		config:        server.GetConfig().FlashSale,
+	}
+}
+
+// GetActiveFlashSales gets ongoing and upcoming flash sales
+func (s *FlashSaleService) GetActiveFlashSales() ([]*response.FlashSaleResponse, error) {
+	sales, err := s.flashSaleRepo.GetActiveFlashSales(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.toFlashSaleResponses(sales)
+}
+
+// GetFlashSale gets a flash sale with the remaining quantity
+func (s *FlashSaleService) GetFlashSale(id uint64) (*response.FlashSaleResponse, error) {
+	sale, err := s.getFlashSale(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.toFlashSaleResponses([]model.FlashSale{*sale})
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+// GetFlashSales gets all flash sales with pagination (admin)
+func (s *FlashSaleService) GetFlashSales(page, pageSize int) (*response.Pagination, error) {
+	sales, total, err := s.flashSaleRepo.GetFlashSales(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.toFlashSaleResponses(sales)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, responses)
+	return &pagination, nil
+}
+
+// CreateFlashSale creates a flash sale and preloads its stock into Redis (admin)
+func (s *FlashSaleService) CreateFlashSale(req request.FlashSaleRequest) (*model.FlashSale, error) {
+	if _, err := s.productRepo.GetProductByID(req.ProductID); err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+
+	sale := &model.FlashSale{
+		ProductID:    req.ProductID,
+		Title:        req.Title,
+		DealPrice:    req.DealPrice,
+		Quantity:     req.Quantity,
+		LimitPerUser: req.LimitPerUser,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		Status:       model.FlashSaleStatusEnabled,
+	}
+	if err := s.flashSaleRepo.CreateFlashSale(sale); err != nil {
+		return nil, err
+	}
+
+	if err := s.resetStock(context.Background(), sale); err != nil {
+		// 抢购时发现库存未加载会自动从数据库加载
+		logger.Warnf("Failed to preload flash sale %d stock: %v", sale.ID, err)
+	}
+	return sale, nil
+}
+
+// UpdateFlashSale updates a flash sale that has not started yet (admin)
+func (s *FlashSaleService) UpdateFlashSale(id uint64, req request.FlashSaleRequest) (*model.FlashSale, error) {
+	sale, err := s.flashSaleRepo.GetFlashSaleByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrFlashSaleNotFound
+	}
+	if !time.Now().Before(sale.StartTime) {
+		return nil, pkgerrors.ErrFlashSaleStarted
+	}
+	if _, err := s.productRepo.GetProductByID(req.ProductID); err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+
+	sale.ProductID = req.ProductID
+	sale.Title = req.Title
+	sale.DealPrice = req.DealPrice
+	sale.Quantity = req.Quantity
+	sale.LimitPerUser = req.LimitPerUser
+	sale.StartTime = req.StartTime
+	sale.EndTime = req.EndTime
+	if err := s.flashSaleRepo.UpdateFlashSale(sale); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	s.cacheService.Delete(ctx, fmt.Sprintf(constant.FlashSaleInfo+"%d", id))
+	if err := s.resetStock(ctx, sale); err != nil {
+		logger.Warnf("Failed to preload flash sale %d stock: %v", sale.ID, err)
+	}
+	return sale, nil
+}
+
+// SetFlashSaleStatus enables or disables a flash sale (admin)
+func (s *FlashSaleService) SetFlashSaleStatus(id uint64, status int) error {
+	if _, err := s.flashSaleRepo.GetFlashSaleByID(id); err != nil {
+		return pkgerrors.ErrFlashSaleNotFound
+	}
+
+	if err := s.flashSaleRepo.UpdateFlashSaleStatus(id, status); err != nil {
+		return err
+	}
+
+	s.cacheService.Delete(context.Background(), fmt.Sprintf(constant.FlashSaleInfo+"%d", id))
+	return nil
+}
+
+// Buy deducts the flash sale stock in Redis and queues the purchase for asynchronous order creation.
+// The returned request ID is used to poll the result.
+func (s *FlashSaleService) Buy(userID, saleID uint64, req request.FlashSaleBuyRequest) (*response.FlashSaleResultResponse, error) {
+	ctx := context.Background()
+
+	sale, err := s.getFlashSale(saleID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if sale.Status != model.FlashSaleStatusEnabled || !now.Before(sale.EndTime) {
+		return nil, pkgerrors.ErrFlashSaleEnded
+	}
+	if now.Before(sale.StartTime) {
+		return nil, pkgerrors.ErrFlashSaleNotStarted
+	}
+	if sale.LimitPerUser > 0 && req.Quantity > sale.LimitPerUser {
+		return nil, pkgerrors.ErrFlashSaleLimitExceeded
+	}
+
+	address, err := s.addressRepo.GetAddressByID(req.AddressID)
+	if err != nil || address.UserID != userID {
+		return nil, pkgerrors.ErrAddressNotFound
+	}
+
+	requestID, err := newRequestID()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(flashSaleMessage{
+		RequestID:   requestID,
+		FlashSaleID: saleID,
+		UserID:      userID,
+		Request:     req,
+		CreatedAt:   now.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := flashSaleResult{
+		UserID: userID,
+		FlashSaleResultResponse: response.FlashSaleResultResponse{
+			RequestID: requestID,
+			Status:    response.FlashSaleResultQueued,
+		},
+	}
+	queued, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := s.deduct(ctx, sale, userID, req.Quantity, requestID, payload, queued)
+	if err != nil {
+		return nil, err
+	}
+	// 库存未加载时从数据库加载后重试一次
+	if code == -1 {
+		if err := s.loadStock(ctx, sale); err != nil {
+			return nil, err
+		}
+		if code, err = s.deduct(ctx, sale, userID, req.Quantity, requestID, payload, queued); err != nil {
+			return nil, err
+		}
+	}
+
+	switch code {
+	case 1:
+		return &result.FlashSaleResultResponse, nil
+	case -3:
+		return nil, pkgerrors.ErrFlashSaleLimitExceeded
+	default:
+		return nil, pkgerrors.ErrFlashSaleSoldOut
+	}
+}
+
+// GetResult gets the result of a purchase request
+func (s *FlashSaleService) GetResult(userID uint64, requestID string) (*response.FlashSaleResultResponse, error) {
+	var result flashSaleResult
+	err := s.cacheService.GetObject(context.Background(), constant.FlashSaleResult+requestID, &result)
+	if err == nil {
+		if result.UserID != userID {
+			return nil, pkgerrors.ErrFlashSaleResultNotFound
+		}
+		return &result.FlashSaleResultResponse, nil
+	}
+
+	// 结果已过期或Redis数据丢失时以数据库为准
+	saleOrder, err := s.flashSaleRepo.GetFlashSaleOrderByRequestID(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if saleOrder == nil || saleOrder.UserID != userID {
+		return nil, pkgerrors.ErrFlashSaleResultNotFound
+	}
+
+	order, err := repository.NewOrderRepository(s.db).GetOrderByID(saleOrder.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	return &response.FlashSaleResultResponse{
+		RequestID: requestID,
+		Status:    response.FlashSaleResultSuccess,
+		OrderID:   order.ID,
+		OrderNo:   order.OrderNo,
+	}, nil
+}
+
+// ConsumeOrders creates orders for queued purchases until ctx is cancelled.
+// Messages are moved to a processing list while being handled so that they survive a crash;
+// SyncStock puts stale ones back to the queue.
+func (s *FlashSaleService) ConsumeOrders(ctx context.Context) {
+	for ctx.Err() == nil {
+		payload, err := s.redisClient.ListBlockingMove(ctx, constant.FlashSaleQueue, constant.FlashSaleProcessing, 2*time.Second)
+		if err == redis.ErrNil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warnf("Failed to read flash sale queue: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		// 记录取出时间，超时按取出时间而不是下单时间计算，排队久的请求不会在处理中被放回队列
+		if err := s.redisClient.HashSet(ctx, constant.FlashSaleProcessingAt, payload, time.Now().Unix()); err != nil {
+			logger.Warnf("Failed to record flash sale processing time: %v", err)
+		}
+
+		s.processOrder(ctx, payload)
+
+		if err := s.redisClient.ListRemove(ctx, constant.FlashSaleProcessing, 1, payload); err != nil {
+			logger.Warnf("Failed to remove processed flash sale request: %v", err)
+		}
+		if err := s.redisClient.HashDelete(ctx, constant.FlashSaleProcessingAt, payload); err != nil {
+			logger.Warnf("Failed to clear flash sale processing time: %v", err)
+		}
+	}
+}
+
+// CancelUnpaidOrders cancels flash sale orders that are not paid in time, releasing their quota
+func (s *FlashSaleService) CancelUnpaidOrders(ctx context.Context) error {
+	if s.config.PayTimeout <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-time.Duration(s.config.PayTimeout) * time.Minute)
+	orders, err := s.flashSaleRepo.GetUnpaidFlashSaleOrders(before, 500)
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := s.orderService.CancelOrder(order.UserID, order.OrderID)
+		if err != nil && err != pkgerrors.ErrOrderStatusInvalid {
+			logger.Warnf("Failed to cancel unpaid flash sale order %d: %v", order.OrderID, err)
+		}
+	}
+	return nil
+}
+
+// SyncStock puts stale processing messages back to the queue and repairs the Redis stock of
+// active flash sales from the database when no purchase is in flight
+func (s *FlashSaleService) SyncStock(ctx context.Context) error {
+	if err := s.requeueStale(ctx, 5*time.Minute); err != nil {
+		return err
+	}
+
+	sales, err := s.flashSaleRepo.GetActiveFlashSales(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range sales {
+		sale := &sales[i]
+		key := fmt.Sprintf(constant.FlashSaleStock+"%d", sale.ID)
+
+		current, err := s.redisClient.Get(ctx, key)
+		if err == redis.ErrNil {
+			if err := s.loadStock(ctx, sale); err != nil {
+				logger.Warnf("Failed to load flash sale %d stock: %v", sale.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// 重新读取数据库，保证读取时间晚于Redis
+		fresh, err := s.flashSaleRepo.GetFlashSaleByID(sale.ID)
+		if err != nil {
+			return err
+		}
+		remaining := fresh.Quantity - fresh.Sold
+		stock, _ := strconv.Atoi(current)
+		// Redis库存只会因崩溃少于数据库；多于数据库时由下单事务兜底，不做修正
+		if stock >= remaining {
+			continue
+		}
+
+		keys := []string{key, constant.FlashSaleQueue, constant.FlashSaleProcessing}
+		synced, err := s.redisClient.RunScript(ctx, syncScript, keys, current, remaining)
+		if err != nil {
+			return err
+		}
+		if n, _ := synced.(int64); n == 1 {
+			logger.Infof("Flash sale %d stock synced from %d to %d", sale.ID, stock, remaining)
+		}
+	}
+	return nil
+}
+
+// processOrder 为队列中的抢购请求创建订单
+func (s *FlashSaleService) processOrder(ctx context.Context, payload string) {
+	var msg flashSaleMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		logger.Errorf("Invalid flash sale message %s: %v", payload, err)
+		return
+	}
+
+	// 重复投递时直接返回已生成的订单
+	if s.recordExistingResult(ctx, msg) {
+		return
+	}
+
+	sale, err := s.flashSaleRepo.GetFlashSaleByID(msg.FlashSaleID)
+	if err != nil {
+		s.fail(ctx, msg, err, true)
+		return
+	}
+
+	params := DealOrderParams{
+		ProductID: sale.ProductID,
+		DealPrice: sale.DealPrice,
+		AddressID: msg.Request.AddressID,
+		Quantity:  msg.Request.Quantity,
+		Blessing:  msg.Request.Blessing,
+		Remark:    msg.Request.Remark,
+	}
+	order, err := s.orderService.CreateDealOrder(msg.UserID, params,
+		func(tx *gorm.DB, order *model.Order) error {
+			flashSaleRepo := repository.NewFlashSaleRepository(tx)
+
+			// 条件更新已售数量，数据库层面保证不超卖，同时锁住活动行
+			ok, err := flashSaleRepo.IncreaseSold(sale.ID, msg.Request.Quantity)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return pkgerrors.ErrFlashSaleSoldOut
+			}
+
+			if sale.LimitPerUser > 0 {
+				purchased, err := flashSaleRepo.GetUserPurchased(sale.ID, msg.UserID)
+				if err != nil {
+					return err
+				}
+				if purchased+msg.Request.Quantity > sale.LimitPerUser {
+					return pkgerrors.ErrFlashSaleLimitExceeded
+				}
+			}
+
+			return flashSaleRepo.CreateFlashSaleOrder(&model.FlashSaleOrder{
+				FlashSaleID: sale.ID,
+				UserID:      msg.UserID,
+				RequestID:   msg.RequestID,
+				OrderID:     order.ID,
+				Quantity:    msg.Request.Quantity,
+				Status:      model.FlashSaleOrderStatusCreated,
+			})
+		})
+	if err != nil {
+		// 并发重复处理时以已提交的记录为准，不再回补库存
+		if s.recordExistingResult(ctx, msg) {
+			return
+		}
+		s.fail(ctx, msg, err, err != pkgerrors.ErrFlashSaleSoldOut)
+		return
+	}
+
+	s.setResult(ctx, msg.UserID, response.FlashSaleResultResponse{
+		RequestID: msg.RequestID,
+		Status:    response.FlashSaleResultSuccess,
+		OrderID:   order.ID,
+		OrderNo:   order.OrderNo,
+	})
+}
+
+// recordExistingResult 请求已生成订单时写入成功结果并返回true
+func (s *FlashSaleService) recordExistingResult(ctx context.Context, msg flashSaleMessage) bool {
+	saleOrder, err := s.flashSaleRepo.GetFlashSaleOrderByRequestID(msg.RequestID)
+	if err != nil || saleOrder == nil {
+		return false
+	}
+
+	result := response.FlashSaleResultResponse{
+		RequestID: msg.RequestID,
+		Status:    response.FlashSaleResultSuccess,
+		OrderID:   saleOrder.OrderID,
+	}
+	if order, err := repository.NewOrderRepository(s.db).GetOrderByID(saleOrder.OrderID); err == nil {
+		result.OrderNo = order.OrderNo
+	}
+	s.setResult(ctx, msg.UserID, result)
+	return true
+}
+
+// fail 记录抢购失败结果并回补Redis中的用户已购数量；restoreStock 为 false 时不回补库存
+func (s *FlashSaleService) fail(ctx context.Context, msg flashSaleMessage, cause error, restoreStock bool) {
+	logger.Warnf("Flash sale request %s of user %d failed: %v", msg.RequestID, msg.UserID, cause)
+
+	if restoreStock {
+		key := fmt.Sprintf(constant.FlashSaleStock+"%d", msg.FlashSaleID)
+		if _, err := s.redisClient.IncrBy(ctx, key, int64(msg.Request.Quantity)); err != nil {
+			logger.Warnf("Failed to restore flash sale %d stock: %v", msg.FlashSaleID, err)
+		}
+	}
+	boughtKey := fmt.Sprintf(constant.FlashSaleBought+"%d", msg.FlashSaleID)
+	if _, err := s.redisClient.HashIncrBy(ctx, boughtKey, strconv.FormatUint(msg.UserID, 10), -int64(msg.Request.Quantity)); err != nil {
+		logger.Warnf("Failed to restore flash sale %d purchases of user %d: %v", msg.FlashSaleID, msg.UserID, err)
+	}
+
+	s.setResult(ctx, msg.UserID, response.FlashSaleResultResponse{
+		RequestID: msg.RequestID,
+		Status:    response.FlashSaleResultFailed,
+		Reason:    flashSaleFailReason(cause),
+	})
+}
+
+// setResult 写入抢购结果
+func (s *FlashSaleService) setResult(ctx context.Context, userID uint64, result response.FlashSaleResultResponse) {
+	err := s.cacheService.Set(ctx, constant.FlashSaleResult+result.RequestID, flashSaleResult{
+		UserID:                  userID,
+		FlashSaleResultResponse: result,
+	}, s.resultTTL())
+	if err != nil {
+		logger.Warnf("Failed to save flash sale result %s: %v", result.RequestID, err)
+	}
+}
+
+// deduct 执行库存扣减脚本
+func (s *FlashSaleService) deduct(ctx context.Context, sale *model.FlashSale, userID uint64, quantity int, requestID string, payload, queued []byte) (int64, error) {
+	keys := []string{
+		fmt.Sprintf(constant.FlashSaleStock+"%d", sale.ID),
+		fmt.Sprintf(constant.FlashSaleBought+"%d", sale.ID),
+		constant.FlashSaleQueue,
+		constant.FlashSaleResult + requestID,
+	}
+	result, err := s.redisClient.RunScript(ctx, deductScript, keys,
+		userID, quantity, sale.LimitPerUser, payload, queued, int(s.resultTTL().Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	code, _ := result.(int64)
+	return code, nil
+}
+
+// loadStock 库存未加载时从数据库加载剩余库存及用户已购数量
+func (s *FlashSaleService) loadStock(ctx context.Context, sale *model.FlashSale) error {
+	lock := redis.NewLock(fmt.Sprintf("flash_sale:load:%d", sale.ID), 5*time.Second)
+	if err := lock.Acquire(ctx); err != nil {
+		return err
+	}
+	defer lock.Release(ctx)
+
+	key := fmt.Sprintf(constant.FlashSaleStock+"%d", sale.ID)
+	exists, err := s.redisClient.Exists(ctx, key)
+	if err != nil || exists {
+		return err
+	}
+	return s.resetStock(ctx, sale)
+}
+
+// resetStock 按数据库重置Redis中的剩余库存及用户已购数量
+func (s *FlashSaleService) resetStock(ctx context.Context, sale *model.FlashSale) error {
+	fresh, err := s.flashSaleRepo.GetFlashSaleByID(sale.ID)
+	if err != nil {
+		return err
+	}
+	purchased, err := s.flashSaleRepo.GetPurchasedByUsers(sale.ID)
+	if err != nil {
+		return err
+	}
+
+	expiration := time.Until(fresh.EndTime) + 24*time.Hour
+	boughtKey := fmt.Sprintf(constant.FlashSaleBought+"%d", sale.ID)
+	if err := s.redisClient.Delete(ctx, boughtKey); err != nil {
+		return err
+	}
+	for _, item := range purchased {
+		if err := s.redisClient.HashSet(ctx, boughtKey, strconv.FormatUint(item.UserID, 10), item.Quantity); err != nil {
+			return err
+		}
+	}
+	if err := s.redisClient.Expire(ctx, boughtKey, expiration); err != nil {
+		return err
+	}
+
+	return s.redisClient.Set(ctx, fmt.Sprintf(constant.FlashSaleStock+"%d", sale.ID), fresh.Quantity-fresh.Sold, expiration)
+}
+
+// requeueStale 将取出后处理超时的请求放回下单队列，订单创建是幂等的，重复处理不会重复下单
+func (s *FlashSaleService) requeueStale(ctx context.Context, age time.Duration) error {
+	payloads, err := s.redisClient.ListRange(ctx, constant.FlashSaleProcessing, 0, -1)
+	if err != nil {
+		return err
+	}
+	startedAt, err := s.redisClient.HashGetAll(ctx, constant.FlashSaleProcessingAt)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deadline := now.Add(-age).Unix()
+	processing := make(map[string]bool, len(payloads))
+	for _, payload := range payloads {
+		processing[payload] = true
+		started, ok := startedAt[payload]
+		if !ok {
+			// 取出后还未记录时间或记录前崩溃，从本次检查开始计时
+			if err := s.redisClient.HashSet(ctx, constant.FlashSaleProcessingAt, payload, now.Unix()); err != nil {
+				return err
+			}
+			continue
+		}
+		if at, err := strconv.ParseInt(started, 10, 64); err == nil && at > deadline {
+			continue
+		}
+
+		if err := s.redisClient.ListRemove(ctx, constant.FlashSaleProcessing, 1, payload); err != nil {
+			return err
+		}
+		if err := s.redisClient.HashDelete(ctx, constant.FlashSaleProcessingAt, payload); err != nil {
+			return err
+		}
+		if err := s.redisClient.ListPush(ctx, constant.FlashSaleQueue, payload); err != nil {
+			return err
+		}
+		logger.Warnf("Requeued stale flash sale request: %s", payload)
+	}
+
+	// 清理已不在处理中队列的记录，如处理完成后删除记录前崩溃
+	var finished []string
+	for payload := range startedAt {
+		if !processing[payload] {
+			finished = append(finished, payload)
+		}
+	}
+	if len(finished) > 0 {
+		return s.redisClient.HashDelete(ctx, constant.FlashSaleProcessingAt, finished...)
+	}
+	return nil
+}
+
+// getFlashSale 获取秒杀活动，短时间缓存以减轻抢购时数据库压力
+func (s *FlashSaleService) getFlashSale(id uint64) (*model.FlashSale, error) {
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf(constant.FlashSaleInfo+"%d", id)
+
+	var sale model.FlashSale
+	if err := s.cacheService.GetObject(ctx, cacheKey, &sale); err == nil {
+		return &sale, nil
+	}
+
+	salePtr, err := s.flashSaleRepo.GetFlashSaleByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrFlashSaleNotFound
+	}
+
+	if err := s.cacheService.Set(ctx, cacheKey, salePtr, 10*time.Second); err != nil {
+		logger.Warnf("Failed to cache flash sale: %v", err)
+	}
+	return salePtr, nil
+}
+
+// toFlashSaleResponses 转换秒杀活动响应，剩余数量优先取Redis库存
+func (s *FlashSaleService) toFlashSaleResponses(sales []model.FlashSale) ([]*response.FlashSaleResponse, error) {
+	productIDs := make([]uint64, len(sales))
+	for i, sale := range sales {
+		productIDs[i] = sale.ProductID
+	}
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	responses := make([]*response.FlashSaleResponse, len(sales))
+	for i, sale := range sales {
+		remaining := sale.Quantity - sale.Sold
+		if stock, err := s.redisClient.Get(ctx, fmt.Sprintf(constant.FlashSaleStock+"%d", sale.ID)); err == nil {
+			if n, err := strconv.Atoi(stock); err == nil && n < remaining {
+				remaining = n
+			}
+		}
+
+		product := productMap[sale.ProductID]
+		responses[i] = &response.FlashSaleResponse{
+			ID:           sale.ID,
+			ProductID:    sale.ProductID,
+			ProductName:  product.Name,
+			ImageUrl:     product.ImageUrl,
+			Title:        sale.Title,
+			Price:        product.Price,
+			DealPrice:    sale.DealPrice,
+			Quantity:     sale.Quantity,
+			Remaining:    remaining,
+			LimitPerUser: sale.LimitPerUser,
+			StartTime:    sale.StartTime,
+			EndTime:      sale.EndTime,
+			Started:      !now.Before(sale.StartTime),
+		}
+	}
+	return responses, nil
+}
+
+func (s *FlashSaleService) resultTTL() time.Duration {
+	if s.config.ResultTTL <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(s.config.ResultTTL) * time.Minute
+}
+
+// releaseFlashSaleQuota 秒杀订单取消后归还Redis中的库存及用户已购数量
+func releaseFlashSaleQuota(ctx context.Context, order *model.FlashSaleOrder) {
+	client := redis.GetClient()
+	if _, err := client.IncrBy(ctx, fmt.Sprintf(constant.FlashSaleStock+"%d", order.FlashSaleID), int64(order.Quantity)); err != nil {
+		logger.Warnf("Failed to release flash sale %d stock: %v", order.FlashSaleID, err)
+	}
+	boughtKey := fmt.Sprintf(constant.FlashSaleBought+"%d", order.FlashSaleID)
+	if _, err := client.HashIncrBy(ctx, boughtKey, strconv.FormatUint(order.UserID, 10), -int64(order.Quantity)); err != nil {
+		logger.Warnf("Failed to release flash sale %d purchases of user %d: %v", order.FlashSaleID, order.UserID, err)
+	}
+}
+
+// flashSaleFailReason 抢购失败原因
+func flashSaleFailReason(err error) string {
+	switch err {
+	case pkgerrors.ErrFlashSaleSoldOut:
+		return "已抢光"
+	case pkgerrors.ErrFlashSaleLimitExceeded:
+		return "超出限购数量"
+	case pkgerrors.ErrAddressNotFound:
+		return "收货地址无效"
+	case pkgerrors.ErrOutOfStock:
+		return "商品库存不足"
+	default:
+		return "下单失败，请稍后重试"
+	}
+}
+
+// newRequestID 生成抢购请求ID
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,610 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/notify"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	"gorm.io/gorm"
+)
+
+// GroupBuyService handles group-buy campaigns. A buyer opens a group at the group price and shares it;
+// the group succeeds when enough participants pay before it expires, otherwise every paid participant
+// is refunded by ExpireGroups. Paid orders wait in the grouping status until the group succeeds.
+type GroupBuyService struct {
+	db           *gorm.DB
+	groupBuyRepo *repository.GroupBuyRepository
+	productRepo  *repository.ProductRepository
+	userRepo     *repository.UserRepository
+	orderService *OrderService
+	config       config.GroupBuyConfig
+}
+
+// NewGroupBuyService creates a new group-buy service
+func NewGroupBuyService() *GroupBuyService {
+	server := server.GetServer()
+	return &GroupBuyService{
+		db:           server.DB,
+		groupBuyRepo: repository.NewGroupBuyRepository(server.DB),
+		productRepo:  repository.NewProductRepository(server.DB),
+		userRepo:     repository.NewUserRepository(server.DB),
+		orderService: NewOrderService(),
+		config:       server.GetConfig().GroupBuy,
+	}
+}
+
+// GetActiveCampaigns gets ongoing group-buy campaigns
+func (s *GroupBuyService) GetActiveCampaigns() ([]*response.GroupBuyCampaignResponse, error) {
+	campaigns, err := s.groupBuyRepo.GetActiveCampaigns(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.toCampaignResponses(campaigns)
+}
+
+// GetCampaign gets a campaign with the open groups that can be joined
+func (s *GroupBuyService) GetCampaign(id uint64) (*response.GroupBuyCampaignResponse, error) {
+	campaign, err := s.groupBuyRepo.GetCampaignByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrGroupBuyNotFound
+	}
+
+	responses, err := s.toCampaignResponses([]model.GroupBuyCampaign{*campaign})
+	if err != nil {
+		return nil, err
+	}
+	resp := responses[0]
+
+	groups, err := s.groupBuyRepo.GetOpenGroups(id, time.Now(), 10)
+	if err != nil {
+		return nil, err
+	}
+	leaderIDs := make([]uint64, len(groups))
+	for i, group := range groups {
+		leaderIDs[i] = group.LeaderID
+	}
+	users, err := s.getUsers(leaderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.OpenGroups = make([]response.GroupBuyGroupBriefResponse, len(groups))
+	for i, group := range groups {
+		leader := users[group.LeaderID]
+		resp.OpenGroups[i] = response.GroupBuyGroupBriefResponse{
+			ID:           group.ID,
+			LeaderName:   leader.Nickname,
+			LeaderAvatar: leader.Avatar,
+			Remaining:    max(group.RequiredSize-group.PaidCount, 0),
+			ExpireAt:     group.ExpireAt,
+		}
+	}
+	return resp, nil
+}
+
+// GetCampaigns gets all campaigns with pagination (admin)
+func (s *GroupBuyService) GetCampaigns(page, pageSize int) (*response.Pagination, error) {
+	campaigns, total, err := s.groupBuyRepo.GetCampaigns(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.toCampaignResponses(campaigns)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, responses)
+	return &pagination, nil
+}
+
+// CreateCampaign creates a group-buy campaign (admin)
+func (s *GroupBuyService) CreateCampaign(req request.GroupBuyCampaignRequest) (*model.GroupBuyCampaign, error) {
+	if _, err := s.productRepo.GetProductByID(req.ProductID); err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+
+	campaign := &model.GroupBuyCampaign{
+		ProductID:  req.ProductID,
+		Title:      req.Title,
+		GroupPrice: req.GroupPrice,
+		GroupSize:  req.GroupSize,
+		TimeLimit:  req.TimeLimit,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Status:     model.GroupBuyCampaignStatusEnabled,
+	}
+	if err := s.groupBuyRepo.CreateCampaign(campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// UpdateCampaign updates a campaign that has not started yet (admin)
+func (s *GroupBuyService) UpdateCampaign(id uint64, req request.GroupBuyCampaignRequest) (*model.GroupBuyCampaign, error) {
+	campaign, err := s.groupBuyRepo.GetCampaignByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrGroupBuyNotFound
+	}
+	if !time.Now().Before(campaign.StartTime) {
+		return nil, pkgerrors.ErrGroupBuyStarted
+	}
+	if _, err := s.productRepo.GetProductByID(req.ProductID); err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+
+	campaign.ProductID = req.ProductID
+	campaign.Title = req.Title
+	campaign.GroupPrice = req.GroupPrice
+	campaign.GroupSize = req.GroupSize
+	campaign.TimeLimit = req.TimeLimit
+	campaign.StartTime = req.StartTime
+	campaign.EndTime = req.EndTime
+	if err := s.groupBuyRepo.UpdateCampaign(campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// SetCampaignStatus enables or disables a campaign (admin). Groups already opened are not affected.
+func (s *GroupBuyService) SetCampaignStatus(id uint64, status int) error {
+	if _, err := s.groupBuyRepo.GetCampaignByID(id); err != nil {
+		return pkgerrors.ErrGroupBuyNotFound
+	}
+	return s.groupBuyRepo.UpdateCampaignStatus(id, status)
+}
+
+// OpenGroup opens a new group as its leader and creates the leader's pending order
+func (s *GroupBuyService) OpenGroup(userID, campaignID uint64, req request.GroupBuyJoinRequest) (*response.GroupBuyOrderResponse, error) {
+	campaign, err := s.groupBuyRepo.GetCampaignByID(campaignID)
+	if err != nil {
+		return nil, pkgerrors.ErrGroupBuyNotFound
+	}
+
+	now := time.Now()
+	if campaign.Status != model.GroupBuyCampaignStatusEnabled || !now.Before(campaign.EndTime) {
+		return nil, pkgerrors.ErrGroupBuyEnded
+	}
+	if now.Before(campaign.StartTime) {
+		return nil, pkgerrors.ErrGroupBuyNotStarted
+	}
+
+	var group *model.GroupBuyGroup
+	order, err := s.orderService.CreateDealOrder(userID, s.dealOrderParams(campaign, 0, req),
+		func(tx *gorm.DB, order *model.Order) error {
+			groupBuyRepo := repository.NewGroupBuyRepository(tx)
+
+			group = &model.GroupBuyGroup{
+				CampaignID:   campaign.ID,
+				LeaderID:     userID,
+				RequiredSize: campaign.GroupSize,
+				Status:       model.GroupBuyGroupStatusOpen,
+				ExpireAt:     now.Add(time.Duration(campaign.TimeLimit) * time.Minute),
+			}
+			if err := groupBuyRepo.CreateGroup(group); err != nil {
+				return err
+			}
+
+			order.GroupID = group.ID
+			if err := repository.NewOrderRepository(tx).UpdateOrder(order.ID, map[string]interface{}{"group_id": group.ID}); err != nil {
+				return err
+			}
+
+			return groupBuyRepo.CreateParticipant(&model.GroupBuyParticipant{
+				GroupID:  group.ID,
+				UserID:   userID,
+				OrderID:  order.ID,
+				IsLeader: true,
+				Status:   model.GroupBuyParticipantStatusJoined,
+			})
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.GroupBuyOrderResponse{
+		GroupID: group.ID,
+		OrderID: order.ID,
+		OrderNo: order.OrderNo,
+	}, nil
+}
+
+// JoinGroup joins an open group and creates the participant's pending order.
+// A slot is held by unpaid participants until their order is paid or cancelled.
+func (s *GroupBuyService) JoinGroup(userID, groupID uint64, req request.GroupBuyJoinRequest) (*response.GroupBuyOrderResponse, error) {
+	group, err := s.groupBuyRepo.GetGroupByID(groupID)
+	if err != nil {
+		return nil, pkgerrors.ErrGroupNotFound
+	}
+	if group.Status != model.GroupBuyGroupStatusOpen || !time.Now().Before(group.ExpireAt) {
+		return nil, pkgerrors.ErrGroupBuyGroupClosed
+	}
+
+	campaign, err := s.groupBuyRepo.GetCampaignByID(group.CampaignID)
+	if err != nil {
+		return nil, pkgerrors.ErrGroupBuyNotFound
+	}
+
+	order, err := s.orderService.CreateDealOrder(userID, s.dealOrderParams(campaign, group.ID, req),
+		func(tx *gorm.DB, order *model.Order) error {
+			groupBuyRepo := repository.NewGroupBuyRepository(tx)
+
+			// 锁住团，保证名额检查与参团记录创建的原子性
+			locked, err := groupBuyRepo.LockGroup(group.ID)
+			if err != nil {
+				return err
+			}
+			if locked.Status != model.GroupBuyGroupStatusOpen || !time.Now().Before(locked.ExpireAt) {
+				return pkgerrors.ErrGroupBuyGroupClosed
+			}
+
+			participants, err := groupBuyRepo.GetActiveParticipants(group.ID)
+			if err != nil {
+				return err
+			}
+			for _, participant := range participants {
+				if participant.UserID == userID {
+					return pkgerrors.ErrGroupBuyAlreadyJoined
+				}
+			}
+			if len(participants) >= locked.RequiredSize {
+				return pkgerrors.ErrGroupBuyGroupFull
+			}
+
+			return groupBuyRepo.CreateParticipant(&model.GroupBuyParticipant{
+				GroupID: group.ID,
+				UserID:  userID,
+				OrderID: order.ID,
+				Status:  model.GroupBuyParticipantStatusJoined,
+			})
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.GroupBuyOrderResponse{
+		GroupID: group.ID,
+		OrderID: order.ID,
+		OrderNo: order.OrderNo,
+	}, nil
+}
+
+// GetGroup gets a group with its members. Share parameters are filled in for a logged-in viewer
+// while the group is open, so that the share link carries the viewer as inviter.
+func (s *GroupBuyService) GetGroup(groupID, viewerID uint64) (*response.GroupBuyGroupResponse, error) {
+	group, err := s.groupBuyRepo.GetGroupByID(groupID)
+	if err != nil {
+		return nil, pkgerrors.ErrGroupNotFound
+	}
+
+	campaign, err := s.groupBuyRepo.GetCampaignByID(group.CampaignID)
+	if err != nil {
+		return nil, pkgerrors.ErrGroupBuyNotFound
+	}
+	campaigns, err := s.toCampaignResponses([]model.GroupBuyCampaign{*campaign})
+	if err != nil {
+		return nil, err
+	}
+
+	participants, err := s.groupBuyRepo.GetActiveParticipants(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint64, len(participants))
+	for i, participant := range participants {
+		userIDs[i] = participant.UserID
+	}
+	users, err := s.getUsers(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.GroupBuyGroupResponse{
+		ID:           group.ID,
+		Campaign:     *campaigns[0],
+		RequiredSize: group.RequiredSize,
+		PaidCount:    group.PaidCount,
+		Remaining:    max(group.RequiredSize-group.PaidCount, 0),
+		Status:       group.Status,
+		ExpireAt:     group.ExpireAt,
+		Members:      make([]response.GroupBuyMemberResponse, len(participants)),
+	}
+	for i, participant := range participants {
+		user := users[participant.UserID]
+		resp.Members[i] = response.GroupBuyMemberResponse{
+			UserID:   participant.UserID,
+			Nickname: user.Nickname,
+			Avatar:   user.Avatar,
+			IsLeader: participant.IsLeader,
+			Paid:     participant.Status == model.GroupBuyParticipantStatusPaid,
+		}
+		if participant.UserID == viewerID {
+			resp.Joined = true
+		}
+	}
+
+	if viewerID > 0 && group.Status == model.GroupBuyGroupStatusOpen {
+		resp.ShareScene = groupShareScene(group.ID, viewerID)
+		resp.SharePath = s.sharePath(group.ID, viewerID)
+	}
+	return resp, nil
+}
+
+// ResolveShareScene parses the scene of a group-buy mini program code
+func (s *GroupBuyService) ResolveShareScene(scene string) (*response.GroupBuyShareResponse, error) {
+	groupID, inviterID, err := parseGroupShareScene(scene)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.groupBuyRepo.GetGroupByID(groupID); err != nil {
+		return nil, pkgerrors.ErrGroupNotFound
+	}
+	return &response.GroupBuyShareResponse{
+		GroupID:   groupID,
+		InviterID: inviterID,
+	}, nil
+}
+
+// ExpireGroups fails open groups past their time limit, refunds paid participants of failed groups
+// through the payment provider, and cancels participants that did not pay in time to free their slots.
+// Every step is idempotent so that a failed run is completed by the next one.
+func (s *GroupBuyService) ExpireGroups(ctx context.Context) error {
+	now := time.Now()
+
+	groups, err := s.groupBuyRepo.GetExpiredOpenGroups(now, 500)
+	if err != nil {
+		return err
+	}
+	for i := range groups {
+		group := &groups[i]
+		failed, err := s.groupBuyRepo.FinishGroup(group.ID, model.GroupBuyGroupStatusFailed)
+		if err != nil {
+			return err
+		}
+		if failed {
+			group.Status = model.GroupBuyGroupStatusFailed
+			logger.Infof("Group %d failed with %d/%d paid", group.ID, group.PaidCount, group.RequiredSize)
+			notifyGroupBuyResult(ctx, group)
+		}
+	}
+
+	refunds, err := s.groupBuyRepo.GetParticipantsToRefund(500)
+	if err != nil {
+		return err
+	}
+	for _, participant := range refunds {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := s.orderService.RefundOrder(participant.OrderID, "拼团失败自动退款")
+		if err == pkgerrors.ErrOrderStatusInvalid {
+			// 订单已不处于可退款状态，不再重试
+			logger.Warnf("Order %d of group %d cannot be refunded, skipped", participant.OrderID, participant.GroupID)
+			err = s.groupBuyRepo.UpdateParticipantStatus(participant.ID, model.GroupBuyParticipantStatusRefunded)
+		}
+		if err != nil {
+			logger.Warnf("Failed to refund order %d of group %d: %v", participant.OrderID, participant.GroupID, err)
+		}
+	}
+
+	var before time.Time
+	if s.config.PayTimeout > 0 {
+		before = now.Add(-time.Duration(s.config.PayTimeout) * time.Minute)
+	}
+	unpaid, err := s.groupBuyRepo.GetUnpaidParticipants(before, 500)
+	if err != nil {
+		return err
+	}
+	for _, participant := range unpaid {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := s.orderService.CancelOrder(participant.UserID, participant.OrderID)
+		if err != nil && err != pkgerrors.ErrOrderStatusInvalid {
+			logger.Warnf("Failed to cancel unpaid order %d of group %d: %v", participant.OrderID, participant.GroupID, err)
+		}
+	}
+	return nil
+}
+
+// dealOrderParams 拼团订单参数，每人限购一件
+func (s *GroupBuyService) dealOrderParams(campaign *model.GroupBuyCampaign, groupID uint64, req request.GroupBuyJoinRequest) DealOrderParams {
+	return DealOrderParams{
+		ProductID: campaign.ProductID,
+		DealPrice: campaign.GroupPrice,
+		AddressID: req.AddressID,
+		Quantity:  1,
+		Blessing:  req.Blessing,
+		Remark:    req.Remark,
+		GroupID:   groupID,
+	}
+}
+
+// sharePath 分享卡片路径
+func (s *GroupBuyService) sharePath(groupID, inviterID uint64) string {
+	path := s.config.SharePath
+	if path == "" {
+		path = "pages/group-buy/detail"
+	}
+	return fmt.Sprintf("%s?groupId=%d&inviter=%d", path, groupID, inviterID)
+}
+
+// getUsers 批量获取用户，按ID索引
+func (s *GroupBuyService) getUsers(ids []uint64) (map[uint64]model.User, error) {
+	users, err := s.userRepo.GetUsersByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[uint64]model.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+	return userMap, nil
+}
+
+// toCampaignResponses 转换拼团活动响应
+func (s *GroupBuyService) toCampaignResponses(campaigns []model.GroupBuyCampaign) ([]*response.GroupBuyCampaignResponse, error) {
+	productIDs := make([]uint64, len(campaigns))
+	for i, campaign := range campaigns {
+		productIDs[i] = campaign.ProductID
+	}
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	responses := make([]*response.GroupBuyCampaignResponse, len(campaigns))
+	for i, campaign := range campaigns {
+		product := productMap[campaign.ProductID]
+		responses[i] = &response.GroupBuyCampaignResponse{
+			ID:          campaign.ID,
+			ProductID:   campaign.ProductID,
+			ProductName: product.Name,
+			ImageUrl:    product.ImageUrl,
+			Title:       campaign.Title,
+			Price:       product.Price,
+			GroupPrice:  campaign.GroupPrice,
+			GroupSize:   campaign.GroupSize,
+			TimeLimit:   campaign.TimeLimit,
+			StartTime:   campaign.StartTime,
+			EndTime:     campaign.EndTime,
+			Status:      campaign.Status,
+		}
+	}
+	return responses, nil
+}
+
+// onGroupBuyPaid 拼团订单支付后在同一事务中更新参团状态，满员时成团并将团内订单转为已支付。
+// 返回刚成团的团；超时后才支付的订单不计入成团，由 ExpireGroups 退款
+func onGroupBuyPaid(tx *gorm.DB, order *model.Order) (*model.GroupBuyGroup, error) {
+	groupBuyRepo := repository.NewGroupBuyRepository(tx)
+
+	group, err := groupBuyRepo.LockGroup(order.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	participant, err := groupBuyRepo.GetParticipantByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if participant == nil || participant.Status != model.GroupBuyParticipantStatusJoined {
+		logger.Warnf("Paid group-buy order %d has no joined participant", order.ID)
+		return nil, nil
+	}
+
+	if err := groupBuyRepo.UpdateParticipantStatus(participant.ID, model.GroupBuyParticipantStatusPaid); err != nil {
+		return nil, err
+	}
+	if group.Status != model.GroupBuyGroupStatusOpen || !time.Now().Before(group.ExpireAt) {
+		return nil, nil
+	}
+
+	if err := groupBuyRepo.UpdateGroupPaidCount(group.ID, 1); err != nil {
+		return nil, err
+	}
+	group.PaidCount++
+	if group.PaidCount < group.RequiredSize {
+		return nil, nil
+	}
+
+	if _, err := groupBuyRepo.FinishGroup(group.ID, model.GroupBuyGroupStatusSuccess); err != nil {
+		return nil, err
+	}
+	if err := repository.NewOrderRepository(tx).ReleaseGroupOrders(group.ID); err != nil {
+		return nil, err
+	}
+	group.Status = model.GroupBuyGroupStatusSuccess
+	return group, nil
+}
+
+// onGroupBuyOrderClosed 拼团订单取消或退款时在同一事务中更新参团状态，释放名额
+func onGroupBuyOrderClosed(tx *gorm.DB, order *model.Order, status int) error {
+	groupBuyRepo := repository.NewGroupBuyRepository(tx)
+
+	// 与支付时相同的加锁顺序：先团后成员
+	group, err := groupBuyRepo.LockGroup(order.GroupID)
+	if err != nil {
+		return err
+	}
+	participant, err := groupBuyRepo.GetParticipantByOrderID(order.ID)
+	if err != nil || participant == nil {
+		return err
+	}
+
+	if participant.Status == model.GroupBuyParticipantStatusPaid && group.Status == model.GroupBuyGroupStatusOpen {
+		if err := groupBuyRepo.UpdateGroupPaidCount(group.ID, -1); err != nil {
+			return err
+		}
+	}
+	return groupBuyRepo.UpdateParticipantStatus(participant.ID, status)
+}
+
+// notifyGroupBuyResult 通知团内已支付成员拼团结果
+func notifyGroupBuyResult(ctx context.Context, group *model.GroupBuyGroup) {
+	participants, err := repository.NewGroupBuyRepository(server.GetServer().DB).
+		GetParticipantsByStatus(group.ID, model.GroupBuyParticipantStatusPaid)
+	if err != nil {
+		logger.Warnf("Failed to load participants of group %d: %v", group.ID, err)
+		return
+	}
+
+	msg := notify.Message{
+		Type:    notify.TypeGroupBuySuccess,
+		Title:   "拼团成功",
+		Content: "您参与的拼团已成团，我们将尽快为您发货",
+	}
+	if group.Status == model.GroupBuyGroupStatusFailed {
+		msg.Type = notify.TypeGroupBuyFailed
+		msg.Title = "拼团失败"
+		msg.Content = "您参与的拼团未在时限内成团，支付款项将原路退回"
+	}
+
+	for _, participant := range participants {
+		msg.UserID = participant.UserID
+		msg.Data = map[string]string{
+			"groupID": strconv.FormatUint(group.ID, 10),
+			"orderID": strconv.FormatUint(participant.OrderID, 10),
+		}
+		notify.Send(ctx, msg)
+	}
+}
+
+// groupShareScene 生成小程序码scene参数，长度不超过32个字符
+func groupShareScene(groupID, inviterID uint64) string {
+	return fmt.Sprintf("g=%d&u=%d", groupID, inviterID)
+}
+
+// parseGroupShareScene 解析小程序码scene参数
+func parseGroupShareScene(scene string) (groupID, inviterID uint64, err error) {
+	// 小程序码传入的scene需要先解码
+	if decoded, err := url.QueryUnescape(scene); err == nil {
+		scene = decoded
+	}
+	values, err := url.ParseQuery(scene)
+	if err != nil {
+		return 0, 0, pkgerrors.ErrGroupBuyInvalidScene
+	}
+
+	groupID, err = strconv.ParseUint(values.Get("g"), 10, 64)
+	if err != nil || groupID == 0 {
+		return 0, 0, pkgerrors.ErrGroupBuyInvalidScene
+	}
+	// 邀请人可选
+	inviterID, _ = strconv.ParseUint(values.Get("u"), 10, 64)
+	return groupID, inviterID, nil
+}
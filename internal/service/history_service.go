@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/minio"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+)
+
+// historySyncBatch 每次从待同步集合取出的用户数
+const historySyncBatch = 100
+
+// recordViewScript 记录浏览：按浏览时间写入有序集合（同一商品只保留最近一次），超出上限时删除最早的记录，
+// 刷新过期时间，并将用户加入待同步集合
+var recordViewScript = redis.NewScript(`
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -tonumber(ARGV[3]) - 1)
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+redis.call('SADD', KEYS[2], ARGV[5])
+return 1
+`)
+
+// hydrateHistoryScript 从MySQL恢复已过期的浏览记录：ARGV[1]为过期时间，其后依次为浏览时间和商品ID。
+// 使用NX不覆盖并发写入的更新的浏览
+var hydrateHistoryScript = redis.NewScript(`
+for i = 2, #ARGV, 2 do
+	redis.call('ZADD', KEYS[1], 'NX', ARGV[i], ARGV[i + 1])
+end
+redis.call('EXPIRE', KEYS[1], ARGV[1])
+return 1
+`)
+
+// historyEntry 一条浏览记录
+type historyEntry struct {
+	productID uint64
+	viewedAt  time.Time
+}
+
+// HistoryService handles the browsing history of users, kept in Redis and mirrored to MySQL
+type HistoryService struct {
+	historyRepo   *repository.HistoryRepository
+	productRepo   *repository.ProductRepository
+	bundleService *BundleService
+	redisClient   *redis.Client
+	config        config.HistoryConfig
+}
+
+// NewHistoryService creates a new history service
+func NewHistoryService() *HistoryService {
+	server := server.GetServer()
+	cfg := server.GetConfig().History
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = 100
+	}
+	if cfg.TTLDays <= 0 {
+		cfg.TTLDays = 30
+	}
+	if cfg.RetentionDays <= 0 {
+		cfg.RetentionDays = 180
+	}
+	return &HistoryService{
+		historyRepo:   repository.NewHistoryRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		bundleService: NewBundleService(),
+		redisClient:   redis.GetClient(),
+		config:        cfg,
+	}
+}
+
+// RecordView records that the user viewed the product. When the Redis history has expired it is
+// restored from MySQL first, so that the new view does not hide the persisted history.
+func (s *HistoryService) RecordView(ctx context.Context, userID, productID uint64) error {
+	if err := s.hydrate(ctx, userID); err != nil {
+		return err
+	}
+
+	_, err := s.redisClient.RunScript(ctx, recordViewScript,
+		[]string{historyKey(userID), constant.HistoryDirty},
+		time.Now().UnixMilli(), productID, s.config.MaxItems, s.config.TTLDays*24*3600, userID)
+	return err
+}
+
+// GetHistories gets the user's recently viewed products, most recent first.
+// Reads from Redis, falling back to MySQL when the Redis history has expired.
+func (s *HistoryService) GetHistories(ctx context.Context, userID uint64, page, pageSize int) (*response.Pagination, error) {
+	entries, total, err := s.getEntries(ctx, userID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// 批量查询商品，按浏览顺序返回，已删除的商品跳过
+	productIDs := make([]uint64, len(entries))
+	for i, entry := range entries {
+		productIDs[i] = entry.productID
+	}
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint64]*model.Product, len(products))
+	bundles := make([]*model.Product, 0)
+	for i := range products {
+		productMap[products[i].ID] = &products[i]
+		if products[i].IsBundle {
+			bundles = append(bundles, &products[i])
+		}
+	}
+	if err := s.bundleService.FillStock(bundles...); err != nil {
+		return nil, err
+	}
+
+	minioClient := minio.GetClient()
+	responses := make([]response.HistoryResponse, 0, len(entries))
+	for _, entry := range entries {
+		product, ok := productMap[entry.productID]
+		if !ok {
+			continue
+		}
+		responses = append(responses, response.HistoryResponse{
+			ProductID:  product.ID,
+			Name:       product.Name,
+			ImageUrl:   minioClient.GetFileURL(product.ImageUrl),
+			Price:      product.Price,
+			StockCount: product.StockCount,
+			Status:     product.Status,
+			ViewedAt:   entry.viewedAt,
+		})
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, responses)
+	return &pagination, nil
+}
+
+// RecentProductIDs gets the IDs of the products the user viewed most recently, e.g. as input for recommendations
+func (s *HistoryService) RecentProductIDs(ctx context.Context, userID uint64, limit int) ([]uint64, error) {
+	entries, _, err := s.getEntries(ctx, userID, 1, limit)
+	if err != nil {
+		return nil, err
+	}
+	productIDs := make([]uint64, len(entries))
+	for i, entry := range entries {
+		productIDs[i] = entry.productID
+	}
+	return productIDs, nil
+}
+
+// DeleteHistory removes one product from the user's browsing history
+func (s *HistoryService) DeleteHistory(ctx context.Context, userID, productID uint64) error {
+	if err := s.redisClient.SortedSetRemove(ctx, historyKey(userID), strconv.FormatUint(productID, 10)); err != nil {
+		return err
+	}
+	return s.historyRepo.DeleteHistory(userID, productID)
+}
+
+// ClearHistories clears the user's browsing history
+func (s *HistoryService) ClearHistories(ctx context.Context, userID uint64) error {
+	if err := s.redisClient.Delete(ctx, historyKey(userID)); err != nil {
+		return err
+	}
+	return s.historyRepo.ClearHistories(userID)
+}
+
+// SyncHistories mirrors the Redis browsing history of recently active users to MySQL,
+// and deletes MySQL records older than the retention period
+func (s *HistoryService) SyncHistories(ctx context.Context) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		members, err := s.redisClient.SetPop(ctx, constant.HistoryDirty, historySyncBatch)
+		if err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			break
+		}
+
+		for _, member := range members {
+			userID, err := strconv.ParseUint(member, 10, 64)
+			if err != nil {
+				continue
+			}
+			if err := s.syncUser(ctx, userID); err != nil {
+				// 同步失败时放回待同步集合，下次重试
+				if addErr := s.redisClient.SetAdd(ctx, constant.HistoryDirty, member); addErr != nil {
+					logger.Warnf("Failed to requeue history sync of user %d: %v", userID, addErr)
+				}
+				return err
+			}
+		}
+	}
+
+	deleted, err := s.historyRepo.DeleteBefore(time.Now().AddDate(0, 0, -s.config.RetentionDays))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		logger.Infof("Deleted %d expired browsing history records", deleted)
+	}
+	return nil
+}
+
+// syncUser 将用户的Redis浏览记录写入MySQL
+func (s *HistoryService) syncUser(ctx context.Context, userID uint64) error {
+	members, err := s.redisClient.SortedSetRevRangeWithScores(ctx, historyKey(userID), 0, -1)
+	if err != nil {
+		return err
+	}
+
+	histories := make([]model.BrowseHistory, 0, len(members))
+	for _, member := range members {
+		entry, ok := toHistoryEntry(member)
+		if !ok {
+			continue
+		}
+		histories = append(histories, model.BrowseHistory{
+			UserID:    userID,
+			ProductID: entry.productID,
+			ViewedAt:  entry.viewedAt,
+		})
+	}
+	return s.historyRepo.UpsertHistories(histories)
+}
+
+// hydrate Redis中没有用户的浏览记录时，从MySQL恢复最近的记录
+func (s *HistoryService) hydrate(ctx context.Context, userID uint64) error {
+	key := historyKey(userID)
+	exists, err := s.redisClient.Exists(ctx, key)
+	if err != nil || exists {
+		return err
+	}
+
+	histories, _, err := s.historyRepo.GetUserHistories(userID, 1, s.config.MaxItems)
+	if err != nil || len(histories) == 0 {
+		return err
+	}
+	args := make([]interface{}, 0, len(histories)*2+1)
+	args = append(args, s.config.TTLDays*24*3600)
+	for _, history := range histories {
+		args = append(args, history.ViewedAt.UnixMilli(), history.ProductID)
+	}
+	_, err = s.redisClient.RunScript(ctx, hydrateHistoryScript, []string{key}, args...)
+	return err
+}
+
+// getEntries 分页获取浏览记录，Redis中没有记录时从MySQL读取。记录浏览前会先从MySQL恢复，
+// 因此Redis中有记录时即包含MySQL中最近的记录
+func (s *HistoryService) getEntries(ctx context.Context, userID uint64, page, pageSize int) ([]historyEntry, int64, error) {
+	key := historyKey(userID)
+	total, err := s.redisClient.SortedSetCard(ctx, key)
+	if err != nil {
+		logger.Warnf("Failed to read browsing history of user %d from Redis: %v", userID, err)
+		total = 0
+	}
+
+	if total > 0 {
+		start := int64((page - 1) * pageSize)
+		members, err := s.redisClient.SortedSetRevRangeWithScores(ctx, key, start, start+int64(pageSize)-1)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries := make([]historyEntry, 0, len(members))
+		for _, member := range members {
+			if entry, ok := toHistoryEntry(member); ok {
+				entries = append(entries, entry)
+			}
+		}
+		return entries, total, nil
+	}
+
+	histories, total, err := s.historyRepo.GetUserHistories(userID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	entries := make([]historyEntry, len(histories))
+	for i, history := range histories {
+		entries[i] = historyEntry{productID: history.ProductID, viewedAt: history.ViewedAt}
+	}
+	return entries, total, nil
+}
+
+// historyKey 用户浏览记录的缓存键
+func historyKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", constant.HistoryUser, userID)
+}
+
+// toHistoryEntry 解析有序集合成员，成员为商品ID，分值为浏览时间（毫秒）
+func toHistoryEntry(member redis.Z) (historyEntry, bool) {
+	productID, err := strconv.ParseUint(fmt.Sprint(member.Member), 10, 64)
+	if err != nil {
+		return historyEntry{}, false
+	}
+	return historyEntry{productID: productID, viewedAt: time.UnixMilli(int64(member.Score))}, true
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+)
+
+const (
+	// homeSectionTTL 首页区块配置缓存时间，后台修改时清除
+	homeSectionTTL = time.Hour
+	// homeCategoryTTL 首页分类缓存时间，分类变更时清除
+	homeCategoryTTL = time.Hour
+	// homePromotionTTL 首页促销广告缓存时间，活动开始和结束依赖较短的缓存时间生效
+	homePromotionTTL = time.Minute
+	// homeProductTTL 首页推荐和热门商品缓存时间
+	homeProductTTL = 5 * time.Minute
+	// homeProductLimit 首页商品区块未配置数量时的默认数量
+	homeProductLimit = 10
+	// homeLoadTimeout 单次加载首页的最长等待时间，超时的区块按加载失败返回
+	homeLoadTimeout = 3 * time.Second
+)
+
+// defaultHomeSections 默认的首页区块及顺序，未配置的区块按此顺序追加并展示
+var defaultHomeSections = []model.HomeSection{
+	{Key: model.HomeSectionBanners, Title: "轮播图", Visible: true},
+	{Key: model.HomeSectionCategories, Title: "分类", Visible: true},
+	{Key: model.HomeSectionPromotions, Title: "促销活动", Visible: true},
+	{Key: model.HomeSectionRecommend, Title: "为你推荐", Visible: true},
+	{Key: model.HomeSectionHot, Title: "热门商品", Visible: true},
+}
+
+// HomeService assembles the home page. Visible sections are loaded concurrently and each is cached
+// separately; a failing or slow section is returned with an error status instead of failing the page.
+type HomeService struct {
+	homeRepo         *repository.HomeRepository
+	cacheService     *redis.CacheService
+	bannerService    *BannerService
+	categoryService  *CategoryService
+	promotionService *PromotionService
+	productService   *ProductService
+	memberService    *MemberService
+}
+
+// NewHomeService creates a new home service
+func NewHomeService() *HomeService {
+	server := server.GetServer()
+	return &HomeService{
+		homeRepo:         repository.NewHomeRepository(server.DB),
+		cacheService:     redis.NewCacheService(),
+		bannerService:    NewBannerService(),
+		categoryService:  NewCategoryService(),
+		promotionService: NewPromotionService(),
+		productService:   NewProductService(),
+		memberService:    NewMemberService(),
+	}
+}
+
+// homeSectionResult 区块加载结果
+type homeSectionResult struct {
+	index int
+	data  interface{}
+	err   error
+}
+
+// GetHome gets the visible sections of the home page in the configured order
+func (s *HomeService) GetHome(userID uint64, city string) *response.HomeResponse {
+	sections, err := s.GetSections()
+	if err != nil {
+		// 配置不可用时按默认区块展示
+		logger.Warnf("Failed to get home sections, using defaults: %v", err)
+		sections = mergeHomeSections(nil)
+	}
+
+	home := &response.HomeResponse{Sections: make([]response.HomeSectionResponse, 0, len(sections))}
+	for _, section := range sections {
+		if !section.Visible {
+			continue
+		}
+		home.Sections = append(home.Sections, response.HomeSectionResponse{
+			Key:    section.Key,
+			Title:  section.Title,
+			Status: response.HomeSectionStatusError,
+			Error:  "section timed out",
+		})
+	}
+
+	results := make(chan homeSectionResult, len(home.Sections))
+	for i := range home.Sections {
+		go func(index int, section model.HomeSection) {
+			result := homeSectionResult{index: index}
+			defer func() {
+				if r := recover(); r != nil {
+					result.err = fmt.Errorf("panic: %v", r)
+				}
+				results <- result
+			}()
+			result.data, result.err = s.loadSection(section, userID, city)
+		}(i, findHomeSection(sections, home.Sections[i].Key))
+	}
+
+	loaded := make([]bool, len(home.Sections))
+	timer := time.NewTimer(homeLoadTimeout)
+	defer timer.Stop()
+	for remaining := len(home.Sections); remaining > 0; remaining-- {
+		select {
+		case result := <-results:
+			loaded[result.index] = true
+			section := &home.Sections[result.index]
+			if result.err != nil {
+				logger.Warnf("Failed to load home section %s: %v", section.Key, result.err)
+				section.Error = "section unavailable"
+				continue
+			}
+			section.Status = response.HomeSectionStatusOK
+			section.Error = ""
+			section.Data = result.data
+		case <-timer.C:
+			// 未返回的区块保持超时状态，加载完成后写入缓冲通道即退出
+			for i, section := range home.Sections {
+				if !loaded[i] {
+					logger.Warnf("Home section %s timed out", section.Key)
+				}
+			}
+			return home
+		}
+	}
+	return home
+}
+
+// GetSections gets the config of all home sections in display order, including hidden ones
+func (s *HomeService) GetSections() ([]model.HomeSection, error) {
+	ctx := context.Background()
+
+	var sections []model.HomeSection
+	if err := s.cacheService.GetObject(ctx, constant.HomeSections, &sections); err == nil {
+		return sections, nil
+	}
+
+	configs, err := s.homeRepo.GetSections()
+	if err != nil {
+		return nil, err
+	}
+	sections = mergeHomeSections(configs)
+	if err := s.cacheService.Set(ctx, constant.HomeSections, sections, homeSectionTTL); err != nil {
+		logger.Warnf("Failed to cache home sections: %v", err)
+	}
+	return sections, nil
+}
+
+// UpdateSections replaces the home section config; sections are shown in the submitted order and
+// sections not submitted are hidden (admin)
+func (s *HomeService) UpdateSections(req request.HomeSectionsRequest) ([]model.HomeSection, error) {
+	submitted := make(map[string]bool, len(req.Sections))
+	sections := make([]model.HomeSection, 0, len(defaultHomeSections))
+	now := time.Now()
+	for _, item := range req.Sections {
+		if submitted[item.Key] {
+			return nil, pkgerrors.ErrInvalidHomeSection
+		}
+		submitted[item.Key] = true
+		sections = append(sections, model.HomeSection{
+			Key:       item.Key,
+			Title:     item.Title,
+			Visible:   item.Visible,
+			Limit:     item.Limit,
+			SortOrder: len(sections),
+			UpdatedAt: now,
+		})
+	}
+	for _, section := range defaultHomeSections {
+		if submitted[section.Key] {
+			continue
+		}
+		sections = append(sections, model.HomeSection{
+			Key:       section.Key,
+			Title:     section.Title,
+			SortOrder: len(sections),
+			UpdatedAt: now,
+		})
+	}
+
+	if err := s.homeRepo.SaveSections(sections); err != nil {
+		return nil, err
+	}
+	s.invalidateHome()
+	return s.GetSections()
+}
+
+// loadSection 加载单个区块的数据
+func (s *HomeService) loadSection(section model.HomeSection, userID uint64, city string) (interface{}, error) {
+	switch section.Key {
+	case model.HomeSectionBanners:
+		// 轮播图按展示时间段缓存在HomeBanners，这里只做受众过滤
+		banners, err := s.bannerService.GetBanners(userID, city)
+		if err != nil {
+			return nil, err
+		}
+		if section.Limit > 0 && len(banners) > section.Limit {
+			banners = banners[:section.Limit]
+		}
+		return banners, nil
+	case model.HomeSectionCategories:
+		return s.getCategories(section.Limit)
+	case model.HomeSectionPromotions:
+		return s.getPromotions(section.Limit)
+	case model.HomeSectionRecommend:
+		return s.getRecommendProducts(homeLimit(section.Limit), userID)
+	case model.HomeSectionHot:
+		return s.getHotProducts(homeLimit(section.Limit), userID)
+	}
+	return nil, fmt.Errorf("unknown home section %s", section.Key)
+}
+
+// getCategories 获取首页分类
+func (s *HomeService) getCategories(limit int) ([]*response.CategoryResponse, error) {
+	var categories []*response.CategoryResponse
+	err := s.cachedSection(constant.HomeCategories, homeCategoryTTL, &categories, func() (err error) {
+		categories, err = s.categoryService.GetCategoriesByParentID(0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(categories) > limit {
+		categories = categories[:limit]
+	}
+	return categories, nil
+}
+
+// getPromotions 获取首页促销广告，读取时剔除缓存期间已结束的活动
+func (s *HomeService) getPromotions(limit int) ([]*response.PromotionResponse, error) {
+	var promotions []*response.PromotionResponse
+	err := s.cachedSection(constant.HomePromotions, homePromotionTTL, &promotions, func() (err error) {
+		promotions, err = s.promotionService.GetPromotions()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]*response.PromotionResponse, 0, len(promotions))
+	for _, promotion := range promotions {
+		if promotion.EndAt != nil && !promotion.EndAt.After(now) {
+			continue
+		}
+		active = append(active, promotion)
+		if limit > 0 && len(active) == limit {
+			break
+		}
+	}
+	return active, nil
+}
+
+// getRecommendProducts 获取首页推荐商品，登录用户的个性化推荐按用户缓存
+func (s *HomeService) getRecommendProducts(limit int, userID uint64) ([]*response.ProductResponse, error) {
+	cacheKey := constant.HomeRecommendProducts
+	if userID != 0 {
+		cacheKey = fmt.Sprintf("%s:%d", constant.HomeRecommendProducts, userID)
+	}
+
+	var products []*response.ProductResponse
+	err := s.cachedSection(cacheKey, homeProductTTL, &products, func() (err error) {
+		products, err = s.productService.GetRecommendProducts(limit, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(products) > limit {
+		products = products[:limit]
+	}
+	return products, nil
+}
+
+// getHotProducts 获取首页热门商品，缓存不含用户信息，读取后按当前用户的会员等级填充会员价
+func (s *HomeService) getHotProducts(limit int, userID uint64) ([]*response.ProductResponse, error) {
+	var products []*response.ProductResponse
+	err := s.cachedSection(constant.HomeHotProducts, homeProductTTL, &products, func() (err error) {
+		products, err = s.productService.GetHotProducts(limit, 0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(products) > limit {
+		products = products[:limit]
+	}
+
+	tier, err := s.memberService.GetUserTier(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tier == nil {
+		return products, nil
+	}
+	for _, product := range products {
+		for _, price := range product.MemberPrices {
+			if price.TierID == tier.ID {
+				memberPrice := price.Price
+				product.MemberPrice = &memberPrice
+				break
+			}
+		}
+	}
+	return products, nil
+}
+
+// cachedSection 读取区块缓存，未命中时加载并写入缓存，缓存不可用时直接使用加载结果
+func (s *HomeService) cachedSection(key string, ttl time.Duration, dest interface{}, load func() error) error {
+	ctx := context.Background()
+	if err := s.cacheService.GetObject(ctx, key, dest); err == nil {
+		return nil
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+	if err := s.cacheService.Set(ctx, key, dest, ttl); err != nil {
+		logger.Warnf("Failed to cache home section %s: %v", key, err)
+	}
+	return nil
+}
+
+// invalidateHome 清除区块配置以及受展示数量影响的区块缓存，登录用户的推荐缓存到期后生效
+func (s *HomeService) invalidateHome() {
+	ctx := context.Background()
+	s.cacheService.Delete(ctx, constant.HomeSections)
+	s.cacheService.Delete(ctx, constant.HomeCategories)
+	s.cacheService.Delete(ctx, constant.HomePromotions)
+	s.cacheService.Delete(ctx, constant.HomeRecommendProducts)
+	s.cacheService.Delete(ctx, constant.HomeHotProducts)
+}
+
+// mergeHomeSections 合并后台配置和默认区块，未配置的区块按默认顺序追加在后面
+func mergeHomeSections(configs []model.HomeSection) []model.HomeSection {
+	sections := make([]model.HomeSection, 0, len(defaultHomeSections))
+	configured := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		defaultSection := findHomeSection(defaultHomeSections, config.Key)
+		if defaultSection.Key == "" {
+			// 已下线的区块
+			continue
+		}
+		if config.Title == "" {
+			config.Title = defaultSection.Title
+		}
+		configured[config.Key] = true
+		sections = append(sections, config)
+	}
+	for _, section := range defaultHomeSections {
+		if !configured[section.Key] {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// findHomeSection 按区块键查找区块，不存在时返回空区块
+func findHomeSection(sections []model.HomeSection, key string) model.HomeSection {
+	for _, section := range sections {
+		if section.Key == key {
+			return section
+		}
+	}
+	return model.HomeSection{}
+}
+
+// homeLimit 商品区块的展示数量，未配置时使用默认数量
+func homeLimit(limit int) int {
+	if limit > 0 {
+		return limit
+	}
+	return homeProductLimit
+}
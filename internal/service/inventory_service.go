@@ -0,0 +1,542 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/notify"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	"gorm.io/gorm"
+)
+
+// lowStockBatch 每轮提醒或列出的低库存商品数量
+const lowStockBatch = 500
+
+// InventoryService is the single entry for changing product stock. Every change is written to the
+// inventory ledger in the caller's transaction. It also reports on-hand versus reserved stock and
+// alerts staff when stock falls to the low-stock threshold of a product.
+type InventoryService struct {
+	db            *gorm.DB
+	inventoryRepo *repository.InventoryRepository
+	productRepo   *repository.ProductRepository
+	userRepo      *repository.UserRepository
+}
+
+// NewInventoryService creates a new inventory service
+func NewInventoryService() *InventoryService {
+	server := server.GetServer()
+	return &InventoryService{
+		db:            server.DB,
+		inventoryRepo: repository.NewInventoryRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		userRepo:      repository.NewUserRepository(server.DB),
+	}
+}
+
+// ChangeStock changes the available stock of a product by movement.Quantity (negative to decrease)
+// and records the movement with the resulting stock, in the given transaction.
+// Decreases take the first-expiring batches first; increases for an order return the units to the
+// batches the order took. A decrease beyond the available stock fails with ErrOutOfStock.
+// Sales use AllocateStock instead, which skips expired batches.
+func (s *InventoryService) ChangeStock(tx *gorm.DB, movement *model.InventoryMovement) error {
+	return s.changeStock(tx, movement, time.Time{})
+}
+
+// AllocateStock decreases the stock of a product for an order delivered on deliveryDate (today when nil),
+// taking only batches that have not expired by the delivery date, first-expiring first
+func (s *InventoryService) AllocateStock(tx *gorm.DB, movement *model.InventoryMovement, deliveryDate *time.Time) error {
+	usableOn := today()
+	if deliveryDate != nil && deliveryDate.After(usableOn) {
+		usableOn = dateOf(*deliveryDate)
+	}
+	return s.changeStock(tx, movement, usableOn)
+}
+
+// ReceiveBatch puts a received batch into stock and records the receipt, in the given transaction.
+// refType and refID name the document the batch was received with, e.g. a purchase order; the batch itself when empty.
+func (s *InventoryService) ReceiveBatch(tx *gorm.DB, batch *model.StockBatch, actorID uint64, refType string, refID uint64) error {
+	batch.ReceivedAt = dateOf(batch.ReceivedAt)
+	batch.ExpiresAt = batch.ReceivedAt.AddDate(0, 0, batch.ShelfLifeDays)
+	batch.Remaining = batch.Quantity
+	if err := repository.NewInventoryRepository(tx).CreateBatch(batch); err != nil {
+		return err
+	}
+
+	if refType == "" {
+		refType, refID = model.InventoryRefBatch, batch.ID
+	}
+	return s.changeStock(tx, &model.InventoryMovement{
+		ProductID: batch.ProductID,
+		Type:      model.InventoryMovementReceive,
+		Quantity:  batch.Quantity,
+		ActorID:   actorID,
+		RefType:   refType,
+		RefID:     refID,
+		Remark:    batch.Remark,
+	}, time.Time{})
+}
+
+// GetInventory gets the available, reserved and on-hand stock of a product (admin)
+func (s *InventoryService) GetInventory(productID uint64) (*response.InventoryResponse, error) {
+	product, err := s.productRepo.GetProductByID(productID)
+	if err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+	if product.IsBundle {
+		return nil, pkgerrors.ErrBundleStockDerived
+	}
+
+	reserved, err := s.reservedQuantities([]uint64{productID})
+	if err != nil {
+		return nil, err
+	}
+	alert, err := s.inventoryRepo.GetStockAlert(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &response.InventoryResponse{
+		ProductID: product.ID,
+		Name:      product.Name,
+		Available: product.StockCount,
+		Reserved:  reserved[productID],
+		OnHand:    product.StockCount + reserved[productID],
+	}
+	if alert != nil {
+		result.Threshold = &alert.Threshold
+		result.LowStock = product.StockCount <= alert.Threshold
+	}
+	return result, nil
+}
+
+// GetMovements gets the inventory ledger of a product with pagination, latest first (admin)
+func (s *InventoryService) GetMovements(productID uint64, movementType *int, page, pageSize int) (*response.Pagination, error) {
+	movements, total, err := s.inventoryRepo.GetMovements(productID, movementType, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, movements)
+	return &pagination, nil
+}
+
+// AdjustStock records a manual adjustment, a stock-take or a wastage of a product (admin).
+// A stock-take gives the counted on-hand quantity, and the available stock is set to it less the reserved quantity.
+func (s *InventoryService) AdjustStock(actorID, productID uint64, req request.InventoryAdjustRequest) (*model.InventoryMovement, error) {
+	movement := &model.InventoryMovement{
+		ProductID: productID,
+		Type:      req.Type,
+		ActorID:   actorID,
+		Remark:    req.Remark,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		product, err := repository.NewProductRepository(tx).LockProduct(productID)
+		if err != nil {
+			return pkgerrors.ErrProductNotFound
+		}
+		if product.IsBundle {
+			return pkgerrors.ErrBundleStockDerived
+		}
+
+		switch req.Type {
+		case model.InventoryMovementAdjustment:
+			if req.Quantity == 0 {
+				return pkgerrors.ErrInvalidInventoryAdjustment
+			}
+			movement.Quantity = req.Quantity
+		case model.InventoryMovementStockTake:
+			if req.Quantity < 0 {
+				return pkgerrors.ErrInvalidInventoryAdjustment
+			}
+			reserved, err := repository.NewInventoryRepository(tx).GetReservedQuantities([]uint64{productID})
+			if err != nil {
+				return err
+			}
+			onHand := product.StockCount
+			for _, item := range reserved {
+				onHand += item.Quantity
+			}
+			movement.Quantity = req.Quantity - onHand
+		case model.InventoryMovementWastage:
+			if req.Quantity <= 0 {
+				return pkgerrors.ErrInvalidInventoryAdjustment
+			}
+			movement.Quantity = -req.Quantity
+		}
+		if product.StockCount+movement.Quantity < 0 {
+			// 不能减去已被订单占用的库存
+			return pkgerrors.ErrInvalidInventoryAdjustment
+		}
+
+		return s.ChangeStock(tx, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movement, nil
+}
+
+// SetStockAlert sets the low-stock threshold of a product and re-arms its alert (admin)
+func (s *InventoryService) SetStockAlert(productID uint64, threshold int) error {
+	product, err := s.productRepo.GetProductByID(productID)
+	if err != nil {
+		return pkgerrors.ErrProductNotFound
+	}
+	if product.IsBundle {
+		return pkgerrors.ErrBundleStockDerived
+	}
+	return s.inventoryRepo.UpsertStockAlert(productID, threshold)
+}
+
+// DeleteStockAlert removes the low-stock threshold of a product (admin)
+func (s *InventoryService) DeleteStockAlert(productID uint64) error {
+	return s.inventoryRepo.DeleteStockAlert(productID)
+}
+
+// GetLowStockProducts gets the products at or below their low-stock threshold, lowest stock first (admin)
+func (s *InventoryService) GetLowStockProducts() ([]response.InventoryResponse, error) {
+	products, err := s.inventoryRepo.GetLowStockProducts(false, lowStockBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uint64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ProductID
+	}
+	reserved, err := s.reservedQuantities(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]response.InventoryResponse, len(products))
+	for i, product := range products {
+		threshold := product.Threshold
+		responses[i] = response.InventoryResponse{
+			ProductID: product.ProductID,
+			Name:      product.Name,
+			Available: product.StockCount,
+			Reserved:  reserved[product.ProductID],
+			OnHand:    product.StockCount + reserved[product.ProductID],
+			Threshold: &threshold,
+			LowStock:  true,
+		}
+	}
+	return responses, nil
+}
+
+// SendLowStockAlerts alerts the admins once for each product that fell to its low-stock threshold.
+// A product is alerted again after its stock has gone back above the threshold.
+func (s *InventoryService) SendLowStockAlerts(ctx context.Context) error {
+	if err := s.inventoryRepo.ResetRecoveredAlerts(); err != nil {
+		return err
+	}
+
+	products, err := s.inventoryRepo.GetLowStockProducts(true, lowStockBatch)
+	if err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	adminIDs, err := s.userRepo.GetUserIDsByRole(constant.UserRoleAdmin)
+	if err != nil {
+		return err
+	}
+	if len(adminIDs) == 0 {
+		logger.Warnf("No admin to alert of %d low-stock products", len(products))
+	}
+
+	alertIDs := make([]uint64, len(products))
+	for i, product := range products {
+		alertIDs[i] = product.AlertID
+		for _, adminID := range adminIDs {
+			notify.Send(ctx, notify.Message{
+				UserID:  adminID,
+				Type:    notify.TypeLowStock,
+				Title:   "库存预警",
+				Content: fmt.Sprintf("商品「%s」可售库存仅剩%d件（预警值%d件）", product.Name, product.StockCount, product.Threshold),
+				Data: map[string]string{
+					"productID": strconv.FormatUint(product.ProductID, 10),
+				},
+			})
+		}
+	}
+	return s.inventoryRepo.MarkStockAlerted(alertIDs, time.Now())
+}
+
+// GetBatches gets the batches of a product with units left, first-expiring first (admin)
+func (s *InventoryService) GetBatches(productID uint64) ([]model.StockBatch, error) {
+	return s.inventoryRepo.GetLiveBatches(productID)
+}
+
+// CreateBatch receives a batch of a product into stock (admin)
+func (s *InventoryService) CreateBatch(actorID, productID uint64, req request.StockBatchRequest) (*model.StockBatch, error) {
+	receivedAt, err := time.ParseInLocation(dateLayout, req.ReceivedAt, time.Local)
+	if err != nil || receivedAt.After(today()) {
+		return nil, pkgerrors.ErrInvalidInventoryAdjustment
+	}
+
+	batch := &model.StockBatch{
+		ProductID:     productID,
+		ReceivedAt:    receivedAt,
+		ShelfLifeDays: req.ShelfLifeDays,
+		Quantity:      req.Quantity,
+		Remark:        req.Remark,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		product, err := repository.NewProductRepository(tx).LockProduct(productID)
+		if err != nil {
+			return pkgerrors.ErrProductNotFound
+		}
+		if product.IsBundle {
+			return pkgerrors.ErrBundleStockDerived
+		}
+		return s.ReceiveBatch(tx, batch, actorID, "", 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// WriteOffExpiredBatches writes off the units left in batches that expired, as wastage in the inventory ledger
+func (s *InventoryService) WriteOffExpiredBatches(ctx context.Context) error {
+	batches, err := s.inventoryRepo.GetExpiredBatches(today(), lowStockBatch)
+	if err != nil {
+		return err
+	}
+
+	var written int
+	for _, batch := range batches {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		quantity, err := s.writeOffBatch(batch.ID)
+		if err != nil {
+			logger.Warnf("Failed to write off expired batch %d of product %d: %v", batch.ID, batch.ProductID, err)
+			continue
+		}
+		written += quantity
+	}
+
+	if written > 0 {
+		logger.Infof("Wrote off %d units of %d expired batches", written, len(batches))
+	}
+	return nil
+}
+
+// GetExpectedWastage gets, for each of the coming days, the units of each product whose batches expire that day
+// if not sold by then (admin). Expired batches not yet written off are counted on today.
+func (s *InventoryService) GetExpectedWastage(days int) ([]response.ExpectedWastageResponse, error) {
+	start := today()
+	wastage, err := s.inventoryRepo.GetExpectedWastage(start.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]response.ExpectedWastageResponse, len(wastage))
+	for i, item := range wastage {
+		date := item.ExpiresAt
+		if date.Before(start) {
+			date = start
+		}
+		responses[i] = response.ExpectedWastageResponse{
+			Date:      date.Format(dateLayout),
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+		}
+	}
+	return responses, nil
+}
+
+// changeStock 修改可售库存并记录流水。扣减时按到期日先后占用到期日晚于 usableOn 的批次，不足部分使用不属于批次的库存；
+// 带单据的增加先归还到该单据占用的批次
+func (s *InventoryService) changeStock(tx *gorm.DB, movement *model.InventoryMovement, usableOn time.Time) error {
+	productRepo := repository.NewProductRepository(tx)
+	inventoryRepo := repository.NewInventoryRepository(tx)
+	switch {
+	case movement.Quantity < 0:
+		// 条件扣减同时锁定商品行，同一商品的批次分配依次进行
+		ok, err := productRepo.DecreaseProductStock(movement.ProductID, -movement.Quantity)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return pkgerrors.ErrOutOfStock
+		}
+	case movement.Quantity > 0:
+		if err := productRepo.IncreaseProductStock(movement.ProductID, movement.Quantity); err != nil {
+			return err
+		}
+	}
+
+	stock, err := productRepo.GetProductStock(movement.ProductID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case movement.Quantity < 0:
+		err = allocateBatches(inventoryRepo, movement, stock-movement.Quantity, usableOn)
+	case movement.Quantity > 0:
+		err = returnToBatches(inventoryRepo, movement)
+	}
+	if err != nil {
+		return err
+	}
+
+	movement.StockAfter = stock
+	return inventoryRepo.CreateMovement(movement)
+}
+
+// writeOffBatch 将到期批次的剩余数量报废，返回报废数量
+func (s *InventoryService) writeOffBatch(batchID uint64) (int, error) {
+	var quantity int
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		productRepo := repository.NewProductRepository(tx)
+		inventoryRepo := repository.NewInventoryRepository(tx)
+
+		batch, err := inventoryRepo.GetBatchByID(batchID)
+		if err != nil {
+			return err
+		}
+		product, err := productRepo.LockProduct(batch.ProductID)
+		if err != nil {
+			return err
+		}
+		// 锁定商品后重新读取，期间可能有订单占用或归还
+		if batch, err = inventoryRepo.GetBatchByID(batchID); err != nil {
+			return err
+		}
+		if batch.Remaining <= 0 {
+			return nil
+		}
+		ok, err := inventoryRepo.ClearBatchRemaining(batch.ID, batch.Remaining)
+		if err != nil || !ok {
+			return err
+		}
+
+		quantity = min(batch.Remaining, product.StockCount)
+		if quantity > 0 {
+			if _, err := productRepo.DecreaseProductStock(product.ID, quantity); err != nil {
+				return err
+			}
+		}
+		return inventoryRepo.CreateMovement(&model.InventoryMovement{
+			ProductID:  product.ID,
+			Type:       model.InventoryMovementWastage,
+			Quantity:   -quantity,
+			StockAfter: product.StockCount - quantity,
+			RefType:    model.InventoryRefBatch,
+			RefID:      batch.ID,
+			Remark:     "批次过期报废",
+		})
+	})
+	return quantity, err
+}
+
+// allocateBatches 按到期日先后从到期日晚于 usableOn 的批次扣减，有单据时记录占用以便归还；
+// 批次不足时使用不属于任何批次的库存，仍不足则返回缺货
+func allocateBatches(inventoryRepo *repository.InventoryRepository, movement *model.InventoryMovement, stockBefore int, usableOn time.Time) error {
+	batches, err := inventoryRepo.GetLiveBatches(movement.ProductID)
+	if err != nil {
+		return err
+	}
+	if len(batches) == 0 {
+		return nil
+	}
+
+	unbatched := stockBefore
+	for _, batch := range batches {
+		unbatched -= batch.Remaining
+	}
+
+	need := -movement.Quantity
+	for _, batch := range batches {
+		if need == 0 {
+			break
+		}
+		if !batch.ExpiresAt.After(usableOn) {
+			continue
+		}
+		quantity := min(batch.Remaining, need)
+		if err := inventoryRepo.IncreaseBatchRemaining(batch.ID, -quantity); err != nil {
+			return err
+		}
+		if movement.RefID > 0 {
+			err := inventoryRepo.CreateAllocation(&model.StockBatchAllocation{
+				BatchID:   batch.ID,
+				ProductID: movement.ProductID,
+				RefType:   movement.RefType,
+				RefID:     movement.RefID,
+				Quantity:  quantity,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		need -= quantity
+	}
+	if need > unbatched {
+		return pkgerrors.ErrOutOfStock
+	}
+	return nil
+}
+
+// returnToBatches 将单据归还的数量退回其占用的批次，超出占用的部分作为不属于批次的库存。
+// 退回已到期的批次时由报废任务再次报废
+func returnToBatches(inventoryRepo *repository.InventoryRepository, movement *model.InventoryMovement) error {
+	if movement.RefType == "" || movement.RefID == 0 {
+		return nil
+	}
+	allocations, err := inventoryRepo.GetAllocations(movement.RefType, movement.RefID, movement.ProductID)
+	if err != nil {
+		return err
+	}
+
+	remaining := movement.Quantity
+	for _, allocation := range allocations {
+		if remaining == 0 {
+			break
+		}
+		quantity := min(allocation.Quantity, remaining)
+		if err := inventoryRepo.DecreaseAllocation(allocation.ID, quantity); err != nil {
+			return err
+		}
+		if err := inventoryRepo.IncreaseBatchRemaining(allocation.BatchID, quantity); err != nil {
+			return err
+		}
+		remaining -= quantity
+	}
+	return nil
+}
+
+// dateOf 时间所在日期的零点
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// reservedQuantities 商品被未发货订单占用的数量
+func (s *InventoryService) reservedQuantities(productIDs []uint64) (map[uint64]int, error) {
+	reserved, err := s.inventoryRepo.GetReservedQuantities(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	quantities := make(map[uint64]int, len(reserved))
+	for _, item := range reserved {
+		quantities[item.ProductID] = item.Quantity
+	}
+	return quantities, nil
+}
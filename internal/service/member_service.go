@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/notify"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	"gorm.io/gorm"
+)
+
+// 会员等级按近12个月的消费计算
+const memberSpendMonths = 12
+
+// MemberService handles business logic for membership tiers and member prices
+type MemberService struct {
+	db           *gorm.DB
+	memberRepo   *repository.MemberRepository
+	userRepo     *repository.UserRepository
+	cacheService *redis.CacheService
+}
+
+// NewMemberService creates a new member service
+func NewMemberService() *MemberService {
+	server := server.GetServer()
+	return &MemberService{
+		db:           server.DB,
+		memberRepo:   repository.NewMemberRepository(server.DB),
+		userRepo:     repository.NewUserRepository(server.DB),
+		cacheService: redis.NewCacheService(),
+	}
+}
+
+// GetTiers gets all tiers ordered by level ascending
+func (s *MemberService) GetTiers() ([]model.MemberTier, error) {
+	ctx := context.Background()
+
+	// Try to get from cache
+	var tiers []model.MemberTier
+	if err := s.cacheService.GetObject(ctx, constant.MemberTiers, &tiers); err == nil {
+		return tiers, nil
+	}
+
+	tiers, err := s.memberRepo.GetTiers()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheService.Set(ctx, constant.MemberTiers, tiers, 10*time.Minute); err != nil {
+		logger.Warnf("Failed to cache member tiers: %v", err)
+	}
+
+	return tiers, nil
+}
+
+// GetTier gets a tier by ID, returning nil for non-members
+func (s *MemberService) GetTier(tierID uint64) (*model.MemberTier, error) {
+	if tierID == 0 {
+		return nil, nil
+	}
+
+	tiers, err := s.GetTiers()
+	if err != nil {
+		return nil, err
+	}
+	// 等级已被删除时按非会员处理
+	return findTier(tiers, tierID), nil
+}
+
+// GetUserTier gets the current tier of a user, returning nil for non-members
+func (s *MemberService) GetUserTier(userID uint64) (*model.MemberTier, error) {
+	if userID == 0 {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTier(user.TierID)
+}
+
+// GetMemberInfo gets the tier, trailing spend and the gap to the next tier of a user
+func (s *MemberService) GetMemberInfo(userID uint64) (*response.MemberInfoResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tiers, err := s.GetTiers()
+	if err != nil {
+		return nil, err
+	}
+
+	spend, err := s.memberRepo.GetUserSpend(userID, spendSince(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &response.MemberInfoResponse{Spend: priceutils.Round(spend)}
+	current := findTier(tiers, user.TierID)
+	if current != nil {
+		info.Tier = toMemberTierResponse(current)
+	}
+
+	// 下一等级为高于当前等级的最低等级
+	for i := range tiers {
+		if current == nil || tiers[i].Level > current.Level {
+			info.NextTier = toMemberTierResponse(&tiers[i])
+			if gap := tiers[i].MinSpend - spend; gap > 0 {
+				info.NextSpend = priceutils.Round(gap)
+			}
+			break
+		}
+	}
+
+	return info, nil
+}
+
+// CreateTier creates a tier
+func (s *MemberService) CreateTier(req request.MemberTierRequest) (*model.MemberTier, error) {
+	tier := &model.MemberTier{
+		Name:         req.Name,
+		Level:        req.Level,
+		MinSpend:     req.MinSpend,
+		DiscountRate: req.DiscountRate,
+		FreeShipping: req.FreeShipping,
+	}
+	if err := s.memberRepo.CreateTier(tier); err != nil {
+		return nil, err
+	}
+
+	s.invalidateTiers()
+	return tier, nil
+}
+
+// UpdateTier updates a tier
+func (s *MemberService) UpdateTier(id uint64, req request.MemberTierRequest) (*model.MemberTier, error) {
+	tier, err := s.memberRepo.GetTierByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrTierNotFound
+	}
+
+	tier.Name = req.Name
+	tier.Level = req.Level
+	tier.MinSpend = req.MinSpend
+	tier.DiscountRate = req.DiscountRate
+	tier.FreeShipping = req.FreeShipping
+	if err := s.memberRepo.UpdateTier(tier); err != nil {
+		return nil, err
+	}
+
+	s.invalidateTiers()
+	return tier, nil
+}
+
+// DeleteTier deletes a tier and its member prices.
+// Users of the deleted tier are treated as non-members until the next recalculation.
+func (s *MemberService) DeleteTier(id uint64) error {
+	if err := s.memberRepo.DeleteTier(id); err != nil {
+		return err
+	}
+
+	s.invalidateTiers()
+	return nil
+}
+
+// GetMemberPrices gets the member prices of a product for every tier
+func (s *MemberService) GetMemberPrices(product *model.Product) ([]response.MemberPriceResponse, error) {
+	prices, err := s.GetMemberPricesBatch([]model.Product{*product})
+	if err != nil {
+		return nil, err
+	}
+	return prices[product.ID], nil
+}
+
+// GetMemberPricesBatch gets the member prices of products for every tier, keyed by product ID.
+// An explicit member price takes precedence over the tier discount rate.
+func (s *MemberService) GetMemberPricesBatch(products []model.Product) (map[uint64][]response.MemberPriceResponse, error) {
+	result := make(map[uint64][]response.MemberPriceResponse, len(products))
+
+	tiers, err := s.GetTiers()
+	if err != nil || len(tiers) == 0 {
+		return result, err
+	}
+
+	explicit, err := s.explicitPrices(products)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, product := range products {
+		prices := make([]response.MemberPriceResponse, len(tiers))
+		for i := range tiers {
+			prices[i] = response.MemberPriceResponse{
+				TierID:   tiers[i].ID,
+				TierName: tiers[i].Name,
+				Price:    memberUnitPrice(&product, &tiers[i], explicit),
+			}
+		}
+		result[product.ID] = prices
+	}
+
+	return result, nil
+}
+
+// UnitPrices gets the unit price of each product for a tier, keyed by product ID.
+// Non-members (nil tier) get the regular price.
+func (s *MemberService) UnitPrices(products []model.Product, tier *model.MemberTier) (map[uint64]float64, error) {
+	result := make(map[uint64]float64, len(products))
+	if tier == nil {
+		for _, product := range products {
+			result[product.ID] = product.Price
+		}
+		return result, nil
+	}
+
+	explicit, err := s.explicitPrices(products)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		result[product.ID] = memberUnitPrice(&product, tier, explicit)
+	}
+	return result, nil
+}
+
+// SetMemberPrice sets a fixed member price of a product for a tier
+func (s *MemberService) SetMemberPrice(productID uint64, req request.MemberPriceRequest) error {
+	if _, err := s.memberRepo.GetTierByID(req.TierID); err != nil {
+		return pkgerrors.ErrTierNotFound
+	}
+	if _, err := repository.NewProductRepository(s.db).GetProductByID(productID); err != nil {
+		return pkgerrors.ErrProductNotFound
+	}
+
+	err := s.memberRepo.SaveMemberPrice(&model.ProductMemberPrice{
+		ProductID: productID,
+		TierID:    req.TierID,
+		Price:     priceutils.Round(req.Price),
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateProductCache(context.Background(), s.cacheService, productID)
+	return nil
+}
+
+// DeleteMemberPrice removes the fixed member price so the tier discount rate applies again
+func (s *MemberService) DeleteMemberPrice(productID, tierID uint64) error {
+	if err := s.memberRepo.DeleteMemberPrice(productID, tierID); err != nil {
+		return err
+	}
+
+	invalidateProductCache(context.Background(), s.cacheService, productID)
+	return nil
+}
+
+// RecalculateUser recalculates the tier of a single user, e.g. after an order is completed
+func (s *MemberService) RecalculateUser(userID uint64) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	spend, err := s.memberRepo.GetUserSpend(userID, spendSince(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	tiers, err := s.GetTiers()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.changeTier(context.Background(), userID, user.TierID, spend, tiers)
+	return err
+}
+
+// RecalculateTiers recalculates the tier of all users from their trailing spend, run nightly
+func (s *MemberService) RecalculateTiers(ctx context.Context) error {
+	const batchSize = 500
+
+	tiers, err := s.memberRepo.GetTiers()
+	if err != nil {
+		return err
+	}
+	since := spendSince(time.Now())
+
+	userRepo := repository.NewUserRepository(s.db)
+	var lastUserID uint64
+	changed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		spends, err := s.memberRepo.GetSpendByUsers(since, lastUserID, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(spends) == 0 {
+			break
+		}
+
+		for _, item := range spends {
+			lastUserID = item.UserID
+
+			user, err := userRepo.GetUserByID(item.UserID)
+			if err != nil {
+				logger.Warnf("Failed to get user %d for tier recalculation: %v", item.UserID, err)
+				continue
+			}
+			updated, err := s.changeTier(ctx, item.UserID, user.TierID, item.Spend, tiers)
+			if err != nil {
+				logger.Warnf("Failed to recalculate tier of user %d: %v", item.UserID, err)
+				continue
+			}
+			if updated {
+				changed++
+			}
+		}
+
+		if len(spends) < batchSize {
+			break
+		}
+	}
+
+	logger.Infof("Recalculated member tiers, %d users changed", changed)
+	return nil
+}
+
+// changeTier 根据消费金额更新用户等级，等级变化时通知用户，返回等级是否变化
+func (s *MemberService) changeTier(ctx context.Context, userID, currentTierID uint64, spend float64, tiers []model.MemberTier) (bool, error) {
+	current := findTier(tiers, currentTierID)
+	target := tierForSpend(tiers, spend)
+	if target == current && (target != nil || currentTierID == 0) {
+		return false, nil
+	}
+
+	var targetID uint64
+	if target != nil {
+		targetID = target.ID
+	}
+	if err := s.memberRepo.UpdateUserTier(userID, targetID); err != nil {
+		return false, err
+	}
+
+	title := "会员等级变更"
+	content := "您的会员等级已调整为普通用户"
+	switch {
+	case target != nil && (current == nil || target.Level > current.Level):
+		title = "会员等级提升"
+		content = fmt.Sprintf("恭喜您升级为%s", target.Name)
+	case target != nil:
+		content = fmt.Sprintf("您的会员等级已调整为%s", target.Name)
+	}
+
+	notify.Send(ctx, notify.Message{
+		UserID:  userID,
+		Type:    notify.TypeTierChanged,
+		Title:   title,
+		Content: content,
+		Data: map[string]string{
+			"fromTierID": fmt.Sprintf("%d", currentTierID),
+			"toTierID":   fmt.Sprintf("%d", targetID),
+		},
+	})
+	return true, nil
+}
+
+// explicitPrices 获取商品设置的固定会员价，key为商品ID和等级ID
+func (s *MemberService) explicitPrices(products []model.Product) (map[[2]uint64]float64, error) {
+	productIDs := make([]uint64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	prices, err := s.memberRepo.GetMemberPricesByProductIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[[2]uint64]float64, len(prices))
+	for _, price := range prices {
+		result[[2]uint64{price.ProductID, price.TierID}] = price.Price
+	}
+	return result, nil
+}
+
+// invalidateTiers 删除会员等级缓存
+func (s *MemberService) invalidateTiers() {
+	s.cacheService.Delete(context.Background(), constant.MemberTiers)
+}
+
+// memberUnitPrice 计算商品在指定等级下的单价，固定会员价优先，否则按等级折扣；不高于原价
+func memberUnitPrice(product *model.Product, tier *model.MemberTier, explicit map[[2]uint64]float64) float64 {
+	price := priceutils.ApplyRate(product.Price, tier.DiscountRate)
+	if p, ok := explicit[[2]uint64{product.ID, tier.ID}]; ok {
+		price = p
+	}
+	if price > product.Price {
+		return product.Price
+	}
+	return price
+}
+
+// tierForSpend 返回消费金额可达到的最高等级，tiers需按等级升序
+func tierForSpend(tiers []model.MemberTier, spend float64) *model.MemberTier {
+	var result *model.MemberTier
+	for i := range tiers {
+		if spend >= tiers[i].MinSpend {
+			result = &tiers[i]
+		}
+	}
+	return result
+}
+
+// findTier 在等级列表中查找等级
+func findTier(tiers []model.MemberTier, id uint64) *model.MemberTier {
+	for i := range tiers {
+		if tiers[i].ID == id {
+			return &tiers[i]
+		}
+	}
+	return nil
+}
+
+// spendSince 计算会员消费统计的起始时间
+func spendSince(now time.Time) time.Time {
+	return now.AddDate(0, -memberSpendMonths, 0)
+}
+
+func toMemberTierResponse(tier *model.MemberTier) *response.MemberTierResponse {
+	return &response.MemberTierResponse{
+		ID:           tier.ID,
+		Name:         tier.Name,
+		Level:        tier.Level,
+		MinSpend:     tier.MinSpend,
+		DiscountRate: tier.DiscountRate,
+		FreeShipping: tier.FreeShipping,
+	}
+}
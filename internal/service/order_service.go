@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/colinjuang/shop-go/internal/app/request"
 	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/payment"
 	"github.com/colinjuang/shop-go/internal/server"
 
 	"github.com/colinjuang/shop-go/internal/app/response"
@@ -15,28 +18,48 @@ import (
 	"github.com/colinjuang/shop-go/internal/pkg/redis"
 	"github.com/colinjuang/shop-go/internal/repository"
 	utils "github.com/colinjuang/shop-go/internal/utils/order"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	"gorm.io/gorm"
 )
 
 // OrderService handles business logic for orders
 type OrderService struct {
-	orderRepo     *repository.OrderRepository
-	orderItemRepo *repository.OrderItemRepository
-	cartRepo      *repository.CartRepository
-	productRepo   *repository.ProductRepository
-	addressRepo   *repository.AddressRepository
-	cacheService  *redis.CacheService
+	db                  *gorm.DB
+	orderRepo           *repository.OrderRepository
+	orderItemRepo       *repository.OrderItemRepository
+	paymentRepo         *repository.OrderPaymentRepository
+	cartRepo            *repository.CartRepository
+	productRepo         *repository.ProductRepository
+	addressRepo         *repository.AddressRepository
+	cacheService        *redis.CacheService
+	pointsService       *PointsService
+	pricingService      *PricingService
+	memberService       *MemberService
+	distributionService *DistributionService
+	bundleService       *BundleService
+	limitService        *PurchaseLimitService
+	inventoryService    *InventoryService
 }
 
 // NewOrderService creates a new order service
 func NewOrderService() *OrderService {
 	server := server.GetServer()
 	return &OrderService{
-		orderRepo:     repository.NewOrderRepository(server.DB),
-		orderItemRepo: repository.NewOrderItemRepository(server.DB),
-		cartRepo:      repository.NewCartRepository(server.DB),
-		productRepo:   repository.NewProductRepository(server.DB),
-		addressRepo:   repository.NewAddressRepository(server.DB),
-		cacheService:  redis.NewCacheService(),
+		db:                  server.DB,
+		orderRepo:           repository.NewOrderRepository(server.DB),
+		orderItemRepo:       repository.NewOrderItemRepository(server.DB),
+		paymentRepo:         repository.NewOrderPaymentRepository(server.DB),
+		cartRepo:            repository.NewCartRepository(server.DB),
+		productRepo:         repository.NewProductRepository(server.DB),
+		addressRepo:         repository.NewAddressRepository(server.DB),
+		cacheService:        redis.NewCacheService(),
+		pointsService:       NewPointsService(),
+		pricingService:      NewPricingService(),
+		memberService:       NewMemberService(),
+		distributionService: NewDistributionService(),
+		bundleService:       NewBundleService(),
+		limitService:        NewPurchaseLimitService(),
+		inventoryService:    NewInventoryService(),
 	}
 }
 
@@ -57,22 +80,30 @@ func (s *OrderService) GetOrderDetail(userID uint64, orderID uint64) (*response.
 		return nil, err
 	}
 
-	orderItemsResponse := make([]response.OrderItemResponse, len(orderItems))
-	for i, item := range orderItems {
-		orderItemsResponse[i] = response.OrderItemResponse{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			Price:     item.Price,
-			Name:      item.Name,
-			ImageUrl:  item.ImageUrl,
-		}
+	payments, err := s.paymentRepo.GetOrderPayments(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemPromotions, err := s.orderItemRepo.GetOrderItemPromotions(orderID)
+	if err != nil {
+		return nil, err
 	}
 
+	orderItemsResponse := toOrderItemResponses(orderItems)
+
 	orderDetail := &response.OrderDetailResponse{
-		OrderID:     order.ID,
-		OrderNo:     order.OrderNo,
-		TotalAmount: order.TotalAmount,
-		OrderItem:   orderItemsResponse,
+		OrderID:           order.ID,
+		OrderNo:           order.OrderNo,
+		TotalAmount:       order.TotalAmount,
+		MemberDiscount:    order.MemberDiscount,
+		PromotionDiscount: order.PromotionDiscount,
+		ShippingFee:       order.ShippingFee,
+		PaymentAmount:     order.PaymentAmount,
+		RefundedAmount:    order.RefundedAmount,
+		Status:            order.Status,
+		OrderItem:         orderItemsResponse,
+		Promotions:        toAppliedPromotionResponses(itemPromotions),
 		Address: response.AddressResponse{
 			ID:           address.ID,
 			Phone:        address.Phone,
@@ -89,9 +120,77 @@ func (s *OrderService) GetOrderDetail(userID uint64, orderID uint64) (*response.
 		},
 	}
 
+	for _, p := range payments {
+		orderDetail.Payments = append(orderDetail.Payments, response.OrderPaymentResponse{
+			Stage:    p.Stage,
+			Amount:   p.Amount,
+			Status:   p.Status,
+			DueStart: p.DueStart,
+			DueEnd:   p.DueEnd,
+			PaidAt:   p.PaidAt,
+		})
+	}
+
 	return orderDetail, nil
 }
 
+// toAppliedPromotionResponses 按活动合计订单各项享受的促销优惠
+func toAppliedPromotionResponses(itemPromotions []model.OrderItemPromotion) []response.AppliedPromotionResponse {
+	indexes := make(map[uint64]int)
+	var responses []response.AppliedPromotionResponse
+	for _, promotion := range itemPromotions {
+		i, ok := indexes[promotion.PromotionID]
+		if !ok {
+			i = len(responses)
+			indexes[promotion.PromotionID] = i
+			responses = append(responses, response.AppliedPromotionResponse{
+				PromotionID: promotion.PromotionID,
+				Title:       promotion.Title,
+				RuleType:    promotion.RuleType,
+			})
+		}
+		responses[i].Discount = priceutils.Round(responses[i].Discount + promotion.Discount)
+	}
+	return responses
+}
+
+// toOrderItemResponses 转换订单项响应，组合商品的组件行嵌套在组合商品行下
+func toOrderItemResponses(items []model.OrderItem) []response.OrderItemResponse {
+	components := make(map[uint64][]response.OrderItemResponse)
+	for _, item := range items {
+		if item.ParentID > 0 {
+			components[item.ParentID] = append(components[item.ParentID], toOrderItemResponse(item))
+		}
+	}
+
+	responses := make([]response.OrderItemResponse, 0, len(items))
+	for _, item := range items {
+		if item.ParentID > 0 {
+			continue
+		}
+		itemResponse := toOrderItemResponse(item)
+		itemResponse.Components = components[item.ID]
+		responses = append(responses, itemResponse)
+	}
+	return responses
+}
+
+// toOrderItemResponse 转换单个订单项响应
+func toOrderItemResponse(item model.OrderItem) response.OrderItemResponse {
+	return response.OrderItemResponse{
+		ID:                item.ID,
+		ProductID:         item.ProductID,
+		Quantity:          item.Quantity,
+		Price:             item.Price,
+		OriginalPrice:     item.OriginalPrice,
+		Name:              item.Name,
+		ImageUrl:          item.ImageUrl,
+		IsBundle:          item.IsBundle,
+		RefundedQty:       item.RefundedQty,
+		PromotionDiscount: item.PromotionDiscount,
+	}
+}
+
 // CreateOrderAndPay 创建订单并支付
 func (s *OrderService) CreateOrderAndPay(userID uint64, req request.CreateOrderAndPayRequest) error {
 	address, err := s.addressRepo.GetAddressByID(req.AddressID)
@@ -103,70 +202,112 @@ func (s *OrderService) CreateOrderAndPay(userID uint64, req request.CreateOrderA
 		return pkgerrors.ErrAddressNotFound
 	}
 
+	deliveryDate, err := parseDeliveryDate(req.DeliveryDate)
+	if err != nil {
+		return err
+	}
+
 	product, err := s.productRepo.GetProductByID(req.ProductID)
 	if err != nil {
 		return err
 	}
 
+	// 组合商品按组件库存计算可售套数
+	if err := s.bundleService.FillStock(product); err != nil {
+		return err
+	}
 	if product.StockCount < req.Quantity {
 		return pkgerrors.ErrOutOfStock
 	}
 
+	pricing, err := s.pricingService.Calculate(userID, []PricingLine{{Product: *product, Quantity: req.Quantity}})
+	if err != nil {
+		return err
+	}
+
 	order := &model.OrderWithOrderItem{
 		Order: model.Order{
 			UserID:        userID,
 			OrderNo:       utils.GenerateOrderNo(userID), // 使用新的订单号生成函数
-			TotalAmount:   product.Price * float64(req.Quantity),
-			PaymentAmount: product.Price * float64(req.Quantity),
 			AddressID:     req.AddressID,
 			ReceiverName:  address.Name,
 			ReceiverPhone: address.Phone,
 			Address:       address.Province + address.City + address.District + address.DetailAddr,
 			PaymentType:   constant.PaymentMethodWechat,
+			DeliveryDate:  deliveryDate,
 			Remark:        req.Remark,
 		},
-		OrderItem: []model.OrderItem{
-			{
-				ProductID: req.ProductID,
-				Quantity:  req.Quantity,
-				Price:     product.Price,
-				Name:      product.Name,
-				ImageUrl:  product.ImageUrl,
-				Blessing:  req.Blessing,
-			},
-		},
 	}
+	applyPricing(order, pricing)
+	order.OrderItem[0].Blessing = req.Blessing
 
-	// 保存订单
-	if err := s.orderRepo.CreateOrder(&order.Order); err != nil {
-		return err
+	return s.saveOrder(order, req.Points, true)
+}
+
+// CreateOrder creates an order from the given items of the user's cart
+func (s *OrderService) CreateOrder(userID uint64, req request.CreateOrderRequest) error {
+	cartIDs := uniqueIDs(req.CartIDs)
+	if len(cartIDs) == 0 {
+		return pkgerrors.ErrInvalidInput
 	}
 
-	// 保存订单项
-	if err := s.orderItemRepo.CreateOrderItem(order.OrderItem); err != nil {
+	carts, err := s.cartRepo.GetCartsByIDs(cartIDs)
+	if err != nil {
 		return err
 	}
+	// 只能结算自己的购物车
+	if len(carts) != len(cartIDs) {
+		return pkgerrors.ErrCartNotFound
+	}
+	for _, cart := range carts {
+		if cart.UserID != userID {
+			return pkgerrors.ErrCartNotFound
+		}
+	}
 
-	// 更新商品库存
-	s.productRepo.UpdateProductStock(req.ProductID, req.Quantity)
+	_, err = s.createCartOrder(userID, req, carts)
+	return err
+}
 
-	return nil
+// CheckoutCart creates an order from the selected items of the user's cart. The items are removed from
+// the cart when the order is paid.
+func (s *OrderService) CheckoutCart(userID uint64, req request.CheckoutCartRequest) (*model.Order, error) {
+	carts, err := s.cartRepo.GetSelectedCarts(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(carts) == 0 {
+		return nil, pkgerrors.ErrInvalidInput
+	}
+
+	return s.createCartOrder(userID, request.CreateOrderRequest{
+		AddressID:    req.AddressID,
+		PaymentType:  req.PaymentType,
+		Points:       req.Points,
+		DeliveryDate: req.DeliveryDate,
+	}, carts)
 }
 
-// CreateOrder creates a new order
-func (s *OrderService) CreateOrder(userID uint64, req request.CreateOrderRequest) error {
+// createCartOrder 按购物车项创建订单，订单项记录来源购物车项，付款后再从购物车扣除
+func (s *OrderService) createCartOrder(userID uint64, req request.CreateOrderRequest, carts []model.Cart) (*model.Order, error) {
 	address, err := s.addressRepo.GetAddressByID(req.AddressID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if address.UserID != userID {
-		return pkgerrors.ErrAddressNotFound
+		return nil, pkgerrors.ErrAddressNotFound
+	}
+
+	deliveryDate, err := parseDeliveryDate(req.DeliveryDate)
+	if err != nil {
+		return nil, err
 	}
 
 	order := &model.OrderWithOrderItem{
 		Order: model.Order{
 			UserID:        userID,                                                                  // 用户ID
+			OrderNo:       utils.GenerateOrderNo(userID),                                           // 订单号
 			TotalAmount:   0,                                                                       // 总金额
 			PaymentAmount: 0,                                                                       // 支付金额
 			Status:        model.OrderStatusPending,                                                // 订单状态
@@ -175,60 +316,965 @@ func (s *OrderService) CreateOrder(userID uint64, req request.CreateOrderRequest
 			ReceiverPhone: address.Phone,                                                           // 收货人电话
 			Address:       address.Province + address.City + address.District + address.DetailAddr, // 地址
 			PaymentType:   constant.PaymentMethodWechat,                                            // 默认微信支付
+			DeliveryDate:  deliveryDate,                                                            // 期望配送日期
 		},
 		OrderItem: []model.OrderItem{},
 	}
 
-	var totalAmount float64
-	var paymentAmount float64
-	var orderItems []model.OrderItem
-	for _, cartID := range req.CartIDs {
-		cart, err := s.cartRepo.GetCartByID(cartID)
+	products := make([]*model.Product, len(carts))
+	for i := range carts {
+		products[i] = &carts[i].Product
+	}
+	if err := s.bundleService.FillStock(products...); err != nil {
+		return nil, err
+	}
+
+	lines := make([]PricingLine, 0, len(carts))
+	for _, cart := range carts {
+		if err := cartCheckoutError(&cart); err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, PricingLine{Product: cart.Product, Quantity: cart.Quantity})
+	}
+
+	pricing, err := s.pricingService.Calculate(userID, lines)
+	if err != nil {
+		return nil, err
+	}
+	applyPricing(order, pricing)
+	for i, cart := range carts {
+		order.OrderItem[i].CartID = cart.ID
+		order.OrderItem[i].Blessing = cart.Blessing
+	}
+
+	if err := s.saveOrder(order, req.Points, true); err != nil {
+		return nil, err
+	}
+	return &order.Order, nil
+}
+
+// DealOrderParams describes an order placed at a campaign deal price, e.g. flash sale, group buy or pre-sale
+type DealOrderParams struct {
+	ProductID    uint64
+	DealPrice    float64
+	AddressID    uint64
+	Quantity     int
+	Blessing     string
+	Remark       string
+	GroupID      uint64     // 拼团ID，非拼团订单为0
+	PreSaleID    uint64     // 预售活动ID，非预售订单为0
+	DeliveryDate *time.Time // 期望配送日期，为空表示尽快配送
+}
+
+// CreateDealOrder creates a pending order at a campaign deal price.
+// claim runs in the order transaction to take the campaign quota; the order is rolled back if it fails.
+func (s *OrderService) CreateDealOrder(userID uint64, params DealOrderParams, claim func(tx *gorm.DB, order *model.Order) error) (*model.Order, error) {
+	address, err := s.addressRepo.GetAddressByID(params.AddressID)
+	if err != nil || address.UserID != userID {
+		return nil, pkgerrors.ErrAddressNotFound
+	}
+
+	product, err := s.productRepo.GetProductByID(params.ProductID)
+	if err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+
+	pricing, err := s.pricingService.Calculate(userID, []PricingLine{{Product: *product, Quantity: params.Quantity, DealPrice: params.DealPrice}})
+	if err != nil {
+		return nil, err
+	}
+
+	order := &model.OrderWithOrderItem{
+		Order: model.Order{
+			UserID:        userID,
+			OrderNo:       utils.GenerateOrderNo(userID),
+			Status:        model.OrderStatusPending,
+			AddressID:     params.AddressID,
+			ReceiverName:  address.Name,
+			ReceiverPhone: address.Phone,
+			Address:       address.Province + address.City + address.District + address.DetailAddr,
+			GroupID:       params.GroupID,
+			PreSaleID:     params.PreSaleID,
+			DeliveryDate:  params.DeliveryDate,
+			PaymentType:   constant.PaymentMethodWechat,
+			Remark:        params.Remark,
+		},
+	}
+	applyPricing(order, pricing)
+	order.OrderItem[0].Blessing = params.Blessing
+
+	// 活动订单由活动自身限购
+	if err := s.saveOrder(order, 0, false, claim); err != nil {
+		return nil, err
+	}
+
+	return &order.Order, nil
+}
+
+// applyPricing 将计价结果写入订单及订单项
+func applyPricing(order *model.OrderWithOrderItem, pricing *PricingResult) {
+	order.TotalAmount = pricing.TotalAmount
+	order.MemberDiscount = pricing.MemberDiscount
+	order.PromotionDiscount = pricing.PromotionDiscount
+	order.ShippingFee = pricing.ShippingFee
+	order.PaymentAmount = pricing.PaymentAmount
+
+	order.OrderItem = make([]model.OrderItem, len(pricing.Lines))
+	for i, line := range pricing.Lines {
+		order.OrderItem[i] = model.OrderItem{
+			ProductID:         line.Product.ID,
+			Quantity:          line.Quantity,
+			Price:             line.UnitPrice,
+			OriginalPrice:     line.OriginalPrice,
+			Name:              line.Product.Name,
+			ImageUrl:          line.Product.ImageUrl,
+			IsBundle:          line.Product.IsBundle,
+			PromotionDiscount: line.PromotionDiscount,
+		}
+		for _, promotion := range line.Promotions {
+			order.OrderItem[i].Promotions = append(order.OrderItem[i].Promotions, model.OrderItemPromotion{
+				PromotionID: promotion.PromotionID,
+				Title:       promotion.Title,
+				RuleType:    promotion.RuleType,
+				Discount:    promotion.Discount,
+			})
+		}
+	}
+}
+
+// saveOrder 在事务中保存订单、订单项、扣减库存并处理积分抵扣。组合商品展开为组件行，由组件扣减库存。
+// checkLimits 为true时先校验商品限购；claims 在同一事务中于订单保存后执行，用于占用秒杀等活动名额，返回错误时整个订单回滚
+func (s *OrderService) saveOrder(order *model.OrderWithOrderItem, points int, checkLimits bool, claims ...func(tx *gorm.DB, order *model.Order) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// 来自购物车的订单项不能已在待付款订单中，需在事务内的其他查询之前锁定用户
+		if err := checkPendingCarts(tx, order.UserID, order.OrderItem); err != nil {
+			return err
+		}
+
+		// 限购校验需在保存订单前锁定用户，保证同一用户并发下单时依次校验
+		if checkLimits {
+			if err := s.limitService.CheckOrder(tx, order.UserID, order.OrderItem); err != nil {
+				return err
+			}
+		}
+
+		// 保存订单
+		if err := repository.NewOrderRepository(tx).CreateOrder(&order.Order); err != nil {
+			return err
+		}
+
+		// 保存订单项
+		if err := expandBundles(tx, order.OrderItem); err != nil {
+			return err
+		}
+		for i := range order.OrderItem {
+			order.OrderItem[i].OrderID = order.ID
+		}
+		orderItemRepo := repository.NewOrderItemRepository(tx)
+		if err := orderItemRepo.CreateOrderItem(order.OrderItem); err != nil {
+			return err
+		}
+
+		// 保存各订单项享受的促销活动，部分退款时按数量折算
+		var itemPromotions []model.OrderItemPromotion
+		for _, item := range order.OrderItem {
+			for _, promotion := range item.Promotions {
+				promotion.OrderID = order.ID
+				promotion.OrderItemID = item.ID
+				itemPromotions = append(itemPromotions, promotion)
+			}
+		}
+		if err := orderItemRepo.CreateOrderItemPromotions(itemPromotions); err != nil {
+			return err
+		}
+
+		// 保存组合商品的组件行
+		var stockItems, components []model.OrderItem
+		for _, item := range order.OrderItem {
+			if !item.IsBundle {
+				stockItems = append(stockItems, item)
+				continue
+			}
+			for _, component := range item.Components {
+				component.OrderID = order.ID
+				component.ParentID = item.ID
+				components = append(components, component)
+			}
+		}
+		if len(components) > 0 {
+			if err := orderItemRepo.CreateOrderItem(components); err != nil {
+				return err
+			}
+			stockItems = append(stockItems, components...)
+		}
+
+		// 扣减商品库存，易腐商品按到期日先后占用配送日期仍未到期的批次
+		for _, item := range stockItems {
+			err := s.inventoryService.AllocateStock(tx, &model.InventoryMovement{
+				ProductID: item.ProductID,
+				Type:      model.InventoryMovementSale,
+				Quantity:  -item.Quantity,
+				ActorID:   order.UserID,
+				RefType:   model.InventoryRefOrder,
+				RefID:     order.ID,
+			}, order.DeliveryDate)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, claim := range claims {
+			if err := claim(tx, &order.Order); err != nil {
+				return err
+			}
+		}
+
+		// 积分抵扣
+		if err := s.pointsService.RedeemForOrder(tx, &order.Order, points); err != nil {
+			return err
+		}
+
+		// 按抵扣后的实付金额为推荐人计算待结算佣金
+		return s.distributionService.HoldForOrder(tx, &order.Order, order.OrderItem)
+	})
+}
+
+// MarkPaid marks the payment due on an order as paid. Group-buy orders wait in the grouping status
+// until the group is full, and are then released to fulfilment together. Pre-orders are paid in two
+// stages: the deposit moves the order to deposit paid, and the balance to paid.
+func (s *OrderService) MarkPaid(userID uint64, orderID uint64) error {
+	order, err := s.orderRepo.GetOrderByIDAndUserID(orderID, userID)
+	if err != nil {
+		return pkgerrors.ErrOrderNotFound
+	}
+
+	from := []int{model.OrderStatusPending}
+	status := model.OrderStatusPaid
+	var due *model.OrderPayment
+	switch {
+	case order.GroupID > 0:
+		status = model.OrderStatusGrouping
+	case order.PreSaleID > 0:
+		if due, err = s.duePayment(order); err != nil {
+			return err
+		}
+		from = []int{order.Status}
+		if due.Stage == model.PaymentStageDeposit {
+			status = model.OrderStatusDepositPaid
+		}
+	}
+
+	var completedGroup *model.GroupBuyGroup
+	var sales []salesChange
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		updated, err := repository.NewOrderRepository(tx).UpdateOrderStatusFrom(order.ID, from, status)
 		if err != nil {
 			return err
 		}
+		if !updated {
+			return pkgerrors.ErrOrderStatusInvalid
+		}
 
-		if cart.Product.StockCount < cart.Quantity {
-			return pkgerrors.ErrOutOfStock
+		if due != nil {
+			updated, err := repository.NewOrderPaymentRepository(tx).UpdatePaymentStatusFrom(due.ID,
+				model.OrderPaymentStatusPending, model.OrderPaymentStatusPaid)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				return pkgerrors.ErrOrderStatusInvalid
+			}
 		}
 
-		orderItem := model.OrderItem{
-			ProductID: cart.ProductID,
-			Quantity:  cart.Quantity,
-			Price:     cart.Product.Price,
-			Name:      cart.Product.Name,
-			ImageUrl:  cart.Product.ImageUrl,
+		// 订单付清时计入销量，预售只付定金时不计
+		if status == model.OrderStatusPaid || status == model.OrderStatusGrouping {
+			items, err := repository.NewOrderItemRepository(tx).GetOrderItemsByOrderID(order.ID)
+			if err != nil {
+				return err
+			}
+			if sales, err = recordOrderSales(tx, orderSaleQuantities(items)); err != nil {
+				return err
+			}
+			// 付款后从购物车扣除已购买的商品，提交订单时不删除，未付款取消的订单商品仍在购物车中
+			if err := removePurchasedCarts(tx, order.UserID, items); err != nil {
+				return err
+			}
 		}
-		orderItems = append(orderItems, orderItem)
 
-		totalAmount += float64(cart.Quantity) * cart.Product.Price
-		paymentAmount += float64(cart.Quantity) * cart.Product.Price
+		if order.GroupID > 0 {
+			completedGroup, err = onGroupBuyPaid(tx, order)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateOrderCache(order)
+	updateSalesRanking(context.Background(), time.Now(), sales)
+	if completedGroup != nil {
+		notifyGroupBuyResult(context.Background(), completedGroup)
 	}
+	return nil
+}
 
-	order.OrderItem = orderItems
-	order.TotalAmount = totalAmount
-	order.PaymentAmount = paymentAmount
+// checkPendingCarts 检查订单项来源的购物车项是否已被待付款订单占用。购物车项付款后才扣除，
+// 重复提交时拒绝，避免生成多个待付款订单重复占用库存；锁定用户使同一用户的并发提交依次检查
+func checkPendingCarts(tx *gorm.DB, userID uint64, items []model.OrderItem) error {
+	var cartIDs []uint64
+	for _, item := range items {
+		if item.CartID > 0 {
+			cartIDs = append(cartIDs, item.CartID)
+		}
+	}
+	if len(cartIDs) == 0 {
+		return nil
+	}
 
-	// 保存订单
-	if err := s.orderRepo.CreateOrder(&order.Order); err != nil {
+	if _, err := repository.NewUserRepository(tx).GetUserByIDForUpdate(userID); err != nil {
 		return err
 	}
+	pending, err := repository.NewOrderItemRepository(tx).GetPendingCartIDs(userID, cartIDs)
+	if err != nil {
+		return err
+	}
+	if len(utils.CartConflicts(cartIDs, pending)) > 0 {
+		return pkgerrors.ErrCartCheckoutPending
+	}
+	return nil
+}
+
+// removePurchasedCarts 从购物车扣除订单项来源购物车项的已购买数量，扣完的购物车项删除
+func removePurchasedCarts(tx *gorm.DB, userID uint64, items []model.OrderItem) error {
+	cartRepo := repository.NewCartRepository(tx)
+	for _, item := range items {
+		if item.CartID == 0 {
+			continue
+		}
+		if err := cartRepo.DeductCart(userID, item.CartID, item.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAmountDue returns the payment number and amount to pay now. Orders paid in stages
+// use the number of the stage payment so that each stage is a separate payment.
+func (s *OrderService) GetAmountDue(order *model.Order) (string, float64, error) {
+	if order.PreSaleID == 0 {
+		return order.OrderNo, order.PaymentAmount, nil
+	}
+
+	due, err := s.duePayment(order)
+	if err != nil {
+		return "", 0, err
+	}
+	return due.PaymentNo, due.Amount, nil
+}
+
+// duePayment 获取分阶段支付订单当前应付的支付记录，并校验支付时间窗口
+func (s *OrderService) duePayment(order *model.Order) (*model.OrderPayment, error) {
+	stage := model.PaymentStageDeposit
+	switch order.Status {
+	case model.OrderStatusPending:
+	case model.OrderStatusDepositPaid:
+		stage = model.PaymentStageBalance
+	default:
+		return nil, pkgerrors.ErrOrderStatusInvalid
+	}
+
+	due, err := s.paymentRepo.GetOrderPaymentByStage(order.ID, stage)
+	if err != nil {
+		return nil, err
+	}
+	if due.Status != model.OrderPaymentStatusPending {
+		return nil, pkgerrors.ErrOrderStatusInvalid
+	}
 
-	// 更新商品库存
-	for _, item := range orderItems {
-		s.productRepo.UpdateProductStock(item.ProductID, item.Quantity)
+	now := time.Now()
+	if now.Before(due.DueStart) {
+		return nil, pkgerrors.ErrPaymentWindowNotOpen
 	}
+	if !now.Before(due.DueEnd) {
+		return nil, pkgerrors.ErrPaymentWindowClosed
+	}
+	return due, nil
+}
+
+// ConfirmReceipt marks a paid or shipped order as completed and grants points
+func (s *OrderService) ConfirmReceipt(userID uint64, orderID uint64) error {
+	order, err := s.orderRepo.GetOrderByIDAndUserID(orderID, userID)
+	if err != nil {
+		return pkgerrors.ErrOrderNotFound
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		updated, err := repository.NewOrderRepository(tx).UpdateOrderStatusFrom(order.ID,
+			[]int{model.OrderStatusPaid, model.OrderStatusShipped}, model.OrderStatusCompleted)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return pkgerrors.ErrOrderStatusInvalid
+		}
+
+		items, err := repository.NewOrderItemRepository(tx).GetOrderItemsByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
 
-	// 删除购物车
-	if len(req.CartIDs) > 0 {
-		for _, id := range req.CartIDs {
-			s.cartRepo.DeleteCart(id)
+		if err := s.pointsService.EarnForOrder(tx, order, items); err != nil {
+			return err
 		}
+
+		return s.distributionService.SettleForOrder(tx, order)
+	})
+	if err != nil {
+		return err
 	}
 
+	s.invalidateOrderCache(order)
+
+	// 订单完成后重新计算会员等级，失败时由每日任务兜底
+	if err := s.memberService.RecalculateUser(userID); err != nil {
+		logger.Warnf("Failed to recalculate member tier of user %d: %v", userID, err)
+	}
 	return nil
 }
 
+// CancelOrder cancels a pending order, releasing stock, flash sale and group-buy quota and redeemed points
+func (s *OrderService) CancelOrder(userID uint64, orderID uint64) error {
+	order, err := s.orderRepo.GetOrderByIDAndUserID(orderID, userID)
+	if err != nil {
+		return pkgerrors.ErrOrderNotFound
+	}
+
+	var flashSaleOrder *model.FlashSaleOrder
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		updated, err := repository.NewOrderRepository(tx).UpdateOrderStatusFrom(order.ID,
+			[]int{model.OrderStatusPending}, model.OrderStatusCancelled)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return pkgerrors.ErrOrderStatusInvalid
+		}
+
+		if err := s.restoreStock(tx, order.ID, model.InventoryMovementCancelRelease, userID); err != nil {
+			return err
+		}
+
+		// 秒杀订单释放活动名额
+		flashSaleRepo := repository.NewFlashSaleRepository(tx)
+		flashSaleOrder, err = flashSaleRepo.CancelFlashSaleOrder(order.ID)
+		if err != nil {
+			return err
+		}
+		if flashSaleOrder != nil {
+			if err := flashSaleRepo.DecreaseSold(flashSaleOrder.FlashSaleID, flashSaleOrder.Quantity); err != nil {
+				return err
+			}
+		}
+
+		// 拼团订单释放参团名额
+		if order.GroupID > 0 {
+			if err := onGroupBuyOrderClosed(tx, order, model.GroupBuyParticipantStatusCancelled); err != nil {
+				return err
+			}
+		}
+
+		if err := s.closePendingPayments(tx, order.ID); err != nil {
+			return err
+		}
+
+		if err := s.distributionService.CancelForOrder(tx, order); err != nil {
+			return err
+		}
+
+		return s.pointsService.ReturnRedeemedForOrder(tx, order)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateOrderCache(order)
+	if flashSaleOrder != nil {
+		releaseFlashSaleQuota(context.Background(), flashSaleOrder)
+	}
+	return nil
+}
+
+// RefundOrder refunds a paid order through the payment provider, releasing stock and reversing points
+func (s *OrderService) RefundOrder(orderID uint64, reason string) error {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return pkgerrors.ErrOrderNotFound
+	}
+
+	var sales []salesChange
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 已付清的订单扣回销量，只付了定金的预售订单未计入销量
+		orderRepo := repository.NewOrderRepository(tx)
+		counted, err := orderRepo.UpdateOrderStatusFrom(order.ID, repository.SalesCountedStatuses, model.OrderStatusRefunded)
+		if err != nil {
+			return err
+		}
+		updated := counted
+		if !counted {
+			updated, err = orderRepo.UpdateOrderStatusFrom(order.ID, []int{model.OrderStatusDepositPaid}, model.OrderStatusRefunded)
+			if err != nil {
+				return err
+			}
+		}
+		if !updated {
+			return pkgerrors.ErrOrderStatusInvalid
+		}
+
+		if counted {
+			items, err := repository.NewOrderItemRepository(tx).GetOrderItemsByOrderID(order.ID)
+			if err != nil {
+				return err
+			}
+			quantities := orderSaleQuantities(items)
+			for productID, quantity := range quantities {
+				quantities[productID] = -quantity
+			}
+			if sales, err = recordOrderSales(tx, quantities); err != nil {
+				return err
+			}
+		}
+
+		if err := s.restoreStock(tx, order.ID, model.InventoryMovementRefundReturn, 0); err != nil {
+			return err
+		}
+
+		if order.GroupID > 0 {
+			if err := onGroupBuyOrderClosed(tx, order, model.GroupBuyParticipantStatusRefunded); err != nil {
+				return err
+			}
+		}
+
+		if err := s.pointsService.ClawbackForOrder(tx, order); err != nil {
+			return err
+		}
+
+		if err := s.pointsService.ReturnRedeemedForOrder(tx, order); err != nil {
+			return err
+		}
+
+		if err := s.distributionService.ReverseForOrder(tx, order); err != nil {
+			return err
+		}
+
+		// 最后调用支付退款，失败时订单回滚保持原状态，可重试
+		return s.refundPayments(tx, order, reason)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateOrderCache(order)
+	updateSalesRanking(context.Background(), order.PaymentTime, sales)
+	return nil
+}
+
+// errRefundWholeOrder 部分退款覆盖了订单全部剩余商品，改为整单退款
+var errRefundWholeOrder = errors.New("partial refund covers the whole order")
+
+// RefundOrderItems refunds part of a paid order item by item (admin). A bundle line is refunded by whole sets,
+// while a single component line, e.g. the vase of a bouquet combo, can be returned on its own at its allocated amount.
+// The returned stock is restored. When nothing refundable would remain the whole order is refunded instead;
+// points and commissions are only reversed by the whole-order refund.
+func (s *OrderService) RefundOrderItems(orderID uint64, req request.RefundOrderItemsRequest) error {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return pkgerrors.ErrOrderNotFound
+	}
+	// 预售订单按定金和尾款分笔支付，不支持部分退款
+	if order.PreSaleID > 0 {
+		return pkgerrors.ErrPartialRefundNotAllowed
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "管理员部分退款"
+	}
+
+	var sales []salesChange
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		orderRepo := repository.NewOrderRepository(tx)
+		order, err = orderRepo.LockOrder(orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != model.OrderStatusPaid && order.Status != model.OrderStatusShipped && order.Status != model.OrderStatusCompleted {
+			return pkgerrors.ErrOrderStatusInvalid
+		}
+
+		orderItemRepo := repository.NewOrderItemRepository(tx)
+		items, err := orderItemRepo.GetOrderItemsForUpdate(order.ID)
+		if err != nil {
+			return err
+		}
+
+		refunds, amount, err := planItemRefunds(items, req.Items)
+		if err != nil {
+			return err
+		}
+
+		// 全部商品都已退还时按整单退款处理，同时退还运费、积分并冲正佣金
+		if allItemsRefunded(items, refunds) {
+			return errRefundWholeOrder
+		}
+
+		refundedSales := make(map[uint64]int)
+		for _, item := range items {
+			qty := refunds[item.ID]
+			if qty == 0 {
+				continue
+			}
+			// 单独退组件时组合商品的销量不变
+			if item.ParentID == 0 {
+				refundedSales[item.ProductID] -= qty
+			}
+			ok, err := orderItemRepo.IncreaseRefundedQty(item.ID, qty)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return pkgerrors.ErrRefundQuantityExceeded
+			}
+			if item.IsBundle {
+				continue
+			}
+			err = s.inventoryService.ChangeStock(tx, &model.InventoryMovement{
+				ProductID: item.ProductID,
+				Type:      model.InventoryMovementRefundReturn,
+				Quantity:  qty,
+				RefType:   model.InventoryRefOrder,
+				RefID:     order.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if sales, err = recordOrderSales(tx, refundedSales); err != nil {
+			return err
+		}
+
+		// 按商品实付占成交金额的比例折算退款金额，积分抵扣部分不退现金，运费在整单退款时退还
+		var itemsAmount float64
+		for _, item := range items {
+			if item.ParentID == 0 {
+				itemsAmount += item.Price*float64(item.Quantity) - item.PromotionDiscount
+			}
+		}
+		paid := order.PaymentAmount - order.ShippingFee
+		refundAmount := 0.0
+		if itemsAmount > 0 && paid > 0 {
+			refundAmount = min(priceutils.Round(amount*paid/itemsAmount), priceutils.Round(paid-order.RefundedAmount))
+		}
+
+		if err := orderRepo.AddRefundedAmount(order.ID, refundAmount); err != nil {
+			return err
+		}
+
+		count, err := orderItemRepo.CountOrderRefunds(order.ID)
+		if err != nil {
+			return err
+		}
+		refundNo := fmt.Sprintf("RF%s%02d", order.OrderNo, count+1)
+		if err := orderItemRepo.CreateOrderRefund(&model.OrderRefund{
+			OrderID:  order.ID,
+			RefundNo: refundNo,
+			Amount:   refundAmount,
+			Reason:   reason,
+		}); err != nil {
+			return err
+		}
+
+		if refundAmount <= 0 {
+			return nil
+		}
+
+		// 最后调用支付退款，失败时回滚，可重试
+		refundReq, err := orderRefundRequest(tx, order, reason)
+		if err != nil {
+			return err
+		}
+		refundReq.RefundNo = refundNo
+		refundReq.RefundAmount = refundAmount
+		return payment.Refund(context.Background(), refundReq)
+	})
+	if errors.Is(err, errRefundWholeOrder) {
+		return s.RefundOrder(orderID, reason)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.invalidateOrderCache(order)
+	updateSalesRanking(context.Background(), order.PaymentTime, sales)
+	return nil
+}
+
+// planItemRefunds 计算各订单项的退款数量及按成交价扣除促销优惠后计算的退款金额。
+// 整套退组合商品时按套数退还各组件，组件已单独退过的部分不再重复退；单独退组件时按组件分摊金额计算
+func planItemRefunds(items []model.OrderItem, reqItems []request.RefundItemRequest) (map[uint64]int, float64, error) {
+	itemMap := make(map[uint64]model.OrderItem, len(items))
+	components := make(map[uint64][]model.OrderItem)
+	for _, item := range items {
+		itemMap[item.ID] = item
+		if item.ParentID > 0 {
+			components[item.ParentID] = append(components[item.ParentID], item)
+		}
+	}
+
+	refunds := make(map[uint64]int)
+	var amount float64
+	refund := func(item model.OrderItem, qty int) {
+		refunds[item.ID] += qty
+		switch {
+		case item.ParentID > 0:
+			amount += item.Allocated * float64(qty) / float64(item.Quantity)
+		case !item.IsBundle:
+			// 促销优惠按退款数量折算，只退还实际支付的部分
+			amount += item.Price*float64(qty) - item.PromotionDiscount*float64(qty)/float64(item.Quantity)
+		}
+	}
+
+	for _, reqItem := range reqItems {
+		item, ok := itemMap[reqItem.OrderItemID]
+		if !ok {
+			return nil, 0, pkgerrors.ErrInvalidInput
+		}
+		if reqItem.Quantity > item.Quantity-item.RefundedQty-refunds[item.ID] {
+			return nil, 0, pkgerrors.ErrRefundQuantityExceeded
+		}
+		refund(item, reqItem.Quantity)
+
+		if !item.IsBundle {
+			continue
+		}
+		for _, component := range components[item.ID] {
+			perSet := component.Quantity / item.Quantity
+			qty := min(perSet*reqItem.Quantity, component.Quantity-component.RefundedQty-refunds[component.ID])
+			if qty > 0 {
+				refund(component, qty)
+			}
+		}
+	}
+	return refunds, amount, nil
+}
+
+// allItemsRefunded 判断本次退款后订单是否已没有未退的商品，组合商品以组件行为准
+func allItemsRefunded(items []model.OrderItem, refunds map[uint64]int) bool {
+	for _, item := range items {
+		if item.IsBundle {
+			continue
+		}
+		if item.RefundedQty+refunds[item.ID] < item.Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// CloseUnpaidBalance cancels a pre-order whose balance was not paid in time, releasing its stock.
+// The deposit is refunded when refundDeposit is set, otherwise it is forfeited.
+func (s *OrderService) CloseUnpaidBalance(orderID uint64, refundDeposit bool) error {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return pkgerrors.ErrOrderNotFound
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		updated, err := repository.NewOrderRepository(tx).UpdateOrderStatusFrom(order.ID,
+			[]int{model.OrderStatusDepositPaid}, model.OrderStatusCancelled)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return pkgerrors.ErrOrderStatusInvalid
+		}
+
+		if err := s.restoreStock(tx, order.ID, model.InventoryMovementCancelRelease, 0); err != nil {
+			return err
+		}
+
+		if err := s.closePendingPayments(tx, order.ID); err != nil {
+			return err
+		}
+
+		if err := s.pointsService.ReturnRedeemedForOrder(tx, order); err != nil {
+			return err
+		}
+
+		if err := s.distributionService.CancelForOrder(tx, order); err != nil {
+			return err
+		}
+
+		if refundDeposit {
+			return s.refundPayments(tx, order, "尾款逾期未支付，定金退回")
+		}
+
+		deposit, err := repository.NewOrderPaymentRepository(tx).GetOrderPaymentByStage(order.ID, model.PaymentStageDeposit)
+		if err != nil {
+			return err
+		}
+		_, err = repository.NewOrderPaymentRepository(tx).UpdatePaymentStatusFrom(deposit.ID,
+			model.OrderPaymentStatusPaid, model.OrderPaymentStatusForfeited)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateOrderCache(order)
+	return nil
+}
+
+// refundPayments 通过支付渠道退还订单已支付的款项。分阶段支付的订单按支付记录逐笔退款；
+// 退款单号固定，重复提交不会重复退款
+func (s *OrderService) refundPayments(tx *gorm.DB, order *model.Order, reason string) error {
+	ctx := context.Background()
+	paymentRepo := repository.NewOrderPaymentRepository(tx)
+
+	payments, err := paymentRepo.GetOrderPayments(order.ID)
+	if err != nil {
+		return err
+	}
+	if len(payments) == 0 {
+		req, err := orderRefundRequest(tx, order, reason)
+		if err != nil {
+			return err
+		}
+
+		// 扣除已部分退款的金额
+		req.RefundAmount = priceutils.Round(req.RefundAmount - order.RefundedAmount)
+		if req.RefundAmount <= 0 {
+			return nil
+		}
+		return payment.Refund(ctx, req)
+	}
+
+	for _, paid := range payments {
+		if paid.Status != model.OrderPaymentStatusPaid {
+			continue
+		}
+		if _, err := paymentRepo.UpdatePaymentStatusFrom(paid.ID, model.OrderPaymentStatusPaid, model.OrderPaymentStatusRefunded); err != nil {
+			return err
+		}
+		err := payment.Refund(ctx, payment.RefundRequest{
+			OrderNo:      paid.PaymentNo,
+			RefundNo:     "RF" + paid.PaymentNo,
+			TotalAmount:  paid.Amount,
+			RefundAmount: paid.Amount,
+			Reason:       reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderRefundRequest 构造整单支付订单的退款请求，退款金额为订单实付金额。
+// 预付订阅的配送订单由订阅预付款支付，按订阅单号退还该次配送的金额
+func orderRefundRequest(tx *gorm.DB, order *model.Order, reason string) (payment.RefundRequest, error) {
+	req := payment.RefundRequest{
+		OrderNo:      order.OrderNo,
+		RefundNo:     "RF" + order.OrderNo,
+		TotalAmount:  order.PaymentAmount,
+		RefundAmount: order.PaymentAmount,
+		Reason:       reason,
+	}
+
+	subscriptionRepo := repository.NewSubscriptionRepository(tx)
+	delivery, err := subscriptionRepo.GetDeliveryByOrderID(order.ID)
+	if err != nil {
+		return req, err
+	}
+	if delivery != nil && delivery.Amount > 0 {
+		subscription, err := subscriptionRepo.GetSubscriptionByID(delivery.SubscriptionID)
+		if err != nil {
+			return req, err
+		}
+		req.OrderNo = subscription.SubscriptionNo
+		req.TotalAmount = subscription.PaidAmount
+		req.RefundAmount = delivery.Amount
+	}
+	return req, nil
+}
+
+// closePendingPayments 关闭订单未支付的支付记录
+func (s *OrderService) closePendingPayments(tx *gorm.DB, orderID uint64) error {
+	paymentRepo := repository.NewOrderPaymentRepository(tx)
+	payments, err := paymentRepo.GetOrderPayments(orderID)
+	if err != nil {
+		return err
+	}
+	for _, pending := range payments {
+		if pending.Status != model.OrderPaymentStatusPending {
+			continue
+		}
+		if _, err := paymentRepo.UpdatePaymentStatusFrom(pending.ID, model.OrderPaymentStatusPending, model.OrderPaymentStatusCancelled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDeliveryDate 解析期望配送日期，为空表示尽快配送，不能早于今天
+func parseDeliveryDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil || date.Before(today()) {
+		return nil, pkgerrors.ErrInvalidDeliveryDate
+	}
+	return &date, nil
+}
+
+// restoreStock 归还订单占用的库存并记录库存流水，组合商品由组件行归还，已部分退款的数量已归还过
+func (s *OrderService) restoreStock(tx *gorm.DB, orderID uint64, movementType int, actorID uint64) error {
+	items, err := repository.NewOrderItemRepository(tx).GetOrderItemsByOrderID(orderID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.IsBundle || item.Quantity <= item.RefundedQty {
+			continue
+		}
+		err := s.inventoryService.ChangeStock(tx, &model.InventoryMovement{
+			ProductID: item.ProductID,
+			Type:      movementType,
+			Quantity:  item.Quantity - item.RefundedQty,
+			ActorID:   actorID,
+			RefType:   model.InventoryRefOrder,
+			RefID:     orderID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidateOrderCache 删除订单缓存
+func (s *OrderService) invalidateOrderCache(order *model.Order) {
+	ctx := context.Background()
+	s.cacheService.Delete(ctx, fmt.Sprintf(constant.OrderPrefix+":%d", order.ID))
+	s.cacheService.Delete(ctx, fmt.Sprintf(constant.OrderNo+":%s", order.OrderNo))
+}
+
 // GetOrderByID gets an order by ID
 func (s *OrderService) GetOrderByID(id uint64, userID uint64) (*model.Order, error) {
 	ctx := context.Background()
@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	categoryutils "github.com/colinjuang/shop-go/internal/utils/category"
+	"gorm.io/gorm"
+)
+
+// PointsService handles business logic for loyalty points
+type PointsService struct {
+	db         *gorm.DB
+	pointsRepo *repository.PointsRepository
+	config     config.PointsConfig
+}
+
+// NewPointsService creates a new points service
+func NewPointsService() *PointsService {
+	server := server.GetServer()
+	return &PointsService{
+		db:         server.DB,
+		pointsRepo: repository.NewPointsRepository(server.DB),
+		config:     server.GetConfig().Points,
+	}
+}
+
+// GetBalance gets the points balance of a user
+func (s *PointsService) GetBalance(userID uint64) (*response.PointsBalanceResponse, error) {
+	user, err := repository.NewUserRepository(s.db).GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiring, err := s.pointsRepo.SumExpiringPoints(userID, time.Now().AddDate(0, 0, 30))
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.PointsBalanceResponse{
+		Points:         user.Points,
+		ExpiringPoints: expiring,
+		RedeemRate:     s.config.RedeemRate,
+		MaxRedeemRatio: s.config.MaxRedeemRatio,
+	}, nil
+}
+
+// GetHistory gets the points history of a user with pagination
+func (s *PointsService) GetHistory(userID uint64, page, pageSize int) (*response.Pagination, error) {
+	ledgers, total, err := s.pointsRepo.GetLedgersByUserID(userID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerResponses := make([]response.PointsLedgerResponse, len(ledgers))
+	for i, ledger := range ledgers {
+		ledgerResponses[i] = response.PointsLedgerResponse{
+			ID:        ledger.ID,
+			Type:      ledger.Type,
+			TypeDesc:  model.PointsTypeDesc[ledger.Type],
+			Change:    ledger.Change,
+			Balance:   ledger.Balance,
+			OrderID:   ledger.OrderID,
+			ExpireAt:  ledger.ExpireAt,
+			Remark:    ledger.Remark,
+			CreatedAt: ledger.CreatedAt,
+		}
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, ledgerResponses)
+	return &pagination, nil
+}
+
+// MaxRedeemable returns how many points can be used on an order of the given amount
+func (s *PointsService) MaxRedeemable(payable float64) int {
+	if s.config.RedeemRate <= 0 || s.config.MaxRedeemRatio <= 0 {
+		return 0
+	}
+
+	limit := int(math.Floor(payable * s.config.MaxRedeemRatio * float64(s.config.RedeemRate)))
+	if s.config.MaxRedeemPoints > 0 && limit > s.config.MaxRedeemPoints {
+		limit = s.config.MaxRedeemPoints
+	}
+	return limit
+}
+
+// RedeemForOrder deducts points from the buyer and applies the discount to the order.
+// It must be called inside the order creation transaction after the order has been saved.
+func (s *PointsService) RedeemForOrder(tx *gorm.DB, order *model.Order, points int) error {
+	if points <= 0 {
+		return nil
+	}
+	// 运费不参与积分抵扣
+	if points > s.MaxRedeemable(order.PaymentAmount-order.ShippingFee) {
+		return pkgerrors.ErrPointsExceedLimit
+	}
+
+	discount := math.Floor(float64(points)*100/float64(s.config.RedeemRate)) / 100
+	ledger := &model.PointsLedger{
+		UserID:  order.UserID,
+		Type:    model.PointsTypeRedeem,
+		Change:  -points,
+		OrderID: order.ID,
+		Remark:  order.OrderNo,
+	}
+	if err := s.apply(tx, ledger, points, false); err != nil {
+		return err
+	}
+
+	order.PointsUsed = points
+	order.PointsDiscount = discount
+	order.PaymentAmount = math.Round((order.PaymentAmount-discount)*100) / 100
+
+	return repository.NewOrderRepository(tx).UpdateOrder(order.ID, map[string]interface{}{
+		"points_used":     order.PointsUsed,
+		"points_discount": order.PointsDiscount,
+		"payment_amount":  order.PaymentAmount,
+	})
+}
+
+// ReturnRedeemedForOrder gives back the points used on a cancelled or refunded order
+func (s *PointsService) ReturnRedeemedForOrder(tx *gorm.DB, order *model.Order) error {
+	if order.PointsUsed <= 0 {
+		return nil
+	}
+
+	pointsRepo := repository.NewPointsRepository(tx)
+	returned, err := pointsRepo.ExistsLedger(order.UserID, model.PointsTypeRedeemReturn, order.ID)
+	if err != nil || returned {
+		return err
+	}
+
+	return s.apply(tx, &model.PointsLedger{
+		UserID:  order.UserID,
+		Type:    model.PointsTypeRedeemReturn,
+		Change:  order.PointsUsed,
+		OrderID: order.ID,
+		Remark:  order.OrderNo,
+	}, 0, false)
+}
+
+// EarnForOrder grants points for a completed order, plus the first-order bonus when applicable
+func (s *PointsService) EarnForOrder(tx *gorm.DB, order *model.Order, items []model.OrderItem) error {
+	pointsRepo := repository.NewPointsRepository(tx)
+
+	// 先锁定用户，同一用户的订单同时完成时依次检查是否已发放，首单奖励只发一次
+	if _, err := repository.NewUserRepository(tx).GetUserByIDForUpdate(order.UserID); err != nil {
+		return err
+	}
+
+	earned, err := pointsRepo.ExistsLedger(order.UserID, model.PointsTypeOrder, order.ID)
+	if err != nil || earned {
+		return err
+	}
+
+	points, err := s.calculateOrderPoints(tx, order, items)
+	if err != nil {
+		return err
+	}
+
+	if points > 0 {
+		err = s.apply(tx, &model.PointsLedger{
+			UserID:  order.UserID,
+			Type:    model.PointsTypeOrder,
+			Change:  points,
+			OrderID: order.ID,
+			Remark:  order.OrderNo,
+		}, 0, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 首单奖励，每个用户只发放一次
+	if s.config.FirstOrderBonus <= 0 {
+		return nil
+	}
+	var bonusCount int64
+	if err := tx.Model(&model.PointsLedger{}).
+		Where("user_id = ? AND type = ?", order.UserID, model.PointsTypeFirstOrder).
+		Count(&bonusCount).Error; err != nil {
+		return err
+	}
+	if bonusCount > 0 {
+		return nil
+	}
+
+	return s.apply(tx, &model.PointsLedger{
+		UserID:  order.UserID,
+		Type:    model.PointsTypeFirstOrder,
+		Change:  s.config.FirstOrderBonus,
+		OrderID: order.ID,
+		Remark:  order.OrderNo,
+	}, 0, false)
+}
+
+// ClawbackForOrder takes back the points earned from a refunded order.
+// The balance may become negative if the points have already been spent.
+func (s *PointsService) ClawbackForOrder(tx *gorm.DB, order *model.Order) error {
+	pointsRepo := repository.NewPointsRepository(tx)
+
+	clawed, err := pointsRepo.ExistsLedger(order.UserID, model.PointsTypeRefund, order.ID)
+	if err != nil || clawed {
+		return err
+	}
+
+	earnedLedgers, err := pointsRepo.GetLedgersByOrderID(order.ID, model.PointsTypeOrder, model.PointsTypeFirstOrder)
+	if err != nil {
+		return err
+	}
+
+	total := 0   // 需要扣回的积分
+	unspent := 0 // 该订单发放且尚未使用的积分
+	for _, ledger := range earnedLedgers {
+		total += ledger.Change
+		if ledger.Remaining > 0 {
+			if err := pointsRepo.UpdateLedgerRemaining(ledger.ID, 0); err != nil {
+				return err
+			}
+			unspent += ledger.Remaining
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	// 已被使用的部分从其他可用积分中扣除
+	return s.apply(tx, &model.PointsLedger{
+		UserID:  order.UserID,
+		Type:    model.PointsTypeRefund,
+		Change:  -total,
+		OrderID: order.ID,
+		Remark:  order.OrderNo,
+	}, total-unspent, true)
+}
+
+// Adjust changes a user's points balance manually
+func (s *PointsService) Adjust(operatorID uint64, req request.AdjustPointsRequest) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		consume := 0
+		if req.Change < 0 {
+			consume = -req.Change
+		}
+		return s.apply(tx, &model.PointsLedger{
+			UserID:     req.UserID,
+			Type:       model.PointsTypeAdjust,
+			Change:     req.Change,
+			OperatorID: operatorID,
+			Remark:     req.Remark,
+		}, consume, false)
+	})
+}
+
+// ExpirePoints writes off points whose validity has passed
+func (s *PointsService) ExpirePoints(ctx context.Context) error {
+	now := time.Now()
+	for {
+		userIDs, err := s.pointsRepo.GetExpiredUserIDs(now, 100)
+		if err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		for _, userID := range userIDs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.expireUserPoints(userID, now); err != nil {
+				logger.Errorf("Failed to expire points of user %d: %v", userID, err)
+				return err
+			}
+		}
+	}
+}
+
+func (s *PointsService) expireUserPoints(userID uint64, now time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		pointsRepo := repository.NewPointsRepository(tx)
+		ledgers, err := pointsRepo.GetExpiredLedgers(userID, now)
+		if err != nil {
+			return err
+		}
+
+		expired := 0
+		for _, ledger := range ledgers {
+			expired += ledger.Remaining
+			if err := pointsRepo.UpdateLedgerRemaining(ledger.ID, 0); err != nil {
+				return err
+			}
+		}
+		if expired == 0 {
+			return nil
+		}
+
+		return s.apply(tx, &model.PointsLedger{
+			UserID: userID,
+			Type:   model.PointsTypeExpire,
+			Change: -expired,
+		}, 0, true)
+	})
+}
+
+// GetRules gets the earn rate of all categories
+func (s *PointsService) GetRules() ([]model.PointsRule, error) {
+	return s.pointsRepo.GetRules()
+}
+
+// SaveRule creates or updates the earn rate of a category
+func (s *PointsService) SaveRule(req request.PointsRuleRequest) error {
+	return s.pointsRepo.SaveRule(&model.PointsRule{
+		CategoryID: req.CategoryID,
+		EarnRate:   req.EarnRate,
+	})
+}
+
+// DeleteRule removes the earn rate of a category so the default rate applies
+func (s *PointsService) DeleteRule(categoryID uint64) error {
+	return s.pointsRepo.DeleteRule(categoryID)
+}
+
+// calculateOrderPoints 按分类积分比例计算订单可获得的积分，以实际支付金额为准
+func (s *PointsService) calculateOrderPoints(tx *gorm.DB, order *model.Order, items []model.OrderItem) (int, error) {
+	// 积分抵扣的部分及运费不获得积分，按商品实付占成交金额的比例折算，促销优惠已从各行成交金额中扣除
+	var itemsAmount float64
+	for _, item := range items {
+		itemsAmount += item.Price*float64(item.Quantity) - item.PromotionDiscount
+	}
+	paid := order.PaymentAmount - order.ShippingFee
+	if itemsAmount <= 0 || paid <= 0 {
+		return 0, nil
+	}
+	payRatio := paid / itemsAmount
+
+	productIDs := make([]uint64, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	products, err := repository.NewProductRepository(tx).GetProductsByIDs(productIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	categoryIDs := make([]uint64, 0, len(products)*2)
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+		categoryIDs = append(categoryIDs, categoryutils.PathIDs(product.CategoryPath)...)
+	}
+
+	rules, err := repository.NewPointsRepository(tx).GetRulesByCategoryIDs(categoryIDs)
+	if err != nil {
+		return 0, err
+	}
+	rateMap := make(map[uint64]float64, len(rules))
+	for _, rule := range rules {
+		rateMap[rule.CategoryID] = rule.EarnRate
+	}
+
+	points := 0.0
+	for _, item := range items {
+		rate := s.config.DefaultEarnRate
+		if product, ok := productMap[item.ProductID]; ok {
+			// 越接近商品所属分类的规则越优先
+			if categoryID, ok := closestCategory(product.CategoryPath, rateMap); ok {
+				rate = rateMap[categoryID]
+			}
+		}
+		points += (item.Price*float64(item.Quantity) - item.PromotionDiscount) * payRatio * rate
+	}
+
+	return int(math.Floor(points)), nil
+}
+
+// apply 写入积分流水并更新用户余额。
+// consume 为需要按过期时间先后从可用积分中扣除的数量；allowNegative 为 false 时余额不足返回错误。
+func (s *PointsService) apply(tx *gorm.DB, ledger *model.PointsLedger, consume int, allowNegative bool) error {
+	userRepo := repository.NewUserRepository(tx)
+	pointsRepo := repository.NewPointsRepository(tx)
+
+	user, err := userRepo.GetUserByIDForUpdate(ledger.UserID)
+	if err != nil {
+		return err
+	}
+
+	balance := user.Points + ledger.Change
+	if balance < 0 && !allowNegative {
+		return pkgerrors.ErrInsufficientPoints
+	}
+
+	if ledger.Change > 0 {
+		ledger.Remaining = ledger.Change
+		if ledger.ExpireAt == nil && s.config.ExpireMonths > 0 {
+			expireAt := time.Now().AddDate(0, s.config.ExpireMonths, 0)
+			ledger.ExpireAt = &expireAt
+		}
+	}
+
+	if consume > 0 {
+		available, err := pointsRepo.GetAvailableLedgers(ledger.UserID)
+		if err != nil {
+			return err
+		}
+		for _, entry := range available {
+			if consume == 0 {
+				break
+			}
+			used := entry.Remaining
+			if used > consume {
+				used = consume
+			}
+			if err := pointsRepo.UpdateLedgerRemaining(entry.ID, entry.Remaining-used); err != nil {
+				return err
+			}
+			consume -= used
+		}
+	}
+
+	ledger.Balance = balance
+	if err := pointsRepo.CreateLedger(ledger); err != nil {
+		return err
+	}
+
+	return userRepo.UpdateUserPoints(ledger.UserID, balance)
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/notify"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	"gorm.io/gorm"
+)
+
+// PreSaleService handles pre-orders paid as a deposit and a balance. Each pre-order has one payment
+// record per stage; the order stays in the deposit paid status, excluded from fulfilment, until the
+// balance is paid. Unpaid balances are closed after the window with the deposit forfeited or refunded.
+type PreSaleService struct {
+	preSaleRepo  *repository.PreSaleRepository
+	productRepo  *repository.ProductRepository
+	orderRepo    *repository.OrderRepository
+	paymentRepo  *repository.OrderPaymentRepository
+	orderService *OrderService
+	config       config.PreSaleConfig
+}
+
+// NewPreSaleService creates a new pre-sale service
+func NewPreSaleService() *PreSaleService {
+	server := server.GetServer()
+	return &PreSaleService{
+		preSaleRepo:  repository.NewPreSaleRepository(server.DB),
+		productRepo:  repository.NewProductRepository(server.DB),
+		orderRepo:    repository.NewOrderRepository(server.DB),
+		paymentRepo:  repository.NewOrderPaymentRepository(server.DB),
+		orderService: NewOrderService(),
+		config:       server.GetConfig().PreSale,
+	}
+}
+
+// GetActivePreSales gets pre-sales whose deposit stage is ongoing or upcoming
+func (s *PreSaleService) GetActivePreSales() ([]*response.PreSaleResponse, error) {
+	preSales, err := s.preSaleRepo.GetActivePreSales(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.toPreSaleResponses(preSales)
+}
+
+// GetPreSale gets a pre-sale by ID
+func (s *PreSaleService) GetPreSale(id uint64) (*response.PreSaleResponse, error) {
+	preSale, err := s.preSaleRepo.GetPreSaleByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrPreSaleNotFound
+	}
+
+	responses, err := s.toPreSaleResponses([]model.PreSale{*preSale})
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+// GetPreSales gets all pre-sales with pagination (admin)
+func (s *PreSaleService) GetPreSales(page, pageSize int) (*response.Pagination, error) {
+	preSales, total, err := s.preSaleRepo.GetPreSales(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.toPreSaleResponses(preSales)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, responses)
+	return &pagination, nil
+}
+
+// CreatePreSale creates a pre-sale (admin)
+func (s *PreSaleService) CreatePreSale(req request.PreSaleRequest) (*model.PreSale, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	preSale := &model.PreSale{Status: model.PreSaleStatusEnabled}
+	applyPreSaleRequest(preSale, req)
+	if err := s.preSaleRepo.CreatePreSale(preSale); err != nil {
+		return nil, err
+	}
+	return preSale, nil
+}
+
+// UpdatePreSale updates a pre-sale before its deposit stage starts (admin)
+func (s *PreSaleService) UpdatePreSale(id uint64, req request.PreSaleRequest) (*model.PreSale, error) {
+	preSale, err := s.preSaleRepo.GetPreSaleByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrPreSaleNotFound
+	}
+	if !time.Now().Before(preSale.DepositStart) {
+		return nil, pkgerrors.ErrPreSaleStarted
+	}
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	applyPreSaleRequest(preSale, req)
+	if err := s.preSaleRepo.UpdatePreSale(preSale); err != nil {
+		return nil, err
+	}
+	return preSale, nil
+}
+
+// SetPreSaleStatus enables or disables a pre-sale (admin). Placed orders are not affected.
+func (s *PreSaleService) SetPreSaleStatus(id uint64, status int) error {
+	if _, err := s.preSaleRepo.GetPreSaleByID(id); err != nil {
+		return pkgerrors.ErrPreSaleNotFound
+	}
+	return s.preSaleRepo.UpdatePreSaleStatus(id, status)
+}
+
+// PlaceOrder creates a pre-order with a deposit and a balance payment record.
+// Stock is taken when the order is placed; shipping is included in the balance.
+func (s *PreSaleService) PlaceOrder(userID, preSaleID uint64, req request.PreSaleOrderRequest) (*response.PreSaleOrderResponse, error) {
+	preSale, err := s.preSaleRepo.GetPreSaleByID(preSaleID)
+	if err != nil {
+		return nil, pkgerrors.ErrPreSaleNotFound
+	}
+
+	now := time.Now()
+	if preSale.Status != model.PreSaleStatusEnabled || !now.Before(preSale.DepositEnd) {
+		return nil, pkgerrors.ErrPreSaleEnded
+	}
+	if now.Before(preSale.DepositStart) {
+		return nil, pkgerrors.ErrPreSaleNotStarted
+	}
+
+	params := DealOrderParams{
+		ProductID: preSale.ProductID,
+		DealPrice: preSale.PreSalePrice,
+		AddressID: req.AddressID,
+		Quantity:  req.Quantity,
+		Blessing:  req.Blessing,
+		Remark:    req.Remark,
+		PreSaleID: preSale.ID,
+	}
+
+	var payments []model.OrderPayment
+	order, err := s.orderService.CreateDealOrder(userID, params, func(tx *gorm.DB, order *model.Order) error {
+		deposit := priceutils.Round(preSale.Deposit * float64(req.Quantity))
+		payments = []model.OrderPayment{
+			{
+				OrderID:   order.ID,
+				PaymentNo: stagePaymentNo(order.OrderNo, model.PaymentStageDeposit),
+				Stage:     model.PaymentStageDeposit,
+				Amount:    deposit,
+				Status:    model.OrderPaymentStatusPending,
+				DueStart:  now,
+				DueEnd:    preSale.DepositEnd,
+			},
+			{
+				OrderID:   order.ID,
+				PaymentNo: stagePaymentNo(order.OrderNo, model.PaymentStageBalance),
+				Stage:     model.PaymentStageBalance,
+				Amount:    priceutils.Round(order.PaymentAmount - deposit),
+				Status:    model.OrderPaymentStatusPending,
+				DueStart:  preSale.BalanceStart,
+				DueEnd:    preSale.BalanceEnd,
+			},
+		}
+		return repository.NewOrderPaymentRepository(tx).CreateOrderPayments(payments)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.PreSaleOrderResponse{
+		OrderID:      order.ID,
+		OrderNo:      order.OrderNo,
+		Deposit:      payments[0].Amount,
+		Balance:      payments[1].Amount,
+		BalanceStart: preSale.BalanceStart,
+		BalanceEnd:   preSale.BalanceEnd,
+	}, nil
+}
+
+// RemindBalance notifies buyers whose balance window closes within the configured hours. Each balance is reminded once.
+func (s *PreSaleService) RemindBalance(ctx context.Context) error {
+	if s.config.RemindBefore <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	payments, err := s.paymentRepo.GetPaymentsToRemind(now, now.Add(time.Duration(s.config.RemindBefore)*time.Hour), 500)
+	if err != nil {
+		return err
+	}
+
+	for _, due := range payments {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		order, err := s.orderRepo.GetOrderByID(due.OrderID)
+		if err != nil {
+			logger.Warnf("Failed to load pre-order %d: %v", due.OrderID, err)
+			continue
+		}
+
+		notify.Send(ctx, notify.Message{
+			UserID:  order.UserID,
+			Type:    notify.TypeBalanceDue,
+			Title:   "预售尾款待支付",
+			Content: fmt.Sprintf("您的预售订单尾款 %.2f 元将于 %s 截止支付，逾期定金将按活动规则处理", due.Amount, due.DueEnd.Format("01-02 15:04")),
+			Data:    map[string]string{"orderID": strconv.FormatUint(order.ID, 10)},
+		})
+		if err := s.paymentRepo.MarkReminded(due.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloseOverdueOrders cancels pre-orders whose deposit was not paid in time, and closes pre-orders
+// whose balance was not paid in time, forfeiting or refunding the deposit by policy
+func (s *PreSaleService) CloseOverdueOrders(ctx context.Context) error {
+	now := time.Now()
+
+	deposits, err := s.paymentRepo.GetOverduePayments(model.PaymentStageDeposit, model.OrderStatusPending, now, 500)
+	if err != nil {
+		return err
+	}
+	for _, due := range deposits {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		order, err := s.orderRepo.GetOrderByID(due.OrderID)
+		if err != nil {
+			logger.Warnf("Failed to load pre-order %d: %v", due.OrderID, err)
+			continue
+		}
+		err = s.orderService.CancelOrder(order.UserID, order.ID)
+		if err != nil && err != pkgerrors.ErrOrderStatusInvalid {
+			logger.Warnf("Failed to cancel pre-order %d with unpaid deposit: %v", order.ID, err)
+		}
+	}
+
+	balances, err := s.paymentRepo.GetOverduePayments(model.PaymentStageBalance, model.OrderStatusDepositPaid, now, 500)
+	if err != nil {
+		return err
+	}
+	for _, due := range balances {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.closeUnpaidBalance(ctx, due); err != nil {
+			logger.Warnf("Failed to close pre-order %d with unpaid balance: %v", due.OrderID, err)
+		}
+	}
+	return nil
+}
+
+// closeUnpaidBalance 关闭尾款逾期的预售订单并通知买家
+func (s *PreSaleService) closeUnpaidBalance(ctx context.Context, due model.OrderPayment) error {
+	order, err := s.orderRepo.GetOrderByID(due.OrderID)
+	if err != nil {
+		return err
+	}
+
+	policy := s.config.ForfeitPolicy
+	if preSale, err := s.preSaleRepo.GetPreSaleByID(order.PreSaleID); err == nil && preSale.ForfeitPolicy != "" {
+		policy = preSale.ForfeitPolicy
+	}
+	refund := policy == model.PreSaleForfeitPolicyRefund
+
+	if err := s.orderService.CloseUnpaidBalance(order.ID, refund); err != nil {
+		if err == pkgerrors.ErrOrderStatusInvalid {
+			return nil
+		}
+		return err
+	}
+
+	content := "您的预售订单尾款逾期未支付，订单已关闭，定金不予退还"
+	if refund {
+		content = "您的预售订单尾款逾期未支付，订单已关闭，定金将原路退回"
+	}
+	notify.Send(ctx, notify.Message{
+		UserID:  order.UserID,
+		Type:    notify.TypePreOrderClosed,
+		Title:   "预售订单已关闭",
+		Content: content,
+		Data:    map[string]string{"orderID": strconv.FormatUint(order.ID, 10)},
+	})
+	return nil
+}
+
+// validate 校验预售商品及定金、尾款时间窗口
+func (s *PreSaleService) validate(req request.PreSaleRequest) error {
+	if _, err := s.productRepo.GetProductByID(req.ProductID); err != nil {
+		return pkgerrors.ErrProductNotFound
+	}
+	// 尾款阶段需在定金阶段结束后开始
+	if req.BalanceStart.Before(req.DepositEnd) {
+		return pkgerrors.ErrPreSaleInvalidSchedule
+	}
+	return nil
+}
+
+// toPreSaleResponses 转换预售活动响应
+func (s *PreSaleService) toPreSaleResponses(preSales []model.PreSale) ([]*response.PreSaleResponse, error) {
+	productIDs := make([]uint64, len(preSales))
+	for i, preSale := range preSales {
+		productIDs[i] = preSale.ProductID
+	}
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	responses := make([]*response.PreSaleResponse, len(preSales))
+	for i, preSale := range preSales {
+		product := productMap[preSale.ProductID]
+		responses[i] = &response.PreSaleResponse{
+			ID:           preSale.ID,
+			ProductID:    preSale.ProductID,
+			ProductName:  product.Name,
+			ImageUrl:     product.ImageUrl,
+			Title:        preSale.Title,
+			Price:        product.Price,
+			PreSalePrice: preSale.PreSalePrice,
+			Deposit:      preSale.Deposit,
+			Balance:      priceutils.Round(preSale.PreSalePrice - preSale.Deposit),
+			DepositStart: preSale.DepositStart,
+			DepositEnd:   preSale.DepositEnd,
+			BalanceStart: preSale.BalanceStart,
+			BalanceEnd:   preSale.BalanceEnd,
+			ShipAt:       preSale.ShipAt,
+			Status:       preSale.Status,
+		}
+	}
+	return responses, nil
+}
+
+// applyPreSaleRequest 将请求写入预售活动
+func applyPreSaleRequest(preSale *model.PreSale, req request.PreSaleRequest) {
+	preSale.ProductID = req.ProductID
+	preSale.Title = req.Title
+	preSale.PreSalePrice = req.PreSalePrice
+	preSale.Deposit = req.Deposit
+	preSale.DepositStart = req.DepositStart
+	preSale.DepositEnd = req.DepositEnd
+	preSale.BalanceStart = req.BalanceStart
+	preSale.BalanceEnd = req.BalanceEnd
+	preSale.ShipAt = req.ShipAt
+	preSale.ForfeitPolicy = req.ForfeitPolicy
+}
+
+// stagePaymentNo 分阶段支付单号：订单号 + 两位阶段号
+func stagePaymentNo(orderNo string, stage int) string {
+	return fmt.Sprintf("%s%02d", orderNo, stage)
+}
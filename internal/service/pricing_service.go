@@ -0,0 +1,145 @@
+package service
+
+import (
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	"github.com/colinjuang/shop-go/internal/server"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+)
+
+// PricingLine is a product and quantity to be priced
+type PricingLine struct {
+	Product  model.Product
+	Quantity int
+	// DealPrice 活动成交价，如秒杀价；大于0时不再叠加会员价
+	DealPrice float64
+}
+
+// PricedLine is a priced line of an order
+type PricedLine struct {
+	Product           model.Product
+	Quantity          int
+	OriginalPrice     float64            // 商品原价
+	UnitPrice         float64            // 成交单价
+	PromotionDiscount float64            // 促销优惠合计
+	Promotions        []AppliedPromotion // 享受的促销活动
+	Amount            float64            // 成交小计，已扣除促销优惠
+}
+
+// PricingResult is the result of pricing an order
+type PricingResult struct {
+	Lines             []PricedLine
+	Tier              *model.MemberTier
+	TotalAmount       float64 // 商品原价合计
+	MemberDiscount    float64 // 会员优惠
+	PromotionDiscount float64 // 促销优惠
+	ShippingFee       float64 // 运费
+	PaymentAmount     float64 // 应付金额，含运费
+	CouponStackable   bool    // 享受的促销活动都允许叠加优惠券，没有享受促销时也为true
+}
+
+// PricingService calculates order prices, shared by all checkout paths
+type PricingService struct {
+	memberService    *MemberService
+	promotionService *PromotionService
+	config           config.ShippingConfig
+}
+
+// NewPricingService creates a new pricing service
+func NewPricingService() *PricingService {
+	server := server.GetServer()
+	return &PricingService{
+		memberService:    NewMemberService(),
+		promotionService: NewPromotionService(),
+		config:           server.GetConfig().Shipping,
+	}
+}
+
+// Calculate prices the lines for a user, applying member prices, promotions and the shipping fee.
+// Promotions are evaluated on member prices; lines at a deal price take part in neither.
+func (s *PricingService) Calculate(userID uint64, lines []PricingLine) (*PricingResult, error) {
+	tier, err := s.memberService.GetUserTier(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]model.Product, len(lines))
+	for i, line := range lines {
+		products[i] = line.Product
+	}
+	unitPrices, err := s.memberService.UnitPrices(products, tier)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PricingResult{
+		Lines:           make([]PricedLine, len(lines)),
+		Tier:            tier,
+		CouponStackable: true,
+	}
+
+	var memberDiscount float64
+	var promotionLines []PromotionLine
+	var promotionIndexes []int
+	for i, line := range lines {
+		unitPrice := unitPrices[line.Product.ID]
+		if line.DealPrice > 0 {
+			unitPrice = line.DealPrice
+		} else {
+			memberDiscount += line.Product.Price*float64(line.Quantity) - unitPrice*float64(line.Quantity)
+			promotionLines = append(promotionLines, PromotionLine{Product: line.Product, UnitPrice: unitPrice, Quantity: line.Quantity})
+			promotionIndexes = append(promotionIndexes, i)
+		}
+		result.Lines[i] = PricedLine{
+			Product:       line.Product,
+			Quantity:      line.Quantity,
+			OriginalPrice: line.Product.Price,
+			UnitPrice:     unitPrice,
+			Amount:        priceutils.Round(unitPrice * float64(line.Quantity)),
+		}
+		result.TotalAmount += line.Product.Price * float64(line.Quantity)
+	}
+
+	if len(promotionLines) > 0 {
+		applied, err := s.promotionService.Evaluate(promotionLines)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range promotionIndexes {
+			line := &result.Lines[i]
+			for _, promotion := range applied[j] {
+				line.PromotionDiscount += promotion.Discount
+				result.CouponStackable = result.CouponStackable && promotion.CouponStackable
+			}
+			line.PromotionDiscount = priceutils.Round(line.PromotionDiscount)
+			line.Promotions = applied[j]
+			line.Amount = priceutils.Round(line.Amount - line.PromotionDiscount)
+			result.PromotionDiscount += line.PromotionDiscount
+		}
+	}
+
+	var subtotal float64
+	for _, line := range result.Lines {
+		subtotal += line.Amount
+	}
+
+	result.TotalAmount = priceutils.Round(result.TotalAmount)
+	result.MemberDiscount = priceutils.Round(memberDiscount)
+	result.PromotionDiscount = priceutils.Round(result.PromotionDiscount)
+	// 满额包邮按扣除促销优惠后的金额判断
+	result.ShippingFee = s.shippingFee(subtotal, tier)
+	result.PaymentAmount = priceutils.Round(subtotal + result.ShippingFee)
+
+	return result, nil
+}
+
+// shippingFee 计算运费，会员等级包邮或满额包邮时免运费
+func (s *PricingService) shippingFee(subtotal float64, tier *model.MemberTier) float64 {
+	if tier != nil && tier.FreeShipping {
+		return 0
+	}
+	if s.config.FreeThreshold > 0 && subtotal >= s.config.FreeThreshold {
+		return 0
+	}
+	return s.config.Fee
+}
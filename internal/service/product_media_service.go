@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/minio"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	htmlutils "github.com/colinjuang/shop-go/internal/utils/html"
+	"gorm.io/gorm"
+)
+
+// mediaGCBatch 清理对象时每批处理的数量
+const mediaGCBatch = 100
+
+// ProductMediaService handles the image gallery and rich detail content of products,
+// and garbage-collects the uploaded objects they no longer use
+type ProductMediaService struct {
+	db            *gorm.DB
+	mediaRepo     *repository.ProductMediaRepository
+	productRepo   *repository.ProductRepository
+	uploadService *UploadService
+	cacheService  *redis.CacheService
+	config        config.MediaConfig
+}
+
+// NewProductMediaService creates a new product media service
+func NewProductMediaService() *ProductMediaService {
+	server := server.GetServer()
+	cfg := server.GetConfig().Media
+	if cfg.MaxGalleryImages <= 0 {
+		cfg.MaxGalleryImages = 9
+	}
+	if cfg.GCGraceHours <= 0 {
+		cfg.GCGraceHours = 24
+	}
+	return &ProductMediaService{
+		db:            server.DB,
+		mediaRepo:     repository.NewProductMediaRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		uploadService: NewUploadService(),
+		cacheService:  redis.NewCacheService(),
+		config:        cfg,
+	}
+}
+
+// UploadMedia uploads a gallery or detail image, or the gallery video (admin).
+// The object is collected as garbage unless a gallery or detail body references it within the grace period.
+func (s *ProductMediaService) UploadMedia(file *multipart.FileHeader) (string, string, error) {
+	objectName, err := s.uploadService.UploadMedia(file)
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.mediaRepo.MarkUnreferenced([]string{objectName}, time.Now()); err != nil {
+		return "", "", err
+	}
+	return objectName, minio.GetClient().GetFileURL(objectName), nil
+}
+
+// GetGallery gets the ordered gallery of a product
+func (s *ProductMediaService) GetGallery(productID uint64) ([]response.GalleryItemResponse, error) {
+	items, err := s.mediaRepo.GetGalleryItems(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	minioClient := minio.GetClient()
+	responses := make([]response.GalleryItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = response.GalleryItemResponse{
+			ID:        item.ID,
+			Type:      item.Type,
+			Url:       minioClient.GetFileURL(item.ObjectName),
+			SortOrder: item.SortOrder,
+		}
+	}
+	return responses, nil
+}
+
+// SetGallery replaces the gallery of a product in the given order (admin). The first image becomes the main image.
+func (s *ProductMediaService) SetGallery(productID uint64, req request.SetGalleryRequest) error {
+	if _, err := s.productRepo.GetProductByID(productID); err != nil {
+		return pkgerrors.ErrProductNotFound
+	}
+
+	var images, videos int
+	items := make([]model.ProductGalleryItem, len(req.Items))
+	for i, reqItem := range req.Items {
+		objectName, ok := toObjectName(reqItem.Url)
+		if !ok {
+			return pkgerrors.ErrInvalidMedia
+		}
+		if reqItem.Type == model.MediaTypeVideo {
+			videos++
+		} else {
+			images++
+		}
+		items[i] = model.ProductGalleryItem{
+			ProductID:  productID,
+			Type:       reqItem.Type,
+			ObjectName: objectName,
+			SortOrder:  i,
+		}
+	}
+	if images > s.config.MaxGalleryImages || videos > 1 {
+		return pkgerrors.ErrInvalidMedia
+	}
+
+	oldItems, err := s.mediaRepo.GetGalleryItems(productID)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		mediaRepo := repository.NewProductMediaRepository(tx)
+		if err := mediaRepo.ReplaceGalleryItems(productID, items); err != nil {
+			return err
+		}
+		if err := s.updateMainImage(mediaRepo, productID, items); err != nil {
+			return err
+		}
+		return trackObjectReferences(mediaRepo, galleryObjectNames(oldItems), galleryObjectNames(items))
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateProductCache(context.Background(), s.cacheService, productID)
+	return nil
+}
+
+// SortGallery reorders the gallery of a product (admin). ids must list every gallery item once.
+func (s *ProductMediaService) SortGallery(productID uint64, req request.SortGalleryRequest) error {
+	items, err := s.mediaRepo.GetGalleryItems(productID)
+	if err != nil {
+		return err
+	}
+	if len(req.IDs) != len(items) {
+		return pkgerrors.ErrInvalidMedia
+	}
+
+	itemMap := make(map[uint64]model.ProductGalleryItem, len(items))
+	for _, item := range items {
+		itemMap[item.ID] = item
+	}
+	sorted := make([]model.ProductGalleryItem, 0, len(items))
+	for _, id := range req.IDs {
+		item, ok := itemMap[id]
+		if !ok {
+			return pkgerrors.ErrInvalidMedia
+		}
+		delete(itemMap, id)
+		sorted = append(sorted, item)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		mediaRepo := repository.NewProductMediaRepository(tx)
+		for i, item := range sorted {
+			if err := mediaRepo.UpdateGallerySortOrder(item.ID, i); err != nil {
+				return err
+			}
+		}
+		return s.updateMainImage(mediaRepo, productID, sorted)
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateProductCache(context.Background(), s.cacheService, productID)
+	return nil
+}
+
+// GetDetail gets the rich detail body of a product
+func (s *ProductMediaService) GetDetail(productID uint64) ([]response.DetailBlockResponse, error) {
+	blocks, err := s.mediaRepo.GetDetailBlocks(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	minioClient := minio.GetClient()
+	responses := make([]response.DetailBlockResponse, len(blocks))
+	for i, block := range blocks {
+		responses[i] = response.DetailBlockResponse{
+			Type:    block.Type,
+			Content: block.Content,
+		}
+		if block.Type == model.MediaTypeImage {
+			responses[i].Url = minioClient.GetFileURL(block.ObjectName)
+		}
+	}
+	return responses, nil
+}
+
+// SetDetail replaces the rich detail body of a product in the given order (admin).
+// Text blocks are sanitized to a small set of formatting tags.
+func (s *ProductMediaService) SetDetail(productID uint64, req request.SetDetailRequest) error {
+	if _, err := s.productRepo.GetProductByID(productID); err != nil {
+		return pkgerrors.ErrProductNotFound
+	}
+
+	blocks := make([]model.ProductDetailBlock, len(req.Blocks))
+	for i, reqBlock := range req.Blocks {
+		block := model.ProductDetailBlock{
+			ProductID: productID,
+			Type:      reqBlock.Type,
+			SortOrder: i,
+		}
+		if reqBlock.Type == model.MediaTypeImage {
+			objectName, ok := toObjectName(reqBlock.Url)
+			if !ok {
+				return pkgerrors.ErrInvalidMedia
+			}
+			block.ObjectName = objectName
+		} else {
+			block.Content = htmlutils.Sanitize(reqBlock.Content)
+			if block.Content == "" {
+				return pkgerrors.ErrInvalidMedia
+			}
+		}
+		blocks[i] = block
+	}
+
+	oldBlocks, err := s.mediaRepo.GetDetailBlocks(productID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		mediaRepo := repository.NewProductMediaRepository(tx)
+		if err := mediaRepo.ReplaceDetailBlocks(productID, blocks); err != nil {
+			return err
+		}
+		return trackObjectReferences(mediaRepo, detailObjectNames(oldBlocks), detailObjectNames(blocks))
+	})
+}
+
+// CollectGarbage deletes objects that no gallery, detail body or main image has referenced for the grace period
+func (s *ProductMediaService) CollectGarbage(ctx context.Context) error {
+	before := time.Now().Add(-time.Duration(s.config.GCGraceHours) * time.Hour)
+	minioClient := minio.GetClient()
+
+	var lastID uint64
+	var deleted int
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		objects, err := s.mediaRepo.GetUnreferencedObjects(before, lastID, mediaGCBatch)
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			break
+		}
+
+		for _, object := range objects {
+			// 删除前再次确认没有被引用，同一对象可能被多个商品使用
+			referenced, err := s.mediaRepo.IsObjectReferenced(object.ObjectName)
+			if err != nil {
+				return err
+			}
+			if referenced {
+				if err := s.mediaRepo.MarkReferenced([]string{object.ObjectName}); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// 先删除记录，期间重新被引用时记录已更新，不会删除对象
+			ok, err := s.mediaRepo.DeleteUnreferencedObject(object.ID, before)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := minioClient.DeleteFile(ctx, object.ObjectName); err != nil {
+				logger.Warnf("Failed to delete unreferenced object %s: %v", object.ObjectName, err)
+				continue
+			}
+			deleted++
+		}
+		lastID = objects[len(objects)-1].ID
+	}
+
+	if deleted > 0 {
+		logger.Infof("Deleted %d unreferenced media objects", deleted)
+	}
+	return nil
+}
+
+// updateMainImage 将相册第一张图片设为商品主图，相册没有图片时保留原主图
+func (s *ProductMediaService) updateMainImage(mediaRepo *repository.ProductMediaRepository, productID uint64, items []model.ProductGalleryItem) error {
+	for _, item := range items {
+		if item.Type == model.MediaTypeImage {
+			return mediaRepo.UpdateProductImage(productID, item.ObjectName)
+		}
+	}
+	return nil
+}
+
+// trackObjectReferences 登记新引用的对象，不再引用的对象记录时间，宽限期后由清理任务删除
+func trackObjectReferences(mediaRepo *repository.ProductMediaRepository, oldNames, newNames []string) error {
+	current := make(map[string]bool, len(newNames))
+	for _, name := range newNames {
+		current[name] = true
+	}
+	var removed []string
+	for _, name := range oldNames {
+		if !current[name] {
+			removed = append(removed, name)
+		}
+	}
+
+	if err := mediaRepo.MarkReferenced(newNames); err != nil {
+		return err
+	}
+	return mediaRepo.MarkUnreferenced(removed, time.Now())
+}
+
+// galleryObjectNames 相册引用的对象名
+func galleryObjectNames(items []model.ProductGalleryItem) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.ObjectName
+	}
+	return names
+}
+
+// detailObjectNames 详情图片块引用的对象名
+func detailObjectNames(blocks []model.ProductDetailBlock) []string {
+	names := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.ObjectName != "" {
+			names = append(names, block.ObjectName)
+		}
+	}
+	return names
+}
+
+// toObjectName 将上传接口返回的文件URL转为MinIO对象名，不接受其他站点的地址
+func toObjectName(url string) (string, bool) {
+	objectName := strings.TrimPrefix(url, minio.GetClient().GetFileURL(""))
+	if objectName == "" || strings.Contains(objectName, "://") || strings.HasPrefix(objectName, "/") {
+		return "", false
+	}
+	return objectName, true
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	"gorm.io/gorm"
+)
+
+// productScheduleBatch 每轮执行的到期计划数量
+const productScheduleBatch = 500
+
+// ProductScheduleService handles scheduled publishing, unpublishing and price changes of products,
+// and the price history used to look up the price in force at a given time
+type ProductScheduleService struct {
+	db           *gorm.DB
+	scheduleRepo *repository.ProductScheduleRepository
+	productRepo  *repository.ProductRepository
+	cacheService *redis.CacheService
+}
+
+// NewProductScheduleService creates a new product schedule service
+func NewProductScheduleService() *ProductScheduleService {
+	server := server.GetServer()
+	return &ProductScheduleService{
+		db:           server.DB,
+		scheduleRepo: repository.NewProductScheduleRepository(server.DB),
+		productRepo:  repository.NewProductRepository(server.DB),
+		cacheService: redis.NewCacheService(),
+	}
+}
+
+// GetSchedules gets the schedules of a product, latest first (admin)
+func (s *ProductScheduleService) GetSchedules(productID uint64) ([]model.ProductSchedule, error) {
+	return s.scheduleRepo.GetSchedulesByProductID(productID)
+}
+
+// CreateSchedule schedules a change of a product (admin). With endAt, an on-sale schedule also takes
+// the product off sale at endAt, and a price change restores the price in force before it at endAt.
+func (s *ProductScheduleService) CreateSchedule(productID uint64, req request.ProductScheduleRequest) ([]model.ProductSchedule, error) {
+	if _, err := s.productRepo.GetProductByID(productID); err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+	if !req.RunAt.After(time.Now()) {
+		return nil, pkgerrors.ErrInvalidProductSchedule
+	}
+	if req.Action == model.ProductScheduleActionPrice && req.Price <= 0 {
+		return nil, pkgerrors.ErrInvalidProductSchedule
+	}
+	if req.EndAt != nil && (req.Action == model.ProductScheduleActionOffSale || !req.EndAt.After(req.RunAt)) {
+		return nil, pkgerrors.ErrInvalidProductSchedule
+	}
+
+	schedules := make([]model.ProductSchedule, 0, 2)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		scheduleRepo := repository.NewProductScheduleRepository(tx)
+		start := model.ProductSchedule{
+			ProductID: productID,
+			Action:    req.Action,
+			RunAt:     req.RunAt,
+			Status:    model.ProductScheduleStatusPending,
+			Remark:    req.Remark,
+		}
+		if req.Action == model.ProductScheduleActionPrice {
+			start.Price = req.Price
+		}
+		if err := scheduleRepo.CreateSchedule(&start); err != nil {
+			return err
+		}
+		schedules = append(schedules, start)
+		if req.EndAt == nil {
+			return nil
+		}
+
+		// 结束计划：上架到期下架，调价到期恢复原价
+		end := model.ProductSchedule{
+			ProductID: productID,
+			Action:    req.Action,
+			RevertOf:  start.ID,
+			RunAt:     *req.EndAt,
+			Status:    model.ProductScheduleStatusPending,
+			Remark:    req.Remark,
+		}
+		if req.Action == model.ProductScheduleActionOnSale {
+			end.Action = model.ProductScheduleActionOffSale
+		}
+		if err := scheduleRepo.CreateSchedule(&end); err != nil {
+			return err
+		}
+		schedules = append(schedules, end)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// CancelSchedule cancels a pending schedule and the pending schedule that would end it (admin)
+func (s *ProductScheduleService) CancelSchedule(id uint64) error {
+	if _, err := s.scheduleRepo.GetScheduleByID(id); err != nil {
+		return pkgerrors.ErrProductScheduleNotFound
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		scheduleRepo := repository.NewProductScheduleRepository(tx)
+		cancelled, err := scheduleRepo.UpdateScheduleStatusFrom(id, model.ProductScheduleStatusPending, model.ProductScheduleStatusCancelled, nil)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return pkgerrors.ErrProductScheduleStatusInvalid
+		}
+		return scheduleRepo.CancelRevertSchedules(id)
+	})
+}
+
+// ApplyDueSchedules applies the schedules whose time has come, in time order. Each schedule is claimed
+// with a conditional status update in the same transaction as the change, so it is applied exactly
+// once even when several replicas run the job.
+func (s *ProductScheduleService) ApplyDueSchedules(ctx context.Context) error {
+	schedules, err := s.scheduleRepo.GetDueSchedules(time.Now(), productScheduleBatch)
+	if err != nil {
+		return err
+	}
+
+	for i := range schedules {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		schedule := &schedules[i]
+		applied, err := s.applySchedule(schedule)
+		if err != nil {
+			logger.Warnf("Failed to apply schedule %d of product %d: %v", schedule.ID, schedule.ProductID, err)
+			continue
+		}
+		if applied {
+			logger.Infof("Applied schedule %d (action %d) of product %d", schedule.ID, schedule.Action, schedule.ProductID)
+			invalidateProductCache(ctx, s.cacheService, schedule.ProductID)
+		}
+	}
+	return nil
+}
+
+// GetPriceHistory gets the price changes of a product with pagination, latest first (admin)
+func (s *ProductScheduleService) GetPriceHistory(productID uint64, page, pageSize int) (*response.Pagination, error) {
+	histories, total, err := s.scheduleRepo.GetPriceHistories(productID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, histories)
+	return &pagination, nil
+}
+
+// GetPriceAt gets the price of a product in force at the given time (admin)
+func (s *ProductScheduleService) GetPriceAt(productID uint64, at time.Time) (*response.PriceAtResponse, error) {
+	product, err := s.productRepo.GetProductByID(productID)
+	if err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+
+	result := &response.PriceAtResponse{
+		ProductID: productID,
+		At:        at,
+		Price:     product.Price,
+	}
+
+	history, err := s.scheduleRepo.GetLatestPriceBefore(productID, at)
+	if err != nil {
+		return nil, err
+	}
+	if history != nil {
+		result.Price = history.Price
+		result.EffectiveAt = &history.EffectiveAt
+		result.ScheduleID = history.ScheduleID
+		return result, nil
+	}
+
+	// 早于第一条记录时为第一次变更前的价格，没有任何记录时价格未变更过
+	next, err := s.scheduleRepo.GetFirstPriceAfter(productID, at)
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		result.Price = next.PreviousPrice
+	}
+	return result, nil
+}
+
+// applySchedule 在事务中抢占并执行计划，返回是否由本次执行
+func (s *ProductScheduleService) applySchedule(schedule *model.ProductSchedule) (bool, error) {
+	applied := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		scheduleRepo := repository.NewProductScheduleRepository(tx)
+		productRepo := repository.NewProductRepository(tx)
+
+		product, err := productRepo.LockProduct(schedule.ProductID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 商品已删除，取消计划
+			_, err = scheduleRepo.UpdateScheduleStatusFrom(schedule.ID, model.ProductScheduleStatusPending, model.ProductScheduleStatusCancelled, nil)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		price := schedule.Price
+		if schedule.RevertOf > 0 {
+			start, err := scheduleRepo.GetScheduleByID(schedule.RevertOf)
+			if err != nil {
+				return err
+			}
+			switch start.Status {
+			case model.ProductScheduleStatusPending:
+				// 开始计划尚未执行成功，下一轮再处理
+				return nil
+			case model.ProductScheduleStatusCancelled:
+				_, err = scheduleRepo.UpdateScheduleStatusFrom(schedule.ID, model.ProductScheduleStatusPending, model.ProductScheduleStatusCancelled, nil)
+				return err
+			}
+			price = start.PreviousPrice
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"applied_at":     now,
+			"previous_price": product.Price,
+		}
+		if schedule.Action == model.ProductScheduleActionPrice {
+			updates["price"] = price
+		}
+		claimed, err := scheduleRepo.UpdateScheduleStatusFrom(schedule.ID, model.ProductScheduleStatusPending, model.ProductScheduleStatusApplied, updates)
+		if err != nil || !claimed {
+			return err
+		}
+
+		switch schedule.Action {
+		case model.ProductScheduleActionOnSale:
+			err = productRepo.UpdateProductStatus(product.ID, 1)
+		case model.ProductScheduleActionOffSale:
+			err = productRepo.UpdateProductStatus(product.ID, 0)
+		case model.ProductScheduleActionPrice:
+			err = productRepo.UpdateProductPrice(product.ID, price)
+			if err == nil {
+				err = scheduleRepo.CreatePriceHistory(&model.PriceHistory{
+					ProductID:     product.ID,
+					Price:         price,
+					PreviousPrice: product.Price,
+					EffectiveAt:   now,
+					ScheduleID:    schedule.ID,
+				})
+			}
+		}
+		if err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
@@ -7,141 +7,323 @@ import (
 
 	"github.com/colinjuang/shop-go/internal/app/response"
 	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/pkg/logger"
 	"github.com/colinjuang/shop-go/internal/pkg/redis"
 	"github.com/colinjuang/shop-go/internal/repository"
 	"github.com/colinjuang/shop-go/internal/server"
 )
 
+const (
+	// alsoBoughtLimit 商品详情中展示的买了又买商品数
+	alsoBoughtLimit = 6
+	// hotRankingWindow 热门商品使用的销量排行窗口
+	hotRankingWindow = "7d"
+	// hotRankingSize 商品列表按热门筛选时取排行的前多少名
+	hotRankingSize = 100
+)
+
 // ProductService handles business logic for products
 type ProductService struct {
-	productRepo  *repository.ProductRepository
-	cacheService *redis.CacheService
+	productRepo      *repository.ProductRepository
+	cacheService     *redis.CacheService
+	memberService    *MemberService
+	bundleService    *BundleService
+	limitService     *PurchaseLimitService
+	favoriteService  *FavoriteService
+	recommendService *RecommendationService
+	rankingService   *SalesRankingService
+	mediaService     *ProductMediaService
+	categoryService  *CategoryService
+	attributeService *AttributeService
 }
 
 // NewProductService creates a new product service
 func NewProductService() *ProductService {
 	server := server.GetServer()
 	return &ProductService{
-		productRepo:  repository.NewProductRepository(server.DB),
-		cacheService: redis.NewCacheService(),
+		productRepo:      repository.NewProductRepository(server.DB),
+		cacheService:     redis.NewCacheService(),
+		memberService:    NewMemberService(),
+		bundleService:    NewBundleService(),
+		limitService:     NewPurchaseLimitService(),
+		favoriteService:  NewFavoriteService(),
+		recommendService: NewRecommendationService(),
+		rankingService:   NewSalesRankingService(),
+		mediaService:     NewProductMediaService(),
+		categoryService:  NewCategoryService(),
+		attributeService: NewAttributeService(),
 	}
 }
 
-// GetProductByID gets a product by ID
-func (s *ProductService) GetProductByID(id uint64) (*response.ProductResponse, error) {
+// productDetail 商品详情中与浏览者无关的部分，整体缓存在商品详情缓存键下
+type productDetail struct {
+	Product     model.Product                       `json:"product"`
+	BundleItems []response.BundleItemResponse       `json:"bundleItems"`
+	Breadcrumbs []response.CategoryBreadcrumb       `json:"breadcrumbs"`
+	Attributes  []response.ProductAttributeResponse `json:"attributes"`
+	Gallery     []response.GalleryItemResponse      `json:"gallery"`
+	Detail      []response.DetailBlockResponse      `json:"detail"`
+}
+
+// GetProductByID gets a product by ID, with member prices for the viewer (userID 0 for guests)
+func (s *ProductService) GetProductByID(id uint64, userID uint64) (*response.ProductResponse, error) {
+	detail, err := s.getProductDetail(id)
+	if err != nil {
+		return nil, err
+	}
+	product := detail.Product
+
+	productResponses, err := s.toProductResponses([]model.Product{product}, userID)
+	if err != nil {
+		return nil, err
+	}
+	productResponses[0].BundleItems = detail.BundleItems
+	productResponses[0].Breadcrumbs = detail.Breadcrumbs
+	productResponses[0].Attributes = detail.Attributes
+	productResponses[0].Gallery = detail.Gallery
+	productResponses[0].Detail = detail.Detail
+
+	// 以下与浏览者或当前时间有关，不进缓存
+	productResponses[0].PurchaseLimits, err = s.limitService.GetActiveLimits(product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	productResponses[0].FavoriteCount, productResponses[0].Favorited, err = s.favoriteService.GetFavoriteInfo(product.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 买了又买
+	alsoBought, err := s.recommendService.AlsoBoughtProducts(product.ID, alsoBoughtLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(alsoBought) > 0 {
+		productResponses[0].AlsoBought, err = s.toProductResponses(alsoBought, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return productResponses[0], nil
+}
+
+// getProductDetail 获取商品详情中与浏览者无关的部分，优先读缓存
+func (s *ProductService) getProductDetail(id uint64) (*productDetail, error) {
 	ctx := context.Background()
-	cacheKey := fmt.Sprintf(constant.ProductPrefix+":%d", id)
+	cacheKey := productCacheKey(id)
 
-	// Try to get from cache
-	var productResponse response.ProductResponse
-	err := s.cacheService.GetObject(ctx, cacheKey, &productResponse)
-	if err == nil {
-		return &productResponse, nil
+	var detail productDetail
+	if err := s.cacheService.GetObject(ctx, cacheKey, &detail); err == nil {
+		return &detail, nil
 	}
 
-	// If not in cache, get from database
 	product, err := s.productRepo.GetProductByID(id)
 	if err != nil {
 		return nil, err
 	}
+	detail.Product = *product
 
-	productResponse = response.ProductResponse{
-		ID:             product.ID,
-		Name:           product.Name,
-		FloralLanguage: product.FloralLanguage,
-		Price:          product.Price,
-		MarketPrice:    product.MarketPrice,
-		SaleCount:      product.SaleCount,
-		StockCount:     product.StockCount,
-		CategoryID:     product.CategoryID,
-		SubCategoryID:  product.SubCategoryID,
-		Material:       product.Material,
-		Packing:        product.Packing,
-		ImageUrl:       product.ImageUrl,
-		Status:         product.Status,
-		Recommend:      product.Recommend,
-		SortOrder:      product.SortOrder,
-		ApplyUser:      product.ApplyUser,
-		CreatedAt:      product.CreatedAt,
-		UpdatedAt:      product.UpdatedAt,
+	// 组合商品返回组件明细
+	if product.IsBundle {
+		detail.BundleItems, err = s.bundleService.GetBundleItems(product.ID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Cache for 1 minute
-	err = s.cacheService.Set(ctx, cacheKey, product, 1*time.Minute)
+	// 从一级分类到所属分类的面包屑
+	detail.Breadcrumbs, err = s.categoryService.GetBreadcrumbs(product.CategoryID)
 	if err != nil {
+		return nil, err
+	}
+
+	detail.Attributes, err = s.attributeService.GetProductAttributes(product.ID, product.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 相册和图文详情
+	detail.Gallery, err = s.mediaService.GetGallery(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	detail.Detail, err = s.mediaService.GetDetail(product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache for 1 minute
+	if err := s.cacheService.Set(ctx, cacheKey, detail, 1*time.Minute); err != nil {
 		// Just log the error, don't fail the request
 		logger.Warnf("Failed to cache product: %v", err)
 	}
+	return &detail, nil
+}
+
+// productCacheKey 商品详情的缓存键
+func productCacheKey(productID uint64) string {
+	return fmt.Sprintf("%s%d", constant.ProductDetail, productID)
+}
 
-	return &productResponse, nil
+// invalidateProductCache 清除商品详情缓存，商品本身及详情中缓存的分类、属性、图文、组件变化时调用
+func invalidateProductCache(ctx context.Context, cacheService *redis.CacheService, productID uint64) {
+	cacheService.Delete(ctx, productCacheKey(productID))
 }
 
-// GetProducts gets products with pagination
-func (s *ProductService) GetProducts(page, pageSize int, categoryID *uint64, hot, recommend *bool) (*response.Pagination, error) {
+// GetProducts gets products with pagination. Hot products are the best sellers of the last 7 days, in ranking order.
+// valueIDs filters by attribute values: any of the values of one attribute, and all of the attributes.
+func (s *ProductService) GetProducts(page, pageSize int, categoryID *uint64, hot, recommend *bool, valueIDs []uint64, userID uint64) (*response.Pagination, error) {
+	valueGroups, err := s.attributeService.GroupValues(valueIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var productIDs []uint64
+	if hot != nil && *hot {
+		var scope uint64
+		if categoryID != nil {
+			scope = *categoryID
+		}
+		ranking, err := s.rankingService.GetRanking(context.Background(), hotRankingWindow, scope, hotRankingSize)
+		if err != nil {
+			return nil, err
+		}
+		productIDs = rankedProductIDs(ranking)
+	}
+
 	// If not in cache, get from database
-	products, total, err := s.productRepo.GetProducts(page, pageSize, categoryID, hot, recommend)
+	products, total, err := s.productRepo.GetProducts(page, pageSize, categoryID, productIDs, recommend, valueGroups)
 	if err != nil {
 		return nil, err
 	}
 
-	pagination := response.NewPagination(total, page, pageSize, products)
+	productResponses, err := s.toProductResponses(products, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, productResponses)
 
 	return &pagination, nil
 }
 
-// GetRecommendProducts gets recommended products
-func (s *ProductService) GetRecommendProducts(limit int) ([]*response.ProductResponse, error) {
-	// Try to get from cache
-	var productResponses []*response.ProductResponse
+// GetRecommendProducts gets recommended products, personalized for logged-in users
+func (s *ProductService) GetRecommendProducts(limit int, userID uint64) ([]*response.ProductResponse, error) {
+	products, err := s.recommendService.RecommendProducts(context.Background(), userID, limit)
+	if err != nil {
+		return nil, err
+	}
 
-	// If not in cache, get from database
-	recommend := true
-	products, _, err := s.productRepo.GetProducts(1, limit, nil, nil, &recommend)
+	return s.toProductResponses(products, userID)
+}
+
+// GetHotProducts gets the best-selling products of the last 7 days
+func (s *ProductService) GetHotProducts(limit int, userID uint64) ([]*response.ProductResponse, error) {
+	ranking, err := s.rankingService.GetRanking(context.Background(), hotRankingWindow, 0, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	productResponses = make([]*response.ProductResponse, len(products))
-	for i, product := range products {
-		productResponses[i] = &response.ProductResponse{
-			ID:             product.ID,
-			Name:           product.Name,
-			FloralLanguage: product.FloralLanguage,
-			Price:          product.Price,
-			MarketPrice:    product.MarketPrice,
-			SaleCount:      product.SaleCount,
-			StockCount:     product.StockCount,
-			CategoryID:     product.CategoryID,
-			SubCategoryID:  product.SubCategoryID,
-			Material:       product.Material,
-			Packing:        product.Packing,
-			ImageUrl:       product.ImageUrl,
-			Status:         product.Status,
-			Recommend:      product.Recommend,
-			SortOrder:      product.SortOrder,
-			ApplyUser:      product.ApplyUser,
-			CreatedAt:      product.CreatedAt,
-			UpdatedAt:      product.UpdatedAt,
+	products, _, err := s.productRepo.GetProducts(1, limit, nil, rankedProductIDs(ranking), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toProductResponses(products, userID)
+}
+
+// GetSalesRanking gets the sales ranking of the rolling window ("24h", "7d" or "30d"), overall or within a category
+func (s *ProductService) GetSalesRanking(window string, categoryID uint64, limit int, userID uint64) ([]response.SalesRankingResponse, error) {
+	ranking, err := s.rankingService.GetRanking(context.Background(), window, categoryID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.GetProductsByIDs(rankedProductIDs(ranking))
+	if err != nil {
+		return nil, err
+	}
+	productResponses, err := s.toProductResponses(products, userID)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint64]*response.ProductResponse, len(productResponses))
+	for _, product := range productResponses {
+		productMap[product.ID] = product
+	}
+
+	// 已删除的商品不展示，名次按展示顺序
+	responses := make([]response.SalesRankingResponse, 0, len(ranking))
+	for _, ranked := range ranking {
+		product, ok := productMap[ranked.ProductID]
+		if !ok {
+			continue
 		}
+		responses = append(responses, response.SalesRankingResponse{
+			Rank:    len(responses) + 1,
+			Sales:   ranked.Sales,
+			Product: product,
+		})
+	}
+	return responses, nil
+}
+
+// GetStockOn gets the stock of a product that can be delivered on the date ("2006-01-02"),
+// excluding units of batches that expire by then
+func (s *ProductService) GetStockOn(productID uint64, date string) (*response.ProductStockResponse, error) {
+	deliveryDate, err := parseDeliveryDate(date)
+	if err != nil {
+		return nil, err
+	}
+	if deliveryDate == nil {
+		now := today()
+		deliveryDate = &now
 	}
 
-	return productResponses, nil
+	product, err := s.productRepo.GetProductByID(productID)
+	if err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+	if err := s.bundleService.FillStockOn(deliveryDate, product); err != nil {
+		return nil, err
+	}
+
+	return &response.ProductStockResponse{
+		ProductID:    product.ID,
+		DeliveryDate: deliveryDate.Format(dateLayout),
+		StockCount:   product.StockCount,
+	}, nil
 }
 
-// GetHotProducts gets hot products
-func (s *ProductService) GetHotProducts(limit int) ([]*response.ProductResponse, error) {
-	// Try to get from cache
-	var productResponses []*response.ProductResponse
+// toProductResponses 转换商品响应并填充各等级会员价及当前用户的会员价，组合商品库存按组件计算
+func (s *ProductService) toProductResponses(products []model.Product, userID uint64) ([]*response.ProductResponse, error) {
+	bundles := make([]*model.Product, 0)
+	for i := range products {
+		if products[i].IsBundle {
+			bundles = append(bundles, &products[i])
+		}
+	}
+	if err := s.bundleService.FillStock(bundles...); err != nil {
+		return nil, err
+	}
 
-	// If not in cache, get from database
-	hot := true
-	products, _, err := s.productRepo.GetProducts(1, limit, nil, &hot, nil)
+	memberPrices, err := s.memberService.GetMemberPricesBatch(products)
 	if err != nil {
 		return nil, err
 	}
 
-	productResponses = make([]*response.ProductResponse, len(products))
+	tier, err := s.memberService.GetUserTier(userID)
+	if err != nil {
+		// 获取用户等级失败时按非会员展示
+		logger.Warnf("Failed to get member tier of user %d: %v", userID, err)
+		tier = nil
+	}
+
+	productResponses := make([]*response.ProductResponse, len(products))
 	for i, product := range products {
 		productResponses[i] = &response.ProductResponse{
 			ID:             product.ID,
@@ -149,21 +331,40 @@ func (s *ProductService) GetHotProducts(limit int) ([]*response.ProductResponse,
 			FloralLanguage: product.FloralLanguage,
 			Price:          product.Price,
 			MarketPrice:    product.MarketPrice,
+			MemberPrices:   memberPrices[product.ID],
 			SaleCount:      product.SaleCount,
 			StockCount:     product.StockCount,
 			CategoryID:     product.CategoryID,
-			SubCategoryID:  product.SubCategoryID,
 			Material:       product.Material,
 			Packing:        product.Packing,
 			ImageUrl:       product.ImageUrl,
 			Status:         product.Status,
+			IsBundle:       product.IsBundle,
 			Recommend:      product.Recommend,
 			SortOrder:      product.SortOrder,
 			ApplyUser:      product.ApplyUser,
 			CreatedAt:      product.CreatedAt,
 			UpdatedAt:      product.UpdatedAt,
 		}
+
+		if tier != nil {
+			for _, price := range memberPrices[product.ID] {
+				if price.TierID == tier.ID {
+					memberPrice := price.Price
+					productResponses[i].MemberPrice = &memberPrice
+				}
+			}
+		}
 	}
 
 	return productResponses, nil
 }
+
+// rankedProductIDs 按排行顺序获取商品ID
+func rankedProductIDs(ranking []RankedProduct) []uint64 {
+	productIDs := make([]uint64, len(ranking))
+	for i, ranked := range ranking {
+		productIDs[i] = ranked.ProductID
+	}
+	return productIDs
+}
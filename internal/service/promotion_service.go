@@ -2,71 +2,492 @@ package service
 
 import (
 	"context"
-	"errors"
+	"slices"
 	"time"
 
+	"github.com/colinjuang/shop-go/internal/app/request"
 	"github.com/colinjuang/shop-go/internal/app/response"
 	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
 	"github.com/colinjuang/shop-go/internal/pkg/logger"
 	"github.com/colinjuang/shop-go/internal/pkg/minio"
 	"github.com/colinjuang/shop-go/internal/pkg/redis"
 	"github.com/colinjuang/shop-go/internal/repository"
 	"github.com/colinjuang/shop-go/internal/server"
+	categoryutils "github.com/colinjuang/shop-go/internal/utils/category"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	promotionutils "github.com/colinjuang/shop-go/internal/utils/promotion"
+	"gorm.io/gorm"
 )
 
-// PromotionService handles business logic for the home page
+// promotionCacheTTL 生效促销缓存的最长时间，下一个开始或结束时间更早时缓存到该时间
+const promotionCacheTTL = 10 * time.Minute
+
+// PromotionService handles promotion activities: the ads on the home page and the discounts
+// evaluated by the pricing service at checkout
 type PromotionService struct {
+	db            *gorm.DB
 	promotionRepo *repository.PromotionRepository
+	productRepo   *repository.ProductRepository
+	categoryRepo  *repository.CategoryRepository
 	cacheService  *redis.CacheService
 }
 
-// NewPromotionService creates a new home service
+// NewPromotionService creates a new promotion service
 func NewPromotionService() *PromotionService {
 	server := server.GetServer()
 	return &PromotionService{
+		db:            server.DB,
 		promotionRepo: repository.NewPromotionRepository(server.DB),
+		productRepo:   repository.NewProductRepository(server.DB),
+		categoryRepo:  repository.NewCategoryRepository(server.DB),
 		cacheService:  redis.NewCacheService(),
 	}
 }
 
-// GetPromotions gets all promotions
+// PromotionLine is an order line taking part in promotions, at its unit price after member prices
+type PromotionLine struct {
+	Product   model.Product
+	UnitPrice float64
+	Quantity  int
+}
+
+// AppliedPromotion is a promotion applied to an order line and the discount it gave the line
+type AppliedPromotion struct {
+	PromotionID     uint64
+	Title           string
+	RuleType        int
+	Discount        float64
+	CouponStackable bool
+}
+
+// GetPromotions gets the promotions running now that have an ad image, for the home page
 func (s *PromotionService) GetPromotions() ([]*response.PromotionResponse, error) {
-	ctx := context.Background()
+	now := time.Now()
+	promotions, err := s.getActivePromotions(context.Background(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	minioClient := minio.GetClient()
+	promotionResponses := make([]*response.PromotionResponse, 0, len(promotions))
+	for _, promotion := range promotions {
+		if promotion.ImageUrl == "" || !promotionActive(&promotion, now) {
+			continue
+		}
+		promotion.ImageUrl = minioClient.GetFileURL(promotion.ImageUrl)
+		promotionResponses = append(promotionResponses, toPromotionResponse(&promotion))
+	}
+	return promotionResponses, nil
+}
+
+// GetAdminPromotions gets all promotions including scheduled, ended and disabled ones, optionally of one status (admin)
+func (s *PromotionService) GetAdminPromotions(status *int) ([]model.Promotion, error) {
+	promotions, err := s.promotionRepo.GetPromotions(status)
+	if err != nil {
+		return nil, err
+	}
+	minioClient := minio.GetClient()
+	for i := range promotions {
+		if promotions[i].ImageUrl != "" {
+			promotions[i].ImageUrl = minioClient.GetFileURL(promotions[i].ImageUrl)
+		}
+	}
+	return promotions, nil
+}
+
+// CreatePromotion creates a promotion (admin)
+func (s *PromotionService) CreatePromotion(req request.PromotionRequest) (*model.Promotion, error) {
+	promotion := &model.Promotion{}
+	if err := s.fillPromotion(promotion, req); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewPromotionRepository(tx).CreatePromotion(promotion); err != nil {
+			return err
+		}
+		return trackObjectReferences(repository.NewProductMediaRepository(tx), nil, promotionImages(promotion))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidatePromotions()
+	return promotion, nil
+}
+
+// UpdatePromotion updates a promotion. Orders already placed keep the discount they were given (admin).
+func (s *PromotionService) UpdatePromotion(id uint64, req request.PromotionRequest) (*model.Promotion, error) {
+	promotion, err := s.promotionRepo.GetPromotionByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrPromotionNotFound
+	}
+	oldImages := promotionImages(promotion)
+	if err := s.fillPromotion(promotion, req); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewPromotionRepository(tx).UpdatePromotion(promotion); err != nil {
+			return err
+		}
+		return trackObjectReferences(repository.NewProductMediaRepository(tx), oldImages, promotionImages(promotion))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidatePromotions()
+	return promotion, nil
+}
+
+// DeletePromotion deletes a promotion, keeping the discounts recorded on orders (admin)
+func (s *PromotionService) DeletePromotion(id uint64) error {
+	promotion, err := s.promotionRepo.GetPromotionByID(id)
+	if err != nil {
+		return pkgerrors.ErrPromotionNotFound
+	}
 
-	// Try to get from cache
-	var promotionResponses []*response.PromotionResponse
-	err := s.cacheService.GetObject(ctx, constant.HomePromotions, &promotionResponses)
-	if err == nil {
-		return promotionResponses, nil
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewPromotionRepository(tx).DeletePromotion(id); err != nil {
+			return err
+		}
+		return trackObjectReferences(repository.NewProductMediaRepository(tx), promotionImages(promotion), nil)
+	})
+	if err != nil {
+		return err
 	}
 
-	// If not in cache, get from database
-	promotions, err := s.promotionRepo.GetPromotions()
+	s.invalidatePromotions()
+	return nil
+}
+
+// Evaluate evaluates the promotions running now against the lines and returns the promotions applied to each line.
+// Stackable promotions are applied one after another by priority, each on the amount left by the previous ones.
+// An exclusive promotion is not combined with others on the lines it discounts; every choice of exclusive
+// promotion is tried and the plan giving the largest total discount is used.
+func (s *PromotionService) Evaluate(lines []PromotionLine) ([][]AppliedPromotion, error) {
+	now := time.Now()
+	promotions, err := s.getActivePromotions(context.Background(), now)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(promotions) == 0 {
-		return nil, errors.New("no promotions found")
+	var stackable, exclusive []model.Promotion
+	for _, promotion := range promotions {
+		if promotion.RuleType == model.PromotionRuleNone || !promotionActive(&promotion, now) {
+			continue
+		}
+		if promotion.Exclusive {
+			exclusive = append(exclusive, promotion)
+		} else {
+			stackable = append(stackable, promotion)
+		}
+	}
+
+	best := planPromotions(lines, nil, stackable)
+	for i := range exclusive {
+		if plan := planPromotions(lines, &exclusive[i], stackable); plan.total > best.total {
+			best = plan
+		}
+	}
+	return best.applied, nil
+}
+
+// promotionPlan 一种活动组合下各行享受的促销活动
+type promotionPlan struct {
+	applied [][]AppliedPromotion
+	total   float64
+}
+
+// planPromotions 先计算互斥活动（可为空），其优惠的行不再参加其他活动，其余行按优先级依次叠加可叠加的活动
+func planPromotions(lines []PromotionLine, exclusive *model.Promotion, stackable []model.Promotion) *promotionPlan {
+	plan := &promotionPlan{applied: make([][]AppliedPromotion, len(lines))}
+	amounts := make([]float64, len(lines))
+	for i, line := range lines {
+		amounts[i] = priceutils.Round(line.UnitPrice * float64(line.Quantity))
+	}
+	claimed := make([]bool, len(lines))
+
+	if exclusive != nil {
+		for _, i := range plan.apply(exclusive, lines, amounts, claimed) {
+			claimed[i] = true
+		}
+	}
+	for i := range stackable {
+		plan.apply(&stackable[i], lines, amounts, claimed)
+	}
+	plan.total = priceutils.Round(plan.total)
+	return plan
+}
+
+// apply 在活动适用且未被互斥活动占用的行上计算优惠，扣减各行剩余金额，返回得到优惠的行
+func (p *promotionPlan) apply(promotion *model.Promotion, lines []PromotionLine, amounts []float64, claimed []bool) []int {
+	var indexes []int
+	var ruleLines []promotionutils.Line
+	for i := range lines {
+		if claimed[i] || amounts[i] <= 0 || !promotionCovers(promotion, &lines[i].Product) {
+			continue
+		}
+		indexes = append(indexes, i)
+		ruleLines = append(ruleLines, promotionutils.Line{
+			ProductID: lines[i].Product.ID,
+			UnitPrice: lines[i].UnitPrice,
+			Quantity:  lines[i].Quantity,
+			Amount:    amounts[i],
+		})
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	var discounted []int
+	for j, discount := range ruleDiscounts(promotion, ruleLines) {
+		if discount <= 0 {
+			continue
+		}
+		i := indexes[j]
+		amounts[i] = priceutils.Round(amounts[i] - discount)
+		p.applied[i] = append(p.applied[i], AppliedPromotion{
+			PromotionID:     promotion.ID,
+			Title:           promotion.Title,
+			RuleType:        promotion.RuleType,
+			Discount:        discount,
+			CouponStackable: promotion.CouponStackable,
+		})
+		p.total += discount
+		discounted = append(discounted, i)
+	}
+	return discounted
+}
+
+// ruleDiscounts 按活动规则计算各行的优惠金额，满减、满折按适用商品的合计金额计算后按金额比例分摊
+func ruleDiscounts(promotion *model.Promotion, lines []promotionutils.Line) []float64 {
+	switch promotion.RuleType {
+	case model.PromotionRuleReduction:
+		discount := promotionutils.Reduction(promotionutils.Total(lines), promotion.Threshold, promotion.Discount, promotion.Repeatable)
+		return promotionutils.Allocate(discount, lines)
+	case model.PromotionRulePercentage:
+		discount := promotionutils.Percentage(promotionutils.Total(lines), promotion.Threshold, promotion.Rate)
+		return promotionutils.Allocate(discount, lines)
+	case model.PromotionRuleBuyFree:
+		return promotionutils.BuyFree(lines, promotion.BuyQty, promotion.FreeQty)
+	case model.PromotionRuleSecondHalf:
+		return promotionutils.SecondHalf(lines)
 	}
-	promotionResponses = make([]*response.PromotionResponse, len(promotions))
+	return make([]float64, len(lines))
+}
 
-	for i, promotion := range promotions {
-		promotionResponses[i] = &response.PromotionResponse{
-			ID:            promotion.ID,
-			Title:         promotion.Title,
-			ImageUrl:      minio.GetClient().GetFileURL(promotion.ImageUrl),
-			SubCategoryID: promotion.SubCategoryID,
-			CreatedAt:     promotion.CreatedAt,
-			UpdatedAt:     promotion.UpdatedAt,
+// promotionCovers 商品是否在活动范围内，指定分类时包括子孙分类的商品
+func promotionCovers(promotion *model.Promotion, product *model.Product) bool {
+	switch promotion.Scope {
+	case model.PromotionScopeCategory:
+		for _, categoryID := range categoryutils.PathIDs(product.CategoryPath) {
+			if slices.Contains(promotion.CategoryIDs, categoryID) {
+				return true
+			}
 		}
+		return false
+	case model.PromotionScopeProduct:
+		return slices.Contains(promotion.ProductIDs, product.ID)
+	default:
+		return true
+	}
+}
+
+// getActivePromotions 获取当前生效的启用促销及其适用范围，缓存到最近的开始或结束时间，保证按时开始和结束
+func (s *PromotionService) getActivePromotions(ctx context.Context, now time.Time) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	if err := s.cacheService.GetObject(ctx, constant.ActivePromotions, &promotions); err == nil {
+		return promotions, nil
 	}
 
-	// Cache for 1 minute
-	err = s.cacheService.Set(ctx, constant.HomePromotions, promotions, 1*time.Minute)
+	unfinished, err := s.promotionRepo.GetUnfinishedPromotions(now)
 	if err != nil {
+		return nil, err
+	}
+
+	ttl := promotionCacheTTL
+	promotions = make([]model.Promotion, 0, len(unfinished))
+	for _, promotion := range unfinished {
+		for _, boundary := range []*time.Time{promotion.StartAt, promotion.EndAt} {
+			if boundary != nil && boundary.After(now) && boundary.Sub(now) < ttl {
+				ttl = boundary.Sub(now)
+			}
+		}
+		if promotionActive(&promotion, now) {
+			promotions = append(promotions, promotion)
+		}
+	}
+
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	if err := s.cacheService.Set(ctx, constant.ActivePromotions, promotions, ttl); err != nil {
 		logger.Warnf("Failed to cache promotions: %v", err)
 	}
+	return promotions, nil
+}
+
+// fillPromotion 校验请求并填充促销，只保留规则类型用到的参数
+func (s *PromotionService) fillPromotion(promotion *model.Promotion, req request.PromotionRequest) error {
+	if req.StartAt != nil && req.EndAt != nil && !req.EndAt.After(*req.StartAt) {
+		return pkgerrors.ErrInvalidPromotion
+	}
 
-	return promotionResponses, nil
+	imageUrl := ""
+	if req.ImageUrl != "" {
+		objectName, ok := toObjectName(req.ImageUrl)
+		if !ok {
+			return pkgerrors.ErrInvalidPromotion
+		}
+		imageUrl = objectName
+	}
+
+	*promotion = model.Promotion{
+		ID:              promotion.ID,
+		Title:           req.Title,
+		ImageUrl:        imageUrl,
+		Description:     req.Description,
+		Scope:           req.Scope,
+		RuleType:        req.RuleType,
+		Exclusive:       req.Exclusive,
+		CouponStackable: req.CouponStackable,
+		Priority:        req.Priority,
+		StartAt:         req.StartAt,
+		EndAt:           req.EndAt,
+		Status:          model.PromotionStatusEnabled,
+		CreatedAt:       promotion.CreatedAt,
+	}
+	if req.Status != nil {
+		promotion.Status = *req.Status
+	}
+
+	switch req.RuleType {
+	case model.PromotionRuleNone:
+		// 仅广告的活动需要图片
+		if imageUrl == "" {
+			return pkgerrors.ErrInvalidPromotion
+		}
+	case model.PromotionRuleReduction:
+		if req.Threshold <= 0 || req.Discount <= 0 || req.Discount > req.Threshold {
+			return pkgerrors.ErrInvalidPromotion
+		}
+		promotion.Threshold = req.Threshold
+		promotion.Discount = req.Discount
+		promotion.Repeatable = req.Repeatable
+	case model.PromotionRulePercentage:
+		if req.Rate <= 0 || req.Rate >= 1 {
+			return pkgerrors.ErrInvalidPromotion
+		}
+		promotion.Threshold = req.Threshold
+		promotion.Rate = req.Rate
+	case model.PromotionRuleBuyFree:
+		if req.BuyQty <= 0 || req.FreeQty <= 0 {
+			return pkgerrors.ErrInvalidPromotion
+		}
+		promotion.BuyQty = req.BuyQty
+		promotion.FreeQty = req.FreeQty
+	}
+
+	return s.fillScope(promotion, req)
+}
+
+// fillScope 校验并填充活动适用的分类或商品
+func (s *PromotionService) fillScope(promotion *model.Promotion, req request.PromotionRequest) error {
+	switch req.Scope {
+	case model.PromotionScopeCategory:
+		categoryIDs := uniqueIDs(req.CategoryIDs)
+		if len(categoryIDs) == 0 {
+			return pkgerrors.ErrInvalidPromotion
+		}
+		for _, categoryID := range categoryIDs {
+			if _, err := s.categoryRepo.GetCategoryByID(categoryID); err != nil {
+				return pkgerrors.ErrCategoryNotFound
+			}
+		}
+		promotion.CategoryIDs = categoryIDs
+	case model.PromotionScopeProduct:
+		productIDs := uniqueIDs(req.ProductIDs)
+		if len(productIDs) == 0 {
+			return pkgerrors.ErrInvalidPromotion
+		}
+		products, err := s.productRepo.GetProductsByIDs(productIDs)
+		if err != nil {
+			return err
+		}
+		if len(products) != len(productIDs) {
+			return pkgerrors.ErrProductNotFound
+		}
+		promotion.ProductIDs = productIDs
+	}
+	return nil
+}
+
+// invalidatePromotions 清除生效促销和首页促销广告缓存
+func (s *PromotionService) invalidatePromotions() {
+	ctx := context.Background()
+	s.cacheService.Delete(ctx, constant.ActivePromotions)
+	s.cacheService.Delete(ctx, constant.HomePromotions)
+}
+
+// promotionActive 促销当前是否在活动时间内
+func promotionActive(promotion *model.Promotion, now time.Time) bool {
+	if promotion.StartAt != nil && promotion.StartAt.After(now) {
+		return false
+	}
+	return promotion.EndAt == nil || promotion.EndAt.After(now)
+}
+
+// promotionImages 促销引用的图片对象名
+func promotionImages(promotion *model.Promotion) []string {
+	if promotion.ImageUrl == "" {
+		return nil
+	}
+	return []string{promotion.ImageUrl}
+}
+
+// toPromotionResponse 转换为促销响应
+func toPromotionResponse(promotion *model.Promotion) *response.PromotionResponse {
+	promotionResponse := &response.PromotionResponse{
+		ID:          promotion.ID,
+		Title:       promotion.Title,
+		ImageUrl:    promotion.ImageUrl,
+		Description: promotion.Description,
+		Scope:       promotion.Scope,
+		CategoryIDs: promotion.CategoryIDs,
+		ProductIDs:  promotion.ProductIDs,
+		RuleType:    promotion.RuleType,
+		Threshold:   promotion.Threshold,
+		Discount:    promotion.Discount,
+		Rate:        promotion.Rate,
+		BuyQty:      promotion.BuyQty,
+		FreeQty:     promotion.FreeQty,
+		Repeatable:  promotion.Repeatable,
+		Exclusive:   promotion.Exclusive,
+		StartAt:     promotion.StartAt,
+		EndAt:       promotion.EndAt,
+		CreatedAt:   promotion.CreatedAt,
+		UpdatedAt:   promotion.UpdatedAt,
+	}
+	if len(promotion.CategoryIDs) > 0 {
+		promotionResponse.SubCategoryID = promotion.CategoryIDs[0]
+	}
+	return promotionResponse
+}
+
+// uniqueIDs 去除重复和为0的ID，保持原顺序
+func uniqueIDs(ids []uint64) []uint64 {
+	seen := make(map[uint64]bool, len(ids))
+	result := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
 }
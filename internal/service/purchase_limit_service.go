@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	"gorm.io/gorm"
+)
+
+// PurchaseLimitService handles per-user purchase limits of products
+type PurchaseLimitService struct {
+	limitRepo   *repository.PurchaseLimitRepository
+	productRepo *repository.ProductRepository
+}
+
+// NewPurchaseLimitService creates a new purchase limit service
+func NewPurchaseLimitService() *PurchaseLimitService {
+	server := server.GetServer()
+	return &PurchaseLimitService{
+		limitRepo:   repository.NewPurchaseLimitRepository(server.DB),
+		productRepo: repository.NewProductRepository(server.DB),
+	}
+}
+
+// GetLimits gets all purchase limit rules of a product (admin)
+func (s *PurchaseLimitService) GetLimits(productID uint64) ([]model.PurchaseLimit, error) {
+	return s.limitRepo.GetLimitsByProductID(productID)
+}
+
+// GetActiveLimits gets the purchase limits of a product currently in effect, for display on the product detail
+func (s *PurchaseLimitService) GetActiveLimits(productID uint64) ([]response.PurchaseLimitResponse, error) {
+	limits, err := s.limitRepo.GetActiveLimits([]uint64{productID}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]response.PurchaseLimitResponse, len(limits))
+	for i, limit := range limits {
+		responses[i] = response.PurchaseLimitResponse{
+			Period:      limit.Period,
+			MaxQuantity: limit.MaxQuantity,
+			StartAt:     limit.StartAt,
+			EndAt:       limit.EndAt,
+			Desc:        fmt.Sprintf("%s限购%d件", model.PurchaseLimitPeriodDesc[limit.Period], limit.MaxQuantity),
+		}
+	}
+	return responses, nil
+}
+
+// CreateLimit creates a purchase limit rule on a product (admin)
+func (s *PurchaseLimitService) CreateLimit(productID uint64, req request.PurchaseLimitRequest) (*model.PurchaseLimit, error) {
+	if _, err := s.productRepo.GetProductByID(productID); err != nil {
+		return nil, pkgerrors.ErrProductNotFound
+	}
+	if req.StartAt != nil && req.EndAt != nil && !req.EndAt.After(*req.StartAt) {
+		return nil, pkgerrors.ErrInvalidPurchaseLimit
+	}
+
+	limit := &model.PurchaseLimit{
+		ProductID:   productID,
+		Period:      req.Period,
+		MaxQuantity: req.MaxQuantity,
+		StartAt:     req.StartAt,
+		EndAt:       req.EndAt,
+	}
+	if err := s.limitRepo.CreateLimit(limit); err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+// UpdateLimit updates a purchase limit rule (admin)
+func (s *PurchaseLimitService) UpdateLimit(id uint64, req request.PurchaseLimitRequest) (*model.PurchaseLimit, error) {
+	limit, err := s.limitRepo.GetLimitByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil {
+		return nil, pkgerrors.ErrPurchaseLimitNotFound
+	}
+	if req.StartAt != nil && req.EndAt != nil && !req.EndAt.After(*req.StartAt) {
+		return nil, pkgerrors.ErrInvalidPurchaseLimit
+	}
+
+	limit.Period = req.Period
+	limit.MaxQuantity = req.MaxQuantity
+	limit.StartAt = req.StartAt
+	limit.EndAt = req.EndAt
+	if err := s.limitRepo.UpdateLimit(limit); err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+// DeleteLimit deletes a purchase limit rule (admin)
+func (s *PurchaseLimitService) DeleteLimit(id uint64) error {
+	return s.limitRepo.DeleteLimit(id)
+}
+
+// CheckOrder checks the items of a new order against the purchase limits of the user.
+// It must be called inside the order transaction before the order is saved: the user row is locked so that
+// concurrent submissions of one user are checked one after another and each sees the orders committed before it.
+func (s *PurchaseLimitService) CheckOrder(tx *gorm.DB, userID uint64, items []model.OrderItem) error {
+	quantities := make(map[uint64]int)
+	names := make(map[uint64]string)
+	productIDs := make([]uint64, 0, len(items))
+	for _, item := range items {
+		if item.ParentID > 0 {
+			continue
+		}
+		quantities[item.ProductID] += item.Quantity
+		names[item.ProductID] = item.Name
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	limits, err := s.lockForLimits(tx, userID, productIDs)
+	if err != nil || len(limits) == 0 {
+		return err
+	}
+	return s.check(tx, userID, limits, quantities, names)
+}
+
+// CheckCart checks the quantity the user would have in the cart after adding quantity units of the product
+// against its purchase limits. It must be called inside the cart transaction before the cart is read or updated.
+func (s *PurchaseLimitService) CheckCart(tx *gorm.DB, userID uint64, product *model.Product, quantity int) error {
+	limits, err := s.lockForLimits(tx, userID, []uint64{product.ID})
+	if err != nil || len(limits) == 0 {
+		return err
+	}
+
+	// 购物车中已有的数量一并计入
+	carts, err := repository.NewCartRepository(tx).GetCart(userID)
+	if err != nil {
+		return err
+	}
+	for _, item := range carts {
+		if item.ProductID == product.ID {
+			quantity += item.Quantity
+		}
+	}
+	return s.check(tx, userID, limits, map[uint64]int{product.ID: quantity}, map[uint64]string{product.ID: product.Name})
+}
+
+// lockForLimits 获取商品当前生效的限购规则，存在规则时锁定用户。
+// 同一用户的下单和加购因此串行执行，且锁定后事务内的查询能读到此前已提交的订单，需在事务内的其他查询之前调用
+func (s *PurchaseLimitService) lockForLimits(tx *gorm.DB, userID uint64, productIDs []uint64) ([]model.PurchaseLimit, error) {
+	limits, err := s.limitRepo.GetActiveLimits(productIDs, time.Now())
+	if err != nil || len(limits) == 0 {
+		return nil, err
+	}
+	if _, err := repository.NewUserRepository(tx).GetUserByIDForUpdate(userID); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// check 按限购规则统计周期内已购数量，超出时返回限购错误
+func (s *PurchaseLimitService) check(tx *gorm.DB, userID uint64, limits []model.PurchaseLimit, quantities map[uint64]int, names map[uint64]string) error {
+	now := time.Now()
+	limitRepo := repository.NewPurchaseLimitRepository(tx)
+	for _, limit := range limits {
+		purchased, err := limitRepo.SumPurchasedQuantity(userID, limit.ProductID, limitPeriodStart(limit, now))
+		if err != nil {
+			return err
+		}
+		quantity := quantities[limit.ProductID]
+		if purchased+quantity <= limit.MaxQuantity {
+			continue
+		}
+
+		remaining := max(limit.MaxQuantity-purchased, 0)
+		return &pkgerrors.PurchaseLimitError{
+			ProductID:   limit.ProductID,
+			ProductName: names[limit.ProductID],
+			Period:      limit.Period,
+			Limit:       limit.MaxQuantity,
+			Purchased:   purchased,
+			Remaining:   remaining,
+			Message: fmt.Sprintf("%s%s限购%d件，您还可购买%d件",
+				names[limit.ProductID], model.PurchaseLimitPeriodDesc[limit.Period], limit.MaxQuantity, remaining),
+		}
+	}
+	return nil
+}
+
+// limitPeriodStart 计算限购周期的开始时间，不早于规则的生效开始时间
+func limitPeriodStart(limit model.PurchaseLimit, now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var start time.Time
+	switch limit.Period {
+	case model.PurchaseLimitPeriodDay:
+		start = today
+	case model.PurchaseLimitPeriodWeek:
+		// 自然周从周一开始
+		start = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	case model.PurchaseLimitPeriodMonth:
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		start = time.Unix(0, 0)
+	}
+
+	if limit.StartAt != nil && limit.StartAt.After(start) {
+		start = *limit.StartAt
+	}
+	return start
+}
@@ -0,0 +1,495 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	inventoryutils "github.com/colinjuang/shop-go/internal/utils/inventory"
+	utils "github.com/colinjuang/shop-go/internal/utils/order"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	"gorm.io/gorm"
+)
+
+// PurchaseService handles suppliers and the purchase orders placed with them. Received units are put into
+// stock through the inventory service, as batches when the item has a shelf life.
+// It also suggests what to reorder from the recent sales velocity of each product.
+type PurchaseService struct {
+	db               *gorm.DB
+	purchaseRepo     *repository.PurchaseRepository
+	productRepo      *repository.ProductRepository
+	inventoryService *InventoryService
+	config           config.PurchaseConfig
+}
+
+// NewPurchaseService creates a new purchase service
+func NewPurchaseService() *PurchaseService {
+	server := server.GetServer()
+	cfg := server.GetConfig().Purchase
+	if cfg.VelocityDays <= 0 {
+		cfg.VelocityDays = 14
+	}
+	if cfg.LeadDays <= 0 {
+		cfg.LeadDays = 2
+	}
+	if cfg.CoverDays <= 0 {
+		cfg.CoverDays = 7
+	}
+	return &PurchaseService{
+		db:               server.DB,
+		purchaseRepo:     repository.NewPurchaseRepository(server.DB),
+		productRepo:      repository.NewProductRepository(server.DB),
+		inventoryService: NewInventoryService(),
+		config:           cfg,
+	}
+}
+
+// GetSuppliers gets the suppliers, optionally of one status (admin)
+func (s *PurchaseService) GetSuppliers(status *int) ([]model.Supplier, error) {
+	return s.purchaseRepo.GetSuppliers(status)
+}
+
+// CreateSupplier creates a supplier, enabled unless the request says otherwise (admin)
+func (s *PurchaseService) CreateSupplier(req request.SupplierRequest) (*model.Supplier, error) {
+	supplier := &model.Supplier{Status: model.SupplierStatusEnabled}
+	fillSupplier(supplier, req)
+	if err := s.purchaseRepo.CreateSupplier(supplier); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// UpdateSupplier updates a supplier (admin). Disabled suppliers keep their purchase orders but get no new ones.
+func (s *PurchaseService) UpdateSupplier(id uint64, req request.SupplierRequest) (*model.Supplier, error) {
+	supplier, err := s.purchaseRepo.GetSupplierByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrSupplierNotFound
+	}
+	fillSupplier(supplier, req)
+	if err := s.purchaseRepo.UpdateSupplier(supplier); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// GetPurchaseOrders gets purchase orders with pagination, optionally of a supplier (0 for all) and a status, latest first (admin)
+func (s *PurchaseService) GetPurchaseOrders(supplierID uint64, status *int, page, pageSize int) (*response.Pagination, error) {
+	orders, total, err := s.purchaseRepo.GetPurchaseOrders(supplierID, status, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, orders)
+	return &pagination, nil
+}
+
+// GetPurchaseOrder gets a purchase order with its supplier and items (admin)
+func (s *PurchaseService) GetPurchaseOrder(id uint64) (*model.PurchaseOrder, error) {
+	order, err := s.purchaseRepo.GetPurchaseOrderByID(id)
+	if err != nil {
+		return nil, pkgerrors.ErrPurchaseOrderNotFound
+	}
+	return order, nil
+}
+
+// CreatePurchaseOrder creates a draft purchase order (admin). It can be changed until it is submitted.
+func (s *PurchaseService) CreatePurchaseOrder(actorID uint64, req request.PurchaseOrderRequest) (*model.PurchaseOrder, error) {
+	expectedAt, err := parseExpectedAt(req.ExpectedAt)
+	if err != nil {
+		return nil, err
+	}
+	items, totalAmount, err := s.buildPurchaseItems(req)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &model.PurchaseOrder{
+		PONo:        utils.GeneratePurchaseOrderNo(actorID),
+		SupplierID:  req.SupplierID,
+		Status:      model.PurchaseOrderStatusDraft,
+		ExpectedAt:  expectedAt,
+		TotalAmount: totalAmount,
+		Remark:      req.Remark,
+		CreatedBy:   actorID,
+		Items:       items,
+	}
+	if err := s.purchaseRepo.CreatePurchaseOrder(order); err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrder(order.ID)
+}
+
+// UpdatePurchaseOrder replaces the supplier, expected arrival and items of a draft purchase order (admin)
+func (s *PurchaseService) UpdatePurchaseOrder(id uint64, req request.PurchaseOrderRequest) (*model.PurchaseOrder, error) {
+	expectedAt, err := parseExpectedAt(req.ExpectedAt)
+	if err != nil {
+		return nil, err
+	}
+	items, totalAmount, err := s.buildPurchaseItems(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		purchaseRepo := repository.NewPurchaseRepository(tx)
+		order, err := purchaseRepo.LockPurchaseOrder(id)
+		if err != nil {
+			return pkgerrors.ErrPurchaseOrderNotFound
+		}
+		if order.Status != model.PurchaseOrderStatusDraft {
+			return pkgerrors.ErrPurchaseOrderStatusInvalid
+		}
+
+		for i := range items {
+			items[i].PurchaseOrderID = id
+		}
+		if err := purchaseRepo.ReplacePurchaseOrderItems(id, items); err != nil {
+			return err
+		}
+		return purchaseRepo.UpdatePurchaseOrder(id, map[string]interface{}{
+			"supplier_id":  req.SupplierID,
+			"expected_at":  expectedAt,
+			"total_amount": totalAmount,
+			"remark":       req.Remark,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrder(id)
+}
+
+// SubmitPurchaseOrder marks a draft purchase order as ordered from the supplier (admin).
+// Its quantities count as incoming stock in reorder suggestions from then on.
+func (s *PurchaseService) SubmitPurchaseOrder(id uint64) (*model.PurchaseOrder, error) {
+	order, err := s.GetPurchaseOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Supplier == nil || order.Supplier.Status != model.SupplierStatusEnabled {
+		return nil, pkgerrors.ErrInvalidPurchaseOrder
+	}
+
+	ok, err := s.purchaseRepo.UpdatePurchaseOrderStatusFrom(id, []int{model.PurchaseOrderStatusDraft},
+		model.PurchaseOrderStatusOrdered, map[string]interface{}{"ordered_at": time.Now()})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, pkgerrors.ErrPurchaseOrderStatusInvalid
+	}
+	return s.GetPurchaseOrder(id)
+}
+
+// CancelPurchaseOrder cancels a purchase order nothing of which has arrived, or closes a partially received
+// one so that the outstanding quantities are no longer expected (admin)
+func (s *PurchaseService) CancelPurchaseOrder(id uint64) (*model.PurchaseOrder, error) {
+	order, err := s.GetPurchaseOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	switch order.Status {
+	case model.PurchaseOrderStatusDraft, model.PurchaseOrderStatusOrdered:
+		ok, err = s.purchaseRepo.UpdatePurchaseOrderStatusFrom(id, []int{order.Status}, model.PurchaseOrderStatusCancelled, nil)
+	case model.PurchaseOrderStatusPartiallyReceived:
+		ok, err = s.purchaseRepo.UpdatePurchaseOrderStatusFrom(id, []int{order.Status}, model.PurchaseOrderStatusClosed, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// 已到货、已取消或状态已被并发修改
+		return nil, pkgerrors.ErrPurchaseOrderStatusInvalid
+	}
+	return s.GetPurchaseOrder(id)
+}
+
+// ReceivePurchaseOrder receives a delivery of some or all of the outstanding items of an ordered purchase order (admin).
+// Items with a shelf life are received as stock batches expiring shelf-life days after the receiving date,
+// the others as non-perishable stock. Every receipt is recorded in the inventory ledger against the purchase order.
+func (s *PurchaseService) ReceivePurchaseOrder(actorID, id uint64, req request.PurchaseReceiveRequest) (*model.PurchaseOrder, error) {
+	receivedAt := today()
+	if req.ReceivedAt != "" {
+		date, err := time.ParseInLocation(dateLayout, req.ReceivedAt, time.Local)
+		if err != nil || date.After(receivedAt) {
+			return nil, pkgerrors.ErrInvalidPurchaseOrder
+		}
+		receivedAt = date
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		purchaseRepo := repository.NewPurchaseRepository(tx)
+		productRepo := repository.NewProductRepository(tx)
+
+		order, err := purchaseRepo.LockPurchaseOrder(id)
+		if err != nil {
+			return pkgerrors.ErrPurchaseOrderNotFound
+		}
+		if order.Status != model.PurchaseOrderStatusOrdered && order.Status != model.PurchaseOrderStatusPartiallyReceived {
+			return pkgerrors.ErrPurchaseOrderStatusInvalid
+		}
+
+		items := make(map[uint64]*model.PurchaseOrderItem, len(order.Items))
+		for i := range order.Items {
+			items[order.Items[i].ID] = &order.Items[i]
+		}
+
+		remark := order.PONo
+		if req.Remark != "" {
+			remark += " " + req.Remark
+		}
+		for _, line := range req.Items {
+			item, ok := items[line.ItemID]
+			if !ok {
+				return pkgerrors.ErrInvalidPurchaseOrder
+			}
+			ok, err := purchaseRepo.IncreaseReceivedQty(item.ID, line.Quantity)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return pkgerrors.ErrPurchaseReceiveExceeded
+			}
+			item.ReceivedQty += line.Quantity
+
+			// 锁定商品后入库，与批次分配的顺序一致
+			product, err := productRepo.LockProduct(item.ProductID)
+			if err != nil {
+				return pkgerrors.ErrProductNotFound
+			}
+			if product.IsBundle {
+				return pkgerrors.ErrBundleStockDerived
+			}
+
+			shelfLifeDays := item.ShelfLifeDays
+			if line.ShelfLifeDays != nil {
+				shelfLifeDays = *line.ShelfLifeDays
+			}
+			if shelfLifeDays > 0 {
+				err = s.inventoryService.ReceiveBatch(tx, &model.StockBatch{
+					ProductID:     item.ProductID,
+					ReceivedAt:    receivedAt,
+					ShelfLifeDays: shelfLifeDays,
+					Quantity:      line.Quantity,
+					Remark:        remark,
+				}, actorID, model.InventoryRefPurchaseOrder, order.ID)
+			} else {
+				err = s.inventoryService.ChangeStock(tx, &model.InventoryMovement{
+					ProductID: item.ProductID,
+					Type:      model.InventoryMovementReceive,
+					Quantity:  line.Quantity,
+					ActorID:   actorID,
+					RefType:   model.InventoryRefPurchaseOrder,
+					RefID:     order.ID,
+					Remark:    remark,
+				})
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		status := model.PurchaseOrderStatusReceived
+		for _, item := range order.Items {
+			if item.ReceivedQty < item.Quantity {
+				status = model.PurchaseOrderStatusPartiallyReceived
+				break
+			}
+		}
+		_, err = purchaseRepo.UpdatePurchaseOrderStatusFrom(order.ID, repository.OpenPurchaseStatuses, status, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPurchaseOrder(id)
+}
+
+// GetReorderSuggestions suggests how many units of each on-sale product to reorder so that the stock covers the
+// days until a new order arrives plus the configured cover days at the recent daily sales, counting units already
+// ordered. Products running out soonest come first (admin).
+func (s *PurchaseService) GetReorderSuggestions() ([]response.ReorderSuggestionResponse, error) {
+	sales, err := s.purchaseRepo.GetStockSales(time.Now().AddDate(0, 0, -s.config.VelocityDays))
+	if err != nil {
+		return nil, err
+	}
+	if len(sales) == 0 {
+		return []response.ReorderSuggestionResponse{}, nil
+	}
+
+	productIDs := make([]uint64, len(sales))
+	for i, sale := range sales {
+		productIDs[i] = sale.ProductID
+	}
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	incomingList, err := s.purchaseRepo.GetIncomingQuantities(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	incoming := make(map[uint64]int, len(incomingList))
+	for _, item := range incomingList {
+		incoming[item.ProductID] = item.Quantity
+	}
+
+	lastSuppliers, err := s.lastSuppliers(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]response.ReorderSuggestionResponse, 0)
+	for _, sale := range sales {
+		product, ok := productMap[sale.ProductID]
+		if !ok || product.IsBundle || product.Status != 1 {
+			continue
+		}
+
+		dailySales := inventoryutils.DailySales(sale.Quantity, s.config.VelocityDays)
+		leadDays := s.config.LeadDays
+		supplier := lastSuppliers[product.ID]
+		if supplier != nil && supplier.LeadDays > 0 {
+			leadDays = supplier.LeadDays
+		}
+		suggested := inventoryutils.ReorderQuantity(dailySales, leadDays, s.config.CoverDays, product.StockCount, incoming[product.ID])
+		if suggested == 0 {
+			continue
+		}
+
+		suggestion := response.ReorderSuggestionResponse{
+			ProductID:    product.ID,
+			Name:         product.Name,
+			Sold:         sale.Quantity,
+			DailySales:   priceutils.Round(dailySales),
+			Available:    product.StockCount,
+			Incoming:     incoming[product.ID],
+			LeadDays:     leadDays,
+			DaysOfStock:  priceutils.Round(float64(max(product.StockCount, 0)) / dailySales),
+			SuggestedQty: suggested,
+		}
+		if supplier != nil {
+			suggestion.LastSupplierID = supplier.ID
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].DaysOfStock != suggestions[j].DaysOfStock {
+			return suggestions[i].DaysOfStock < suggestions[j].DaysOfStock
+		}
+		return suggestions[i].ProductID < suggestions[j].ProductID
+	})
+	return suggestions, nil
+}
+
+// buildPurchaseItems 校验供应商和商品并生成采购明细，返回明细及采购金额
+func (s *PurchaseService) buildPurchaseItems(req request.PurchaseOrderRequest) ([]model.PurchaseOrderItem, float64, error) {
+	supplier, err := s.purchaseRepo.GetSupplierByID(req.SupplierID)
+	if err != nil {
+		return nil, 0, pkgerrors.ErrSupplierNotFound
+	}
+	if supplier.Status != model.SupplierStatusEnabled {
+		return nil, 0, pkgerrors.ErrInvalidPurchaseOrder
+	}
+
+	productIDs := make([]uint64, len(req.Items))
+	for i, reqItem := range req.Items {
+		productIDs[i] = reqItem.ProductID
+	}
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	items := make([]model.PurchaseOrderItem, len(req.Items))
+	seen := make(map[uint64]bool, len(req.Items))
+	var totalAmount float64
+	for i, reqItem := range req.Items {
+		product, ok := productMap[reqItem.ProductID]
+		// 组合商品的库存由组件决定，同一商品只能出现一次
+		if !ok || product.IsBundle || seen[product.ID] {
+			return nil, 0, pkgerrors.ErrInvalidPurchaseOrder
+		}
+		seen[product.ID] = true
+
+		items[i] = model.PurchaseOrderItem{
+			ProductID:     product.ID,
+			Name:          product.Name,
+			Quantity:      reqItem.Quantity,
+			UnitCost:      priceutils.Round(reqItem.UnitCost),
+			ShelfLifeDays: reqItem.ShelfLifeDays,
+		}
+		totalAmount += float64(reqItem.Quantity) * items[i].UnitCost
+	}
+	return items, priceutils.Round(totalAmount), nil
+}
+
+// lastSuppliers 商品最近一次采购的供应商
+func (s *PurchaseService) lastSuppliers(productIDs []uint64) (map[uint64]*model.Supplier, error) {
+	rows, err := s.purchaseRepo.GetLastSuppliers(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	suppliers, err := s.purchaseRepo.GetSuppliers(nil)
+	if err != nil {
+		return nil, err
+	}
+	supplierMap := make(map[uint64]*model.Supplier, len(suppliers))
+	for i := range suppliers {
+		supplierMap[suppliers[i].ID] = &suppliers[i]
+	}
+
+	// 按采购单倒序排列，每个商品取第一条
+	result := make(map[uint64]*model.Supplier, len(productIDs))
+	for _, row := range rows {
+		if _, ok := result[row.ProductID]; ok {
+			continue
+		}
+		result[row.ProductID] = supplierMap[row.SupplierID]
+	}
+	return result, nil
+}
+
+// fillSupplier 用请求填充供应商信息，未指定状态时保留原状态
+func fillSupplier(supplier *model.Supplier, req request.SupplierRequest) {
+	supplier.Name = req.Name
+	supplier.Contact = req.Contact
+	supplier.Phone = req.Phone
+	supplier.WechatID = req.WechatID
+	supplier.Address = req.Address
+	supplier.LeadDays = req.LeadDays
+	supplier.Remark = req.Remark
+	if req.Status != nil {
+		supplier.Status = *req.Status
+	}
+}
+
+// parseExpectedAt 解析预计到货日期，为空时返回nil
+func parseExpectedAt(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		return nil, pkgerrors.ErrInvalidPurchaseOrder
+	}
+	return &date, nil
+}
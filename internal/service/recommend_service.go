@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	recommendutils "github.com/colinjuang/shop-go/internal/utils/recommend"
+)
+
+const (
+	// purchaseScanBatch 计算相似度时每批读取的购买记录数
+	purchaseScanBatch = 5000
+	// recommendSeedLimit 个性化推荐时参考的最近购买和浏览商品数
+	recommendSeedLimit = 20
+	// viewedSeedWeight 浏览过的商品相对购买过的商品的权重
+	viewedSeedWeight = 0.5
+)
+
+// RecommendationService handles "customers also bought" and personalized product recommendations
+type RecommendationService struct {
+	recommendRepo  *repository.RecommendRepository
+	productRepo    *repository.ProductRepository
+	historyService *HistoryService
+	config         config.RecommendConfig
+}
+
+// NewRecommendationService creates a new recommendation service
+func NewRecommendationService() *RecommendationService {
+	server := server.GetServer()
+	cfg := server.GetConfig().Recommend
+	if cfg.WindowDays <= 0 {
+		cfg.WindowDays = 180
+	}
+	if cfg.HalfLifeDays <= 0 {
+		cfg.HalfLifeDays = 30
+	}
+	if cfg.TopN <= 0 {
+		cfg.TopN = 20
+	}
+	return &RecommendationService{
+		recommendRepo:  repository.NewRecommendRepository(server.DB),
+		productRepo:    repository.NewProductRepository(server.DB),
+		historyService: NewHistoryService(),
+		config:         cfg,
+	}
+}
+
+// BuildSimilarities mines paid orders of the recent window for products bought by the same users,
+// weighting older purchases less, and replaces the stored top similar products of every product
+func (s *RecommendationService) BuildSimilarities(ctx context.Context) error {
+	now := time.Now()
+	since := now.AddDate(0, 0, -s.config.WindowDays)
+
+	var purchases []recommendutils.Purchase
+	var lastID uint64
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		records, err := s.recommendRepo.GetPurchasesAfterID(since, lastID, purchaseScanBatch)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			break
+		}
+		for _, record := range records {
+			purchases = append(purchases, recommendutils.Purchase{
+				UserID:    record.UserID,
+				ProductID: record.ProductID,
+				Time:      record.CreatedAt,
+			})
+		}
+		lastID = records[len(records)-1].ID
+	}
+
+	halfLife := time.Duration(s.config.HalfLifeDays) * 24 * time.Hour
+	result := recommendutils.CoOccurrence(purchases, now, halfLife, s.config.TopN)
+
+	similarities := make([]model.ProductSimilarity, 0)
+	for productID, similars := range result {
+		for _, similar := range similars {
+			similarities = append(similarities, model.ProductSimilarity{
+				ProductID: productID,
+				SimilarID: similar.ProductID,
+				Score:     similar.Score,
+			})
+		}
+	}
+	if err := s.recommendRepo.ReplaceSimilarities(similarities); err != nil {
+		return err
+	}
+
+	logger.Infof("Built %d product similarities from %d purchases", len(similarities), len(purchases))
+	return nil
+}
+
+// AlsoBoughtProducts gets on-sale products often bought together with the product, most similar first
+func (s *RecommendationService) AlsoBoughtProducts(productID uint64, limit int) ([]model.Product, error) {
+	similarities, err := s.recommendRepo.GetSimilarities(productID, s.config.TopN)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uint64, len(similarities))
+	for i, similarity := range similarities {
+		productIDs[i] = similarity.SimilarID
+	}
+	return s.onSaleProducts(productIDs, limit)
+}
+
+// RecommendProducts gets recommended products for the user (userID 0 for guests).
+// Logged-in users get products similar to what they bought and viewed recently; products flagged
+// as recommended by the admin fill the rest, and are all that guests and new users get.
+func (s *RecommendationService) RecommendProducts(ctx context.Context, userID uint64, limit int) ([]model.Product, error) {
+	products := make([]model.Product, 0, limit)
+	if userID > 0 {
+		personalized, err := s.personalizedProducts(ctx, userID, limit)
+		if err != nil {
+			// 个性化推荐失败时退回人工推荐
+			logger.Warnf("Failed to get personalized recommendations of user %d: %v", userID, err)
+		} else {
+			products = append(products, personalized...)
+		}
+	}
+	if len(products) >= limit {
+		return products, nil
+	}
+
+	// 人工推荐补足
+	recommend := true
+	manual, _, err := s.productRepo.GetProducts(1, limit+len(products), nil, nil, &recommend, nil)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[uint64]bool, len(products))
+	for _, product := range products {
+		selected[product.ID] = true
+	}
+	for _, product := range manual {
+		if len(products) >= limit {
+			break
+		}
+		if selected[product.ID] || product.Status != 1 {
+			continue
+		}
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+// personalizedProducts 以用户最近购买和浏览的商品为种子，累加种子的相似商品得分，排除已购买的商品
+func (s *RecommendationService) personalizedProducts(ctx context.Context, userID uint64, limit int) ([]model.Product, error) {
+	purchased, err := s.recommendRepo.GetUserPurchasedProductIDs(userID, recommendSeedLimit)
+	if err != nil {
+		return nil, err
+	}
+	viewed, err := s.historyService.RecentProductIDs(ctx, userID, recommendSeedLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[uint64]float64, len(purchased)+len(viewed))
+	for _, productID := range viewed {
+		weights[productID] = viewedSeedWeight
+	}
+	for _, productID := range purchased {
+		weights[productID] = 1
+	}
+	if len(weights) == 0 {
+		return nil, nil
+	}
+
+	seeds := make([]uint64, 0, len(weights))
+	for productID := range weights {
+		seeds = append(seeds, productID)
+	}
+	similarities, err := s.recommendRepo.GetSimilaritiesByProductIDs(seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[uint64]bool, len(purchased))
+	for _, productID := range purchased {
+		excluded[productID] = true
+	}
+	scores := make(map[uint64]float64)
+	for _, similarity := range similarities {
+		if excluded[similarity.SimilarID] {
+			continue
+		}
+		scores[similarity.SimilarID] += weights[similarity.ProductID] * similarity.Score
+	}
+
+	productIDs := make([]uint64, 0, len(scores))
+	for productID := range scores {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool {
+		if scores[productIDs[i]] != scores[productIDs[j]] {
+			return scores[productIDs[i]] > scores[productIDs[j]]
+		}
+		return productIDs[i] < productIDs[j]
+	})
+	return s.onSaleProducts(productIDs, limit)
+}
+
+// onSaleProducts 按给定顺序获取在售商品，最多返回limit个
+func (s *RecommendationService) onSaleProducts(productIDs []uint64, limit int) ([]model.Product, error) {
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	result := make([]model.Product, 0, limit)
+	for _, productID := range productIDs {
+		if len(result) >= limit {
+			break
+		}
+		product, ok := productMap[productID]
+		if !ok || product.Status != 1 {
+			continue
+		}
+		result = append(result, product)
+	}
+	return result, nil
+}
@@ -2,25 +2,30 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/colinjuang/shop-go/internal/app/response"
 	"github.com/colinjuang/shop-go/internal/model"
 	"github.com/colinjuang/shop-go/internal/pkg/minio"
 )
 
 // ReportService handles business logic for generating reports
 type ReportService struct {
-	productService *ProductService
-	orderService   *OrderService
+	productService  *ProductService
+	orderService    *OrderService
+	purchaseService *PurchaseService
 }
 
 // NewReportService creates a new report service
 func NewReportService() *ReportService {
 	return &ReportService{
-		productService: NewProductService(),
-		orderService:   NewOrderService(),
+		productService:  NewProductService(),
+		orderService:    NewOrderService(),
+		purchaseService: NewPurchaseService(),
 	}
 }
 
@@ -46,7 +51,7 @@ func (s *ReportService) GenerateProductCatalogPDF(ctx context.Context, categoryI
 		// In a real application, you would use a PDF library like gofpdf
 
 		// Get products from category
-		pagination, err := s.productService.GetProducts(1, 100, categoryID, nil, nil)
+		pagination, err := s.productService.GetProducts(1, 100, categoryID, nil, nil, nil, 0)
 		if err != nil {
 			return err
 		}
@@ -64,7 +69,7 @@ func (s *ReportService) GenerateProductCatalogPDF(ctx context.Context, categoryI
 		file.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
 
 		// Write products
-		products, ok := pagination.Data.([]model.Product)
+		products, ok := pagination.Data.([]*response.ProductResponse)
 		if !ok {
 			return fmt.Errorf("unexpected data type in pagination")
 		}
@@ -124,13 +129,20 @@ func (s *ReportService) GenerateOrderInvoicePDF(ctx context.Context, orderID uin
 		// Order items
 		file.WriteString("Items:\n")
 		for _, item := range order.OrderItem {
+			// 组合商品只列出组合商品行
+			if item.ParentID > 0 {
+				continue
+			}
 			file.WriteString(fmt.Sprintf("%s - Qty: %d - Price: %.2f - Total: %.2f\n",
-				item.Name, item.Quantity, item.Price, float64(item.Quantity)*item.Price))
+				item.Name, item.Quantity, item.Price, float64(item.Quantity)*item.Price-item.PromotionDiscount))
 		}
 		file.WriteString("\n")
 
 		// Totals
 		file.WriteString(fmt.Sprintf("Subtotal: %.2f\n", order.TotalAmount))
+		if order.PromotionDiscount > 0 {
+			file.WriteString(fmt.Sprintf("Promotion Discount: -%.2f\n", order.PromotionDiscount))
+		}
 		file.WriteString(fmt.Sprintf("Total: %.2f\n", order.PaymentAmount))
 
 		return nil
@@ -155,7 +167,7 @@ func (s *ReportService) ExportProductsToCSV(ctx context.Context, categoryID *uin
 	// Get cached file or generate a new one
 	return minio.GetCachedFileWithTempFile(ctx, key, opts, func(tempPath string) error {
 		// Get products from category
-		pagination, err := s.productService.GetProducts(1, 1000, categoryID, nil, nil)
+		pagination, err := s.productService.GetProducts(1, 1000, categoryID, nil, nil, nil, 0)
 		if err != nil {
 			return err
 		}
@@ -171,7 +183,7 @@ func (s *ReportService) ExportProductsToCSV(ctx context.Context, categoryID *uin
 		file.WriteString("ID,Name,Price,Stock,Description,Category\n")
 
 		// Write products
-		products, ok := pagination.Data.([]model.Product)
+		products, ok := pagination.Data.([]*response.ProductResponse)
 		if !ok {
 			return fmt.Errorf("unexpected data type in pagination")
 		}
@@ -185,3 +197,140 @@ func (s *ReportService) ExportProductsToCSV(ctx context.Context, categoryID *uin
 		return nil
 	})
 }
+
+// GeneratePurchaseOrderPDF generates a PDF of a purchase order to send to the supplier (admin)
+func (s *ReportService) GeneratePurchaseOrderPDF(ctx context.Context, id uint64) (string, error) {
+	order, err := s.purchaseService.GetPurchaseOrder(id)
+	if err != nil {
+		return "", err
+	}
+
+	// Create a cache key based on the order and its receiving progress
+	key := fmt.Sprintf("purchase_order_%d_%d_%d", order.ID, order.UpdatedAt.Unix(), purchaseReceivedQty(order))
+
+	// Set cache options
+	opts := minio.DefaultCacheOptions()
+	opts.Prefix = "purchase-orders"
+	opts.ContentType = "application/pdf"
+	opts.TTL = 30 * 24 * time.Hour // 30 days
+
+	// Get cached file or generate a new one
+	return minio.GetCachedFileWithTempFile(ctx, key, opts, func(tempPath string) error {
+		// This simulates generating a PDF purchase order
+		// In a real application, you would use a PDF library like gofpdf
+		file, err := os.Create(tempPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		// Write header
+		file.WriteString("PURCHASE ORDER\n")
+		file.WriteString("==============\n\n")
+		file.WriteString(fmt.Sprintf("PO Number: %s\n", order.PONo))
+		file.WriteString(fmt.Sprintf("Date: %s\n", order.CreatedAt.Format("2006-01-02")))
+		file.WriteString(fmt.Sprintf("Status: %s\n", purchaseOrderStatusText(order.Status)))
+		if order.ExpectedAt != nil {
+			file.WriteString(fmt.Sprintf("Expected Arrival: %s\n", order.ExpectedAt.Format("2006-01-02")))
+		}
+		file.WriteString("\n")
+
+		// Supplier
+		if order.Supplier != nil {
+			file.WriteString("Supplier:\n")
+			file.WriteString(fmt.Sprintf("%s\n", order.Supplier.Name))
+			file.WriteString(fmt.Sprintf("%s %s\n", order.Supplier.Contact, order.Supplier.Phone))
+			file.WriteString(fmt.Sprintf("%s\n\n", order.Supplier.Address))
+		}
+
+		// Items
+		file.WriteString("Items:\n")
+		for _, item := range order.Items {
+			file.WriteString(fmt.Sprintf("%s - Qty: %d - Received: %d - Unit Cost: %.2f - Total: %.2f\n",
+				item.Name, item.Quantity, item.ReceivedQty, item.UnitCost, float64(item.Quantity)*item.UnitCost))
+		}
+		file.WriteString("\n")
+
+		// Totals
+		file.WriteString(fmt.Sprintf("Total: %.2f\n", order.TotalAmount))
+		if order.Remark != "" {
+			file.WriteString(fmt.Sprintf("Remark: %s\n", order.Remark))
+		}
+
+		return nil
+	})
+}
+
+// ExportPurchaseOrderToCSV exports the items of a purchase order to a CSV file (admin)
+func (s *ReportService) ExportPurchaseOrderToCSV(ctx context.Context, id uint64) (string, error) {
+	order, err := s.purchaseService.GetPurchaseOrder(id)
+	if err != nil {
+		return "", err
+	}
+
+	// Create a cache key based on the order and its receiving progress
+	key := fmt.Sprintf("purchase_order_export_%d_%d_%d", order.ID, order.UpdatedAt.Unix(), purchaseReceivedQty(order))
+
+	// Set cache options
+	opts := minio.DefaultCacheOptions()
+	opts.Prefix = "exports"
+	opts.ContentType = "text/csv"
+	opts.TTL = 24 * time.Hour
+
+	// Get cached file or generate a new one
+	return minio.GetCachedFileWithTempFile(ctx, key, opts, func(tempPath string) error {
+		file, err := os.Create(tempPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		// 商品名称和备注可能含逗号，使用csv包转义
+		writer := csv.NewWriter(file)
+		supplierName := ""
+		if order.Supplier != nil {
+			supplierName = order.Supplier.Name
+		}
+		expectedAt := ""
+		if order.ExpectedAt != nil {
+			expectedAt = order.ExpectedAt.Format("2006-01-02")
+		}
+
+		writer.Write([]string{"PO Number", "Supplier", "Status", "Expected Arrival", "Product ID", "Product",
+			"Quantity", "Received", "Unit Cost", "Total", "Shelf Life Days"})
+		for _, item := range order.Items {
+			writer.Write([]string{
+				order.PONo,
+				supplierName,
+				purchaseOrderStatusText(order.Status),
+				expectedAt,
+				strconv.FormatUint(item.ProductID, 10),
+				item.Name,
+				strconv.Itoa(item.Quantity),
+				strconv.Itoa(item.ReceivedQty),
+				fmt.Sprintf("%.2f", item.UnitCost),
+				fmt.Sprintf("%.2f", float64(item.Quantity)*item.UnitCost),
+				strconv.Itoa(item.ShelfLifeDays),
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+}
+
+// purchaseReceivedQty 采购单已到货的总数量，同一秒内多次到货时用于区分缓存
+func purchaseReceivedQty(order *model.PurchaseOrder) int {
+	var received int
+	for _, item := range order.Items {
+		received += item.ReceivedQty
+	}
+	return received
+}
+
+// purchaseOrderStatusText 采购单状态描述
+func purchaseOrderStatusText(status int) string {
+	if text, ok := model.PurchaseOrderStatusDesc[status]; ok {
+		return text
+	}
+	return strconv.Itoa(status)
+}
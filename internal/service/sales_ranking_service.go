@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/constant"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/redis"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	categoryutils "github.com/colinjuang/shop-go/internal/utils/category"
+	"gorm.io/gorm"
+)
+
+const (
+	// salesRankingScopeAll 全站排行范围，分类排行以分类ID为范围
+	salesRankingScopeAll = "all"
+	// salesRankingMaxHours 最长的排行窗口小时数，小时桶保留到移出该窗口
+	salesRankingMaxHours = 30 * 24
+	// salesRankingWindowTTL 窗口排行合并结果的缓存时间
+	salesRankingWindowTTL = time.Minute
+	// salesRankingRebuildInterval 从订单重建排行的周期
+	salesRankingRebuildInterval = 24 * time.Hour
+)
+
+// salesRankingWindows 排行窗口及包含的小时数
+var salesRankingWindows = map[string]int{
+	"24h": 24,
+	"7d":  7 * 24,
+	"30d": 30 * 24,
+}
+
+// salesIncrScript 增加商品在各范围小时桶中的销量。扣减时桶已过期则跳过，销量不大于0时移除商品
+var salesIncrScript = redis.NewScript(`
+local delta = tonumber(ARGV[2])
+for _, key in ipairs(KEYS) do
+  if delta > 0 or redis.call('EXISTS', key) == 1 then
+    local score = tonumber(redis.call('ZINCRBY', key, delta, ARGV[1]))
+    if score <= 0 then
+      redis.call('ZREM', key, ARGV[1])
+    end
+    redis.call('EXPIRE', key, ARGV[3])
+  end
+end
+return 1
+`)
+
+// salesWindowScript 合并窗口内的小时桶得到排行并短时缓存，返回销量最高的商品及销量
+var salesWindowScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+  redis.call('ZUNIONSTORE', KEYS[1], #KEYS - 1, unpack(KEYS, 2))
+  redis.call('EXPIRE', KEYS[1], ARGV[1])
+end
+return redis.call('ZREVRANGE', KEYS[1], 0, tonumber(ARGV[2]) - 1, 'WITHSCORES')
+`)
+
+// salesRebuildScript 用重建的销量替换一个范围内的全部小时桶，ARGV[1]为桶序号到商品和销量列表的JSON
+var salesRebuildScript = redis.NewScript(`
+local data = cjson.decode(ARGV[1])
+for i, key in ipairs(KEYS) do
+  redis.call('DEL', key)
+  local members = data[tostring(i)]
+  if members then
+    for j = 1, #members, 2 do
+      redis.call('ZADD', key, members[j + 1], members[j])
+    end
+    redis.call('EXPIRE', key, ARGV[2])
+  end
+end
+return 1
+`)
+
+// RankedProduct is a product in a sales ranking with its sales in the window
+type RankedProduct struct {
+	ProductID uint64
+	Sales     int
+}
+
+// salesChange 商品销量变化及所在的排行范围
+type salesChange struct {
+	productID uint64
+	scopes    []string
+	quantity  int
+}
+
+// SalesRankingService handles product sales counters and the rolling sales rankings kept in Redis
+type SalesRankingService struct {
+	salesRepo   *repository.SalesRepository
+	productRepo *repository.ProductRepository
+	redisClient *redis.Client
+}
+
+// NewSalesRankingService creates a new sales ranking service
+func NewSalesRankingService() *SalesRankingService {
+	server := server.GetServer()
+	return &SalesRankingService{
+		salesRepo:   repository.NewSalesRepository(server.DB),
+		productRepo: repository.NewProductRepository(server.DB),
+		redisClient: redis.GetClient(),
+	}
+}
+
+// GetRanking gets the best-selling products of the rolling window ("24h", "7d" or "30d"),
+// overall or within a category and its descendants (categoryID 0 for overall)
+func (s *SalesRankingService) GetRanking(ctx context.Context, window string, categoryID uint64, limit int) ([]RankedProduct, error) {
+	hours, ok := salesRankingWindows[window]
+	if !ok {
+		return nil, pkgerrors.ErrInvalidRankingWindow
+	}
+	scope := salesRankingScopeAll
+	if categoryID > 0 {
+		scope = strconv.FormatUint(categoryID, 10)
+	}
+
+	currentHour := time.Now().Unix() / 3600
+	keys := make([]string, 0, hours+1)
+	keys = append(keys, constant.SalesRankingWindow+window+":"+scope)
+	for hour := currentHour - int64(hours) + 1; hour <= currentHour; hour++ {
+		keys = append(keys, salesBucketKey(scope, hour))
+	}
+
+	result, err := s.redisClient.RunScript(ctx, salesWindowScript, keys, int(salesRankingWindowTTL.Seconds()), limit)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := result.([]interface{})
+	ranking := make([]RankedProduct, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		productID, err := strconv.ParseUint(fmt.Sprint(values[i]), 10, 64)
+		if err != nil {
+			continue
+		}
+		sales, err := strconv.ParseFloat(fmt.Sprint(values[i+1]), 64)
+		if err != nil || sales <= 0 {
+			continue
+		}
+		ranking = append(ranking, RankedProduct{ProductID: productID, Sales: int(sales)})
+	}
+	return ranking, nil
+}
+
+// ReconcileRankings rebuilds the rankings from paid orders when they were lost with Redis,
+// and at least once a day to correct updates that failed after an order was paid or refunded
+func (s *SalesRankingService) ReconcileRankings(ctx context.Context) error {
+	built, err := s.redisClient.Exists(ctx, constant.SalesRankingBuilt)
+	if err != nil {
+		return err
+	}
+	if built {
+		return nil
+	}
+	if err := s.RebuildRankings(ctx); err != nil {
+		return err
+	}
+	return s.redisClient.Set(ctx, constant.SalesRankingBuilt, time.Now().Unix(), salesRankingRebuildInterval)
+}
+
+// RebuildRankings rebuilds the hourly sales buckets of every ranking scope from order_items
+func (s *SalesRankingService) RebuildRankings(ctx context.Context) error {
+	currentHour := time.Now().Unix() / 3600
+	firstHour := currentHour - salesRankingMaxHours + 1
+	sales, err := s.salesRepo.GetHourlySales(time.Unix(firstHour*3600, 0))
+	if err != nil {
+		return err
+	}
+
+	productIDs := make([]uint64, 0)
+	seen := make(map[uint64]bool)
+	for _, sale := range sales {
+		if !seen[sale.ProductID] {
+			seen[sale.ProductID] = true
+			productIDs = append(productIDs, sale.ProductID)
+		}
+	}
+	products, err := s.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return err
+	}
+	productScopes := make(map[uint64][]string, len(products))
+	for _, product := range products {
+		productScopes[product.ID] = salesRankingScopes(product)
+	}
+
+	// 按范围和小时汇总，已删除的商品只计入全站排行
+	buckets := map[string]map[string][]interface{}{salesRankingScopeAll: {}}
+	for _, sale := range sales {
+		if sale.Hour < firstHour || sale.Hour > currentHour {
+			continue
+		}
+		scopes, ok := productScopes[sale.ProductID]
+		if !ok {
+			scopes = []string{salesRankingScopeAll}
+		}
+		index := strconv.FormatInt(sale.Hour-firstHour+1, 10)
+		for _, scope := range scopes {
+			if buckets[scope] == nil {
+				buckets[scope] = make(map[string][]interface{})
+			}
+			buckets[scope][index] = append(buckets[scope][index], strconv.FormatUint(sale.ProductID, 10), sale.Quantity)
+		}
+	}
+
+	// 没有销量的分类也要清空，避免保留Redis中过时的桶
+	categoryIDs, err := s.salesRepo.GetCategoryIDs()
+	if err != nil {
+		return err
+	}
+	for _, categoryID := range categoryIDs {
+		scope := strconv.FormatUint(categoryID, 10)
+		if buckets[scope] == nil {
+			buckets[scope] = make(map[string][]interface{})
+		}
+	}
+
+	ttl := int((salesRankingMaxHours + 1) * time.Hour / time.Second)
+	for scope, data := range buckets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		keys := make([]string, 0, salesRankingMaxHours)
+		for hour := firstHour; hour <= currentHour; hour++ {
+			keys = append(keys, salesBucketKey(scope, hour))
+		}
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if _, err := s.redisClient.RunScript(ctx, salesRebuildScript, keys, string(payload), ttl); err != nil {
+			return err
+		}
+		for window := range salesRankingWindows {
+			if err := s.redisClient.Delete(ctx, constant.SalesRankingWindow+window+":"+scope); err != nil {
+				return err
+			}
+		}
+	}
+
+	logger.Infof("Rebuilt sales rankings of %d scopes from %d hourly sales", len(buckets), len(sales))
+	return nil
+}
+
+// orderSaleQuantities 统计订单未退款的商品数量，组合商品按组合商品本身计数
+func orderSaleQuantities(items []model.OrderItem) map[uint64]int {
+	quantities := make(map[uint64]int)
+	for _, item := range items {
+		if item.ParentID > 0 || item.Quantity <= item.RefundedQty {
+			continue
+		}
+		quantities[item.ProductID] += item.Quantity - item.RefundedQty
+	}
+	return quantities
+}
+
+// recordOrderSales 在订单事务中更新商品销量，退款时数量为负。返回的销量变化在事务提交后用于更新排行
+func recordOrderSales(tx *gorm.DB, quantities map[uint64]int) ([]salesChange, error) {
+	if len(quantities) == 0 {
+		return nil, nil
+	}
+
+	productIDs := make([]uint64, 0, len(quantities))
+	for productID := range quantities {
+		productIDs = append(productIDs, productID)
+	}
+	products, err := repository.NewProductRepository(tx).GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	salesRepo := repository.NewSalesRepository(tx)
+	changes := make([]salesChange, 0, len(products))
+	for _, product := range products {
+		quantity := quantities[product.ID]
+		if quantity == 0 {
+			continue
+		}
+		if err := salesRepo.IncreaseSaleCount(product.ID, quantity); err != nil {
+			return nil, err
+		}
+		changes = append(changes, salesChange{
+			productID: product.ID,
+			scopes:    salesRankingScopes(product),
+			quantity:  quantity,
+		})
+	}
+	return changes, nil
+}
+
+// updateSalesRanking 将销量变化计入支付时间所在小时的排行桶，失败只记录日志，由对账任务重建修正
+func updateSalesRanking(ctx context.Context, paidAt time.Time, changes []salesChange) {
+	hour := paidAt.Unix() / 3600
+	ttl := time.Until(time.Unix((hour+salesRankingMaxHours+1)*3600, 0))
+	if ttl <= 0 {
+		// 支付时间已移出所有排行窗口
+		return
+	}
+
+	client := redis.GetClient()
+	for _, change := range changes {
+		keys := make([]string, len(change.scopes))
+		for i, scope := range change.scopes {
+			keys[i] = salesBucketKey(scope, hour)
+		}
+		if _, err := client.RunScript(ctx, salesIncrScript, keys, change.productID, change.quantity, int(ttl.Seconds())); err != nil {
+			logger.Warnf("Failed to update sales ranking of product %d: %v", change.productID, err)
+		}
+	}
+}
+
+// salesRankingScopes 商品所在的排行范围：全站、所属分类及其各级祖先分类
+func salesRankingScopes(product model.Product) []string {
+	scopes := []string{salesRankingScopeAll}
+	for _, categoryID := range categoryutils.PathIDs(product.CategoryPath) {
+		scopes = append(scopes, strconv.FormatUint(categoryID, 10))
+	}
+	return scopes
+}
+
+// salesBucketKey 排行范围某小时的销量桶键
+func salesBucketKey(scope string, hour int64) string {
+	return fmt.Sprintf("%s%s:%d", constant.SalesRankingBucket, scope, hour)
+}
@@ -0,0 +1,517 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/colinjuang/shop-go/internal/app/request"
+	"github.com/colinjuang/shop-go/internal/app/response"
+	"github.com/colinjuang/shop-go/internal/config"
+	"github.com/colinjuang/shop-go/internal/model"
+	pkgerrors "github.com/colinjuang/shop-go/internal/pkg/errors"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
+	"github.com/colinjuang/shop-go/internal/pkg/notify"
+	"github.com/colinjuang/shop-go/internal/pkg/payment"
+	"github.com/colinjuang/shop-go/internal/repository"
+	"github.com/colinjuang/shop-go/internal/server"
+	utils "github.com/colinjuang/shop-go/internal/utils/order"
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+	"gorm.io/gorm"
+)
+
+// dateLayout 配送日期格式
+const dateLayout = "2006-01-02"
+
+// SubscriptionService handles recurring subscriptions. A background job generates one child order
+// per delivery through the order service, so stock and order status follow the normal order flow.
+// Prepaid plans pay all deliveries up front at a locked price and each child order is paid from the
+// remaining balance; other plans generate pending orders that the user pays per delivery.
+type SubscriptionService struct {
+	db               *gorm.DB
+	subscriptionRepo *repository.SubscriptionRepository
+	productRepo      *repository.ProductRepository
+	addressRepo      *repository.AddressRepository
+	orderService     *OrderService
+	memberService    *MemberService
+	pricingService   *PricingService
+	config           config.SubscriptionConfig
+}
+
+// NewSubscriptionService creates a new subscription service
+func NewSubscriptionService() *SubscriptionService {
+	server := server.GetServer()
+	return &SubscriptionService{
+		db:               server.DB,
+		subscriptionRepo: repository.NewSubscriptionRepository(server.DB),
+		productRepo:      repository.NewProductRepository(server.DB),
+		addressRepo:      repository.NewAddressRepository(server.DB),
+		orderService:     NewOrderService(),
+		memberService:    NewMemberService(),
+		pricingService:   NewPricingService(),
+		config:           server.GetConfig().Subscription,
+	}
+}
+
+// CreateSubscription creates a subscription plan. The per-delivery amount is quoted with the
+// user's member price and the shipping fee; prepaid plans lock it and wait for payment.
+func (s *SubscriptionService) CreateSubscription(userID uint64, req request.SubscriptionRequest) (*response.SubscriptionResponse, error) {
+	address, err := s.addressRepo.GetAddressByID(req.AddressID)
+	if err != nil || address.UserID != userID {
+		return nil, pkgerrors.ErrAddressNotFound
+	}
+
+	tier, err := s.memberService.GetUserTier(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &model.Subscription{
+		SubscriptionNo:  utils.GenerateSubscriptionNo(userID),
+		UserID:          userID,
+		Quantity:        req.Quantity,
+		Frequency:       req.Frequency,
+		Weekday:         req.Weekday,
+		DeliverySlot:    req.DeliverySlot,
+		AddressID:       req.AddressID,
+		Blessing:        req.Blessing,
+		Remark:          req.Remark,
+		TotalDeliveries: req.TotalDeliveries,
+		Prepaid:         req.Prepaid,
+		Status:          model.SubscriptionStatusActive,
+	}
+
+	if req.ProductID > 0 {
+		product, err := s.productRepo.GetProductByID(req.ProductID)
+		if err != nil || product.Status != 1 {
+			return nil, pkgerrors.ErrProductNotFound
+		}
+		unitPrices, err := s.memberService.UnitPrices([]model.Product{*product}, tier)
+		if err != nil {
+			return nil, err
+		}
+		subscription.ProductID = product.ID
+		subscription.UnitPrice = unitPrices[product.ID]
+	} else {
+		// 花艺师自选按预算定价，每次配送时从分类中挑选不超过预算的商品
+		if req.Budget <= 0 {
+			return nil, pkgerrors.ErrSubscriptionInvalidPlan
+		}
+		subscription.CategoryID = req.CategoryID
+		subscription.Budget = priceutils.Round(req.Budget)
+		subscription.UnitPrice = subscription.Budget
+	}
+
+	subtotal := priceutils.Round(subscription.UnitPrice * float64(req.Quantity))
+	subscription.DeliveryAmount = priceutils.Round(subtotal + s.pricingService.shippingFee(subtotal, tier))
+	subscription.NextDeliveryDate = s.firstDeliveryDate(req.Weekday)
+
+	if req.Prepaid {
+		subscription.PaidAmount = priceutils.Round(subscription.DeliveryAmount * float64(req.TotalDeliveries))
+		subscription.Status = model.SubscriptionStatusPendingPayment
+	}
+
+	if err := s.subscriptionRepo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	return s.toSubscriptionResponse(subscription, nil)
+}
+
+// GetUserSubscriptions gets the subscriptions of a user with pagination
+func (s *SubscriptionService) GetUserSubscriptions(userID uint64, page, pageSize int) (*response.Pagination, error) {
+	subscriptions, total, err := s.subscriptionRepo.GetUserSubscriptions(userID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*response.SubscriptionResponse, len(subscriptions))
+	for i := range subscriptions {
+		if responses[i], err = s.toSubscriptionResponse(&subscriptions[i], nil); err != nil {
+			return nil, err
+		}
+	}
+
+	pagination := response.NewPagination(total, page, pageSize, responses)
+	return &pagination, nil
+}
+
+// GetSubscription gets a subscription of a user with its deliveries
+func (s *SubscriptionService) GetSubscription(userID, id uint64) (*response.SubscriptionResponse, error) {
+	subscription, err := s.subscriptionRepo.GetSubscriptionByIDAndUserID(id, userID)
+	if err != nil {
+		return nil, pkgerrors.ErrSubscriptionNotFound
+	}
+
+	deliveries, err := s.subscriptionRepo.GetDeliveries(subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toSubscriptionResponse(subscription, deliveries)
+}
+
+// PaySubscription marks the prepayment of a prepaid plan as paid and starts its deliveries
+func (s *SubscriptionService) PaySubscription(userID, id uint64) (*response.SubscriptionResponse, error) {
+	subscription, err := s.subscriptionRepo.GetSubscriptionByIDAndUserID(id, userID)
+	if err != nil {
+		return nil, pkgerrors.ErrSubscriptionNotFound
+	}
+	if !subscription.Prepaid {
+		return nil, pkgerrors.ErrSubscriptionNotPrepaid
+	}
+
+	// 创建后迟迟未支付时，首次配送顺延到最近可配送的日期
+	next := maxDate(subscription.NextDeliveryDate, s.firstDeliveryDate(subscription.Weekday))
+	updated, err := s.subscriptionRepo.UpdateSubscriptionStatusFrom(subscription.ID,
+		[]int{model.SubscriptionStatusPendingPayment},
+		map[string]interface{}{
+			"status":             model.SubscriptionStatusActive,
+			"balance":            subscription.PaidAmount,
+			"paid_at":            time.Now(),
+			"next_delivery_date": next,
+		})
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, pkgerrors.ErrSubscriptionStatusInvalid
+	}
+
+	return s.GetSubscription(userID, id)
+}
+
+// PauseSubscription pauses an active subscription; no orders are generated while paused
+func (s *SubscriptionService) PauseSubscription(userID, id uint64) error {
+	subscription, err := s.subscriptionRepo.GetSubscriptionByIDAndUserID(id, userID)
+	if err != nil {
+		return pkgerrors.ErrSubscriptionNotFound
+	}
+
+	updated, err := s.subscriptionRepo.UpdateSubscriptionStatusFrom(subscription.ID,
+		[]int{model.SubscriptionStatusActive},
+		map[string]interface{}{"status": model.SubscriptionStatusPaused})
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return pkgerrors.ErrSubscriptionStatusInvalid
+	}
+	return nil
+}
+
+// ResumeSubscription resumes a paused subscription. Deliveries missed while paused are not made up;
+// the next delivery moves to the nearest delivery day that can still be prepared.
+func (s *SubscriptionService) ResumeSubscription(userID, id uint64) error {
+	subscription, err := s.subscriptionRepo.GetSubscriptionByIDAndUserID(id, userID)
+	if err != nil {
+		return pkgerrors.ErrSubscriptionNotFound
+	}
+
+	updated, err := s.subscriptionRepo.UpdateSubscriptionStatusFrom(subscription.ID,
+		[]int{model.SubscriptionStatusPaused},
+		map[string]interface{}{
+			"status":             model.SubscriptionStatusActive,
+			"next_delivery_date": maxDate(subscription.NextDeliveryDate, s.firstDeliveryDate(subscription.Weekday)),
+		})
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return pkgerrors.ErrSubscriptionStatusInvalid
+	}
+	return nil
+}
+
+// SkipNextDelivery postpones the next delivery by one interval without using up a delivery
+func (s *SubscriptionService) SkipNextDelivery(userID, id uint64) error {
+	subscription, err := s.subscriptionRepo.GetSubscriptionByIDAndUserID(id, userID)
+	if err != nil {
+		return pkgerrors.ErrSubscriptionNotFound
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		subscriptionRepo := repository.NewSubscriptionRepository(tx)
+		locked, err := subscriptionRepo.LockSubscription(subscription.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != model.SubscriptionStatusActive && locked.Status != model.SubscriptionStatusPaused {
+			return pkgerrors.ErrSubscriptionStatusInvalid
+		}
+
+		next := locked.NextDeliveryDate.AddDate(0, 0, deliveryInterval(locked.Frequency))
+		return subscriptionRepo.UpdateSubscription(locked.ID, map[string]interface{}{"next_delivery_date": next})
+	})
+}
+
+// CancelSubscription cancels a subscription and refunds the unused prepaid balance.
+// Orders already generated are not affected.
+func (s *SubscriptionService) CancelSubscription(userID, id uint64) error {
+	subscription, err := s.subscriptionRepo.GetSubscriptionByIDAndUserID(id, userID)
+	if err != nil {
+		return pkgerrors.ErrSubscriptionNotFound
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		subscriptionRepo := repository.NewSubscriptionRepository(tx)
+		locked, err := subscriptionRepo.LockSubscription(subscription.ID)
+		if err != nil {
+			return err
+		}
+		switch locked.Status {
+		case model.SubscriptionStatusPendingPayment, model.SubscriptionStatusActive, model.SubscriptionStatusPaused:
+		default:
+			return pkgerrors.ErrSubscriptionStatusInvalid
+		}
+
+		err = subscriptionRepo.UpdateSubscription(locked.ID, map[string]interface{}{
+			"status":  model.SubscriptionStatusCancelled,
+			"balance": 0,
+		})
+		if err != nil {
+			return err
+		}
+
+		if locked.Balance <= 0 {
+			return nil
+		}
+		// 最后调用支付退款，失败时回滚保持原状态，可重试
+		return payment.Refund(context.Background(), payment.RefundRequest{
+			OrderNo:      locked.SubscriptionNo,
+			RefundNo:     "RF" + locked.SubscriptionNo,
+			TotalAmount:  locked.PaidAmount,
+			RefundAmount: locked.Balance,
+			Reason:       "取消周期订阅，退还剩余预付款",
+		})
+	})
+}
+
+// GenerateOrders generates the child order of every active subscription whose next delivery falls
+// within the lead days. Failed subscriptions are logged and retried on the next run.
+func (s *SubscriptionService) GenerateOrders(ctx context.Context) error {
+	until := today().AddDate(0, 0, s.config.LeadDays)
+	subscriptions, err := s.subscriptionRepo.GetDueSubscriptions(until, 200)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.generateOrder(ctx, subscription); err != nil {
+			logger.Warnf("Failed to generate order of subscription %d: %v", subscription.ID, err)
+		}
+	}
+	return nil
+}
+
+// generateOrder 生成订阅的下一次配送订单。订单保存的事务中锁定订阅并写入配送记录，
+// 配送记录按（订阅, 次数）唯一，保证每次配送只生成一个订单
+func (s *SubscriptionService) generateOrder(ctx context.Context, subscription model.Subscription) error {
+	productID := subscription.ProductID
+	if productID == 0 {
+		product, err := s.productRepo.GetBestProductWithinBudget(subscription.CategoryID, subscription.Budget, subscription.Quantity)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return fmt.Errorf("no product within budget %.2f in category %d", subscription.Budget, subscription.CategoryID)
+		}
+		productID = product.ID
+	}
+
+	// 预付和花艺师自选按锁定单价下单，按次支付的指定商品按下单时的价格
+	var dealPrice float64
+	if subscription.Prepaid || subscription.ProductID == 0 {
+		dealPrice = subscription.UnitPrice
+	}
+
+	sequence := subscription.DeliveredCount + 1
+	deliveryDate := subscription.NextDeliveryDate
+	remark := fmt.Sprintf("周期订阅%s 第%d/%d次配送，配送日期 %s %s", subscription.SubscriptionNo,
+		sequence, subscription.TotalDeliveries, deliveryDate.Format(dateLayout), subscription.DeliverySlot)
+	if subscription.Remark != "" {
+		remark += "；" + subscription.Remark
+	}
+
+	params := DealOrderParams{
+		ProductID:    productID,
+		DealPrice:    dealPrice,
+		AddressID:    subscription.AddressID,
+		Quantity:     subscription.Quantity,
+		Blessing:     subscription.Blessing,
+		Remark:       remark,
+		DeliveryDate: &deliveryDate,
+	}
+
+	var sales []salesChange
+	order, err := s.orderService.CreateDealOrder(subscription.UserID, params, func(tx *gorm.DB, order *model.Order) error {
+		subscriptionRepo := repository.NewSubscriptionRepository(tx)
+		locked, err := subscriptionRepo.LockSubscription(subscription.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != model.SubscriptionStatusActive || locked.DeliveredCount != subscription.DeliveredCount {
+			return pkgerrors.ErrSubscriptionStatusInvalid
+		}
+
+		delivery := &model.SubscriptionDelivery{
+			SubscriptionID: locked.ID,
+			Sequence:       sequence,
+			DeliveryDate:   deliveryDate,
+			OrderID:        order.ID,
+		}
+
+		updates := map[string]interface{}{
+			"delivered_count":    sequence,
+			"next_delivery_date": deliveryDate.AddDate(0, 0, deliveryInterval(locked.Frequency)),
+		}
+		if sequence >= locked.TotalDeliveries {
+			updates["status"] = model.SubscriptionStatusCompleted
+		}
+
+		if locked.Prepaid {
+			// 从预付款中扣除本次配送金额，最后一次扣除全部余额以消除分摊误差
+			delivery.Amount = locked.DeliveryAmount
+			if sequence >= locked.TotalDeliveries || delivery.Amount > locked.Balance {
+				delivery.Amount = locked.Balance
+			}
+			updates["balance"] = priceutils.Round(locked.Balance - delivery.Amount)
+
+			sales, err = payFromSubscription(tx, order, delivery.Amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := subscriptionRepo.CreateDelivery(delivery); err != nil {
+			return err
+		}
+		return subscriptionRepo.UpdateSubscription(locked.ID, updates)
+	})
+	if err != nil {
+		return err
+	}
+	updateSalesRanking(ctx, time.Now(), sales)
+
+	title := "订阅配送订单已生成"
+	content := fmt.Sprintf("您的周期订阅第%d次配送订单已生成，将于 %s 配送", sequence, deliveryDate.Format(dateLayout))
+	if !subscription.Prepaid {
+		title = "订阅配送订单待支付"
+		content = fmt.Sprintf("您的周期订阅第%d次配送订单已生成，请在配送日 %s 前完成支付", sequence, deliveryDate.Format(dateLayout))
+	}
+	notify.Send(ctx, notify.Message{
+		UserID:  subscription.UserID,
+		Type:    notify.TypeSubscriptionOrder,
+		Title:   title,
+		Content: content,
+		Data: map[string]string{
+			"subscriptionID": strconv.FormatUint(subscription.ID, 10),
+			"orderID":        strconv.FormatUint(order.ID, 10),
+		},
+	})
+	return nil
+}
+
+// payFromSubscription 以订阅预付款支付配送订单：订单金额按锁定的配送金额结算，运费随之调整，并计入销量
+func payFromSubscription(tx *gorm.DB, order *model.Order, amount float64) ([]salesChange, error) {
+	orderRepo := repository.NewOrderRepository(tx)
+	subtotal := order.PaymentAmount - order.ShippingFee
+	order.ShippingFee = priceutils.Round(max(amount-subtotal, 0))
+	order.PaymentAmount = amount
+	err := orderRepo.UpdateOrder(order.ID, map[string]interface{}{
+		"shipping_fee":   order.ShippingFee,
+		"payment_amount": order.PaymentAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := orderRepo.UpdateOrderStatusFrom(order.ID, []int{model.OrderStatusPending}, model.OrderStatusPaid)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, pkgerrors.ErrOrderStatusInvalid
+	}
+
+	items, err := repository.NewOrderItemRepository(tx).GetOrderItemsByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	return recordOrderSales(tx, orderSaleQuantities(items))
+}
+
+// firstDeliveryDate 计算最近一个可配送的配送日：不早于今天加提前生成天数
+func (s *SubscriptionService) firstDeliveryDate(weekday int) time.Time {
+	from := today().AddDate(0, 0, s.config.LeadDays)
+	days := (weekday - int(from.Weekday()) + 7) % 7
+	return from.AddDate(0, 0, days)
+}
+
+// toSubscriptionResponse 转换订阅响应
+func (s *SubscriptionService) toSubscriptionResponse(subscription *model.Subscription, deliveries []model.SubscriptionDelivery) (*response.SubscriptionResponse, error) {
+	resp := &response.SubscriptionResponse{
+		ID:               subscription.ID,
+		SubscriptionNo:   subscription.SubscriptionNo,
+		ProductID:        subscription.ProductID,
+		CategoryID:       subscription.CategoryID,
+		Budget:           subscription.Budget,
+		Quantity:         subscription.Quantity,
+		Frequency:        subscription.Frequency,
+		Weekday:          subscription.Weekday,
+		DeliverySlot:     subscription.DeliverySlot,
+		AddressID:        subscription.AddressID,
+		TotalDeliveries:  subscription.TotalDeliveries,
+		DeliveredCount:   subscription.DeliveredCount,
+		NextDeliveryDate: subscription.NextDeliveryDate.Format(dateLayout),
+		Prepaid:          subscription.Prepaid,
+		DeliveryAmount:   subscription.DeliveryAmount,
+		PaidAmount:       subscription.PaidAmount,
+		Balance:          subscription.Balance,
+		Status:           subscription.Status,
+		CreatedAt:        subscription.CreatedAt,
+	}
+
+	if subscription.ProductID > 0 {
+		product, err := s.productRepo.GetProductByID(subscription.ProductID)
+		if err == nil {
+			resp.ProductName = product.Name
+			resp.ImageUrl = product.ImageUrl
+		}
+	}
+
+	for _, delivery := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, response.SubscriptionDeliveryResponse{
+			Sequence:     delivery.Sequence,
+			DeliveryDate: delivery.DeliveryDate.Format(dateLayout),
+			OrderID:      delivery.OrderID,
+			Amount:       delivery.Amount,
+		})
+	}
+	return resp, nil
+}
+
+// deliveryInterval 配送间隔天数
+func deliveryInterval(frequency string) int {
+	if frequency == model.SubscriptionFrequencyBiweekly {
+		return 14
+	}
+	return 7
+}
+
+// today 当天零点
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// maxDate 返回较晚的日期
+func maxDate(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -66,6 +66,37 @@ func (s *UploadService) UploadFile(file *multipart.FileHeader) (string, error) {
 	return s.minioClient.GetFileURL(objectName), nil
 }
 
+// UploadMedia uploads an image or a short mp4 video for product galleries and detail content to MinIO,
+// returning the object name. There is no local fallback, since unused objects are garbage-collected from MinIO.
+func (s *UploadService) UploadMedia(file *multipart.FileHeader) (string, error) {
+	cfg := config.GetConfig()
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	maxSize := cfg.Upload.MaxSize
+	switch ext {
+	case ".jpg", ".jpeg", ".png", ".gif":
+	case ".mp4":
+		maxSize = cfg.Upload.MaxVideoSize
+	default:
+		return "", errors.New("only image and mp4 video files are allowed")
+	}
+	if maxSize > 0 && file.Size > maxSize {
+		return "", fmt.Errorf("file size exceeds the limit: %d bytes", maxSize)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	objectName := fmt.Sprintf("products/%d%s", time.Now().UnixNano(), ext)
+	if err := s.minioClient.UploadFile(context.Background(), objectName, src, getMimeType(ext)); err != nil {
+		return "", err
+	}
+	return objectName, nil
+}
+
 // uploadToLocalStorage uploads a file to local storage (fallback method)
 func (s *UploadService) uploadToLocalStorage(file *multipart.FileHeader, fileName, savePath string) (string, error) {
 	// Create save path if not exists
@@ -153,6 +184,8 @@ func getMimeType(ext string) string {
 		return "image/png"
 	case ".gif":
 		return "image/gif"
+	case ".mp4":
+		return "video/mp4"
 	default:
 		return "application/octet-stream"
 	}
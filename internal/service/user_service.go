@@ -53,6 +53,7 @@ func (s *UserService) LoginWithWechat(openID, nickname, avatar string, gender in
 	// Generate JWT token
 	token, err := middleware.GenerateToken(middleware.UserClaim{
 		UserID: user.ID,
+		Role:   user.Role,
 	})
 	if err != nil {
 		return "", err
@@ -132,6 +133,7 @@ func (s *UserService) Login(username, password string) (string, error) {
 	// 生成token
 	token, err := middleware.GenerateToken(middleware.UserClaim{
 		UserID: user.ID,
+		Role:   user.Role,
 	})
 	if err != nil {
 		return "", err
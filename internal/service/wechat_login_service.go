@@ -5,6 +5,7 @@ import (
 
 	"github.com/colinjuang/shop-go/internal/config"
 	"github.com/colinjuang/shop-go/internal/model"
+	"github.com/colinjuang/shop-go/internal/pkg/logger"
 	"github.com/colinjuang/shop-go/internal/repository"
 	"github.com/colinjuang/shop-go/internal/server"
 	"github.com/golang-jwt/jwt/v4"
@@ -14,19 +15,23 @@ import (
 )
 
 type WechatLoginService struct {
-	config   *config.Config
-	userRepo *repository.UserRepository
+	config              *config.Config
+	userRepo            *repository.UserRepository
+	distributionService *DistributionService
 }
 
 func NewWechatLoginService() *WechatLoginService {
 	server := server.GetServer()
 	return &WechatLoginService{
-		config:   config.GetConfig(),
-		userRepo: repository.NewUserRepository(server.DB),
+		config:              config.GetConfig(),
+		userRepo:            repository.NewUserRepository(server.DB),
+		distributionService: NewDistributionService(),
 	}
 }
 
-func (s *WechatLoginService) WechatMiniLogin(code string) (string, error) {
+// WechatMiniLogin logs in with a mini program code. scene is the scene of the shared
+// mini program code the user entered from, used to bind the referrer at first login.
+func (s *WechatLoginService) WechatMiniLogin(code, scene string) (string, error) {
 	// 初始化小程序
 	miniProgram := wechat.NewWechat().GetMiniProgram(&miniConfig.Config{
 		AppID:     s.config.Wechat.AppID,
@@ -55,6 +60,13 @@ func (s *WechatLoginService) WechatMiniLogin(code string) (string, error) {
 		if err != nil {
 			return "", err
 		}
+
+		// 首次登录时绑定推荐人，绑定失败不影响登录
+		if scene != "" {
+			if err := s.distributionService.BindReferral(user.ID, scene); err != nil {
+				logger.Warnf("Failed to bind referrer of user %d with scene %q: %v", user.ID, scene, err)
+			}
+		}
 	}
 
 	// 生成token
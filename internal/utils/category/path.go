@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RootPath 一级分类的父路径
+const RootPath = "/"
+
+// ChildPath 子分类的路径：父路径加上子分类ID，如 ChildPath("/1/5/", 12) = "/1/5/12/"
+func ChildPath(parentPath string, id uint64) string {
+	if parentPath == "" {
+		parentPath = RootPath
+	}
+	return parentPath + strconv.FormatUint(id, 10) + "/"
+}
+
+// PathIDs 路径中从一级分类到分类本身的ID，格式错误的部分被忽略
+func PathIDs(path string) []uint64 {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	ids := make([]uint64, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// IsWithin 路径是否为祖先路径本身或其后代
+func IsWithin(path, ancestorPath string) bool {
+	return ancestorPath != "" && strings.HasPrefix(path, ancestorPath)
+}
+
+// Level 路径对应的分类层级，一级分类为1
+func Level(path string) int {
+	return len(PathIDs(path))
+}
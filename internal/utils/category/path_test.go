@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChildPath(t *testing.T) {
+	if got := ChildPath(RootPath, 3); got != "/3/" {
+		t.Errorf("期望/3/，实际%s", got)
+	}
+	if got := ChildPath("/1/5/", 12); got != "/1/5/12/" {
+		t.Errorf("期望/1/5/12/，实际%s", got)
+	}
+	// 父路径为空时视为一级分类
+	if got := ChildPath("", 7); got != "/7/" {
+		t.Errorf("期望/7/，实际%s", got)
+	}
+}
+
+func TestPathIDs(t *testing.T) {
+	if got := PathIDs("/1/5/12/"); !reflect.DeepEqual(got, []uint64{1, 5, 12}) {
+		t.Errorf("期望[1 5 12]，实际%v", got)
+	}
+	if got := PathIDs(""); len(got) != 0 {
+		t.Errorf("期望空，实际%v", got)
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	cases := []struct {
+		path     string
+		ancestor string
+		expected bool
+	}{
+		{"/1/5/12/", "/1/5/", true},
+		{"/1/5/", "/1/5/", true},
+		// 分类ID前缀相同但不是后代
+		{"/1/50/", "/1/5/", false},
+		{"/2/", "/1/", false},
+		{"/1/", "", false},
+	}
+	for _, c := range cases {
+		if got := IsWithin(c.path, c.ancestor); got != c.expected {
+			t.Errorf("IsWithin(%s, %s) 期望%v，实际%v", c.path, c.ancestor, c.expected, got)
+		}
+	}
+}
+
+func TestLevel(t *testing.T) {
+	if got := Level("/1/5/12/"); got != 3 {
+		t.Errorf("期望3，实际%d", got)
+	}
+}
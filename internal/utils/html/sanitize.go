@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"html"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags 富文本允许的标签，其余标签去掉但保留其中的文字
+var allowedTags = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Br:         true,
+	atom.Strong:     true,
+	atom.B:          true,
+	atom.Em:         true,
+	atom.I:          true,
+	atom.U:          true,
+	atom.Span:       true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Li:         true,
+	atom.Blockquote: true,
+}
+
+// droppedTags 连同内容一起去掉的标签
+var droppedTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Textarea: true,
+	atom.Select:   true,
+	atom.Svg:      true,
+	atom.Math:     true,
+}
+
+// Sanitize 按白名单清理富文本HTML：只保留允许的标签且去掉全部属性，脚本等危险标签连同内容删除，
+// 文字重新转义，未闭合的标签自动闭合
+func Sanitize(input string) string {
+	var b strings.Builder
+	var open []atom.Atom
+	skipDepth := 0
+
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(input))
+	for {
+		tt := tokenizer.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		switch tt {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if droppedTags[token.DataAtom] {
+				if tt == nethtml.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 || !allowedTags[token.DataAtom] {
+				continue
+			}
+			if token.DataAtom == atom.Br {
+				b.WriteString("<br/>")
+				continue
+			}
+			if tt == nethtml.SelfClosingTagToken {
+				continue
+			}
+			b.WriteString("<" + token.DataAtom.String() + ">")
+			open = append(open, token.DataAtom)
+		case nethtml.EndTagToken:
+			if droppedTags[token.DataAtom] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 || !allowedTags[token.DataAtom] {
+				continue
+			}
+			// 只闭合已打开的标签，同时闭合其内部未闭合的标签
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.DataAtom {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j].String() + ">")
+				}
+				open = open[:i]
+				break
+			}
+		case nethtml.TextToken:
+			if skipDepth == 0 {
+				b.WriteString(html.EscapeString(token.Data))
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i].String() + ">")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"允许的标签", "<p>红玫瑰<strong>11枝</strong></p>", "<p>红玫瑰<strong>11枝</strong></p>"},
+		{"去掉属性", `<p style="color:red" onclick="alert(1)">花语</p>`, "<p>花语</p>"},
+		{"删除脚本及内容", "<p>a</p><script>alert(1)</script><p>b</p>", "<p>a</p><p>b</p>"},
+		{"去掉不允许的标签保留文字", `<div><a href="javascript:alert(1)">链接</a></div>`, "链接"},
+		{"去掉图片", `<p><img src=x onerror=alert(1)>图</p>`, "<p>图</p>"},
+		{"转义文字", "<p>1 < 2 & 3</p>", "<p>1 &lt; 2 &amp; 3</p>"},
+		{"自动闭合", "<p><em>未闭合", "<p><em>未闭合</em></p>"},
+		{"忽略多余的结束标签", "</li><p>a</p></ul>", "<p>a</p>"},
+		{"换行", "第一行<br>第二行<br/>", "第一行<br/>第二行<br/>"},
+		{"嵌套的危险标签", "<style><script>x</script>y</style>z", "z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.input); got != tt.expected {
+				t.Errorf("期望%q，实际%q", tt.expected, got)
+			}
+		})
+	}
+}
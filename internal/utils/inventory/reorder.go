@@ -0,0 +1,26 @@
+package utils
+
+import "math"
+
+// DailySales 统计窗口内的日均销量
+func DailySales(sold, windowDays int) float64 {
+	if windowDays <= 0 || sold <= 0 {
+		return 0
+	}
+	return float64(sold) / float64(windowDays)
+}
+
+// ReorderQuantity 建议补货数量：按日均销量备足到货前及到货后 coverDays 天的需求，
+// 扣除可售库存和已下单未到货的数量，向上取整，不足时返回0
+func ReorderQuantity(dailySales float64, leadDays, coverDays, available, incoming int) int {
+	if dailySales <= 0 {
+		return 0
+	}
+	demand := dailySales * float64(leadDays+coverDays)
+	shortage := demand - float64(available+incoming)
+	if shortage <= 0 {
+		return 0
+	}
+	// 避免浮点误差把整数需求向上多取一件
+	return int(math.Ceil(shortage - 1e-9))
+}
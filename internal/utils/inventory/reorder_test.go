@@ -0,0 +1,36 @@
+package utils
+
+import "testing"
+
+func TestDailySales(t *testing.T) {
+	if got := DailySales(28, 14); got != 2 {
+		t.Errorf("期望2，实际%v", got)
+	}
+	if got := DailySales(10, 0); got != 0 {
+		t.Errorf("期望0，实际%v", got)
+	}
+}
+
+func TestReorderQuantity(t *testing.T) {
+	cases := []struct {
+		name      string
+		daily     float64
+		lead      int
+		cover     int
+		available int
+		incoming  int
+		expected  int
+	}{
+		{"库存不足", 2, 2, 7, 5, 0, 13},
+		{"扣除在途", 2, 2, 7, 5, 10, 3},
+		{"库存充足", 2, 2, 7, 20, 0, 0},
+		{"向上取整", 1.5, 1, 2, 2, 0, 3},
+		{"没有销量", 0, 2, 7, 0, 0, 0},
+		{"整数需求不多取", 0.1, 3, 7, 0, 0, 1},
+	}
+	for _, c := range cases {
+		if got := ReorderQuantity(c.daily, c.lead, c.cover, c.available, c.incoming); got != c.expected {
+			t.Errorf("%s：期望%d，实际%d", c.name, c.expected, got)
+		}
+	}
+}
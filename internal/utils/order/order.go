@@ -9,6 +9,10 @@ import (
 const (
 	// 订单号前缀
 	OrderPrefix = "ORD"
+	// 订阅单号前缀
+	SubscriptionPrefix = "SUB"
+	// 采购单号前缀
+	PurchaseOrderPrefix = "PUR"
 	// 订单号时间格式
 	TimeFormat = "20060102150405"
 	// 随机数长度
@@ -24,6 +28,21 @@ var (
 // 格式: ORD + 时间戳(14位) + 用户ID(4位) + 序号(4位)
 // 例如: ORD2024031512345612345678
 func GenerateOrderNo(userID uint64) string {
+	return generateNo(OrderPrefix, userID)
+}
+
+// GenerateSubscriptionNo 生成唯一的订阅单号，格式与订单号相同，前缀为 SUB
+func GenerateSubscriptionNo(userID uint64) string {
+	return generateNo(SubscriptionPrefix, userID)
+}
+
+// GeneratePurchaseOrderNo 生成唯一的采购单号，格式与订单号相同，前缀为 PUR，用户ID为创建采购单的管理员
+func GeneratePurchaseOrderNo(userID uint64) string {
+	return generateNo(PurchaseOrderPrefix, userID)
+}
+
+// generateNo 生成 前缀 + 时间戳(14位) + 用户ID(4位) + 序号(4位) 格式的单号
+func generateNo(prefix string, userID uint64) string {
 	// 获取当前时间
 	now := time.Now()
 
@@ -38,9 +57,28 @@ func GenerateOrderNo(userID uint64) string {
 
 	// 组合订单号
 	return fmt.Sprintf("%s%s%s%s",
-		OrderPrefix,
+		prefix,
 		now.Format(TimeFormat),
 		userIDStr,
 		seqStr,
 	)
 }
+
+// CartConflicts 返回本次下单的购物车项中已被待付款订单占用的项。购物车项付款后才从购物车扣除，
+// 同一购物车项同时只能有一个待付款订单，避免重复提交生成多个订单占用库存
+func CartConflicts(cartIDs []uint64, pendingCartIDs []uint64) []uint64 {
+	pending := make(map[uint64]bool, len(pendingCartIDs))
+	for _, id := range pendingCartIDs {
+		pending[id] = true
+	}
+
+	var conflicts []uint64
+	for _, id := range cartIDs {
+		if pending[id] {
+			conflicts = append(conflicts, id)
+			// 同一购物车项只返回一次
+			delete(pending, id)
+		}
+	}
+	return conflicts
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"reflect"
 	"regexp"
 	"testing"
 	"time"
@@ -94,3 +95,52 @@ func TestGenerateOrderNo(t *testing.T) {
 		}
 	})
 }
+
+func TestGenerateSubscriptionNo(t *testing.T) {
+	subscriptionNo := GenerateSubscriptionNo(1234)
+
+	matched, err := regexp.MatchString(`^SUB\d{14}1234\d{4}$`, subscriptionNo)
+	if err != nil {
+		t.Fatalf("正则表达式匹配错误: %v", err)
+	}
+	if !matched {
+		t.Errorf("订阅单号格式错误: %s", subscriptionNo)
+	}
+}
+
+func TestGeneratePurchaseOrderNo(t *testing.T) {
+	poNo := GeneratePurchaseOrderNo(1234)
+
+	matched, err := regexp.MatchString(`^PUR\d{14}1234\d{4}$`, poNo)
+	if err != nil {
+		t.Fatalf("正则表达式匹配错误: %v", err)
+	}
+	if !matched {
+		t.Errorf("采购单号格式错误: %s", poNo)
+	}
+}
+
+func TestCartConflicts(t *testing.T) {
+	cartIDs := []uint64{3, 7}
+
+	// 第一次提交：购物车项没有待付款订单
+	if got := CartConflicts(cartIDs, nil); len(got) != 0 {
+		t.Errorf("期望%v，实际%v", []uint64{}, got)
+	}
+
+	// 第一次提交生成的待付款订单占用了这些购物车项，再次提交同一购物车被拒绝
+	pending := cartIDs
+	if got := CartConflicts(cartIDs, pending); !reflect.DeepEqual(got, []uint64{3, 7}) {
+		t.Errorf("期望%v，实际%v", []uint64{3, 7}, got)
+	}
+
+	// 只有部分购物车项被占用时同样冲突，重复的购物车项只返回一次
+	if got := CartConflicts([]uint64{7, 9, 7}, pending); !reflect.DeepEqual(got, []uint64{7}) {
+		t.Errorf("期望%v，实际%v", []uint64{7}, got)
+	}
+
+	// 其他购物车项不受影响
+	if got := CartConflicts([]uint64{9}, pending); len(got) != 0 {
+		t.Errorf("期望%v，实际%v", []uint64{}, got)
+	}
+}
@@ -0,0 +1,16 @@
+package utils
+
+import "math"
+
+// Round 金额四舍五入保留两位小数
+func Round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// ApplyRate 按折扣率计算折后价，如 ApplyRate(100, 0.95) = 95
+func ApplyRate(price float64, rate float64) float64 {
+	if rate <= 0 || rate >= 1 {
+		return price
+	}
+	return Round(price * rate)
+}
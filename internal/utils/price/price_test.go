@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestRound(t *testing.T) {
+	cases := map[float64]float64{
+		1.006:  1.01,
+		19.994: 19.99,
+		0:      0,
+		188:    188,
+	}
+	for input, expected := range cases {
+		if got := Round(input); got != expected {
+			t.Errorf("Round(%v) 期望%v，实际%v", input, expected, got)
+		}
+	}
+}
+
+func TestApplyRate(t *testing.T) {
+	if got := ApplyRate(188, 0.95); got != 178.6 {
+		t.Errorf("期望178.6，实际%v", got)
+	}
+	// 折扣率无效时返回原价
+	if got := ApplyRate(188, 0); got != 188 {
+		t.Errorf("期望188，实际%v", got)
+	}
+	if got := ApplyRate(188, 1); got != 188 {
+		t.Errorf("期望188，实际%v", got)
+	}
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"math"
+	"sort"
+
+	priceutils "github.com/colinjuang/shop-go/internal/utils/price"
+)
+
+// Line 参与促销计算的商品行
+type Line struct {
+	ProductID uint64  // 商品ID，同一商品可能因祝福语不同分为多行
+	UnitPrice float64 // 成交单价
+	Quantity  int
+	Amount    float64 // 可优惠的金额，叠加多个活动时为扣除前面活动优惠后的金额
+}
+
+// Total 各行可优惠金额合计
+func Total(lines []Line) float64 {
+	var total float64
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return priceutils.Round(total)
+}
+
+// Reduction 满减：满 threshold 元减 discount 元，repeatable 时每满 threshold 元减一次，优惠不超过金额
+func Reduction(amount, threshold, discount float64, repeatable bool) float64 {
+	if threshold <= 0 || discount <= 0 || amount < threshold {
+		return 0
+	}
+	times := 1.0
+	if repeatable {
+		// 避免浮点误差少算一次
+		times = math.Floor(amount/threshold + 1e-9)
+	}
+	return min(priceutils.Round(discount*times), amount)
+}
+
+// Percentage 满折：满 threshold 元按 rate 折扣，如 rate 为0.8时优惠金额的两成；threshold 为0表示不设门槛
+func Percentage(amount, threshold, rate float64) float64 {
+	if rate <= 0 || rate >= 1 || amount <= 0 || amount < threshold {
+		return 0
+	}
+	return priceutils.Round(amount * (1 - rate))
+}
+
+// BuyFree 买N送M：所有商品按单价从高到低排列，每 buy+free 件中价格最低的 free 件免费，返回各行的优惠金额
+func BuyFree(lines []Line, buy, free int) []float64 {
+	discounts := make([]float64, len(lines))
+	if buy <= 0 || free <= 0 {
+		return discounts
+	}
+
+	type unit struct {
+		line  int
+		price float64
+	}
+	var units []unit
+	for i, line := range lines {
+		for j := 0; j < line.Quantity; j++ {
+			units = append(units, unit{line: i, price: line.UnitPrice})
+		}
+	}
+	sort.SliceStable(units, func(a, b int) bool {
+		return units[a].price > units[b].price
+	})
+
+	group := buy + free
+	for start := 0; start+group <= len(units); start += group {
+		for _, u := range units[start+buy : start+group] {
+			discounts[u.line] += u.price
+		}
+	}
+	return capDiscounts(lines, discounts)
+}
+
+// SecondHalf 第二件半价：同一商品的各行合并计算件数，每两件中的第二件按半价计算，返回各行的优惠金额。
+// 半价的件数按单价从高到低排列后取最后的几件，优惠计入这些件所在的行
+func SecondHalf(lines []Line) []float64 {
+	discounts := make([]float64, len(lines))
+
+	type unit struct {
+		line  int
+		price float64
+	}
+	var productIDs []uint64
+	units := make(map[uint64][]unit)
+	for i, line := range lines {
+		if _, ok := units[line.ProductID]; !ok {
+			productIDs = append(productIDs, line.ProductID)
+		}
+		for j := 0; j < line.Quantity; j++ {
+			units[line.ProductID] = append(units[line.ProductID], unit{line: i, price: line.UnitPrice})
+		}
+	}
+
+	for _, productID := range productIDs {
+		productUnits := units[productID]
+		sort.SliceStable(productUnits, func(a, b int) bool {
+			return productUnits[a].price > productUnits[b].price
+		})
+		for _, u := range productUnits[len(productUnits)-len(productUnits)/2:] {
+			discounts[u.line] += u.price / 2
+		}
+	}
+	return capDiscounts(lines, discounts)
+}
+
+// Allocate 按可优惠金额的比例把满减、满折的优惠分摊到各行，最后一行取余数，保证合计等于优惠金额
+func Allocate(discount float64, lines []Line) []float64 {
+	allocated := make([]float64, len(lines))
+	total := Total(lines)
+	if discount <= 0 || total <= 0 {
+		return allocated
+	}
+
+	remaining := priceutils.Round(discount)
+	for i, line := range lines {
+		if i == len(lines)-1 {
+			allocated[i] = remaining
+			break
+		}
+		allocated[i] = min(priceutils.Round(discount*line.Amount/total), remaining)
+		remaining = priceutils.Round(remaining - allocated[i])
+	}
+	return allocated
+}
+
+// capDiscounts 优惠金额保留两位小数，且不超过各行的可优惠金额
+func capDiscounts(lines []Line, discounts []float64) []float64 {
+	for i := range discounts {
+		discounts[i] = min(priceutils.Round(discounts[i]), lines[i].Amount)
+	}
+	return discounts
+}
@@ -0,0 +1,134 @@
+package utils
+
+import "testing"
+
+func TestReduction(t *testing.T) {
+	cases := []struct {
+		name       string
+		amount     float64
+		threshold  float64
+		discount   float64
+		repeatable bool
+		expected   float64
+	}{
+		{"未满门槛", 99, 100, 20, false, 0},
+		{"满门槛", 150, 100, 20, false, 20},
+		{"每满减", 350, 100, 20, true, 60},
+		{"正好整倍", 300, 100, 20, true, 60},
+		{"不超过金额", 100, 100, 120, false, 100},
+	}
+	for _, c := range cases {
+		if got := Reduction(c.amount, c.threshold, c.discount, c.repeatable); got != c.expected {
+			t.Errorf("%s：期望%v，实际%v", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	if got := Percentage(200, 100, 0.8); got != 40 {
+		t.Errorf("期望40，实际%v", got)
+	}
+	if got := Percentage(99, 100, 0.8); got != 0 {
+		t.Errorf("期望0，实际%v", got)
+	}
+	if got := Percentage(33.33, 0, 0.9); got != 3.33 {
+		t.Errorf("期望3.33，实际%v", got)
+	}
+	if got := Percentage(100, 0, 1); got != 0 {
+		t.Errorf("期望0，实际%v", got)
+	}
+}
+
+func TestBuyFree(t *testing.T) {
+	// 买2送1：5件按单价排列为 50 50 30 30 10，第一组最便宜的30免费，剩余两件不满一组
+	lines := []Line{
+		{UnitPrice: 50, Quantity: 2, Amount: 100},
+		{UnitPrice: 30, Quantity: 2, Amount: 60},
+		{UnitPrice: 10, Quantity: 1, Amount: 10},
+	}
+	got := BuyFree(lines, 2, 1)
+	expected := []float64{0, 30, 0}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("第%d行：期望%v，实际%v", i, expected[i], got[i])
+		}
+	}
+
+	// 6件正好两组，每组最便宜的一件免费
+	lines = []Line{
+		{UnitPrice: 50, Quantity: 3, Amount: 150},
+		{UnitPrice: 10, Quantity: 3, Amount: 30},
+	}
+	got = BuyFree(lines, 2, 1)
+	if got[0] != 50 || got[1] != 10 {
+		t.Errorf("期望[50 10]，实际%v", got)
+	}
+}
+
+func TestSecondHalf(t *testing.T) {
+	lines := []Line{
+		{ProductID: 1, UnitPrice: 39.9, Quantity: 3, Amount: 119.7},
+		{ProductID: 2, UnitPrice: 20, Quantity: 1, Amount: 20},
+		{ProductID: 3, UnitPrice: 10, Quantity: 4, Amount: 5},
+	}
+	got := SecondHalf(lines)
+	expected := []float64{19.95, 0, 5}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("第%d行：期望%v，实际%v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestSecondHalfAcrossLines(t *testing.T) {
+	// 同一商品因祝福语不同分为两行，各1件时合计2件，第二件半价计入后一行
+	lines := []Line{
+		{ProductID: 1, UnitPrice: 39.9, Quantity: 1, Amount: 39.9},
+		{ProductID: 2, UnitPrice: 20, Quantity: 1, Amount: 20},
+		{ProductID: 1, UnitPrice: 39.9, Quantity: 1, Amount: 39.9},
+	}
+	got := SecondHalf(lines)
+	expected := []float64{0, 0, 19.95}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("第%d行：期望%v，实际%v", i, expected[i], got[i])
+		}
+	}
+
+	// 合计3件时只有1件半价，合计4件时2件半价
+	got = SecondHalf([]Line{
+		{ProductID: 1, UnitPrice: 10, Quantity: 2, Amount: 20},
+		{ProductID: 1, UnitPrice: 10, Quantity: 1, Amount: 10},
+	})
+	if got[0] != 0 || got[1] != 5 {
+		t.Errorf("期望%v，实际%v", []float64{0, 5}, got)
+	}
+	got = SecondHalf([]Line{
+		{ProductID: 1, UnitPrice: 10, Quantity: 3, Amount: 30},
+		{ProductID: 1, UnitPrice: 10, Quantity: 1, Amount: 10},
+	})
+	if got[0] != 5 || got[1] != 5 {
+		t.Errorf("期望%v，实际%v", []float64{5, 5}, got)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	lines := []Line{
+		{Amount: 100},
+		{Amount: 50},
+		{Amount: 50},
+	}
+	got := Allocate(30, lines)
+	expected := []float64{15, 7.5, 7.5}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("第%d行：期望%v，实际%v", i, expected[i], got[i])
+		}
+	}
+
+	// 除不尽时最后一行取余数
+	got = Allocate(10, []Line{{Amount: 1}, {Amount: 1}, {Amount: 1}})
+	if got[0] != 3.33 || got[1] != 3.33 || got[2] != 3.34 {
+		t.Errorf("期望[3.33 3.33 3.34]，实际%v", got)
+	}
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// maxProductsPerUser 每个用户参与计算的最近购买商品数上限，避免个别大客户产生过多商品对
+const maxProductsPerUser = 50
+
+// Purchase 一条购买记录
+type Purchase struct {
+	UserID    uint64
+	ProductID uint64
+	Time      time.Time
+}
+
+// Similar 相似商品及相似度
+type Similar struct {
+	ProductID uint64
+	Score     float64
+}
+
+// Decay 按半衰期计算时间衰减权重，age 为购买距今的时长，经过一个半衰期权重减半
+func Decay(age, halfLife time.Duration) float64 {
+	if halfLife <= 0 || age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+// CoOccurrence 按同一用户购买过的商品计算商品两两之间的相似度，返回每个商品相似度最高的 topN 个商品。
+// 每次购买按时间衰减加权，商品对的共现权重取两次购买中较旧一次的权重；
+// 相似度为共现权重除以两个商品购买权重的几何平均，降低热门商品的影响
+func CoOccurrence(purchases []Purchase, now time.Time, halfLife time.Duration, topN int) map[uint64][]Similar {
+	// 每个用户每个商品只保留最近一次购买
+	latest := make(map[uint64]map[uint64]time.Time)
+	for _, p := range purchases {
+		products, ok := latest[p.UserID]
+		if !ok {
+			products = make(map[uint64]time.Time)
+			latest[p.UserID] = products
+		}
+		if t, ok := products[p.ProductID]; !ok || p.Time.After(t) {
+			products[p.ProductID] = p.Time
+		}
+	}
+
+	weights := make(map[uint64]float64)
+	pairs := make(map[[2]uint64]float64)
+	for _, products := range latest {
+		items := make([]Similar, 0, len(products))
+		for productID, t := range products {
+			items = append(items, Similar{ProductID: productID, Score: Decay(now.Sub(t), halfLife)})
+		}
+		sortSimilar(items)
+		if len(items) > maxProductsPerUser {
+			items = items[:maxProductsPerUser]
+		}
+
+		for i, a := range items {
+			weights[a.ProductID] += a.Score
+			for _, b := range items[i+1:] {
+				w := math.Min(a.Score, b.Score)
+				pairs[[2]uint64{a.ProductID, b.ProductID}] += w
+				pairs[[2]uint64{b.ProductID, a.ProductID}] += w
+			}
+		}
+	}
+
+	result := make(map[uint64][]Similar)
+	for pair, w := range pairs {
+		score := w / math.Sqrt(weights[pair[0]]*weights[pair[1]])
+		result[pair[0]] = append(result[pair[0]], Similar{ProductID: pair[1], Score: score})
+	}
+	for productID, similars := range result {
+		sortSimilar(similars)
+		if topN > 0 && len(similars) > topN {
+			result[productID] = similars[:topN]
+		}
+	}
+	return result
+}
+
+// sortSimilar 按得分降序排序，得分相同时按商品ID升序，保证结果稳定
+func sortSimilar(items []Similar) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].ProductID < items[j].ProductID
+	})
+}
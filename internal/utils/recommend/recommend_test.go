@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDecay(t *testing.T) {
+	halfLife := 30 * 24 * time.Hour
+	if got := Decay(0, halfLife); got != 1 {
+		t.Errorf("期望1，实际%v", got)
+	}
+	if got := Decay(halfLife, halfLife); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("期望0.5，实际%v", got)
+	}
+	if got := Decay(2*halfLife, halfLife); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("期望0.25，实际%v", got)
+	}
+	// 半衰期无效时不衰减
+	if got := Decay(halfLife, 0); got != 1 {
+		t.Errorf("期望1，实际%v", got)
+	}
+}
+
+func TestCoOccurrence(t *testing.T) {
+	now := time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC)
+	purchases := []Purchase{
+		// 用户1、2都买了花束1和花瓶2，用户3买了花束1和巧克力3
+		{UserID: 1, ProductID: 1, Time: now},
+		{UserID: 1, ProductID: 2, Time: now},
+		{UserID: 2, ProductID: 1, Time: now},
+		{UserID: 2, ProductID: 2, Time: now},
+		{UserID: 3, ProductID: 1, Time: now},
+		{UserID: 3, ProductID: 3, Time: now},
+		// 同一用户重复购买只计一次
+		{UserID: 3, ProductID: 3, Time: now.Add(-time.Hour)},
+		// 只买了一个商品的用户不产生商品对
+		{UserID: 4, ProductID: 4, Time: now},
+	}
+
+	result := CoOccurrence(purchases, now, 30*24*time.Hour, 10)
+
+	similars := result[1]
+	if len(similars) != 2 {
+		t.Fatalf("期望2个相似商品，实际%d", len(similars))
+	}
+	if similars[0].ProductID != 2 || similars[1].ProductID != 3 {
+		t.Errorf("期望相似商品顺序为2、3，实际%v", similars)
+	}
+	// 花束1被3人购买，花瓶2被2人购买且都与花束1同时购买：2/sqrt(3*2)
+	if math.Abs(similars[0].Score-2/math.Sqrt(6)) > 1e-9 {
+		t.Errorf("相似度计算错误：%v", similars[0].Score)
+	}
+	if _, ok := result[4]; ok {
+		t.Errorf("单独购买的商品不应有相似商品")
+	}
+}
+
+func TestCoOccurrenceTopN(t *testing.T) {
+	now := time.Now()
+	var purchases []Purchase
+	for productID := uint64(1); productID <= 5; productID++ {
+		purchases = append(purchases, Purchase{UserID: 1, ProductID: productID, Time: now})
+	}
+
+	result := CoOccurrence(purchases, now, 0, 2)
+	if len(result[1]) != 2 {
+		t.Errorf("期望保留2个相似商品，实际%d", len(result[1]))
+	}
+}